@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+// ListAuditLogs returns one page of changes the authenticated user made or
+// was affected by, newest first.
+func (c *Client) ListAuditLogs(ctx context.Context, opts AuditLogOptions) ([]AuditLogEntry, error) {
+	return c.listAuditLogs(ctx, "/api/audit-logs", opts)
+}
+
+// AuditLogs iterates over all of the user's audit entries, newest first.
+func (c *Client) AuditLogs(ctx context.Context, opts AuditLogOptions) iter.Seq2[AuditLogEntry, error] {
+	return paginateAuditLogs(opts, func(opts AuditLogOptions) ([]AuditLogEntry, error) {
+		return c.ListAuditLogs(ctx, opts)
+	})
+}
+
+// EventAuditLogs returns the full history of one of the user's events.
+func (c *Client) EventAuditLogs(ctx context.Context, eventID int64) ([]AuditLogEntry, error) {
+	return c.listAuditLogs(ctx, eventPath(eventID)+"/audit-logs", AuditLogOptions{})
+}
+
+// ListAllAuditLogs returns one page of every user's audit entries. It
+// requires an admin account.
+func (c *Client) ListAllAuditLogs(ctx context.Context, opts AuditLogOptions) ([]AuditLogEntry, error) {
+	return c.listAuditLogs(ctx, "/api/admin/audit-logs", opts)
+}
+
+// AllAuditLogs iterates over every user's audit entries. It requires an admin
+// account.
+func (c *Client) AllAuditLogs(ctx context.Context, opts AuditLogOptions) iter.Seq2[AuditLogEntry, error] {
+	return paginateAuditLogs(opts, func(opts AuditLogOptions) ([]AuditLogEntry, error) {
+		return c.ListAllAuditLogs(ctx, opts)
+	})
+}
+
+func (c *Client) listAuditLogs(ctx context.Context, path string, opts AuditLogOptions) ([]AuditLogEntry, error) {
+	var entries []AuditLogEntry
+	if err := c.do(ctx, http.MethodGet, path, opts.query(), nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// SignUp registers a new user and authenticates the client as them.
+func (c *Client) SignUp(ctx context.Context, input SignUpInput) (*AuthResult, error) {
+	var result AuthResult
+	if err := c.do(ctx, http.MethodPost, "/api/signup", nil, input, &result); err != nil {
+		return nil, err
+	}
+	c.SetToken(result.Token)
+	return &result, nil
+}
+
+// Login authenticates the client with an email and password.
+func (c *Client) Login(ctx context.Context, input LoginInput) (*AuthResult, error) {
+	var result AuthResult
+	if err := c.do(ctx, http.MethodPost, "/api/login", nil, input, &result); err != nil {
+		return nil, err
+	}
+	c.SetToken(result.Token)
+	return &result, nil
+}
+
+// Logout clears the session cookie and forgets the client's token.
+func (c *Client) Logout(ctx context.Context) error {
+	if err := c.do(ctx, http.MethodPost, "/api/logout", nil, nil, nil); err != nil {
+		return err
+	}
+	c.SetToken("")
+	return nil
+}
@@ -0,0 +1,206 @@
+// Package client is a Go client for the SlotSwapper HTTP API.
+//
+// A Client authenticates with the token returned by SignUp or Login, sending
+// it as a bearer token by default or relying on the access_token cookie when
+// created WithCookieAuth. Every method takes a context, and failed requests
+// return an *Error that matches one of the Err kinds with errors.Is:
+//
+//	c := client.New("https://slotswapper.example.com")
+//	if _, err := c.Login(ctx, client.LoginInput{Email: email, Password: password}); err != nil {
+//		return err
+//	}
+//	for event, err := range c.Events(ctx, client.EventListOptions{}) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(event.Title)
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Client calls the SlotSwapper API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	cookieAuth bool
+	retry      RetryPolicy
+
+	mu    sync.RWMutex
+	token string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used to send requests. The default is
+// a client without a timeout; rely on contexts or set one here.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithToken authenticates requests with an existing access token, e.g. one
+// issued to a service account.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithCookieAuth authenticates with the access_token cookie set by SignUp and
+// Login instead of an Authorization header. A cookie jar is added to the HTTP
+// client if it has none.
+func WithCookieAuth() Option {
+	return func(c *Client) { c.cookieAuth = true }
+}
+
+// WithRetry retries idempotent requests according to policy. Use
+// DefaultRetryPolicy for sensible defaults.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) { c.retry = policy }
+}
+
+// New returns a client for the API served at baseURL, such as
+// https://slotswapper.example.com.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.cookieAuth && c.httpClient.Jar == nil {
+		jar, _ := cookiejar.New(nil)
+		httpClient := *c.httpClient
+		httpClient.Jar = jar
+		c.httpClient = &httpClient
+	}
+	return c
+}
+
+// Token returns the access token the client currently holds, if any.
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// SetToken replaces the access token sent with bearer authentication.
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// Health reports whether the server is up.
+func (c *Client) Health(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/health", nil, nil, nil)
+}
+
+// OpenAPI returns the server's OpenAPI 3.1 document.
+func (c *Client) OpenAPI(ctx context.Context) (json.RawMessage, error) {
+	var doc json.RawMessage
+	if err := c.do(ctx, http.MethodGet, "/api/openapi.json", nil, nil, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// do sends a request with body encoded as JSON and decodes a successful JSON
+// response into out. Idempotent requests are retried according to the
+// client's retry policy.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	return c.doWithHeader(ctx, method, path, query, body, out, nil)
+}
+
+// doIdempotent sends a POST to an endpoint that honours the Idempotency-Key
+// header. Every attempt carries the same fresh key, so the request can be
+// retried like an idempotent one: the server replays the first response
+// instead of repeating the change.
+func (c *Client) doIdempotent(ctx context.Context, path string, body, out any) error {
+	header := http.Header{"Idempotency-Key": {rand.Text()}}
+	return c.doWithHeader(ctx, http.MethodPost, path, nil, body, out, header)
+}
+
+// doWithHeader sends a request with extra headers, such as If-Match.
+func (c *Client) doWithHeader(ctx context.Context, method, path string, query url.Values, body, out any, header http.Header) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	attempts := 1
+	if (isIdempotent(method) || header.Get("Idempotency-Key") != "") && c.retry.MaxAttempts > 1 {
+		attempts = c.retry.MaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, method, target, payload, header)
+		if attempt < attempts && ctx.Err() == nil && shouldRetry(resp, err) {
+			wait := c.retry.backoff(attempt, resp)
+			if resp != nil {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			if err := sleep(ctx, wait); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		return decodeResponse(resp, out)
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, target string, payload []byte, header http.Header) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token := c.Token(); token != "" && !c.cookieAuth {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.httpClient.Do(req)
+}
+
+func decodeResponse(resp *http.Response, out any) error {
+	if resp.StatusCode >= http.StatusBadRequest {
+		return newResponseError(resp)
+	}
+	if out == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,605 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"slotswapper/client"
+	"slotswapper/internal/api"
+	"slotswapper/internal/crypto"
+	"slotswapper/internal/db"
+	"slotswapper/internal/repository"
+	"slotswapper/internal/services"
+)
+
+// newTestServer serves the real API on an in-memory database, wrapped in the
+// same middleware as the production binary.
+func newTestServer(t *testing.T) (*httptest.Server, *db.Queries) {
+	t.Helper()
+	queries := repository.SetupTestDB(t)
+	userRepo := repository.NewUserRepository(queries)
+	eventRepo := repository.NewEventRepository(queries)
+	swapRepo := repository.NewSwapRequestRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	transactor := repository.NewTransactor(queries)
+	jwtManager := crypto.NewJWT("test-jwt-secret", 10*time.Minute)
+
+	server := api.NewServer(nil,
+		services.NewAuthService(userRepo, auditRepo, transactor, crypto.NewPassword(), jwtManager),
+		services.NewUserService(userRepo, eventRepo, swapRepo, repository.NewAccessTokenRepository(queries), auditRepo, repository.NewNotificationRepository(queries), transactor, crypto.NewPassword()),
+		services.NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(queries), transactor),
+		services.NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(queries), transactor),
+		services.NewAuditService(auditRepo, userRepo),
+		services.NewAccessTokenService(repository.NewAccessTokenRepository(queries), auditRepo, transactor),
+		services.NewIdempotencyService(repository.NewIdempotencyKeyRepository(queries), transactor, 0),
+		services.NewNotificationService(repository.NewNotificationRepository(queries)),
+		jwtManager,
+	)
+	router := http.NewServeMux()
+	server.RegisterRoutes(router)
+
+	ts := httptest.NewServer(api.RequestMetadataMiddleware(nil)(api.TimeZoneMiddleware(router)))
+	t.Cleanup(ts.Close)
+	return ts, queries
+}
+
+func signUp(t *testing.T, c *client.Client, name, email string) *client.AuthResult {
+	t.Helper()
+	result, err := c.SignUp(context.Background(), client.SignUpInput{Name: name, Email: email, Password: "password123"})
+	if err != nil {
+		t.Fatalf("sign up %s: %v", email, err)
+	}
+	return result
+}
+
+func createEvent(t *testing.T, c *client.Client, title string, start time.Time, status string) *client.Event {
+	t.Helper()
+	event, err := c.CreateEvent(context.Background(), client.CreateEventInput{
+		Title:     title,
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+		Status:    status,
+	})
+	if err != nil {
+		t.Fatalf("create event %s: %v", title, err)
+	}
+	return event
+}
+
+func TestClient_Auth(t *testing.T) {
+	ts, _ := newTestServer(t)
+	ctx := context.Background()
+
+	t.Run("bearer token from sign up", func(t *testing.T) {
+		c := client.New(ts.URL)
+		result := signUp(t, c, "Alice", "alice@example.com")
+		if c.Token() != result.Token || result.Token == "" {
+			t.Fatalf("expected client to hold the issued token")
+		}
+
+		me, err := c.Me(ctx)
+		if err != nil {
+			t.Fatalf("Me: %v", err)
+		}
+		if me.Email != "alice@example.com" || me.ID != result.User.ID {
+			t.Errorf("unexpected profile %+v", me)
+		}
+
+		// Another client can reuse the token.
+		other := client.New(ts.URL, client.WithToken(result.Token))
+		if _, err := other.Me(ctx); err != nil {
+			t.Errorf("Me with WithToken: %v", err)
+		}
+	})
+
+	t.Run("cookie auth", func(t *testing.T) {
+		c := client.New(ts.URL, client.WithCookieAuth())
+		if _, err := c.Login(ctx, client.LoginInput{Email: "alice@example.com", Password: "password123"}); err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		if _, err := c.Me(ctx); err != nil {
+			t.Fatalf("Me with cookie: %v", err)
+		}
+
+		if err := c.Logout(ctx); err != nil {
+			t.Fatalf("Logout: %v", err)
+		}
+		if _, err := c.Me(ctx); !errors.Is(err, client.ErrUnauthorized) {
+			t.Errorf("expected ErrUnauthorized after logout, got %v", err)
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		c := client.New(ts.URL)
+		_, err := c.Login(ctx, client.LoginInput{Email: "alice@example.com", Password: "wrong-password"})
+		if !errors.Is(err, client.ErrUnauthorized) {
+			t.Errorf("expected ErrUnauthorized, got %v", err)
+		}
+		if c.Token() != "" {
+			t.Errorf("expected no token after failed login")
+		}
+	})
+
+	t.Run("time zone and public profile", func(t *testing.T) {
+		c := client.New(ts.URL)
+		bob := signUp(t, c, "Bob", "bob@example.com")
+
+		me, err := c.SetTimeZone(ctx, "Europe/Berlin")
+		if err != nil {
+			t.Fatalf("SetTimeZone: %v", err)
+		}
+		if me.TimeZone != "Europe/Berlin" {
+			t.Errorf("expected Europe/Berlin, got %s", me.TimeZone)
+		}
+
+		profile, err := c.User(ctx, bob.User.ID)
+		if err != nil {
+			t.Fatalf("User: %v", err)
+		}
+		if profile.Name != "Bob" {
+			t.Errorf("expected Bob, got %s", profile.Name)
+		}
+	})
+
+	t.Run("health and OpenAPI", func(t *testing.T) {
+		c := client.New(ts.URL)
+		if err := c.Health(ctx); err != nil {
+			t.Errorf("Health: %v", err)
+		}
+		doc, err := c.OpenAPI(ctx)
+		if err != nil || len(doc) == 0 {
+			t.Errorf("OpenAPI: %v", err)
+		}
+	})
+}
+
+func TestClient_Events(t *testing.T) {
+	ts, _ := newTestServer(t)
+	ctx := context.Background()
+	c := client.New(ts.URL)
+	signUp(t, c, "Alice", "alice@example.com")
+	start := time.Date(2030, time.January, 7, 9, 0, 0, 0, time.UTC)
+
+	t.Run("create, get, update, status and delete", func(t *testing.T) {
+		event := createEvent(t, c, "Standup", start, client.StatusBusy)
+
+		got, err := c.GetEvent(ctx, event.ID)
+		if err != nil {
+			t.Fatalf("GetEvent: %v", err)
+		}
+		if got.Title != "Standup" || !got.StartTime.Equal(start) {
+			t.Errorf("unexpected event %+v", got)
+		}
+
+		updated, err := c.UpdateEvent(ctx, event.ID, client.UpdateEventInput{Title: "Retro", StartTime: start, EndTime: start.Add(30 * time.Minute), Version: got.Version})
+		if err != nil {
+			t.Fatalf("UpdateEvent: %v", err)
+		}
+		if updated.Title != "Retro" || updated.Version != got.Version+1 {
+			t.Errorf("expected Retro at the next version, got %+v", updated)
+		}
+		_, err = c.UpdateEvent(ctx, event.ID, client.UpdateEventInput{Title: "Planning", StartTime: start, EndTime: start.Add(30 * time.Minute), Version: got.Version})
+		if !errors.Is(err, client.ErrPreconditionFailed) {
+			t.Errorf("expected ErrPreconditionFailed for a stale version, got %v", err)
+		}
+
+		title := "Planning"
+		patched, err := c.PatchEvent(ctx, event.ID, client.EventPatch{Title: &title, Version: updated.Version})
+		if err != nil {
+			t.Fatalf("PatchEvent: %v", err)
+		}
+		if patched.Event.Title != "Planning" || !patched.Event.EndTime.Equal(updated.EndTime) || patched.SideEffects.StatusChanged {
+			t.Errorf("expected only the title to change, got %+v", patched)
+		}
+
+		swappable, err := c.SetEventStatus(ctx, event.ID, client.StatusSwappable)
+		if err != nil {
+			t.Fatalf("SetEventStatus: %v", err)
+		}
+		if swappable.Status != client.StatusSwappable {
+			t.Errorf("expected SWAPPABLE, got %s", swappable.Status)
+		}
+
+		if err := c.DeleteEvent(ctx, event.ID); err != nil {
+			t.Fatalf("DeleteEvent: %v", err)
+		}
+		if _, err := c.GetEvent(ctx, event.ID); !errors.Is(err, client.ErrNotFound) {
+			t.Errorf("expected ErrNotFound after delete, got %v", err)
+		}
+	})
+
+	t.Run("recurring events", func(t *testing.T) {
+		events, err := c.CreateRecurringEvents(ctx, client.CreateEventInput{
+			Title:     "Gym",
+			StartTime: start.AddDate(0, 1, 0),
+			EndTime:   start.AddDate(0, 1, 0).Add(time.Hour),
+			Status:    client.StatusBusy,
+		}, client.Recurrence{Frequency: "WEEKLY", Count: 3})
+		if err != nil {
+			t.Fatalf("CreateRecurringEvents: %v", err)
+		}
+		if len(events) != 3 {
+			t.Fatalf("expected 3 events, got %d", len(events))
+		}
+		if got := events[2].StartTime.Sub(events[0].StartTime); got != 14*24*time.Hour {
+			t.Errorf("expected weekly spacing, got %v", got)
+		}
+	})
+
+	t.Run("iterator follows cursors", func(t *testing.T) {
+		var titles []string
+		for event, err := range c.Events(ctx, client.EventListOptions{PageOptions: client.PageOptions{Limit: 2}}) {
+			if err != nil {
+				t.Fatalf("Events: %v", err)
+			}
+			titles = append(titles, event.Title)
+		}
+		if len(titles) != 3 {
+			t.Errorf("expected the 3 recurring events across pages, got %v", titles)
+		}
+
+		page, err := c.ListEvents(ctx, client.EventListOptions{PageOptions: client.PageOptions{Limit: 2}})
+		if err != nil {
+			t.Fatalf("ListEvents: %v", err)
+		}
+		if len(page.Items) != 2 || page.NextCursor == "" {
+			t.Errorf("expected a first page of 2 with a cursor, got %d items and cursor %q", len(page.Items), page.NextCursor)
+		}
+	})
+
+	t.Run("iterator stops early", func(t *testing.T) {
+		count := 0
+		for range c.Events(ctx, client.EventListOptions{PageOptions: client.PageOptions{Limit: 1}}) {
+			count++
+			break
+		}
+		if count != 1 {
+			t.Errorf("expected to stop after one event, got %d", count)
+		}
+	})
+
+	t.Run("iterator reports errors", func(t *testing.T) {
+		var errs int
+		for _, err := range c.Events(ctx, client.EventListOptions{Sort: "title"}) {
+			if !errors.Is(err, client.ErrValidation) {
+				t.Errorf("expected ErrValidation, got %v", err)
+			}
+			errs++
+		}
+		if errs != 1 {
+			t.Errorf("expected exactly one error, got %d", errs)
+		}
+	})
+}
+
+func TestClient_Swaps(t *testing.T) {
+	ts, queries := newTestServer(t)
+	ctx := context.Background()
+	alice := client.New(ts.URL)
+	aliceUser := signUp(t, alice, "Alice", "alice@example.com").User
+	bob := client.New(ts.URL, client.WithCookieAuth())
+	bobUser := signUp(t, bob, "Bob", "bob@example.com").User
+
+	start := time.Date(2030, time.February, 4, 9, 0, 0, 0, time.UTC)
+	aliceSlot := createEvent(t, alice, "Alice's shift", start, client.StatusSwappable)
+	bobSlot := createEvent(t, bob, "Bob's shift", start.Add(2*time.Hour), client.StatusSwappable)
+
+	var offered []client.SwappableSlot
+	for slot, err := range alice.SwappableSlots(ctx, client.PageOptions{}) {
+		if err != nil {
+			t.Fatalf("SwappableSlots: %v", err)
+		}
+		offered = append(offered, slot)
+	}
+	if len(offered) != 1 || offered[0].ID != bobSlot.ID || offered[0].OwnerName != "Bob" {
+		t.Fatalf("expected Bob's slot to be offered, got %+v", offered)
+	}
+
+	swap, err := alice.RequestSwap(ctx, client.CreateSwapRequestInput{
+		ResponderUserID: bobUser.ID,
+		RequesterSlotID: aliceSlot.ID,
+		ResponderSlotID: bobSlot.ID,
+	})
+	if err != nil {
+		t.Fatalf("RequestSwap: %v", err)
+	}
+
+	incoming, err := bob.ListIncomingSwapRequests(ctx, client.SwapListOptions{})
+	if err != nil {
+		t.Fatalf("ListIncomingSwapRequests: %v", err)
+	}
+	if len(incoming.Items) != 1 || incoming.Items[0].RequesterName != "Alice" {
+		t.Errorf("unexpected incoming requests %+v", incoming.Items)
+	}
+	var outgoing []client.SwapRequestSummary
+	for summary, err := range alice.OutgoingSwapRequests(ctx, client.SwapListOptions{}) {
+		if err != nil {
+			t.Fatalf("OutgoingSwapRequests: %v", err)
+		}
+		outgoing = append(outgoing, summary)
+	}
+	if len(outgoing) != 1 || outgoing[0].ResponderName != "Bob" {
+		t.Errorf("unexpected outgoing requests %+v", outgoing)
+	}
+
+	_, err = alice.RespondToSwap(ctx, swap.ID, client.RespondInput{Status: client.SwapAccepted})
+	if !errors.Is(err, client.ErrForbidden) {
+		t.Errorf("expected the requester to be forbidden from responding, got %v", err)
+	}
+
+	accepted, err := bob.RespondToSwap(ctx, swap.ID, client.RespondInput{Status: client.SwapAccepted})
+	if err != nil {
+		t.Fatalf("RespondToSwap: %v", err)
+	}
+	if accepted.Status != client.SwapAccepted || accepted.ResolvedByUserID == nil || *accepted.ResolvedByUserID != bobUser.ID {
+		t.Errorf("unexpected accepted swap %+v", accepted)
+	}
+
+	_, err = bob.RespondToSwap(ctx, swap.ID, client.RespondInput{Status: client.SwapRejected})
+	if !errors.Is(err, client.ErrInvalidState) {
+		t.Errorf("expected ErrInvalidState for a resolved swap, got %v", err)
+	}
+
+	history, err := alice.ListOutgoingSwapHistory(ctx, client.SwapHistoryOptions{Status: client.SwapAccepted})
+	if err != nil {
+		t.Fatalf("ListOutgoingSwapHistory: %v", err)
+	}
+	if len(history.Items) != 1 || history.Items[0].ResolvedByName != "Bob" {
+		t.Errorf("unexpected outgoing history %+v", history.Items)
+	}
+	var received int
+	for _, err := range bob.IncomingSwapHistory(ctx, client.SwapHistoryOptions{CounterpartyID: aliceUser.ID}) {
+		if err != nil {
+			t.Fatalf("IncomingSwapHistory: %v", err)
+		}
+		received++
+	}
+	if received != 1 {
+		t.Errorf("expected 1 incoming history entry, got %d", received)
+	}
+
+	t.Run("audit logs", func(t *testing.T) {
+		entries, err := alice.EventAuditLogs(ctx, aliceSlot.ID)
+		if err != nil {
+			t.Fatalf("EventAuditLogs: %v", err)
+		}
+		if len(entries) == 0 {
+			t.Fatalf("expected audit entries for the swapped event")
+		}
+
+		var all []client.AuditLogEntry
+		for entry, err := range alice.AuditLogs(ctx, client.AuditLogOptions{Limit: 1}) {
+			if err != nil {
+				t.Fatalf("AuditLogs: %v", err)
+			}
+			all = append(all, entry)
+		}
+		if len(all) < 3 {
+			t.Errorf("expected to page through several entries, got %d", len(all))
+		}
+		for i := 1; i < len(all); i++ {
+			if all[i].ID >= all[i-1].ID {
+				t.Fatalf("expected entries newest first, got %d after %d", all[i].ID, all[i-1].ID)
+			}
+		}
+
+		if _, err := alice.ListAllAuditLogs(ctx, client.AuditLogOptions{}); !errors.Is(err, client.ErrForbidden) {
+			t.Errorf("expected ErrForbidden for a non-admin, got %v", err)
+		}
+		if err := queries.UpdateUserIsAdmin(ctx, db.UpdateUserIsAdminParams{IsAdmin: true, ID: aliceUser.ID}); err != nil {
+			t.Fatalf("promote alice: %v", err)
+		}
+		events, err := alice.ListAllAuditLogs(ctx, client.AuditLogOptions{EntityType: "event", ActorUserID: bobUser.ID})
+		if err != nil {
+			t.Fatalf("ListAllAuditLogs: %v", err)
+		}
+		for _, entry := range events {
+			if entry.EntityType != "event" || entry.ActorUserID != bobUser.ID {
+				t.Errorf("filter not applied to %+v", entry)
+			}
+		}
+	})
+
+	t.Run("withdraw", func(t *testing.T) {
+		mine := createEvent(t, alice, "Alice's second shift", start.Add(24*time.Hour), client.StatusSwappable)
+		theirs := createEvent(t, bob, "Bob's second shift", start.Add(26*time.Hour), client.StatusSwappable)
+		swap, err := alice.RequestSwap(ctx, client.CreateSwapRequestInput{
+			ResponderUserID: bobUser.ID,
+			RequesterSlotID: mine.ID,
+			ResponderSlotID: theirs.ID,
+		})
+		if err != nil {
+			t.Fatalf("RequestSwap: %v", err)
+		}
+
+		if _, err := bob.WithdrawSwapRequest(ctx, swap.ID); !errors.Is(err, client.ErrForbidden) {
+			t.Errorf("expected the responder to be forbidden from withdrawing, got %v", err)
+		}
+		withdrawn, err := alice.WithdrawSwapRequest(ctx, swap.ID)
+		if err != nil {
+			t.Fatalf("WithdrawSwapRequest: %v", err)
+		}
+		if withdrawn.Status != client.SwapWithdrawn {
+			t.Errorf("expected WITHDRAWN, got %s", withdrawn.Status)
+		}
+		released, err := alice.GetEvent(ctx, mine.ID)
+		if err != nil {
+			t.Fatalf("GetEvent: %v", err)
+		}
+		if released.Status != client.StatusSwappable {
+			t.Errorf("expected Alice's slot to be back on offer, got %s", released.Status)
+		}
+	})
+
+	t.Run("giveaway", func(t *testing.T) {
+		spare := createEvent(t, bob, "Bob's spare shift", start.Add(48*time.Hour), client.StatusBusy)
+		given, err := bob.GiveAwayEvent(ctx, spare.ID, client.GiveawayOwnerPicks)
+		if err != nil {
+			t.Fatalf("GiveAwayEvent: %v", err)
+		}
+		if given.Status != client.StatusSwappable || given.Giveaway == nil || *given.Giveaway != client.GiveawayOwnerPicks {
+			t.Fatalf("expected the shift to be given away, got %+v", given)
+		}
+
+		claim, err := alice.ClaimSlot(ctx, spare.ID, false)
+		if err != nil {
+			t.Fatalf("ClaimSlot: %v", err)
+		}
+		if claim.Kind != client.KindTransfer || claim.Status != client.SwapPending || claim.RequesterSlotID != 0 {
+			t.Errorf("expected a pending transfer, got %+v", claim)
+		}
+		incoming, err := bob.ListIncomingSwapRequests(ctx, client.SwapListOptions{})
+		if err != nil {
+			t.Fatalf("ListIncomingSwapRequests: %v", err)
+		}
+		if len(incoming.Items) != 1 || incoming.Items[0].Kind != client.KindTransfer || incoming.Items[0].RequesterEventTitle != "" {
+			t.Fatalf("expected the claim among Bob's requests, got %+v", incoming.Items)
+		}
+
+		if _, err := bob.RespondToSwap(ctx, claim.ID, client.RespondInput{Status: client.SwapAccepted}); err != nil {
+			t.Fatalf("RespondToSwap: %v", err)
+		}
+		taken, err := alice.GetEvent(ctx, spare.ID)
+		if err != nil {
+			t.Fatalf("GetEvent: %v", err)
+		}
+		if taken.UserID != aliceUser.ID || taken.Giveaway != nil {
+			t.Errorf("expected the shift to be Alice's, got %+v", taken)
+		}
+	})
+}
+
+func TestClient_Cancellation(t *testing.T) {
+	ts, _ := newTestServer(t)
+	ctx := context.Background()
+	alice := client.New(ts.URL)
+	signUp(t, alice, "Alice", "alice@example.com")
+	bob := client.New(ts.URL)
+	bobUser := signUp(t, bob, "Bob", "bob@example.com").User
+
+	start := time.Date(2030, time.March, 4, 9, 0, 0, 0, time.UTC)
+	aliceSlot := createEvent(t, alice, "Alice's shift", start, client.StatusSwappable)
+	bobSlot := createEvent(t, bob, "Bob's shift", start.Add(2*time.Hour), client.StatusSwappable)
+	swap, err := alice.RequestSwap(ctx, client.CreateSwapRequestInput{
+		ResponderUserID: bobUser.ID,
+		RequesterSlotID: aliceSlot.ID,
+		ResponderSlotID: bobSlot.ID,
+	})
+	if err != nil {
+		t.Fatalf("RequestSwap: %v", err)
+	}
+
+	if err := bob.DeactivateAccount(ctx); err != nil {
+		t.Fatalf("DeactivateAccount: %v", err)
+	}
+	if _, err := bob.Me(ctx); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("expected a deactivated account to be signed out, got %v", err)
+	}
+
+	history, err := alice.ListOutgoingSwapHistory(ctx, client.SwapHistoryOptions{Status: client.SwapCancelled})
+	if err != nil {
+		t.Fatalf("ListOutgoingSwapHistory: %v", err)
+	}
+	if len(history.Items) != 1 || history.Items[0].ID != swap.ID || history.Items[0].CancelReason == nil || *history.Items[0].CancelReason != "user_deactivated" {
+		t.Fatalf("unexpected cancelled history %+v", history.Items)
+	}
+	released, err := alice.GetEvent(ctx, aliceSlot.ID)
+	if err != nil {
+		t.Fatalf("GetEvent: %v", err)
+	}
+	if released.Status != client.StatusSwappable {
+		t.Errorf("expected Alice's slot to be released, got %s", released.Status)
+	}
+
+	var unread []client.Notification
+	for notification, err := range alice.Notifications(ctx, client.NotificationListOptions{UnreadOnly: true}) {
+		if err != nil {
+			t.Fatalf("Notifications: %v", err)
+		}
+		unread = append(unread, notification)
+	}
+	if len(unread) != 1 || unread[0].Reason != "user_deactivated" {
+		t.Fatalf("unexpected notifications %+v", unread)
+	}
+	read, err := alice.MarkNotificationRead(ctx, unread[0].ID)
+	if err != nil {
+		t.Fatalf("MarkNotificationRead: %v", err)
+	}
+	if read.ReadAt == nil {
+		t.Errorf("expected the notification to be read, got %+v", read)
+	}
+	page, err := alice.ListNotifications(ctx, client.NotificationListOptions{UnreadOnly: true})
+	if err != nil {
+		t.Fatalf("ListNotifications: %v", err)
+	}
+	if len(page.Items) != 0 {
+		t.Errorf("expected no unread notifications, got %+v", page.Items)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	ts, _ := newTestServer(t)
+	ctx := context.Background()
+	c := client.New(ts.URL)
+	signUp(t, c, "Alice", "alice@example.com")
+	start := time.Date(2030, time.March, 4, 9, 0, 0, 0, time.UTC)
+
+	t.Run("validation fields", func(t *testing.T) {
+		_, err := c.CreateEvent(ctx, client.CreateEventInput{Title: "", StartTime: start, EndTime: start, Status: client.StatusBusy})
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected *client.Error, got %v", err)
+		}
+		if apiErr.StatusCode != http.StatusBadRequest || apiErr.Code != "validation_failed" || !errors.Is(err, client.ErrValidation) {
+			t.Errorf("unexpected error %+v", apiErr)
+		}
+		fields := map[string]bool{}
+		for _, field := range apiErr.Errors {
+			fields[field.Field] = true
+		}
+		if !fields["title"] || !fields["end_time"] {
+			t.Errorf("expected title and end_time errors, got %+v", apiErr.Errors)
+		}
+	})
+
+	t.Run("conflicting events", func(t *testing.T) {
+		existing := createEvent(t, c, "Existing", start, client.StatusBusy)
+		_, err := c.CreateEvent(ctx, client.CreateEventInput{Title: "Clash", StartTime: start, EndTime: start.Add(time.Hour), Status: client.StatusBusy})
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) || !errors.Is(err, client.ErrConflict) {
+			t.Fatalf("expected a conflict, got %v", err)
+		}
+		if len(apiErr.ConflictingEvents) != 1 || apiErr.ConflictingEvents[0].ID != existing.ID {
+			t.Errorf("expected the existing event as the conflict, got %+v", apiErr.ConflictingEvents)
+		}
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		_, err := client.New(ts.URL).Me(ctx)
+		if !errors.Is(err, client.ErrUnauthorized) {
+			t.Errorf("expected ErrUnauthorized, got %v", err)
+		}
+	})
+
+	t.Run("non-problem body", func(t *testing.T) {
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "upstream unavailable", http.StatusBadGateway)
+		}))
+		defer proxy.Close()
+
+		err := client.New(proxy.URL).Health(ctx)
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected *client.Error, got %v", err)
+		}
+		if apiErr.StatusCode != http.StatusBadGateway || apiErr.Detail != "upstream unavailable" {
+			t.Errorf("unexpected error %+v", apiErr)
+		}
+	})
+}
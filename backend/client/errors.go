@@ -0,0 +1,102 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Error kinds, mirroring the server's. Every *Error wraps the kind matching
+// its problem code, so callers can classify failures with errors.Is.
+var (
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrInvalidState = errors.New("invalid state")
+	// ErrPreconditionFailed means the resource changed since it was read.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// codeKinds maps the problem code the server sends to an error kind.
+var codeKinds = map[string]error{
+	"validation_failed":   ErrValidation,
+	"unauthorized":        ErrUnauthorized,
+	"forbidden":           ErrForbidden,
+	"not_found":           ErrNotFound,
+	"conflict":            ErrConflict,
+	"invalid_state":       ErrInvalidState,
+	"precondition_failed": ErrPreconditionFailed,
+}
+
+// statusKinds classifies problems that carry no code, such as malformed
+// requests rejected by a handler.
+var statusKinds = map[int]error{
+	http.StatusBadRequest:         ErrValidation,
+	http.StatusUnauthorized:       ErrUnauthorized,
+	http.StatusForbidden:          ErrForbidden,
+	http.StatusNotFound:           ErrNotFound,
+	http.StatusConflict:           ErrConflict,
+	http.StatusPreconditionFailed: ErrPreconditionFailed,
+}
+
+// FieldError describes why one input field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error is an RFC 9457 problem returned by the server. ConflictingEvents is
+// set when an event would overlap the user's other events, and
+// CounterpartyConflict names the other side of a swap, such as "requester",
+// when theirs would.
+type Error struct {
+	StatusCode           int          `json:"status"`
+	Type                 string       `json:"type"`
+	Title                string       `json:"title"`
+	Detail               string       `json:"detail"`
+	Instance             string       `json:"instance"`
+	Code                 string       `json:"code"`
+	Errors               []FieldError `json:"errors"`
+	ConflictingEvents    []Event      `json:"conflicting_events"`
+	CounterpartyConflict string       `json:"counterparty_conflict"`
+}
+
+func (e *Error) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("slotswapper: %d %s", e.StatusCode, e.Title)
+	}
+	return fmt.Sprintf("slotswapper: %d %s: %s", e.StatusCode, e.Title, e.Detail)
+}
+
+func (e *Error) Unwrap() error {
+	if kind, ok := codeKinds[e.Code]; ok {
+		return kind
+	}
+	return statusKinds[e.StatusCode]
+}
+
+// newResponseError reads a failed response. Bodies that are not problem
+// documents, such as a proxy's error page, are kept as the detail.
+func newResponseError(resp *http.Response) error {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	apiErr := &Error{}
+	if json.Unmarshal(body, apiErr) != nil {
+		apiErr = &Error{Detail: strings.TrimSpace(string(body))}
+	}
+	apiErr.StatusCode = resp.StatusCode
+	if apiErr.Title == "" {
+		apiErr.Title = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func (c *Client) CreateEvent(ctx context.Context, input CreateEventInput) (*Event, error) {
+	var event Event
+	if err := c.doIdempotent(ctx, "/api/events", input, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// CreateRecurringEvents creates a series of events, the first at
+// input.StartTime, repeating at the same wall-clock time in the event's zone.
+func (c *Client) CreateRecurringEvents(ctx context.Context, input CreateEventInput, recurrence Recurrence) ([]Event, error) {
+	body := struct {
+		CreateEventInput
+		Recurrence Recurrence `json:"recurrence"`
+	}{input, recurrence}
+
+	var events []Event
+	if err := c.doIdempotent(ctx, "/api/events/recurring", body, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ListEvents returns one page of the authenticated user's events.
+func (c *Client) ListEvents(ctx context.Context, opts EventListOptions) (*Page[Event], error) {
+	var page Page[Event]
+	if err := c.do(ctx, http.MethodGet, "/api/events/user", opts.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Events iterates over all of the authenticated user's events matching opts,
+// starting at opts.Cursor.
+func (c *Client) Events(ctx context.Context, opts EventListOptions) iter.Seq2[Event, error] {
+	return paginate(opts.Cursor, func(cursor string) (*Page[Event], error) {
+		opts.Cursor = cursor
+		return c.ListEvents(ctx, opts)
+	})
+}
+
+func (c *Client) GetEvent(ctx context.Context, id int64) (*Event, error) {
+	var event Event
+	if err := c.do(ctx, http.MethodGet, eventPath(id), nil, nil, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (c *Client) UpdateEvent(ctx context.Context, id int64, input UpdateEventInput) (*Event, error) {
+	var event Event
+	if err := c.doWithHeader(ctx, http.MethodPut, eventPath(id), nil, input, &event, ifMatch(input.Version)); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// PatchEvent changes only the fields set in patch.
+func (c *Client) PatchEvent(ctx context.Context, id int64, patch EventPatch) (*PatchEventResult, error) {
+	var query url.Values
+	if patch.AllowOverlap {
+		query = url.Values{"allow_overlap": {"true"}}
+	}
+	var result PatchEventResult
+	if err := c.doWithHeader(ctx, http.MethodPatch, eventPath(id), query, patch, &result, ifMatch(patch.Version)); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetEventStatus marks an event BUSY or SWAPPABLE.
+func (c *Client) SetEventStatus(ctx context.Context, id int64, status string) (*Event, error) {
+	var event Event
+	body := map[string]string{"status": status}
+	if err := c.do(ctx, http.MethodPost, eventPath(id)+"/status", nil, body, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// GiveAwayEvent puts an event on the marketplace for anyone to claim, with
+// GiveawayFirstCome or GiveawayOwnerPicks. SetEventStatus ends the giveaway.
+func (c *Client) GiveAwayEvent(ctx context.Context, id int64, giveaway string) (*Event, error) {
+	var event Event
+	body := map[string]string{"status": StatusSwappable, "giveaway": giveaway}
+	if err := c.do(ctx, http.MethodPost, eventPath(id)+"/status", nil, body, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (c *Client) DeleteEvent(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, eventPath(id), nil, nil, nil)
+}
+
+// ifMatch makes a write conditional on the event still having version. Zero
+// means any version.
+func ifMatch(version int64) http.Header {
+	if version == 0 {
+		return nil
+	}
+	return http.Header{"If-Match": {strconv.Quote(strconv.FormatInt(version, 10))}}
+}
+
+func eventPath(id int64) string {
+	return fmt.Sprintf("/api/events/%d", id)
+}
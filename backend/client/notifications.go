@@ -0,0 +1,35 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+// ListNotifications returns one page of the user's notifications, newest
+// first.
+func (c *Client) ListNotifications(ctx context.Context, opts NotificationListOptions) (*Page[Notification], error) {
+	var page Page[Notification]
+	if err := c.do(ctx, http.MethodGet, "/api/notifications", opts.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Notifications iterates over all of the user's notifications.
+func (c *Client) Notifications(ctx context.Context, opts NotificationListOptions) iter.Seq2[Notification, error] {
+	return paginate(opts.Cursor, func(cursor string) (*Page[Notification], error) {
+		opts.Cursor = cursor
+		return c.ListNotifications(ctx, opts)
+	})
+}
+
+// MarkNotificationRead marks one of the user's notifications as read.
+func (c *Client) MarkNotificationRead(ctx context.Context, id int64) (*Notification, error) {
+	var notification Notification
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/api/notifications/%d/read", id), nil, nil, &notification); err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"iter"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// PageOptions selects one page of a listing. Limit is 1-100 and defaults to
+// the server's page size; Cursor is the NextCursor of the previous page.
+type PageOptions struct {
+	Limit  int
+	Cursor string
+}
+
+func (o PageOptions) encode(query url.Values) {
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Cursor != "" {
+		query.Set("cursor", o.Cursor)
+	}
+}
+
+// EventListOptions filters and orders the user's events. The time bounds are
+// inclusive and ignored when zero; Sort is start_time (the default) or
+// -start_time.
+type EventListOptions struct {
+	Status    string
+	StartFrom time.Time
+	StartTo   time.Time
+	EndFrom   time.Time
+	EndTo     time.Time
+	Sort      string
+	PageOptions
+}
+
+func (o EventListOptions) query() url.Values {
+	query := url.Values{}
+	if o.Status != "" {
+		query.Set("status", o.Status)
+	}
+	setTime(query, "start_from", o.StartFrom)
+	setTime(query, "start_to", o.StartTo)
+	setTime(query, "end_from", o.EndFrom)
+	setTime(query, "end_to", o.EndTo)
+	if o.Sort != "" {
+		query.Set("sort", o.Sort)
+	}
+	o.PageOptions.encode(query)
+	return query
+}
+
+// SwapListOptions orders pending swap requests by created_at or -created_at
+// (the default).
+type SwapListOptions struct {
+	Sort string
+	PageOptions
+}
+
+func (o SwapListOptions) query() url.Values {
+	query := url.Values{}
+	if o.Sort != "" {
+		query.Set("sort", o.Sort)
+	}
+	o.PageOptions.encode(query)
+	return query
+}
+
+// SwapHistoryOptions filters the swap request history. From and To bound the
+// creation time inclusively; Sort is created_at, resolved_at or either
+// prefixed with "-", defaulting to -created_at.
+type SwapHistoryOptions struct {
+	Status         string
+	CounterpartyID int64
+	From           time.Time
+	To             time.Time
+	Sort           string
+	PageOptions
+}
+
+func (o SwapHistoryOptions) query() url.Values {
+	query := url.Values{}
+	if o.Status != "" {
+		query.Set("status", o.Status)
+	}
+	if o.CounterpartyID != 0 {
+		query.Set("counterparty_id", strconv.FormatInt(o.CounterpartyID, 10))
+	}
+	setTime(query, "from", o.From)
+	setTime(query, "to", o.To)
+	if o.Sort != "" {
+		query.Set("sort", o.Sort)
+	}
+	o.PageOptions.encode(query)
+	return query
+}
+
+// NotificationListOptions pages the user's notifications newest first,
+// optionally only those not yet read.
+type NotificationListOptions struct {
+	UnreadOnly bool
+	PageOptions
+}
+
+func (o NotificationListOptions) query() url.Values {
+	query := url.Values{}
+	if o.UnreadOnly {
+		query.Set("unread", "true")
+	}
+	o.PageOptions.encode(query)
+	return query
+}
+
+// AuditLogOptions pages audit entries newest first. Before is the ID of the
+// last entry already seen.
+type AuditLogOptions struct {
+	// EntityType and ActorUserID filter the admin listing only.
+	EntityType  string
+	ActorUserID int64
+	Before      int64
+	Limit       int
+}
+
+func (o AuditLogOptions) query() url.Values {
+	query := url.Values{}
+	if o.EntityType != "" {
+		query.Set("entity_type", o.EntityType)
+	}
+	if o.ActorUserID != 0 {
+		query.Set("actor_user_id", strconv.FormatInt(o.ActorUserID, 10))
+	}
+	if o.Before != 0 {
+		query.Set("before", strconv.FormatInt(o.Before, 10))
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	return query
+}
+
+func setTime(query url.Values, name string, t time.Time) {
+	if !t.IsZero() {
+		query.Set(name, t.Format(time.RFC3339))
+	}
+}
+
+// paginate yields every item of a cursor-paginated listing, fetching pages on
+// demand. Iteration stops after the first error.
+func paginate[T any](cursor string, fetch func(cursor string) (*Page[T], error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			page, err := fetch(cursor)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			cursor = page.NextCursor
+		}
+	}
+}
+
+// paginateAuditLogs yields audit entries page by page, continuing before the
+// last entry of each page until a page comes back empty.
+func paginateAuditLogs(opts AuditLogOptions, fetch func(AuditLogOptions) ([]AuditLogEntry, error)) iter.Seq2[AuditLogEntry, error] {
+	return func(yield func(AuditLogEntry, error) bool) {
+		for {
+			entries, err := fetch(opts)
+			if err != nil {
+				yield(AuditLogEntry{}, err)
+				return
+			}
+			for _, entry := range entries {
+				if !yield(entry, nil) {
+					return
+				}
+			}
+			if len(entries) == 0 || (opts.Limit > 0 && len(entries) < opts.Limit) {
+				return
+			}
+			opts.Before = entries[len(entries)-1].ID
+		}
+	}
+}
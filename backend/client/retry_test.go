@@ -0,0 +1,144 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"slotswapper/client"
+)
+
+// flaky answers the first failures requests with status and passes the rest
+// to next.
+func flaky(next http.Handler, failures int32, status int) (http.Handler, *atomic.Int32) {
+	var calls atomic.Int32
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}), &calls
+}
+
+func TestClient_Retry(t *testing.T) {
+	backend, _ := newTestServer(t)
+	ctx := context.Background()
+	policy := client.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+	alice := client.New(backend.URL)
+	signUp(t, alice, "Alice", "alice@example.com")
+
+	t.Run("retries idempotent requests", func(t *testing.T) {
+		handler, calls := flaky(backend.Config.Handler, 2, http.StatusServiceUnavailable)
+		ts := httptest.NewServer(handler)
+		defer ts.Close()
+
+		me, err := client.New(ts.URL, client.WithToken(alice.Token()), client.WithRetry(policy)).Me(ctx)
+		if err != nil {
+			t.Fatalf("Me: %v", err)
+		}
+		if me.Name != "Alice" || calls.Load() != 3 {
+			t.Errorf("expected success on the third attempt, got %d calls", calls.Load())
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		handler, calls := flaky(backend.Config.Handler, 5, http.StatusBadGateway)
+		ts := httptest.NewServer(handler)
+		defer ts.Close()
+
+		_, err := client.New(ts.URL, client.WithToken(alice.Token()), client.WithRetry(policy)).Me(ctx)
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+			t.Errorf("expected the last 502, got %v", err)
+		}
+		if calls.Load() != 3 {
+			t.Errorf("expected 3 attempts, got %d", calls.Load())
+		}
+	})
+
+	t.Run("does not retry non-idempotent requests", func(t *testing.T) {
+		handler, calls := flaky(backend.Config.Handler, 1, http.StatusServiceUnavailable)
+		ts := httptest.NewServer(handler)
+		defer ts.Close()
+
+		c := client.New(ts.URL, client.WithRetry(policy))
+		_, err := c.Login(ctx, client.LoginInput{Email: "alice@example.com", Password: "password123"})
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected the 503 to be returned, got %v", err)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("expected a single attempt, got %d", calls.Load())
+		}
+	})
+
+	t.Run("retries creations with an idempotency key", func(t *testing.T) {
+		// The first attempt reaches the server, but its response is lost.
+		var calls atomic.Int32
+		var keys []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+			if calls.Add(1) == 1 {
+				backend.Config.Handler.ServeHTTP(httptest.NewRecorder(), r)
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			backend.Config.Handler.ServeHTTP(w, r)
+		}))
+		defer ts.Close()
+
+		c := client.New(ts.URL, client.WithToken(alice.Token()), client.WithRetry(policy))
+		start := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+		event, err := c.CreateEvent(ctx, client.CreateEventInput{Title: "Retried", StartTime: start, EndTime: start.Add(time.Hour), Status: "BUSY"})
+		if err != nil {
+			t.Fatalf("CreateEvent: %v", err)
+		}
+		if calls.Load() != 2 || keys[0] == "" || keys[0] != keys[1] {
+			t.Fatalf("expected two attempts with the same key, got %q", keys)
+		}
+
+		page, err := c.ListEvents(ctx, client.EventListOptions{})
+		if err != nil {
+			t.Fatalf("ListEvents: %v", err)
+		}
+		var created int
+		for _, e := range page.Items {
+			if e.Title == "Retried" {
+				created++
+				if e.ID != event.ID {
+					t.Errorf("expected the replayed event %d, got %d", e.ID, event.ID)
+				}
+			}
+		}
+		if created != 1 {
+			t.Errorf("expected the retry to create no duplicate, got %d events", created)
+		}
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		_, err := client.New(backend.URL, client.WithRetry(policy)).Me(ctx)
+		if !errors.Is(err, client.ErrUnauthorized) {
+			t.Errorf("expected ErrUnauthorized, got %v", err)
+		}
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		handler, _ := flaky(backend.Config.Handler, 100, http.StatusServiceUnavailable)
+		ts := httptest.NewServer(handler)
+		defer ts.Close()
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		slow := client.RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: time.Second}
+		_, err := client.New(ts.URL, client.WithRetry(slow)).Me(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// ListSwappableSlots returns one page of other users' swappable events.
+func (c *Client) ListSwappableSlots(ctx context.Context, opts PageOptions) (*Page[SwappableSlot], error) {
+	query := url.Values{}
+	opts.encode(query)
+	var page Page[SwappableSlot]
+	if err := c.do(ctx, http.MethodGet, "/api/swappable-slots", query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// SwappableSlots iterates over all of other users' swappable events.
+func (c *Client) SwappableSlots(ctx context.Context, opts PageOptions) iter.Seq2[SwappableSlot, error] {
+	return paginate(opts.Cursor, func(cursor string) (*Page[SwappableSlot], error) {
+		opts.Cursor = cursor
+		return c.ListSwappableSlots(ctx, opts)
+	})
+}
+
+// RequestSwap offers one of the user's swappable slots for another user's.
+// The offered slot is reserved until the request is closed; the other one
+// stays open to competing offers.
+func (c *Client) RequestSwap(ctx context.Context, input CreateSwapRequestInput) (*SwapRequest, error) {
+	var swap SwapRequest
+	if err := c.doIdempotent(ctx, "/api/swap-request", input, &swap); err != nil {
+		return nil, err
+	}
+	return &swap, nil
+}
+
+// RespondToSwap accepts or rejects a swap request addressed to the user.
+// Accepting supersedes the other pending offers for either slot.
+func (c *Client) RespondToSwap(ctx context.Context, id int64, input RespondInput) (*SwapRequest, error) {
+	var swap SwapRequest
+	if err := c.doIdempotent(ctx, fmt.Sprintf("/api/swap-response/%d", id), input, &swap); err != nil {
+		return nil, err
+	}
+	return &swap, nil
+}
+
+// WithdrawSwapRequest takes back a pending request the user sent, putting
+// the offered slot back on the marketplace.
+func (c *Client) WithdrawSwapRequest(ctx context.Context, id int64) (*SwapRequest, error) {
+	var swap SwapRequest
+	if err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/swap-requests/%d", id), nil, nil, &swap); err != nil {
+		return nil, err
+	}
+	return &swap, nil
+}
+
+// ClaimSlot asks for a slot that is being given away. A first-come slot is
+// the user's at once and the request comes back ACCEPTED; otherwise it stays
+// PENDING until the owner picks a claimant. allowOverlap skips the check
+// against the user's other events.
+func (c *Client) ClaimSlot(ctx context.Context, eventID int64, allowOverlap bool) (*SwapRequest, error) {
+	var query url.Values
+	if allowOverlap {
+		query = url.Values{"allow_overlap": {"true"}}
+	}
+	var swap SwapRequest
+	header := http.Header{"Idempotency-Key": {rand.Text()}}
+	if err := c.doWithHeader(ctx, http.MethodPost, fmt.Sprintf("/api/events/%d/claim", eventID), query, nil, &swap, header); err != nil {
+		return nil, err
+	}
+	return &swap, nil
+}
+
+// ListIncomingSwapRequests returns one page of pending requests for the
+// user's slots.
+func (c *Client) ListIncomingSwapRequests(ctx context.Context, opts SwapListOptions) (*Page[SwapRequestSummary], error) {
+	return c.listSwapRequests(ctx, "/api/swap-requests/incoming", opts)
+}
+
+// ListOutgoingSwapRequests returns one page of the user's pending requests.
+func (c *Client) ListOutgoingSwapRequests(ctx context.Context, opts SwapListOptions) (*Page[SwapRequestSummary], error) {
+	return c.listSwapRequests(ctx, "/api/swap-requests/outgoing", opts)
+}
+
+func (c *Client) IncomingSwapRequests(ctx context.Context, opts SwapListOptions) iter.Seq2[SwapRequestSummary, error] {
+	return paginate(opts.Cursor, func(cursor string) (*Page[SwapRequestSummary], error) {
+		opts.Cursor = cursor
+		return c.ListIncomingSwapRequests(ctx, opts)
+	})
+}
+
+func (c *Client) OutgoingSwapRequests(ctx context.Context, opts SwapListOptions) iter.Seq2[SwapRequestSummary, error] {
+	return paginate(opts.Cursor, func(cursor string) (*Page[SwapRequestSummary], error) {
+		opts.Cursor = cursor
+		return c.ListOutgoingSwapRequests(ctx, opts)
+	})
+}
+
+func (c *Client) listSwapRequests(ctx context.Context, path string, opts SwapListOptions) (*Page[SwapRequestSummary], error) {
+	var page Page[SwapRequestSummary]
+	if err := c.do(ctx, http.MethodGet, path, opts.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// ListIncomingSwapHistory returns one page of requests the user received, in
+// any status.
+func (c *Client) ListIncomingSwapHistory(ctx context.Context, opts SwapHistoryOptions) (*Page[SwapRequestHistoryEntry], error) {
+	return c.listSwapHistory(ctx, "/api/swap-requests/incoming/history", opts)
+}
+
+// ListOutgoingSwapHistory returns one page of requests the user sent, in any
+// status.
+func (c *Client) ListOutgoingSwapHistory(ctx context.Context, opts SwapHistoryOptions) (*Page[SwapRequestHistoryEntry], error) {
+	return c.listSwapHistory(ctx, "/api/swap-requests/outgoing/history", opts)
+}
+
+func (c *Client) IncomingSwapHistory(ctx context.Context, opts SwapHistoryOptions) iter.Seq2[SwapRequestHistoryEntry, error] {
+	return paginate(opts.Cursor, func(cursor string) (*Page[SwapRequestHistoryEntry], error) {
+		opts.Cursor = cursor
+		return c.ListIncomingSwapHistory(ctx, opts)
+	})
+}
+
+func (c *Client) OutgoingSwapHistory(ctx context.Context, opts SwapHistoryOptions) iter.Seq2[SwapRequestHistoryEntry, error] {
+	return paginate(opts.Cursor, func(cursor string) (*Page[SwapRequestHistoryEntry], error) {
+		opts.Cursor = cursor
+		return c.ListOutgoingSwapHistory(ctx, opts)
+	})
+}
+
+func (c *Client) listSwapHistory(ctx context.Context, path string, opts SwapHistoryOptions) (*Page[SwapRequestHistoryEntry], error) {
+	var page Page[SwapRequestHistoryEntry]
+	if err := c.do(ctx, http.MethodGet, path, opts.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
@@ -0,0 +1,272 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event statuses.
+const (
+	StatusBusy        = "BUSY"
+	StatusSwappable   = "SWAPPABLE"
+	StatusSwapPending = "SWAP_PENDING"
+)
+
+// Swap request statuses.
+const (
+	SwapPending    = "PENDING"
+	SwapAccepted   = "ACCEPTED"
+	SwapRejected   = "REJECTED"
+	SwapWithdrawn  = "WITHDRAWN"
+	SwapSuperseded = "SUPERSEDED"
+	SwapCancelled  = "CANCELLED"
+)
+
+// Ways of giving a slot away.
+const (
+	// GiveawayFirstCome transfers the slot to the first user who claims it.
+	GiveawayFirstCome = "FIRST_COME"
+	// GiveawayOwnerPicks keeps claims pending until the owner accepts one.
+	GiveawayOwnerPicks = "OWNER_PICKS"
+)
+
+// Swap request kinds.
+const (
+	KindSwap = "SWAP"
+	// KindTransfer is a claim on a slot that is being given away. It has no
+	// requester slot.
+	KindTransfer = "TRANSFER"
+)
+
+// User is the authenticated user's own profile.
+type User struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	IsAdmin   bool      `json:"is_admin"`
+	TimeZone  string    `json:"time_zone"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PublicUser is the part of another user's profile anyone may see.
+type PublicUser struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AuthResult is returned by SignUp and Login.
+type AuthResult struct {
+	User  User   `json:"user"`
+	Token string `json:"token"`
+}
+
+type SignUpInput struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	TimeZone string `json:"time_zone,omitempty"`
+}
+
+type LoginInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type Event struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Status    string    `json:"status"`
+	UserID    int64     `json:"user_id"`
+	TimeZone  string    `json:"time_zone"`
+	// Giveaway is set while a SWAPPABLE event is being given away.
+	Giveaway *string `json:"giveaway"`
+	// Version changes on every write to the event.
+	Version   int64     `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SwappableSlot is another user's event offered for swapping.
+type SwappableSlot struct {
+	Event
+	OwnerName string `json:"owner_name"`
+}
+
+type CreateEventInput struct {
+	Title     string    `json:"title"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Status    string    `json:"status"`
+	// TimeZone defaults to the user's preferred zone.
+	TimeZone string `json:"time_zone,omitempty"`
+	// AllowOverlap skips the check against the user's other events.
+	AllowOverlap bool `json:"allow_overlap,omitempty"`
+}
+
+// Recurrence repeats an event DAILY or WEEKLY, Count times in total.
+type Recurrence struct {
+	Frequency string `json:"frequency"`
+	Count     int    `json:"count"`
+}
+
+type UpdateEventInput struct {
+	Title     string    `json:"title"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	// TimeZone replaces the event's zone when set.
+	TimeZone     string `json:"time_zone,omitempty"`
+	AllowOverlap bool   `json:"allow_overlap,omitempty"`
+	// Version, when set, makes the update fail with ErrPreconditionFailed
+	// if the event has changed since it had this version.
+	Version int64 `json:"-"`
+}
+
+// EventPatch changes some of an event's fields; nil fields are left as they
+// are. Moving a SWAPPABLE or SWAP_PENDING event cancels its pending swap
+// requests.
+type EventPatch struct {
+	Title     *string    `json:"title,omitempty"`
+	StartTime *time.Time `json:"start_time,omitempty"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+	TimeZone  *string    `json:"time_zone,omitempty"`
+	// AllowOverlap skips the check against the user's other events.
+	AllowOverlap bool `json:"-"`
+	// Version, when set, makes the patch fail with ErrPreconditionFailed
+	// if the event has changed since it had this version.
+	Version int64 `json:"-"`
+}
+
+// PatchEventResult is a patched event and what else the patch changed.
+type PatchEventResult struct {
+	Event       Event            `json:"event"`
+	SideEffects EventSideEffects `json:"side_effects"`
+}
+
+// EventSideEffects lists the changes an edit made beyond the event's fields.
+type EventSideEffects struct {
+	// CancelledSwapRequests are the pending swap requests that were cancelled.
+	CancelledSwapRequests []int64 `json:"cancelled_swap_requests"`
+	// ReleasedSlots are the slots other users offered in those requests,
+	// which are back on the marketplace.
+	ReleasedSlots []int64 `json:"released_slots"`
+	// StatusChanged is set when the event went from SWAP_PENDING to BUSY.
+	StatusChanged bool `json:"status_changed"`
+}
+
+// SwapRequest is a swap or, with Kind TRANSFER, a claim on a slot that is
+// being given away. A transfer has a zero RequesterSlotID.
+type SwapRequest struct {
+	ID               int64      `json:"id"`
+	Kind             string     `json:"kind"`
+	RequesterUserID  int64      `json:"requester_user_id"`
+	ResponderUserID  int64      `json:"responder_user_id"`
+	RequesterSlotID  int64      `json:"requester_slot_id"`
+	ResponderSlotID  int64      `json:"responder_slot_id"`
+	Status           string     `json:"status"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	ResolvedByUserID *int64     `json:"resolved_by_user_id"`
+	ResolvedAt       *time.Time `json:"resolved_at"`
+	// CancelReason says why a CANCELLED request was closed.
+	CancelReason *string `json:"cancel_reason"`
+}
+
+type CreateSwapRequestInput struct {
+	ResponderUserID int64 `json:"responder_user_id"`
+	RequesterSlotID int64 `json:"requester_slot_id"`
+	ResponderSlotID int64 `json:"responder_slot_id"`
+}
+
+// RespondInput accepts or rejects a swap request.
+type RespondInput struct {
+	Status string `json:"status"`
+	// AllowOverlap accepts the swap even if the responder ends up owning
+	// overlapping events. A conflict on the requester's side still fails.
+	AllowOverlap bool `json:"allow_overlap,omitempty"`
+}
+
+// SwapRequestSummary is a pending swap request with both slots. Incoming
+// requests name the requester and outgoing requests the responder. A transfer
+// has an empty requester title and zero times.
+type SwapRequestSummary struct {
+	ID                      int64     `json:"id"`
+	Kind                    string    `json:"kind"`
+	Status                  string    `json:"status"`
+	RequesterUserID         int64     `json:"requester_user_id,omitempty"`
+	RequesterName           string    `json:"requester_name,omitempty"`
+	ResponderUserID         int64     `json:"responder_user_id,omitempty"`
+	ResponderName           string    `json:"responder_name,omitempty"`
+	RequesterEventTitle     string    `json:"requester_event_title"`
+	RequesterEventStartTime time.Time `json:"requester_event_start_time"`
+	RequesterEventEndTime   time.Time `json:"requester_event_end_time"`
+	ResponderEventTitle     string    `json:"responder_event_title"`
+	ResponderEventStartTime time.Time `json:"responder_event_start_time"`
+	ResponderEventEndTime   time.Time `json:"responder_event_end_time"`
+}
+
+// SwapRequestHistoryEntry is a swap request in any status, with who resolved
+// it. Like SwapRequestSummary it names only the counterparty. A slot that has
+// since been deleted keeps its ID but has an empty title and zero times.
+type SwapRequestHistoryEntry struct {
+	ID                      int64      `json:"id"`
+	Kind                    string     `json:"kind"`
+	Status                  string     `json:"status"`
+	RequesterUserID         int64      `json:"requester_user_id,omitempty"`
+	RequesterName           string     `json:"requester_name,omitempty"`
+	ResponderUserID         int64      `json:"responder_user_id,omitempty"`
+	ResponderName           string     `json:"responder_name,omitempty"`
+	RequesterSlotID         int64      `json:"requester_slot_id"`
+	RequesterEventTitle     string     `json:"requester_event_title"`
+	RequesterEventStartTime time.Time  `json:"requester_event_start_time"`
+	RequesterEventEndTime   time.Time  `json:"requester_event_end_time"`
+	ResponderSlotID         int64      `json:"responder_slot_id"`
+	ResponderEventTitle     string     `json:"responder_event_title"`
+	ResponderEventStartTime time.Time  `json:"responder_event_start_time"`
+	ResponderEventEndTime   time.Time  `json:"responder_event_end_time"`
+	ResolvedByUserID        *int64     `json:"resolved_by_user_id"`
+	ResolvedByName          string     `json:"resolved_by_name"`
+	ResolvedAt              *time.Time `json:"resolved_at"`
+	CancelReason            *string    `json:"cancel_reason"`
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
+}
+
+// AuditLogEntry records one change. Before and After hold the entity as JSON,
+// or null for creations and deletions.
+type AuditLogEntry struct {
+	ID          int64           `json:"id"`
+	ActorUserID int64           `json:"actor_user_id"`
+	Action      string          `json:"action"`
+	EntityType  string          `json:"entity_type"`
+	EntityID    int64           `json:"entity_id"`
+	Before      json.RawMessage `json:"before"`
+	After       json.RawMessage `json:"after"`
+	RequestID   string          `json:"request_id"`
+	IPAddress   string          `json:"ip_address"`
+	UserAgent   string          `json:"user_agent"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// Notification tells a user about a change someone else made to one of
+// their swaps. ReadAt is nil until it is marked read.
+type Notification struct {
+	ID            int64      `json:"id"`
+	UserID        int64      `json:"user_id"`
+	Kind          string     `json:"kind"`
+	SwapRequestID *int64     `json:"swap_request_id"`
+	Reason        string     `json:"reason"`
+	Message       string     `json:"message"`
+	ReadAt        *time.Time `json:"read_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Page is one page of a listing. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+}
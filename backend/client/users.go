@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Me returns the authenticated user's profile.
+func (c *Client) Me(ctx context.Context) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodGet, "/api/me", nil, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetTimeZone sets the IANA zone, such as Europe/Berlin, that the
+// authenticated user's new events default to.
+func (c *Client) SetTimeZone(ctx context.Context, timeZone string) (*User, error) {
+	var user User
+	body := map[string]string{"time_zone": timeZone}
+	if err := c.do(ctx, http.MethodPut, "/api/me/time-zone", nil, body, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeactivateAccount closes the authenticated user's account. Their pending
+// swap requests are cancelled, their access tokens revoked and the session
+// ends; the account cannot sign in again.
+func (c *Client) DeactivateAccount(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/api/me", nil, nil, nil)
+}
+
+// User returns another user's public profile.
+func (c *Client) User(ctx context.Context, id int64) (*PublicUser, error) {
+	var user PublicUser
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/users/%d", id), nil, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,529 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"slotswapper/client"
+)
+
+func commands() *command {
+	return &command{
+		name:    "slotswapper-cli",
+		summary: "Manage SlotSwapper events and swaps from the terminal.",
+		children: []*command{
+			{name: "login", summary: "Log in and save the access token", setup: loginCommand},
+			{name: "logout", summary: "Forget the saved access token", setup: logoutCommand},
+			{name: "whoami", summary: "Show the logged-in user", setup: whoamiCommand},
+			{name: "events", summary: "List, create and update your events", children: []*command{
+				{name: "list", summary: "List your events", setup: listEventsCommand,
+					values: map[string][]string{"status": eventStatuses, "sort": {"start_time", "-start_time"}}},
+				{name: "create", summary: "Create an event or a recurring series", setup: createEventCommand,
+					values: map[string][]string{"status": eventStatuses, "repeat": {"DAILY", "WEEKLY"}}},
+				{name: "swappable", args: "<event-id>", summary: "Offer an event for swapping", setup: setEventStatusCommand(client.StatusSwappable)},
+				{name: "busy", args: "<event-id>", summary: "Withdraw an event from the marketplace", setup: setEventStatusCommand(client.StatusBusy)},
+				{name: "giveaway", args: "<event-id>", summary: "Give an event away to anyone who claims it", setup: giveAwayEventCommand,
+					values: map[string][]string{"mode": {client.GiveawayFirstCome, client.GiveawayOwnerPicks}}},
+				{name: "delete", args: "<event-id>", summary: "Delete an event", setup: deleteEventCommand},
+			}},
+			{name: "marketplace", summary: "Browse other users' swappable slots", setup: marketplaceCommand},
+			{name: "swaps", summary: "Send and answer swap requests", children: []*command{
+				{name: "request", summary: "Offer one of your slots for someone else's", setup: requestSwapCommand},
+				{name: "incoming", summary: "List pending requests for your slots", setup: listSwapsCommand(true)},
+				{name: "outgoing", summary: "List your pending requests", setup: listSwapsCommand(false)},
+				{name: "accept", args: "<swap-id>", summary: "Accept a swap request", setup: respondCommand(client.SwapAccepted)},
+				{name: "reject", args: "<swap-id>", summary: "Reject a swap request", setup: respondCommand(client.SwapRejected)},
+				{name: "withdraw", args: "<swap-id>", summary: "Withdraw a swap request you sent", setup: withdrawSwapCommand},
+				{name: "claim", args: "<event-id>", summary: "Claim a slot that is being given away", setup: claimSlotCommand},
+				{name: "history", summary: "List resolved and pending requests", setup: swapHistoryCommand,
+					values: map[string][]string{"status": {client.SwapPending, client.SwapAccepted, client.SwapRejected, client.SwapWithdrawn, client.SwapSuperseded, client.SwapCancelled}}},
+			}},
+			{name: "completion", args: "bash|zsh|fish", summary: "Print a shell completion script", setup: completionCommand},
+		},
+	}
+}
+
+var eventStatuses = []string{client.StatusBusy, client.StatusSwappable, client.StatusSwapPending}
+
+func loginCommand(fs *flag.FlagSet) action {
+	email := fs.String("email", "", "account email (prompted if omitted)")
+	password := fs.String("password", "", "account password (default: $SLOTSWAPPER_PASSWORD, else read from stdin)")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+		input := bufio.NewReader(c.stdin)
+		var err error
+		if *email == "" {
+			if *email, err = prompt(c, input, "Email: "); err != nil {
+				return err
+			}
+		}
+		if *password == "" {
+			*password = os.Getenv("SLOTSWAPPER_PASSWORD")
+		}
+		if *password == "" {
+			if *password, err = prompt(c, input, "Password: "); err != nil {
+				return err
+			}
+		}
+
+		api := client.New(c.serverURL())
+		result, err := api.Login(ctx, client.LoginInput{Email: *email, Password: *password})
+		if err != nil {
+			return err
+		}
+
+		c.config.Server = c.serverURL()
+		c.config.Email = result.User.Email
+		c.config.Token = result.Token
+		if err := saveConfig(c.configPath, c.config); err != nil {
+			return fmt.Errorf("logged in but could not save the token: %w", err)
+		}
+		fmt.Fprintf(c.stderr, "Logged in to %s as %s.\n", c.config.Server, result.User.Email)
+		return c.print(result.User, func() *table { return userTable(result.User) })
+	}
+}
+
+func logoutCommand(fs *flag.FlagSet) action {
+	return func(ctx context.Context, c *cli, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+		c.config.Token = ""
+		if err := saveConfig(c.configPath, c.config); err != nil {
+			return err
+		}
+		fmt.Fprintln(c.stderr, "Logged out.")
+		return nil
+	}
+}
+
+func whoamiCommand(fs *flag.FlagSet) action {
+	return func(ctx context.Context, c *cli, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+		user, err := c.client().Me(ctx)
+		if err != nil {
+			return err
+		}
+		return c.print(user, func() *table { return userTable(*user) })
+	}
+}
+
+func listEventsCommand(fs *flag.FlagSet) action {
+	status := fs.String("status", "", "only list events with this status")
+	from := fs.String("from", "", "only list events starting at or after this time")
+	to := fs.String("to", "", "only list events starting at or before this time")
+	sort := fs.String("sort", "", "start_time (default) or -start_time")
+	limit := fs.Int("limit", 0, "list at most this many events (default: all)")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+		opts := client.EventListOptions{Status: *status, Sort: *sort, PageOptions: pageSize(*limit)}
+		var err error
+		if opts.StartFrom, err = parseOptionalTime("from", *from); err != nil {
+			return err
+		}
+		if opts.StartTo, err = parseOptionalTime("to", *to); err != nil {
+			return err
+		}
+
+		events, err := collect(c.client().Events(ctx, opts), *limit)
+		if err != nil {
+			return err
+		}
+		return c.print(events, func() *table { return eventTable(events...) })
+	}
+}
+
+func createEventCommand(fs *flag.FlagSet) action {
+	title := fs.String("title", "", "event title (required)")
+	start := fs.String("start", "", "start time, RFC 3339 or YYYY-MM-DDTHH:MM in -tz (required)")
+	end := fs.String("end", "", "end time (default: start plus -duration)")
+	duration := fs.Duration("duration", time.Hour, "event length when -end is omitted")
+	status := fs.String("status", client.StatusBusy, "BUSY or SWAPPABLE")
+	tz := fs.String("tz", "", "IANA time zone of the event (default: your preferred zone)")
+	allowOverlap := fs.Bool("allow-overlap", false, "create the event even if it overlaps another of yours")
+	repeat := fs.String("repeat", "", "repeat DAILY or WEEKLY")
+	count := fs.Int("count", 1, "number of occurrences with -repeat")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+		if *title == "" || *start == "" {
+			return usageError("-title and -start are required")
+		}
+
+		loc := time.Local
+		var err error
+		if *tz != "" {
+			if loc, err = time.LoadLocation(*tz); err != nil {
+				return usageError("invalid -tz: " + err.Error())
+			}
+		}
+		input := client.CreateEventInput{Title: *title, Status: *status, TimeZone: *tz, AllowOverlap: *allowOverlap}
+		if input.StartTime, err = parseTime("start", *start, loc); err != nil {
+			return err
+		}
+		input.EndTime = input.StartTime.Add(*duration)
+		if *end != "" {
+			if input.EndTime, err = parseTime("end", *end, loc); err != nil {
+				return err
+			}
+		}
+
+		api := c.client()
+		var events []client.Event
+		if *repeat != "" {
+			events, err = api.CreateRecurringEvents(ctx, input, client.Recurrence{Frequency: strings.ToUpper(*repeat), Count: *count})
+			if err != nil {
+				return err
+			}
+			return c.print(events, func() *table { return eventTable(events...) })
+		}
+		event, err := api.CreateEvent(ctx, input)
+		if err != nil {
+			return err
+		}
+		return c.print(event, func() *table { return eventTable(*event) })
+	}
+}
+
+func setEventStatusCommand(status string) func(fs *flag.FlagSet) action {
+	return func(fs *flag.FlagSet) action {
+		return func(ctx context.Context, c *cli, args []string) error {
+			id, err := parseID(args)
+			if err != nil {
+				return err
+			}
+			event, err := c.client().SetEventStatus(ctx, id, status)
+			if err != nil {
+				return err
+			}
+			return c.print(event, func() *table { return eventTable(*event) })
+		}
+	}
+}
+
+func giveAwayEventCommand(fs *flag.FlagSet) action {
+	mode := fs.String("mode", client.GiveawayFirstCome, "FIRST_COME to hand it to the first claimant, OWNER_PICKS to choose one")
+	return func(ctx context.Context, c *cli, args []string) error {
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+		event, err := c.client().GiveAwayEvent(ctx, id, strings.ToUpper(*mode))
+		if err != nil {
+			return err
+		}
+		return c.print(event, func() *table { return eventTable(*event) })
+	}
+}
+
+func deleteEventCommand(fs *flag.FlagSet) action {
+	return func(ctx context.Context, c *cli, args []string) error {
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+		if err := c.client().DeleteEvent(ctx, id); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stderr, "Deleted event %d.\n", id)
+		return nil
+	}
+}
+
+func marketplaceCommand(fs *flag.FlagSet) action {
+	limit := fs.Int("limit", 0, "list at most this many slots (default: all)")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+		slots, err := collect(c.client().SwappableSlots(ctx, pageSize(*limit)), *limit)
+		if err != nil {
+			return err
+		}
+		return c.print(slots, func() *table {
+			t := &table{header: []string{"ID", "OWNER", "TITLE", "START", "END", "GIVEAWAY"}}
+			for _, slot := range slots {
+				var giveaway string
+				if slot.Giveaway != nil {
+					giveaway = *slot.Giveaway
+				}
+				t.add(formatID(slot.ID), slot.OwnerName, slot.Title, formatTime(slot.StartTime), formatTime(slot.EndTime), giveaway)
+			}
+			return t
+		})
+	}
+}
+
+func requestSwapCommand(fs *flag.FlagSet) action {
+	mine := fs.Int64("mine", 0, "ID of your swappable event to give (required)")
+	theirs := fs.Int64("theirs", 0, "ID of the marketplace slot you want (required)")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+		if *mine == 0 || *theirs == 0 {
+			return usageError("-mine and -theirs are required")
+		}
+
+		api := c.client()
+		var owner int64
+		for slot, err := range api.SwappableSlots(ctx, client.PageOptions{Limit: 100}) {
+			if err != nil {
+				return err
+			}
+			if slot.ID == *theirs {
+				owner = slot.UserID
+				break
+			}
+		}
+		if owner == 0 {
+			return fmt.Errorf("slot %d is not in the marketplace", *theirs)
+		}
+
+		swap, err := api.RequestSwap(ctx, client.CreateSwapRequestInput{ResponderUserID: owner, RequesterSlotID: *mine, ResponderSlotID: *theirs})
+		if err != nil {
+			return err
+		}
+		return c.print(swap, func() *table { return swapTable(*swap) })
+	}
+}
+
+func listSwapsCommand(incoming bool) func(fs *flag.FlagSet) action {
+	return func(fs *flag.FlagSet) action {
+		limit := fs.Int("limit", 0, "list at most this many requests (default: all)")
+		return func(ctx context.Context, c *cli, args []string) error {
+			if err := noArgs(args); err != nil {
+				return err
+			}
+			opts := client.SwapListOptions{PageOptions: pageSize(*limit)}
+			seq := c.client().OutgoingSwapRequests(ctx, opts)
+			if incoming {
+				seq = c.client().IncomingSwapRequests(ctx, opts)
+			}
+			swaps, err := collect(seq, *limit)
+			if err != nil {
+				return err
+			}
+			return c.print(swaps, func() *table {
+				if incoming {
+					t := &table{header: []string{"ID", "FROM", "THEY GIVE", "START", "YOU GIVE", "START"}}
+					for _, s := range swaps {
+						title, start := requesterSide(s)
+						t.add(formatID(s.ID), s.RequesterName, title, start, s.ResponderEventTitle, formatTime(s.ResponderEventStartTime))
+					}
+					return t
+				}
+				t := &table{header: []string{"ID", "TO", "YOU GIVE", "START", "THEY GIVE", "START"}}
+				for _, s := range swaps {
+					title, start := requesterSide(s)
+					t.add(formatID(s.ID), s.ResponderName, title, start, s.ResponderEventTitle, formatTime(s.ResponderEventStartTime))
+				}
+				return t
+			})
+		}
+	}
+}
+
+// requesterSide describes what the requester gives: their slot, or nothing
+// for a claim on a slot that is being given away.
+func requesterSide(s client.SwapRequestSummary) (title, start string) {
+	if s.Kind == client.KindTransfer {
+		return "(nothing)", ""
+	}
+	return s.RequesterEventTitle, formatTime(s.RequesterEventStartTime)
+}
+
+func respondCommand(status string) func(fs *flag.FlagSet) action {
+	return func(fs *flag.FlagSet) action {
+		var allowOverlap *bool
+		if status == client.SwapAccepted {
+			allowOverlap = fs.Bool("allow-overlap", false, "accept even if you end up with overlapping events")
+		}
+		return func(ctx context.Context, c *cli, args []string) error {
+			id, err := parseID(args)
+			if err != nil {
+				return err
+			}
+			input := client.RespondInput{Status: status}
+			if allowOverlap != nil {
+				input.AllowOverlap = *allowOverlap
+			}
+			swap, err := c.client().RespondToSwap(ctx, id, input)
+			if err != nil {
+				return err
+			}
+			return c.print(swap, func() *table { return swapTable(*swap) })
+		}
+	}
+}
+
+func withdrawSwapCommand(fs *flag.FlagSet) action {
+	return func(ctx context.Context, c *cli, args []string) error {
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+		swap, err := c.client().WithdrawSwapRequest(ctx, id)
+		if err != nil {
+			return err
+		}
+		return c.print(swap, func() *table { return swapTable(*swap) })
+	}
+}
+
+func claimSlotCommand(fs *flag.FlagSet) action {
+	allowOverlap := fs.Bool("allow-overlap", false, "claim even if the slot overlaps one of your events")
+	return func(ctx context.Context, c *cli, args []string) error {
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+		swap, err := c.client().ClaimSlot(ctx, id, *allowOverlap)
+		if err != nil {
+			return err
+		}
+		return c.print(swap, func() *table { return swapTable(*swap) })
+	}
+}
+
+func swapHistoryCommand(fs *flag.FlagSet) action {
+	incoming := fs.Bool("incoming", false, "list requests you received instead of those you sent")
+	status := fs.String("status", "", "only list requests with this status")
+	limit := fs.Int("limit", 0, "list at most this many requests (default: all)")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+		opts := client.SwapHistoryOptions{Status: *status, PageOptions: pageSize(*limit)}
+		seq := c.client().OutgoingSwapHistory(ctx, opts)
+		if *incoming {
+			seq = c.client().IncomingSwapHistory(ctx, opts)
+		}
+		entries, err := collect(seq, *limit)
+		if err != nil {
+			return err
+		}
+		return c.print(entries, func() *table {
+			t := &table{header: []string{"ID", "STATUS", "WITH", "REQUESTER SLOT", "RESPONDER SLOT", "CREATED", "RESOLVED BY"}}
+			for _, e := range entries {
+				with := e.ResponderName
+				if *incoming {
+					with = e.RequesterName
+				}
+				t.add(formatID(e.ID), e.Status, with, e.RequesterEventTitle, e.ResponderEventTitle, formatTime(e.CreatedAt), e.ResolvedByName)
+			}
+			return t
+		})
+	}
+}
+
+func userTable(user client.User) *table {
+	t := &table{header: []string{"ID", "NAME", "EMAIL", "TIME ZONE", "ADMIN"}}
+	t.add(formatID(user.ID), user.Name, user.Email, user.TimeZone, strconv.FormatBool(user.IsAdmin))
+	return t
+}
+
+func eventTable(events ...client.Event) *table {
+	t := &table{header: []string{"ID", "TITLE", "START", "END", "STATUS"}}
+	for _, event := range events {
+		t.add(formatID(event.ID), event.Title, formatTime(event.StartTime), formatTime(event.EndTime), event.Status)
+	}
+	return t
+}
+
+func swapTable(swap client.SwapRequest) *table {
+	t := &table{header: []string{"ID", "KIND", "STATUS", "REQUESTER SLOT", "RESPONDER SLOT", "CREATED"}}
+	requesterSlot := formatID(swap.RequesterSlotID)
+	if swap.Kind == client.KindTransfer {
+		requesterSlot = "-"
+	}
+	t.add(formatID(swap.ID), swap.Kind, swap.Status, requesterSlot, formatID(swap.ResponderSlotID), formatTime(swap.CreatedAt))
+	return t
+}
+
+// collect gathers up to limit items from seq, or all of them if limit is 0.
+func collect[T any](seq iter.Seq2[T, error], limit int) ([]T, error) {
+	items := []T{}
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if limit > 0 && len(items) >= limit {
+			break
+		}
+	}
+	return items, nil
+}
+
+// pageSize requests pages no larger than needed for limit results.
+func pageSize(limit int) client.PageOptions {
+	if limit > 0 {
+		return client.PageOptions{Limit: min(limit, 100)}
+	}
+	return client.PageOptions{Limit: 100}
+}
+
+func noArgs(args []string) error {
+	if len(args) > 0 {
+		return usageError(fmt.Sprintf("unexpected argument %q", args[0]))
+	}
+	return nil
+}
+
+func parseID(args []string) (int64, error) {
+	if len(args) != 1 {
+		return 0, usageError("expected exactly one ID")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, usageError(fmt.Sprintf("invalid ID %q", args[0]))
+	}
+	return id, nil
+}
+
+// timeLayouts are accepted for times on the command line. Layouts without an
+// offset are read in the event's or the local time zone.
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+func parseTime(name, value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, usageError(fmt.Sprintf("invalid -%s %q: use RFC 3339 or YYYY-MM-DDTHH:MM", name, value))
+}
+
+func parseOptionalTime(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return parseTime(name, value, time.Local)
+}
+
+// prompt writes label to stderr and reads one line from input.
+func prompt(c *cli, input *bufio.Reader, label string) (string, error) {
+	fmt.Fprint(c.stderr, label)
+	line, err := input.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", fmt.Errorf("reading %s%w", strings.ToLower(label), err)
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"strings"
+)
+
+// Completion scripts ask the hidden __complete command for candidates, so
+// they stay in sync with the command tree.
+var completionScripts = map[string]string{
+	"bash": `_slotswapper_cli() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	local IFS=$'\n'
+	COMPREPLY=($(compgen -W "$(slotswapper-cli __complete "${COMP_WORDS[@]:1:COMP_CWORD-1}" 2>/dev/null)" -- "$cur"))
+}
+complete -F _slotswapper_cli slotswapper-cli
+`,
+	"zsh": `#compdef slotswapper-cli
+_slotswapper_cli() {
+	local -a candidates
+	candidates=(${(f)"$(slotswapper-cli __complete ${words[2,CURRENT-1]} 2>/dev/null)"})
+	compadd -- $candidates
+}
+compdef _slotswapper_cli slotswapper-cli
+`,
+	"fish": `complete -c slotswapper-cli -f -a '(slotswapper-cli __complete (commandline -opc)[2..-1])'
+`,
+}
+
+func completionCommand(fs *flag.FlagSet) action {
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 1 || completionScripts[args[0]] == "" {
+			return usageError("expected one of bash, zsh or fish")
+		}
+		_, err := io.WriteString(c.stdout, completionScripts[args[0]])
+		return err
+	}
+}
+
+// complete returns the candidates for the word following words, the
+// arguments typed so far: the values of a flag that expects one, otherwise
+// subcommands and flags. Shells call it through "slotswapper-cli __complete".
+func complete(root *command, words []string) []string {
+	cmd := root
+	for len(words) > 0 && cmd.children != nil {
+		child := cmd.child(words[0])
+		if child == nil {
+			break
+		}
+		cmd, words = child, words[1:]
+	}
+
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	(&cli{}).globalFlags(fs)
+	if cmd.setup != nil {
+		cmd.setup(fs)
+	}
+
+	if n := len(words); n > 0 {
+		name := strings.TrimLeft(words[n-1], "-")
+		if f := fs.Lookup(name); f != nil && strings.HasPrefix(words[n-1], "-") && !strings.Contains(name, "=") && !isBoolFlag(f) {
+			if name == "o" {
+				return []string{"table", "json"}
+			}
+			return cmd.values[name]
+		}
+	}
+
+	var candidates []string
+	for _, child := range cmd.children {
+		candidates = append(candidates, child.name)
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		candidates = append(candidates, "-"+f.Name)
+	})
+	return candidates
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
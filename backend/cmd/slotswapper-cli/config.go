@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const defaultServer = "http://localhost:8080"
+
+// config is the CLI's saved state. It holds an access token, so it is written
+// readable by the owner only.
+type config struct {
+	Server string `json:"server,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Token  string `json:"token,omitempty"`
+}
+
+// defaultConfigPath is $SLOTSWAPPER_CLI_CONFIG, or slotswapper/cli.json in
+// the user's configuration directory.
+func defaultConfigPath() string {
+	if path := os.Getenv("SLOTSWAPPER_CLI_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "slotswapper-cli.json"
+	}
+	return filepath.Join(dir, "slotswapper", "cli.json")
+}
+
+// loadConfig reads the configuration at path. A missing file is an empty
+// configuration.
+func loadConfig(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+func saveConfig(path string, cfg *config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so that a failed write never leaves a
+	// truncated config behind.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,209 @@
+// Command slotswapper-cli manages events and swaps from the terminal through
+// the SlotSwapper API.
+//
+//	slotswapper-cli login -email alice@example.com
+//	slotswapper-cli events create -title "On-call" -start 2026-05-04T09:00 -duration 8h
+//	slotswapper-cli events swappable 42
+//	slotswapper-cli marketplace
+//	slotswapper-cli swaps request -mine 42 -theirs 17
+//	slotswapper-cli swaps accept 7 -o json
+//
+// Run "slotswapper-cli completion bash|zsh|fish" for shell completion.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"slotswapper/client"
+)
+
+// cli holds the state shared by every command: where to send requests, how
+// to print results and the saved login.
+type cli struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+
+	configPath string
+	server     string
+	output     string
+	config     *config
+}
+
+// action runs a command with its positional arguments.
+type action func(ctx context.Context, c *cli, args []string) error
+
+// command is a node of the command tree. Groups have children; leaves have a
+// setup function that declares their flags and returns the action to run.
+type command struct {
+	name     string
+	args     string
+	summary  string
+	setup    func(fs *flag.FlagSet) action
+	children []*command
+	// values lists the accepted values of flags, for shell completion.
+	values map[string][]string
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	c := &cli{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+	os.Exit(c.run(ctx, os.Args[1:]))
+}
+
+// run executes the command named by args and returns the exit code.
+func (c *cli) run(ctx context.Context, args []string) int {
+	root := commands()
+	if len(args) > 0 && args[0] == "__complete" {
+		for _, candidate := range complete(root, args[1:]) {
+			fmt.Fprintln(c.stdout, candidate)
+		}
+		return 0
+	}
+
+	cmd, path := root, []string{"slotswapper-cli"}
+	for len(args) > 0 && cmd.children != nil && !strings.HasPrefix(args[0], "-") {
+		child := cmd.child(args[0])
+		if child == nil {
+			fmt.Fprintf(c.stderr, "slotswapper-cli: unknown command %q\n\n", strings.Join(append(path[1:], args[0]), " "))
+			cmd.usage(c.stderr, path)
+			return 2
+		}
+		cmd, path, args = child, append(path, args[0]), args[1:]
+	}
+
+	fs := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.globalFlags(fs)
+	var act action
+	if cmd.setup != nil {
+		act = cmd.setup(fs)
+	}
+	fs.Usage = func() { cmd.usage(c.stderr, path); fs.PrintDefaults() }
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if act == nil {
+		cmd.usage(c.stderr, path)
+		return 2
+	}
+	if c.output != "table" && c.output != "json" {
+		fmt.Fprintf(c.stderr, "slotswapper-cli: -o must be table or json\n")
+		return 2
+	}
+
+	if c.config, err = loadConfig(c.configPath); err != nil {
+		fmt.Fprintf(c.stderr, "slotswapper-cli: %v\n", err)
+		return 1
+	}
+	if err := act(ctx, c, args); err != nil {
+		c.printError(err)
+		var usage usageError
+		if errors.As(err, &usage) {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// parseArgs parses flags anywhere among the positional arguments, so that
+// "swaps accept 7 -o json" works, and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional, args = append(positional, rest[0]), rest[1:]
+	}
+}
+
+// globalFlags are accepted by every command, before or after its name.
+func (c *cli) globalFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.configPath, "config", defaultConfigPath(), "path to the CLI configuration file")
+	fs.StringVar(&c.server, "server", "", "API base URL (default: the server used at login, or "+defaultServer+")")
+	fs.StringVar(&c.output, "o", "table", "output format: table or json")
+}
+
+// client returns an API client for the configured server, authenticated with
+// the saved token.
+func (c *cli) client() *client.Client {
+	return client.New(c.serverURL(), client.WithToken(c.config.Token), client.WithRetry(client.DefaultRetryPolicy))
+}
+
+func (c *cli) serverURL() string {
+	switch {
+	case c.server != "":
+		return c.server
+	case os.Getenv("SLOTSWAPPER_SERVER") != "":
+		return os.Getenv("SLOTSWAPPER_SERVER")
+	case c.config.Server != "":
+		return c.config.Server
+	}
+	return defaultServer
+}
+
+// usageError reports invalid arguments; it exits with status 2.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+func (c *cli) printError(err error) {
+	var apiErr *client.Error
+	if errors.As(err, &apiErr) && len(apiErr.Errors) > 0 {
+		fmt.Fprintf(c.stderr, "slotswapper-cli: %s\n", apiErr.Detail)
+		for _, field := range apiErr.Errors {
+			fmt.Fprintf(c.stderr, "  %s\n", field.Message)
+		}
+		return
+	}
+	if errors.Is(err, client.ErrUnauthorized) && c.config.Token == "" {
+		err = errors.New("not logged in; run slotswapper-cli login")
+	}
+	fmt.Fprintf(c.stderr, "slotswapper-cli: %v\n", err)
+}
+
+func (cmd *command) child(name string) *command {
+	for _, child := range cmd.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+func (cmd *command) usage(w io.Writer, path []string) {
+	line := strings.Join(path, " ")
+	if cmd.children != nil {
+		fmt.Fprintf(w, "Usage: %s <command> [flags]\n\n", line)
+		if cmd.summary != "" {
+			fmt.Fprintf(w, "%s\n\n", cmd.summary)
+		}
+		fmt.Fprintln(w, "Commands:")
+		for _, child := range cmd.children {
+			fmt.Fprintf(w, "  %-12s %s\n", child.name, child.summary)
+		}
+		return
+	}
+	fmt.Fprintf(w, "Usage: %s\n\n%s\n\nFlags:\n", strings.TrimSpace(line+" [flags] "+cmd.args), cmd.summary)
+}
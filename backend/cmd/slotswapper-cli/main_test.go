@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"slotswapper/client"
+	"slotswapper/internal/api"
+	"slotswapper/internal/crypto"
+	"slotswapper/internal/repository"
+	"slotswapper/internal/services"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	queries := repository.SetupTestDB(t)
+	userRepo := repository.NewUserRepository(queries)
+	eventRepo := repository.NewEventRepository(queries)
+	swapRepo := repository.NewSwapRequestRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	transactor := repository.NewTransactor(queries)
+	jwtManager := crypto.NewJWT("test-jwt-secret", 10*time.Minute)
+
+	server := api.NewServer(nil,
+		services.NewAuthService(userRepo, auditRepo, transactor, crypto.NewPassword(), jwtManager),
+		services.NewUserService(userRepo, eventRepo, swapRepo, repository.NewAccessTokenRepository(queries), auditRepo, repository.NewNotificationRepository(queries), transactor, crypto.NewPassword()),
+		services.NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(queries), transactor),
+		services.NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(queries), transactor),
+		services.NewAuditService(auditRepo, userRepo),
+		services.NewAccessTokenService(repository.NewAccessTokenRepository(queries), auditRepo, transactor),
+		services.NewIdempotencyService(repository.NewIdempotencyKeyRepository(queries), transactor, 0),
+		services.NewNotificationService(repository.NewNotificationRepository(queries)),
+		jwtManager,
+	)
+	router := http.NewServeMux()
+	server.RegisterRoutes(router)
+	ts := httptest.NewServer(api.TimeZoneMiddleware(router))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// user runs CLI commands with its own configuration file.
+type user struct {
+	t      *testing.T
+	config string
+	server string
+}
+
+func newUser(t *testing.T, ts *httptest.Server, name, email string) *user {
+	t.Helper()
+	_, err := client.New(ts.URL).SignUp(context.Background(), client.SignUpInput{Name: name, Email: email, Password: "password123"})
+	if err != nil {
+		t.Fatalf("sign up %s: %v", email, err)
+	}
+	return &user{t: t, config: filepath.Join(t.TempDir(), "cli.json"), server: ts.URL}
+}
+
+// run executes the CLI and returns its exit code, stdout and stderr.
+func (u *user) run(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	c := &cli{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr}
+	code := c.run(context.Background(), append(args, "-config", u.config))
+	return code, stdout.String(), stderr.String()
+}
+
+// mustRun executes the CLI with JSON output and decodes the result into v.
+func (u *user) mustRun(v any, args ...string) {
+	u.t.Helper()
+	code, stdout, stderr := u.run(append(args, "-o", "json")...)
+	if code != 0 {
+		u.t.Fatalf("%v exited with %d: %s", args, code, stderr)
+	}
+	if v != nil {
+		if err := json.Unmarshal([]byte(stdout), v); err != nil {
+			u.t.Fatalf("%v printed invalid JSON %q: %v", args, stdout, err)
+		}
+	}
+}
+
+func TestCLI(t *testing.T) {
+	ts := newTestServer(t)
+	alice := newUser(t, ts, "Alice", "alice@example.com")
+	bob := newUser(t, ts, "Bob", "bob@example.com")
+
+	t.Run("login saves the token", func(t *testing.T) {
+		code, _, stderr := alice.run("login", "-server", ts.URL, "-email", "alice@example.com", "-password", "password123")
+		if code != 0 {
+			t.Fatalf("login exited with %d: %s", code, stderr)
+		}
+		info, err := os.Stat(alice.config)
+		if err != nil {
+			t.Fatalf("config not written: %v", err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("expected config mode 0600, got %v", info.Mode().Perm())
+		}
+		cfg, _ := loadConfig(alice.config)
+		if cfg.Token == "" || cfg.Server != ts.URL {
+			t.Errorf("unexpected saved config %+v", cfg)
+		}
+
+		// Later commands reuse the saved server and token.
+		var me client.User
+		alice.mustRun(&me, "whoami")
+		if me.Email != "alice@example.com" {
+			t.Errorf("expected alice, got %+v", me)
+		}
+	})
+
+	t.Run("password from stdin", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		c := &cli{stdin: strings.NewReader("password123\n"), stdout: &stdout, stderr: &stderr}
+		code := c.run(context.Background(), []string{"login", "-server", ts.URL, "-email", "bob@example.com", "-config", bob.config})
+		if code != 0 {
+			t.Fatalf("login exited with %d: %s", code, stderr.String())
+		}
+		if !strings.Contains(stderr.String(), "Password: ") {
+			t.Errorf("expected a password prompt, got %q", stderr.String())
+		}
+	})
+
+	var aliceEvent, bobEvent client.Event
+	t.Run("create and list events", func(t *testing.T) {
+		alice.mustRun(&aliceEvent, "events", "create", "-title", "Night shift", "-start", "2030-05-06T22:00", "-duration", "8h", "-tz", "Europe/Berlin")
+		if aliceEvent.Status != client.StatusBusy || aliceEvent.EndTime.Sub(aliceEvent.StartTime) != 8*time.Hour {
+			t.Errorf("unexpected event %+v", aliceEvent)
+		}
+		if want := time.Date(2030, time.May, 6, 20, 0, 0, 0, time.UTC); !aliceEvent.StartTime.Equal(want) {
+			t.Errorf("expected start %v, got %v", want, aliceEvent.StartTime)
+		}
+
+		var series []client.Event
+		alice.mustRun(&series, "events", "create", "-title", "Standup", "-start", "2030-06-03T09:00:00Z", "-end", "2030-06-03T09:15:00Z", "-repeat", "daily", "-count", "3")
+		if len(series) != 3 {
+			t.Fatalf("expected 3 events, got %d", len(series))
+		}
+
+		var events []client.Event
+		alice.mustRun(&events, "events", "list", "-limit", "2")
+		if len(events) != 2 || events[0].ID != aliceEvent.ID {
+			t.Errorf("expected the first 2 events, got %+v", events)
+		}
+
+		code, stdout, _ := alice.run("events", "list")
+		if code != 0 || !strings.Contains(stdout, "TITLE") || strings.Count(stdout, "Standup") != 3 {
+			t.Errorf("unexpected table:\n%s", stdout)
+		}
+
+		alice.mustRun(&aliceEvent, "events", "swappable", formatID(aliceEvent.ID))
+		if aliceEvent.Status != client.StatusSwappable {
+			t.Errorf("expected SWAPPABLE, got %s", aliceEvent.Status)
+		}
+	})
+
+	t.Run("swap through the marketplace", func(t *testing.T) {
+		bob.mustRun(&bobEvent, "events", "create", "-title", "Day shift", "-start", "2030-05-07T08:00:00Z", "-status", "SWAPPABLE")
+
+		var slots []client.SwappableSlot
+		alice.mustRun(&slots, "marketplace")
+		if len(slots) != 1 || slots[0].ID != bobEvent.ID || slots[0].OwnerName != "Bob" {
+			t.Fatalf("expected Bob's slot in the marketplace, got %+v", slots)
+		}
+
+		var withdrawn client.SwapRequest
+		alice.mustRun(&withdrawn, "swaps", "request", "-mine", formatID(aliceEvent.ID), "-theirs", formatID(bobEvent.ID))
+		alice.mustRun(&withdrawn, "swaps", "withdraw", formatID(withdrawn.ID))
+		if withdrawn.Status != client.SwapWithdrawn {
+			t.Errorf("expected WITHDRAWN, got %s", withdrawn.Status)
+		}
+
+		var swap client.SwapRequest
+		alice.mustRun(&swap, "swaps", "request", "-mine", formatID(aliceEvent.ID), "-theirs", formatID(bobEvent.ID))
+
+		code, stdout, _ := bob.run("swaps", "incoming")
+		if code != 0 || !strings.Contains(stdout, "Alice") || !strings.Contains(stdout, "Night shift") {
+			t.Errorf("unexpected incoming table:\n%s", stdout)
+		}
+
+		var accepted client.SwapRequest
+		bob.mustRun(&accepted, "swaps", "accept", formatID(swap.ID))
+		if accepted.Status != client.SwapAccepted {
+			t.Errorf("expected ACCEPTED, got %s", accepted.Status)
+		}
+
+		code, stdout, _ = alice.run("swaps", "history", "-status", "ACCEPTED")
+		if code != 0 || !strings.Contains(stdout, "ACCEPTED") || !strings.Contains(stdout, "Bob") {
+			t.Errorf("unexpected history table:\n%s", stdout)
+		}
+	})
+
+	t.Run("claim a giveaway", func(t *testing.T) {
+		var spare client.Event
+		bob.mustRun(&spare, "events", "create", "-title", "Spare shift", "-start", "2030-05-09T08:00:00Z")
+		bob.mustRun(&spare, "events", "giveaway", "-mode", "owner_picks", formatID(spare.ID))
+		if spare.Giveaway == nil || *spare.Giveaway != client.GiveawayOwnerPicks {
+			t.Fatalf("expected the shift to be given away, got %+v", spare)
+		}
+
+		code, stdout, _ := alice.run("marketplace")
+		if code != 0 || !strings.Contains(stdout, "OWNER_PICKS") {
+			t.Errorf("unexpected marketplace table:\n%s", stdout)
+		}
+
+		var claim client.SwapRequest
+		alice.mustRun(&claim, "swaps", "claim", formatID(spare.ID))
+		if claim.Kind != client.KindTransfer || claim.Status != client.SwapPending {
+			t.Errorf("expected a pending transfer, got %+v", claim)
+		}
+		code, stdout, _ = bob.run("swaps", "incoming")
+		if code != 0 || !strings.Contains(stdout, "(nothing)") || !strings.Contains(stdout, "Spare shift") {
+			t.Errorf("unexpected incoming table:\n%s", stdout)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		code, _, stderr := alice.run("events", "create", "-title", "Backwards", "-start", "2030-05-08T10:00:00Z", "-end", "2030-05-08T09:00:00Z")
+		if code != 1 || !strings.Contains(stderr, "end_time must be after start_time") {
+			t.Errorf("expected a validation error, got %d: %s", code, stderr)
+		}
+
+		code, _, stderr = alice.run("swaps", "accept", "abc")
+		if code != 2 || !strings.Contains(stderr, "invalid ID") {
+			t.Errorf("expected a usage error, got %d: %s", code, stderr)
+		}
+
+		code, _, _ = alice.run("events", "list", "-o", "yaml")
+		if code != 2 {
+			t.Errorf("expected exit code 2 for an unknown format, got %d", code)
+		}
+
+		code, _, _ = alice.run("logout")
+		if code != 0 {
+			t.Fatalf("logout exited with %d", code)
+		}
+		code, _, stderr = alice.run("whoami")
+		if code != 1 || !strings.Contains(stderr, "not logged in") {
+			t.Errorf("expected a login hint, got %d: %s", code, stderr)
+		}
+	})
+}
+
+func TestCompletion(t *testing.T) {
+	root := commands()
+	tests := []struct {
+		words []string
+		want  []string
+	}{
+		{nil, []string{"login", "events", "swaps", "completion", "-o"}},
+		{[]string{"events"}, []string{"list", "create", "swappable"}},
+		{[]string{"events", "create"}, []string{"-title", "-start", "-repeat", "-allow-overlap"}},
+		{[]string{"events", "create", "-status"}, []string{"BUSY", "SWAPPABLE"}},
+		{[]string{"swaps", "history", "-o"}, []string{"table", "json"}},
+		{[]string{"swaps", "accept", "-allow-overlap"}, []string{"-allow-overlap", "-server"}},
+		{[]string{"events", "giveaway", "-mode"}, []string{"FIRST_COME", "OWNER_PICKS"}},
+	}
+	for _, tt := range tests {
+		got := complete(root, tt.words)
+		for _, want := range tt.want {
+			if !slices.Contains(got, want) {
+				t.Errorf("complete(%q) = %q, missing %q", tt.words, got, want)
+			}
+		}
+	}
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		var stdout bytes.Buffer
+		c := &cli{stdout: &stdout, stderr: &bytes.Buffer{}}
+		if code := c.run(context.Background(), []string{"completion", shell, "-config", filepath.Join(t.TempDir(), "cli.json")}); code != 0 {
+			t.Errorf("completion %s exited with %d", shell, code)
+		}
+		if !strings.Contains(stdout.String(), "slotswapper-cli __complete") {
+			t.Errorf("completion %s does not call __complete:\n%s", shell, stdout.String())
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// table is a list of rows printed with aligned columns.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// print writes v as indented JSON with -o json, or the table built by toTable
+// otherwise.
+func (c *cli) print(v any, toTable func() *table) error {
+	if c.output == "json" {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	t := toTable()
+	if len(t.rows) == 0 {
+		fmt.Fprintln(c.stderr, "No results.")
+		return nil
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// formatTime renders t in the local zone. Times shown in tables are compact;
+// JSON output keeps full RFC 3339 timestamps.
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04 MST")
+}
+
+func formatID(id int64) string {
+	return fmt.Sprint(id)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"slotswapper/internal/crypto"
+)
+
+const keysUsage = `usage: slotswapper keys <command> [flags]
+
+Manages the key set that signs access tokens (jwtKeysFile).
+
+commands:
+  generate  create a new key set with one key
+  rotate    add a new signing key, keeping older keys for verification
+  list      show the keys in the set, newest first
+
+The server reads the key set at startup; restart it after a change.
+`
+
+// runKeys runs the "keys" subcommand and returns the exit code.
+func runKeys(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, keysUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("slotswapper keys "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	defaultFile := os.Getenv("SLOTSWAPPER_JWT_KEYS_FILE")
+	if defaultFile == "" {
+		defaultFile = "jwt-keys.json"
+	}
+	file := fs.String("file", defaultFile, "path to the key set (env SLOTSWAPPER_JWT_KEYS_FILE)")
+	alg := crypto.AlgEdDSA
+	keep := 2
+	grace := time.Hour
+	switch args[0] {
+	case "generate":
+		fs.StringVar(&alg, "alg", alg, "signing algorithm: EdDSA or RS256")
+	case "rotate":
+		fs.StringVar(&alg, "alg", alg, "signing algorithm: EdDSA or RS256")
+		fs.IntVar(&keep, "keep", keep, "number of keys to keep, including the new one; keep one more than fit in an access token lifetime")
+		fs.DurationVar(&grace, "grace", grace, "how long the new key is only published before it signs tokens; allow for verifiers' JWKS caches")
+	case "list":
+	default:
+		fmt.Fprintf(stderr, "unknown keys command %q\n\n%s", args[0], keysUsage)
+		return 2
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "generate":
+		err = generateKeys(*file, alg, stdout)
+	case "rotate":
+		err = rotateKeys(*file, alg, keep, grace, stdout)
+	case "list":
+		err = listKeys(*file, stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
+
+func generateKeys(file, alg string, stdout io.Writer) error {
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("%s already exists; use rotate to add a key", file)
+	}
+	key, err := crypto.GenerateSigningKey(alg)
+	if err != nil {
+		return err
+	}
+	if err := crypto.SaveKeySet(file, &crypto.KeySet{Keys: []crypto.SigningKey{key}}); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "created %s with %s key %s\n", file, key.Algorithm, key.ID)
+	return nil
+}
+
+func rotateKeys(file, alg string, keep int, grace time.Duration, stdout io.Writer) error {
+	keys, err := crypto.LoadKeySet(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s does not exist; use generate to create it", file)
+	}
+	if err != nil {
+		return err
+	}
+	key, err := crypto.GenerateSigningKey(alg)
+	if err != nil {
+		return err
+	}
+	keys.Rotate(key, keep, grace)
+	if err := crypto.SaveKeySet(file, keys); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s key %s signs tokens from %s or the next rotation; %d keys kept. Restart the server to publish it.\n", key.Algorithm, key.ID, keys.Keys[0].ActivatesAt.Format(time.RFC3339), len(keys.Keys))
+	return nil
+}
+
+func listKeys(file string, stdout io.Writer) error {
+	keys, err := crypto.LoadKeySet(file)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	signing, _ := keys.Signing(now)
+	for _, key := range keys.Keys {
+		role := "verify"
+		switch {
+		case key.ActivatesAt.After(now):
+			role = "pending until " + key.ActivatesAt.Format(time.RFC3339)
+		case key.ID == signing.ID:
+			role = "sign"
+		}
+		fmt.Fprintf(stdout, "%s\t%s\t%s\t%s\n", key.ID, key.Algorithm, key.CreatedAt.Format(time.RFC3339), role)
+	}
+	return nil
+}
@@ -1,66 +1,123 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"flag"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
+	// The runtime image has no zoneinfo database; embed it for time zones.
+	_ "time/tzdata"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/rs/cors"
 
+	"slotswapper/db/migrations"
 	"slotswapper/internal/api"
+	appconfig "slotswapper/internal/config"
 	"slotswapper/internal/crypto"
 	"slotswapper/internal/db"
+	"slotswapper/internal/logging"
+	"slotswapper/internal/metrics"
 	"slotswapper/internal/repository"
 	"slotswapper/internal/services"
+	"slotswapper/internal/tracing"
 )
 
 func main() {
-	configPath := flag.String("config", "config.json", "path to configuration file")
-	dbPath := flag.String("db", "db/slotswapper.db", "path to database file")
-	port := os.Getenv("PORT")
-	flag.Parse()
-	config, err := api.LoadConfig(*configPath)
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		os.Exit(runKeys(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "states" {
+		os.Exit(runStates(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	promoteAdmin := flag.String("promote-admin", "", "grant admin rights to the user with this email and exit")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration, with secrets redacted, and exit")
+	config, err := appconfig.Load(flag.CommandLine, os.Args[1:], os.Getenv)
 	if err != nil {
-		log.Fatalf("failed to load config: %v", err)
+		fatal("invalid configuration", err)
 	}
-	applyRenderCloudConfig(config)
-	
-	dbConn, err := sql.Open("sqlite3", *dbPath)
+	if *printConfig {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		enc.Encode(config.Redacted())
+		return
+	}
+
+	logger, err := logging.New(os.Stderr, config.LogFormat, config.LogLevel)
 	if err != nil {
-		log.Fatalf("failed to open database: %v", err)
+		fatal("invalid logging config", err)
+	}
+	slog.SetDefault(logger)
+	if config.JWTKeysFile == "" && config.JWTSecret == appconfig.DevJWTSecret {
+		slog.Warn("signing tokens with the development JWT secret; set jwtSecret before exposing the server")
 	}
-	defer dbConn.Close()
 
-	migrations, err := os.ReadFile("./db/migrations/001_initial_schema.sql")
+	shutdownTracing, err := tracing.Setup(context.Background(), os.Stdout, config.TracingExporter, config.OTLPEndpoint)
 	if err != nil {
-		log.Fatalf("failed to read migrations: %v", err)
+		fatal("invalid tracing config", err)
 	}
 
-	_, err = dbConn.Exec(string(migrations))
+	dbConn, err := sql.Open("sqlite3", config.DatabaseDSN)
 	if err != nil {
-		log.Fatalf("failed to run migrations: %v", err)
+		fatal("failed to open database", err)
+	}
+	defer dbConn.Close()
+
+	if err := migrations.Apply(context.Background(), dbConn); err != nil {
+		fatal("failed to run migrations", err)
 	}
 
-	queries := db.New(dbConn)
+	// The marketplace gauges query the database on every scrape; keep those
+	// queries out of the query timings.
+	appMetrics := metrics.New(db.New(dbConn))
+	queries := db.NewObserved(dbConn, appMetrics.ObserveQuery)
+
+	if *promoteAdmin != "" {
+		user, err := queries.GetUserByEmail(context.Background(), *promoteAdmin)
+		if err != nil {
+			fatal("failed to find user "+*promoteAdmin, err)
+		}
+		if err := queries.UpdateUserIsAdmin(context.Background(), db.UpdateUserIsAdminParams{IsAdmin: true, ID: user.ID}); err != nil {
+			fatal("failed to promote user", err)
+		}
+		slog.Info("user promoted to admin", "user_id", user.ID, "email", user.Email)
+		return
+	}
 
 	passwordCrypto := crypto.NewPassword()
-	jwtManager := crypto.NewJWT("supersecretjwtkey", time.Hour*24) // TODO: Move secret to config
+	jwtManager, err := newJWTManager(config)
+	if err != nil {
+		fatal("failed to load JWT keys", err)
+	}
 
 	userRepo := repository.NewUserRepository(queries)
 	eventRepo := repository.NewEventRepository(queries)
 	swapRepo := repository.NewSwapRequestRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	notificationRepo := repository.NewNotificationRepository(queries)
+	transactor := repository.NewTransactor(queries)
 
-	authService := services.NewAuthService(userRepo, passwordCrypto, jwtManager)
-	userService := services.NewUserService(userRepo, passwordCrypto)
-	eventService := services.NewEventService(eventRepo, userRepo, swapRepo)
-	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo)
+	authService := services.NewAuthService(userRepo, auditRepo, transactor, passwordCrypto, jwtManager)
+	userService := services.NewUserService(userRepo, eventRepo, swapRepo, repository.NewAccessTokenRepository(queries), auditRepo, notificationRepo, transactor, passwordCrypto)
+	eventService := services.NewEventService(eventRepo, userRepo, swapRepo, auditRepo, notificationRepo, transactor)
+	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, notificationRepo, transactor)
+	auditService := services.NewAuditService(auditRepo, userRepo)
+	accessTokenService := services.NewAccessTokenService(repository.NewAccessTokenRepository(queries), auditRepo, transactor)
+	notificationService := services.NewNotificationService(notificationRepo)
+	idempotencyService := services.NewIdempotencyService(repository.NewIdempotencyKeyRepository(queries), transactor, time.Duration(config.IdempotencyKeyTTL))
 
+	server := api.NewServer(&config.Config, authService, userService, eventService, swapRequestService, auditService, accessTokenService, idempotencyService, notificationService, jwtManager)
 
-	server := api.NewServer(config, authService, userService, eventService, swapRequestService, jwtManager)
+	// Validate has already rejected malformed entries.
+	trustedProxies, _ := config.TrustedProxyPrefixes()
 
 	router := http.NewServeMux()
 	server.RegisterRoutes(router)
@@ -68,21 +125,63 @@ func main() {
 	// Setup CORS middleware
 	c := cors.New(cors.Options{
 		AllowedOrigins:   config.AllowedOrigins,
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "X-Request-ID", "Idempotency-Key", "If-Match", "If-None-Match", "traceparent", "tracestate"},
+		ExposedHeaders:   []string{"X-Request-ID", "Idempotent-Replayed", "ETag"},
 		AllowCredentials: true,
-		Debug:            true,
 	})
 
-	handler := c.Handler(router)
+	handler := api.LoggingMiddleware(logger, router)(api.MetricsMiddleware(appMetrics, router)(api.TracingMiddleware(router)(c.Handler(api.RequestMetadataMiddleware(trustedProxies)(api.TimeZoneMiddleware(router))))))
+
+	// Drain on SIGINT or SIGTERM, then flush the traces and close the
+	// database once no request is left running.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// The metrics listener stops with the API server, and before the
+	// database closes, since scrapes read the marketplace gauges from it.
+	metricsCtx, stopMetrics := context.WithCancel(ctx)
+	metricsDone := make(chan struct{})
+	go func() {
+		defer close(metricsDone)
+		if config.MetricsAddr == "" {
+			return
+		}
+		if err := api.ServeMetrics(metricsCtx, config.MetricsAddr, appMetrics.Handler()); err != nil {
+			slog.Error("metrics server failed", "error", err)
+		}
+	}()
+	serveErr := server.ListenAndServe(ctx, handler)
+	stopMetrics()
+	<-metricsDone
+	if err := shutdownTracing(context.Background()); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+	}
+	if err := dbConn.Close(); err != nil {
+		slog.Error("failed to close database", "error", err)
+	}
+	if serveErr != nil {
+		fatal("server failed", serveErr)
+	}
+	slog.Info("server stopped")
+}
 
-	Addr := ":8080"
-	if config != nil && config.Addr != "" {
-		Addr = config.Addr
+// newJWTManager signs tokens with the configured key set, or with the shared
+// secret when there is none.
+func newJWTManager(config *appconfig.Config) (crypto.JWT, error) {
+	ttl := time.Duration(config.AccessTokenTTL)
+	opts := []crypto.JWTOption{crypto.WithIssuer(config.JWTIssuer), crypto.WithAudience(config.JWTAudience)}
+	if config.JWTKeysFile == "" {
+		return crypto.NewJWT(config.JWTSecret, ttl, opts...), nil
 	}
-	if port != "" {
-		Addr = ":" + port
+	keys, err := crypto.LoadKeySet(config.JWTKeysFile)
+	if err != nil {
+		return nil, err
 	}
-	log.Printf("Server starting on %s\n", Addr)
-	log.Fatal(http.ListenAndServe(Addr, handler))
+	return crypto.NewKeySetJWT(keys, ttl, opts...), nil
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
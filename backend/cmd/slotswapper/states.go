@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"slotswapper/internal/services"
+)
+
+// runStates runs the "states" subcommand, which prints the event and swap
+// request state machines, and returns the exit code.
+func runStates(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("slotswapper states", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "dot", "output format: dot (Graphviz) or json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	graphs := []services.StateGraph{services.EventStateMachine.Graph(), services.SwapStateMachine.Graph()}
+	switch *format {
+	case "dot":
+		for _, graph := range graphs {
+			fmt.Fprint(stdout, graph.DOT())
+		}
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(graphs); err != nil {
+			fmt.Fprintln(stderr, "error:", err)
+			return 1
+		}
+	default:
+		fmt.Fprintf(stderr, "unknown format %q; use dot or json\n", *format)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,50 @@
+-- 002_audit_logs.sql
+
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_user_id INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    entity_type TEXT NOT NULL CHECK(entity_type IN ('user', 'event', 'swap_request')),
+    entity_id INTEGER NOT NULL,
+    before_value TEXT NOT NULL DEFAULT 'null',
+    after_value TEXT NOT NULL DEFAULT 'null',
+    request_id TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Users whose data an entry touched, e.g. both the previous and the new owner
+-- of a swapped slot. Drives the per-user history view.
+CREATE TABLE IF NOT EXISTS audit_log_subjects (
+    audit_log_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    PRIMARY KEY (audit_log_id, user_id),
+    FOREIGN KEY (audit_log_id) REFERENCES audit_logs(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs(entity_type, entity_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs(actor_user_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_subjects_user ON audit_log_subjects(user_id, audit_log_id);
+
+CREATE TRIGGER IF NOT EXISTS audit_logs_no_update BEFORE UPDATE ON audit_logs
+BEGIN
+    SELECT RAISE(ABORT, 'audit_logs is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_logs_no_delete BEFORE DELETE ON audit_logs
+BEGIN
+    SELECT RAISE(ABORT, 'audit_logs is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_subjects_no_update BEFORE UPDATE ON audit_log_subjects
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log_subjects is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_subjects_no_delete BEFORE DELETE ON audit_log_subjects
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log_subjects is append-only');
+END;
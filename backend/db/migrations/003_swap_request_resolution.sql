@@ -0,0 +1,7 @@
+-- 003_swap_request_resolution.sql
+
+ALTER TABLE swap_requests ADD COLUMN resolved_by_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE swap_requests ADD COLUMN resolved_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_swap_requests_responder ON swap_requests(responder_user_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_swap_requests_requester ON swap_requests(requester_user_id, status, created_at);
@@ -0,0 +1,16 @@
+-- 004_list_pagination.sql
+
+-- Keyset pagination compares event times as text, which only orders
+-- correctly when every value carries the same offset. Events are now written
+-- in UTC; rewrite the rows stored with the client's offset to match.
+UPDATE events
+SET start_time = strftime('%Y-%m-%d %H:%M:%S', start_time) || '+00:00',
+    end_time = strftime('%Y-%m-%d %H:%M:%S', end_time) || '+00:00'
+WHERE start_time NOT LIKE '%+00:00' OR end_time NOT LIKE '%+00:00';
+
+-- The implicit rowid at the end of each index provides the id tie-breaker.
+CREATE INDEX IF NOT EXISTS idx_events_user_start ON events(user_id, start_time);
+CREATE INDEX IF NOT EXISTS idx_events_status_start ON events(status, start_time);
+
+CREATE INDEX IF NOT EXISTS idx_swap_requests_responder_pending ON swap_requests(responder_user_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_swap_requests_requester_pending ON swap_requests(requester_user_id) WHERE status = 'PENDING';
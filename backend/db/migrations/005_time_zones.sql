@@ -0,0 +1,6 @@
+-- 005_time_zones.sql
+
+-- IANA zone names. Event times themselves stay in UTC; the zone records the
+-- wall clock the owner meant so that recurrences and rendering follow DST.
+ALTER TABLE users ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC';
+ALTER TABLE events ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC';
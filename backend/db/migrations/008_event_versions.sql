@@ -0,0 +1,5 @@
+-- 008_event_versions.sql
+
+-- Incremented on every change to an event. It is the event's ETag, so that a
+-- client can make a write conditional on having seen the latest version.
+ALTER TABLE events ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,55 @@
+-- 009_swap_cancellation.sql
+
+-- A pending swap request that can no longer go ahead, because one of its
+-- slots was edited or deleted or one of its users left, is closed as
+-- CANCELLED with a reason instead of being deleted. SQLite cannot change a
+-- CHECK constraint in place, so the table is rebuilt.
+CREATE TABLE swap_requests_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    requester_user_id INTEGER NOT NULL,
+    responder_user_id INTEGER NOT NULL,
+    requester_slot_id INTEGER NOT NULL,
+    responder_slot_id INTEGER NOT NULL,
+    status TEXT NOT NULL CHECK(status IN ('PENDING', 'ACCEPTED', 'REJECTED', 'CANCELLED')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_by_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    cancel_reason TEXT CHECK(cancel_reason IN ('slot_modified', 'slot_deleted', 'requester_withdrew', 'user_deactivated')),
+    FOREIGN KEY (requester_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (responder_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (requester_slot_id) REFERENCES events(id) ON DELETE CASCADE,
+    FOREIGN KEY (responder_slot_id) REFERENCES events(id) ON DELETE CASCADE,
+    CHECK((status = 'CANCELLED') = (cancel_reason IS NOT NULL))
+);
+
+INSERT INTO swap_requests_new (id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at, resolved_by_user_id, resolved_at)
+SELECT id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at, resolved_by_user_id, resolved_at
+FROM swap_requests;
+
+DROP TABLE swap_requests;
+ALTER TABLE swap_requests_new RENAME TO swap_requests;
+
+CREATE INDEX IF NOT EXISTS idx_swap_requests_responder ON swap_requests(responder_user_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_swap_requests_requester ON swap_requests(requester_user_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_swap_requests_responder_pending ON swap_requests(responder_user_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_swap_requests_requester_pending ON swap_requests(requester_user_id) WHERE status = 'PENDING';
+
+-- Removing an account deactivates it: the row stays so that swap history and
+-- audit entries keep their names, but the user can no longer sign in.
+ALTER TABLE users ADD COLUMN deactivated_at TIMESTAMP;
+
+-- Messages for a user about changes other people made to their swaps.
+CREATE TABLE IF NOT EXISTS notifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    swap_request_id INTEGER,
+    reason TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, id);
@@ -0,0 +1,36 @@
+-- 010_keep_closed_swap_requests.sql
+
+-- A swap request outlives its slots: deleting an event closes its pending
+-- requests as CANCELLED, and those, like accepted and rejected ones, stay in
+-- both users' history. The slot columns therefore no longer cascade; they
+-- keep the ID of the deleted event, and the history shows it without a title
+-- or times.
+CREATE TABLE swap_requests_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    requester_user_id INTEGER NOT NULL,
+    responder_user_id INTEGER NOT NULL,
+    requester_slot_id INTEGER NOT NULL,
+    responder_slot_id INTEGER NOT NULL,
+    status TEXT NOT NULL CHECK(status IN ('PENDING', 'ACCEPTED', 'REJECTED', 'CANCELLED')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_by_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    cancel_reason TEXT CHECK(cancel_reason IN ('slot_modified', 'slot_deleted', 'requester_withdrew', 'user_deactivated')),
+    FOREIGN KEY (requester_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (responder_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK((status = 'CANCELLED') = (cancel_reason IS NOT NULL))
+);
+
+INSERT INTO swap_requests_new SELECT * FROM swap_requests;
+
+DROP TABLE swap_requests;
+ALTER TABLE swap_requests_new RENAME TO swap_requests;
+
+CREATE INDEX IF NOT EXISTS idx_swap_requests_responder ON swap_requests(responder_user_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_swap_requests_requester ON swap_requests(requester_user_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_swap_requests_responder_pending ON swap_requests(responder_user_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_swap_requests_requester_pending ON swap_requests(requester_user_id) WHERE status = 'PENDING';
+-- Looking up the requests for a slot that is being changed or deleted.
+CREATE INDEX IF NOT EXISTS idx_swap_requests_requester_slot ON swap_requests(requester_slot_id);
+CREATE INDEX IF NOT EXISTS idx_swap_requests_responder_slot ON swap_requests(responder_slot_id);
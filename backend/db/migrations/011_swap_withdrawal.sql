@@ -0,0 +1,34 @@
+-- 011_swap_withdrawal.sql
+
+-- A requester who takes an offer back now withdraws it, closing the request
+-- as WITHDRAWN, instead of rejecting it, so that withdrawals can be told
+-- apart from the responder's rejections. That replaces the
+-- requester_withdrew cancel reason, which was never recorded.
+CREATE TABLE swap_requests_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    requester_user_id INTEGER NOT NULL,
+    responder_user_id INTEGER NOT NULL,
+    requester_slot_id INTEGER NOT NULL,
+    responder_slot_id INTEGER NOT NULL,
+    status TEXT NOT NULL CHECK(status IN ('PENDING', 'ACCEPTED', 'REJECTED', 'WITHDRAWN', 'CANCELLED')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_by_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    cancel_reason TEXT CHECK(cancel_reason IN ('slot_modified', 'slot_deleted', 'user_deactivated')),
+    FOREIGN KEY (requester_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (responder_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK((status = 'CANCELLED') = (cancel_reason IS NOT NULL))
+);
+
+INSERT INTO swap_requests_new SELECT * FROM swap_requests;
+
+DROP TABLE swap_requests;
+ALTER TABLE swap_requests_new RENAME TO swap_requests;
+
+CREATE INDEX IF NOT EXISTS idx_swap_requests_responder ON swap_requests(responder_user_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_swap_requests_requester ON swap_requests(requester_user_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_swap_requests_responder_pending ON swap_requests(responder_user_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_swap_requests_requester_pending ON swap_requests(requester_user_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_swap_requests_requester_slot ON swap_requests(requester_slot_id);
+CREATE INDEX IF NOT EXISTS idx_swap_requests_responder_slot ON swap_requests(responder_slot_id);
@@ -0,0 +1,40 @@
+-- 013_slot_transfers.sql
+
+-- An owner can give a swappable slot away, to whoever claims it first or to
+-- the claimant they pick. The giveaway ends when the slot leaves the
+-- marketplace.
+ALTER TABLE events ADD COLUMN giveaway TEXT CHECK(giveaway IS NULL OR giveaway IN ('FIRST_COME', 'OWNER_PICKS') AND status = 'SWAPPABLE');
+
+-- A claim is a TRANSFER request: it asks for the responder's slot and offers
+-- none in return, so it has no requester slot.
+CREATE TABLE swap_requests_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    requester_user_id INTEGER NOT NULL,
+    responder_user_id INTEGER NOT NULL,
+    requester_slot_id INTEGER,
+    responder_slot_id INTEGER NOT NULL,
+    status TEXT NOT NULL CHECK(status IN ('PENDING', 'ACCEPTED', 'REJECTED', 'WITHDRAWN', 'SUPERSEDED', 'CANCELLED')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_by_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    cancel_reason TEXT CHECK(cancel_reason IN ('slot_modified', 'slot_deleted', 'user_deactivated')),
+    kind TEXT NOT NULL DEFAULT 'SWAP' CHECK(kind IN ('SWAP', 'TRANSFER')),
+    FOREIGN KEY (requester_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (responder_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK((status = 'CANCELLED') = (cancel_reason IS NOT NULL)),
+    CHECK((kind = 'SWAP') = (requester_slot_id IS NOT NULL))
+);
+
+INSERT INTO swap_requests_new (id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at, resolved_by_user_id, resolved_at, cancel_reason)
+SELECT id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at, resolved_by_user_id, resolved_at, cancel_reason FROM swap_requests;
+
+DROP TABLE swap_requests;
+ALTER TABLE swap_requests_new RENAME TO swap_requests;
+
+CREATE INDEX IF NOT EXISTS idx_swap_requests_responder ON swap_requests(responder_user_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_swap_requests_requester ON swap_requests(requester_user_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_swap_requests_responder_pending ON swap_requests(responder_user_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_swap_requests_requester_pending ON swap_requests(requester_user_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_swap_requests_requester_slot ON swap_requests(requester_slot_id);
+CREATE INDEX IF NOT EXISTS idx_swap_requests_responder_slot ON swap_requests(responder_slot_id);
@@ -0,0 +1,12 @@
+-- 014_idempotency_headers.sql
+
+-- Replays restore every header the handler set, such as ETag and Location,
+-- not just Content-Type. response_headers holds them as a JSON object of
+-- header names to value lists.
+ALTER TABLE idempotency_keys ADD COLUMN response_headers TEXT NOT NULL DEFAULT '{}';
+
+UPDATE idempotency_keys
+SET response_headers = json_object('Content-Type', json_array(content_type))
+WHERE content_type != '';
+
+ALTER TABLE idempotency_keys DROP COLUMN content_type;
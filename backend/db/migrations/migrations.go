@@ -0,0 +1,67 @@
+// Package migrations embeds the SQL schema files in this directory and applies
+// them in lexical order, recording each applied file in schema_migrations.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Apply runs every migration that has not been recorded yet. Each file runs in
+// its own transaction together with its bookkeeping row.
+func Apply(ctx context.Context, conn *sql.DB) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version TEXT PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var applied int
+		err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations WHERE version = ?", name).Scan(&applied)
+		if err != nil {
+			return fmt.Errorf("failed to check migration %s: %w", name, err)
+		}
+		if applied > 0 {
+			continue
+		}
+
+		contents, err := files.ReadFile(name)
+		if err != nil {
+			return err
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, string(contents)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %s: %w", name, err)
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES (?)", name); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %s: %w", name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
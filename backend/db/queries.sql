@@ -14,11 +14,28 @@ SELECT * FROM users
 WHERE email = ?;
 
 -- name: GetUserByID :one
-SELECT id, name, email, created_at, updated_at FROM users
+SELECT id, name, email, is_admin, time_zone, created_at, updated_at FROM users
+WHERE id = ? AND deactivated_at IS NULL;
+
+-- name: UpdateUserTimeZone :exec
+UPDATE users
+SET time_zone = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: UpdateUserIsAdmin :exec
+UPDATE users
+SET is_admin = ?
 WHERE id = ?;
 
 -- name: GetPublicUserByID :one
 SELECT id, name, created_at, updated_at FROM users
+WHERE id = ? AND deactivated_at IS NULL;
+
+-- name: DeactivateUser :exec
+UPDATE users
+SET deactivated_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
 WHERE id = ?;
 
 -- name: CreateEvent :one
@@ -27,12 +44,14 @@ INSERT INTO events (
     start_time,
     end_time,
     status,
-    user_id
+    user_id,
+    time_zone
 ) VALUES (
     ?,
     ?,
     ?,
     ?,
+    ?,
     ?
 ) RETURNING *;
 
@@ -50,13 +69,16 @@ WHERE user_id = ? AND status = ?;
 
 -- name: UpdateEventStatus :one
 UPDATE events
-SET status = ?
+SET status = ?,
+    giveaway = ?,
+    version = version + 1
 WHERE id = ?
 RETURNING *;
 
 -- name: UpdateEventUserID :one
 UPDATE events
-SET user_id = ?
+SET user_id = ?,
+    version = version + 1
 WHERE id = ?
 RETURNING *;
 
@@ -69,30 +91,105 @@ UPDATE events
 SET title = ?,
     start_time = ?,
     end_time = ?,
-    status = ?
+    status = ?,
+    giveaway = ?,
+    time_zone = ?,
+    version = version + 1
 WHERE id = ?
 RETURNING *;
 
 -- name: GetSwappableEvents :many
 SELECT
-    e.id, e.title, e.start_time, e.end_time, e.status, e.user_id, e.created_at, e.updated_at,
+    e.id, e.title, e.start_time, e.end_time, e.status, e.giveaway, e.user_id, e.time_zone, e.created_at, e.updated_at,
     u.name as owner_name
 FROM events e
 JOIN users u ON e.user_id = u.id
 WHERE e.status = 'SWAPPABLE' AND e.user_id != ?;
 
+-- name: CountEventsByStatus :one
+SELECT COUNT(*) FROM events
+WHERE status = ?;
+
+-- name: ListOverlappingEvents :many
+SELECT * FROM events
+WHERE user_id = sqlc.arg(user_id)
+    AND start_time < sqlc.arg(end_time)
+    AND end_time > sqlc.arg(start_time)
+    AND id != sqlc.arg(exclude_id)
+ORDER BY start_time, id;
+
+-- name: ListEventsByUserID :many
+SELECT * FROM events
+WHERE user_id = sqlc.arg(user_id)
+    AND status = COALESCE(sqlc.narg(status), status)
+    AND start_time >= COALESCE(sqlc.narg(start_from), start_time)
+    AND start_time <= COALESCE(sqlc.narg(start_to), start_time)
+    AND end_time >= COALESCE(sqlc.narg(end_from), end_time)
+    AND end_time <= COALESCE(sqlc.narg(end_to), end_time)
+    AND start_time >= sqlc.arg(after_start_time)
+    AND (start_time > sqlc.arg(after_start_time) OR id > sqlc.arg(after_id))
+ORDER BY start_time, id
+LIMIT sqlc.arg(limit);
+
+-- name: ListEventsByUserIDDesc :many
+SELECT * FROM events
+WHERE user_id = sqlc.arg(user_id)
+    AND status = COALESCE(sqlc.narg(status), status)
+    AND start_time >= COALESCE(sqlc.narg(start_from), start_time)
+    AND start_time <= COALESCE(sqlc.narg(start_to), start_time)
+    AND end_time >= COALESCE(sqlc.narg(end_from), end_time)
+    AND end_time <= COALESCE(sqlc.narg(end_to), end_time)
+    AND start_time <= sqlc.arg(after_start_time)
+    AND (start_time < sqlc.arg(after_start_time) OR id < sqlc.arg(after_id))
+ORDER BY start_time DESC, id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: ListSwappableEvents :many
+SELECT
+    e.id, e.title, e.start_time, e.end_time, e.status, e.giveaway, e.user_id, e.time_zone, e.version, e.created_at, e.updated_at,
+    u.name as owner_name
+FROM events e
+JOIN users u ON e.user_id = u.id
+WHERE e.status = 'SWAPPABLE' AND e.user_id != sqlc.arg(user_id)
+    AND e.start_time >= COALESCE(sqlc.narg(start_from), e.start_time)
+    AND e.start_time <= COALESCE(sqlc.narg(start_to), e.start_time)
+    AND e.end_time >= COALESCE(sqlc.narg(end_from), e.end_time)
+    AND e.end_time <= COALESCE(sqlc.narg(end_to), e.end_time)
+    AND e.start_time >= sqlc.arg(after_start_time)
+    AND (e.start_time > sqlc.arg(after_start_time) OR e.id > sqlc.arg(after_id))
+ORDER BY e.start_time, e.id
+LIMIT sqlc.arg(limit);
+
+-- name: ListSwappableEventsDesc :many
+SELECT
+    e.id, e.title, e.start_time, e.end_time, e.status, e.giveaway, e.user_id, e.time_zone, e.version, e.created_at, e.updated_at,
+    u.name as owner_name
+FROM events e
+JOIN users u ON e.user_id = u.id
+WHERE e.status = 'SWAPPABLE' AND e.user_id != sqlc.arg(user_id)
+    AND e.start_time >= COALESCE(sqlc.narg(start_from), e.start_time)
+    AND e.start_time <= COALESCE(sqlc.narg(start_to), e.start_time)
+    AND e.end_time >= COALESCE(sqlc.narg(end_from), e.end_time)
+    AND e.end_time <= COALESCE(sqlc.narg(end_to), e.end_time)
+    AND e.start_time <= sqlc.arg(after_start_time)
+    AND (e.start_time < sqlc.arg(after_start_time) OR e.id < sqlc.arg(after_id))
+ORDER BY e.start_time DESC, e.id DESC
+LIMIT sqlc.arg(limit);
+
 -- name: CreateSwapRequest :one
 INSERT INTO swap_requests (
     requester_user_id,
     responder_user_id,
     requester_slot_id,
     responder_slot_id,
-    status
+    status,
+    kind
 ) VALUES (
     ?,
     ?,
     ?,
     ?,
+    ?,
     ?
 ) RETURNING *;
 
@@ -106,21 +203,47 @@ SET status = ?
 WHERE id = ?
 RETURNING *;
 
--- name: DeleteSwapRequest :exec
-DELETE FROM swap_requests
-WHERE id = ?;
+-- name: ResolveSwapRequest :one
+UPDATE swap_requests
+SET status = ?,
+    resolved_by_user_id = ?,
+    resolved_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: CancelSwapRequest :one
+UPDATE swap_requests
+SET status = 'CANCELLED',
+    cancel_reason = ?,
+    resolved_by_user_id = ?,
+    resolved_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: CountSwapRequestsByStatus :one
+SELECT COUNT(*) FROM swap_requests
+WHERE status = ?;
 
 -- name: GetSwapRequestsByEventID :many
 SELECT * FROM swap_requests
 WHERE requester_slot_id = ? OR responder_slot_id = ?;
 
--- name: GetIncomingSwapRequests :many
+-- name: GetPendingSwapRequestsByUserID :many
+SELECT * FROM swap_requests
+WHERE status = 'PENDING'
+    AND (requester_user_id = sqlc.arg(user_id) OR responder_user_id = sqlc.arg(user_id))
+ORDER BY id;
+
+-- name: ListIncomingSwapRequests :many
 SELECT
     sr.id,
     sr.status,
+    sr.kind,
     sr.requester_user_id,
     requester.name AS requester_name,
-    requester_event.title AS requester_event_title,
+    COALESCE(requester_event.title, '') AS requester_event_title,
     requester_event.start_time AS requester_event_start_time,
     requester_event.end_time AS requester_event_end_time,
     responder_event.title AS responder_event_title,
@@ -131,19 +254,50 @@ FROM
 JOIN
     users requester ON sr.requester_user_id = requester.id
 JOIN
+    events responder_event ON sr.responder_slot_id = responder_event.id
+LEFT JOIN
     events requester_event ON sr.requester_slot_id = requester_event.id
+WHERE
+    sr.responder_user_id = sqlc.arg(user_id) AND sr.status = 'PENDING'
+    AND sr.id > sqlc.arg(after_id)
+ORDER BY sr.id
+LIMIT sqlc.arg(limit);
+
+-- name: ListIncomingSwapRequestsDesc :many
+SELECT
+    sr.id,
+    sr.status,
+    sr.kind,
+    sr.requester_user_id,
+    requester.name AS requester_name,
+    COALESCE(requester_event.title, '') AS requester_event_title,
+    requester_event.start_time AS requester_event_start_time,
+    requester_event.end_time AS requester_event_end_time,
+    responder_event.title AS responder_event_title,
+    responder_event.start_time AS responder_event_start_time,
+    responder_event.end_time AS responder_event_end_time
+FROM
+    swap_requests sr
+JOIN
+    users requester ON sr.requester_user_id = requester.id
 JOIN
     events responder_event ON sr.responder_slot_id = responder_event.id
+LEFT JOIN
+    events requester_event ON sr.requester_slot_id = requester_event.id
 WHERE
-    sr.responder_user_id = ? AND sr.status = 'PENDING';
+    sr.responder_user_id = sqlc.arg(user_id) AND sr.status = 'PENDING'
+    AND sr.id < sqlc.arg(after_id)
+ORDER BY sr.id DESC
+LIMIT sqlc.arg(limit);
 
--- name: GetOutgoingSwapRequests :many
+-- name: ListOutgoingSwapRequests :many
 SELECT
     sr.id,
     sr.status,
+    sr.kind,
     sr.responder_user_id,
     responder.name AS responder_name,
-    requester_event.title AS requester_event_title,
+    COALESCE(requester_event.title, '') AS requester_event_title,
     requester_event.start_time AS requester_event_start_time,
     requester_event.end_time AS requester_event_end_time,
     responder_event.title AS responder_event_title,
@@ -154,8 +308,376 @@ FROM
 JOIN
     users responder ON sr.responder_user_id = responder.id
 JOIN
+    events responder_event ON sr.responder_slot_id = responder_event.id
+LEFT JOIN
     events requester_event ON sr.requester_slot_id = requester_event.id
+WHERE
+    sr.requester_user_id = sqlc.arg(user_id) AND sr.status = 'PENDING'
+    AND sr.id > sqlc.arg(after_id)
+ORDER BY sr.id
+LIMIT sqlc.arg(limit);
+
+-- name: ListOutgoingSwapRequestsDesc :many
+SELECT
+    sr.id,
+    sr.status,
+    sr.kind,
+    sr.responder_user_id,
+    responder.name AS responder_name,
+    COALESCE(requester_event.title, '') AS requester_event_title,
+    requester_event.start_time AS requester_event_start_time,
+    requester_event.end_time AS requester_event_end_time,
+    responder_event.title AS responder_event_title,
+    responder_event.start_time AS responder_event_start_time,
+    responder_event.end_time AS responder_event_end_time
+FROM
+    swap_requests sr
+JOIN
+    users responder ON sr.responder_user_id = responder.id
 JOIN
     events responder_event ON sr.responder_slot_id = responder_event.id
+LEFT JOIN
+    events requester_event ON sr.requester_slot_id = requester_event.id
 WHERE
-    sr.requester_user_id = ? AND sr.status = 'PENDING';
\ No newline at end of file
+    sr.requester_user_id = sqlc.arg(user_id) AND sr.status = 'PENDING'
+    AND sr.id < sqlc.arg(after_id)
+ORDER BY sr.id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: GetIncomingSwapRequestHistory :many
+SELECT
+    id,
+    status,
+    kind,
+    requester_user_id,
+    requester_name,
+    requester_slot_id,
+    requester_event_title,
+    requester_event_start_time,
+    requester_event_end_time,
+    responder_slot_id,
+    responder_event_title,
+    responder_event_start_time,
+    responder_event_end_time,
+    resolved_by_user_id,
+    resolved_by_name,
+    resolved_at,
+    cancel_reason,
+    created_at,
+    updated_at,
+    sort_key
+FROM (
+    SELECT
+        sr.id,
+        sr.status,
+        sr.kind,
+        sr.requester_user_id,
+        requester.name AS requester_name,
+        sr.requester_slot_id,
+        COALESCE(requester_event.title, '') AS requester_event_title,
+        requester_event.start_time AS requester_event_start_time,
+        requester_event.end_time AS requester_event_end_time,
+        sr.responder_slot_id,
+        COALESCE(responder_event.title, '') AS responder_event_title,
+        responder_event.start_time AS responder_event_start_time,
+        responder_event.end_time AS responder_event_end_time,
+        sr.resolved_by_user_id,
+        COALESCE(resolver.name, '') AS resolved_by_name,
+        sr.resolved_at,
+        sr.cancel_reason,
+        sr.created_at,
+        sr.updated_at,
+        CAST(CASE
+            WHEN CAST(sqlc.arg(sort) AS TEXT) = 'created_at' THEN julianday(sr.created_at)
+            WHEN CAST(sqlc.arg(sort) AS TEXT) = '-created_at' THEN -julianday(sr.created_at)
+            WHEN CAST(sqlc.arg(sort) AS TEXT) = 'resolved_at' THEN COALESCE(julianday(sr.resolved_at), 1e7)
+            ELSE COALESCE(-julianday(sr.resolved_at), 1e7)
+        END AS REAL) AS sort_key
+    FROM
+        swap_requests sr
+    JOIN
+        users requester ON sr.requester_user_id = requester.id
+    LEFT JOIN
+        events requester_event ON sr.requester_slot_id = requester_event.id
+    LEFT JOIN
+        events responder_event ON sr.responder_slot_id = responder_event.id
+    LEFT JOIN
+        users resolver ON sr.resolved_by_user_id = resolver.id
+    WHERE
+        sr.responder_user_id = sqlc.arg(user_id)
+        AND sr.status = COALESCE(sqlc.narg(status), sr.status)
+        AND sr.requester_user_id = COALESCE(sqlc.narg(counterparty_id), sr.requester_user_id)
+        AND sr.created_at >= COALESCE(sqlc.narg(created_from), sr.created_at)
+        AND sr.created_at <= COALESCE(sqlc.narg(created_to), sr.created_at)
+        -- Keyset condition: rows strictly after (after_sort_key, after_id) in
+        -- the "sort_key ASC, id DESC" order below.
+        AND (
+            CASE
+                WHEN CAST(sqlc.arg(sort) AS TEXT) = 'created_at' THEN julianday(sr.created_at)
+                WHEN CAST(sqlc.arg(sort) AS TEXT) = '-created_at' THEN -julianday(sr.created_at)
+                WHEN CAST(sqlc.arg(sort) AS TEXT) = 'resolved_at' THEN COALESCE(julianday(sr.resolved_at), 1e7)
+                ELSE COALESCE(-julianday(sr.resolved_at), 1e7)
+            END > CAST(sqlc.arg(after_sort_key) AS REAL)
+            OR (
+                CASE
+                WHEN CAST(sqlc.arg(sort) AS TEXT) = 'created_at' THEN julianday(sr.created_at)
+                WHEN CAST(sqlc.arg(sort) AS TEXT) = '-created_at' THEN -julianday(sr.created_at)
+                WHEN CAST(sqlc.arg(sort) AS TEXT) = 'resolved_at' THEN COALESCE(julianday(sr.resolved_at), 1e7)
+                ELSE COALESCE(-julianday(sr.resolved_at), 1e7)
+            END = CAST(sqlc.arg(after_sort_key) AS REAL)
+                AND sr.id < sqlc.arg(after_id)
+            )
+        )
+) AS history
+ORDER BY history.sort_key, history.id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: GetOutgoingSwapRequestHistory :many
+SELECT
+    id,
+    status,
+    kind,
+    responder_user_id,
+    responder_name,
+    requester_slot_id,
+    requester_event_title,
+    requester_event_start_time,
+    requester_event_end_time,
+    responder_slot_id,
+    responder_event_title,
+    responder_event_start_time,
+    responder_event_end_time,
+    resolved_by_user_id,
+    resolved_by_name,
+    resolved_at,
+    cancel_reason,
+    created_at,
+    updated_at,
+    sort_key
+FROM (
+    SELECT
+        sr.id,
+        sr.status,
+        sr.kind,
+        sr.responder_user_id,
+        responder.name AS responder_name,
+        sr.requester_slot_id,
+        COALESCE(requester_event.title, '') AS requester_event_title,
+        requester_event.start_time AS requester_event_start_time,
+        requester_event.end_time AS requester_event_end_time,
+        sr.responder_slot_id,
+        COALESCE(responder_event.title, '') AS responder_event_title,
+        responder_event.start_time AS responder_event_start_time,
+        responder_event.end_time AS responder_event_end_time,
+        sr.resolved_by_user_id,
+        COALESCE(resolver.name, '') AS resolved_by_name,
+        sr.resolved_at,
+        sr.cancel_reason,
+        sr.created_at,
+        sr.updated_at,
+        CAST(CASE
+            WHEN CAST(sqlc.arg(sort) AS TEXT) = 'created_at' THEN julianday(sr.created_at)
+            WHEN CAST(sqlc.arg(sort) AS TEXT) = '-created_at' THEN -julianday(sr.created_at)
+            WHEN CAST(sqlc.arg(sort) AS TEXT) = 'resolved_at' THEN COALESCE(julianday(sr.resolved_at), 1e7)
+            ELSE COALESCE(-julianday(sr.resolved_at), 1e7)
+        END AS REAL) AS sort_key
+    FROM
+        swap_requests sr
+    JOIN
+        users responder ON sr.responder_user_id = responder.id
+    LEFT JOIN
+        events requester_event ON sr.requester_slot_id = requester_event.id
+    LEFT JOIN
+        events responder_event ON sr.responder_slot_id = responder_event.id
+    LEFT JOIN
+        users resolver ON sr.resolved_by_user_id = resolver.id
+    WHERE
+        sr.requester_user_id = sqlc.arg(user_id)
+        AND sr.status = COALESCE(sqlc.narg(status), sr.status)
+        AND sr.responder_user_id = COALESCE(sqlc.narg(counterparty_id), sr.responder_user_id)
+        AND sr.created_at >= COALESCE(sqlc.narg(created_from), sr.created_at)
+        AND sr.created_at <= COALESCE(sqlc.narg(created_to), sr.created_at)
+        -- Keyset condition: rows strictly after (after_sort_key, after_id) in
+        -- the "sort_key ASC, id DESC" order below.
+        AND (
+            CASE
+                WHEN CAST(sqlc.arg(sort) AS TEXT) = 'created_at' THEN julianday(sr.created_at)
+                WHEN CAST(sqlc.arg(sort) AS TEXT) = '-created_at' THEN -julianday(sr.created_at)
+                WHEN CAST(sqlc.arg(sort) AS TEXT) = 'resolved_at' THEN COALESCE(julianday(sr.resolved_at), 1e7)
+                ELSE COALESCE(-julianday(sr.resolved_at), 1e7)
+            END > CAST(sqlc.arg(after_sort_key) AS REAL)
+            OR (
+                CASE
+                WHEN CAST(sqlc.arg(sort) AS TEXT) = 'created_at' THEN julianday(sr.created_at)
+                WHEN CAST(sqlc.arg(sort) AS TEXT) = '-created_at' THEN -julianday(sr.created_at)
+                WHEN CAST(sqlc.arg(sort) AS TEXT) = 'resolved_at' THEN COALESCE(julianday(sr.resolved_at), 1e7)
+                ELSE COALESCE(-julianday(sr.resolved_at), 1e7)
+            END = CAST(sqlc.arg(after_sort_key) AS REAL)
+                AND sr.id < sqlc.arg(after_id)
+            )
+        )
+) AS history
+ORDER BY history.sort_key, history.id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: CreateAuditLog :one
+INSERT INTO audit_logs (
+    actor_user_id,
+    action,
+    entity_type,
+    entity_id,
+    before_value,
+    after_value,
+    request_id,
+    ip_address,
+    user_agent
+) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+) RETURNING *;
+
+-- name: AddAuditLogSubject :exec
+INSERT OR IGNORE INTO audit_log_subjects (
+    audit_log_id,
+    user_id
+) VALUES (
+    ?,
+    ?
+);
+
+-- name: ListAuditLogsByEntity :many
+SELECT * FROM audit_logs
+WHERE entity_type = ? AND entity_id = ?
+ORDER BY id;
+
+-- name: ListAuditLogsBySubject :many
+SELECT a.* FROM audit_logs a
+JOIN audit_log_subjects s ON s.audit_log_id = a.id
+WHERE s.user_id = sqlc.arg(user_id) AND a.id < sqlc.arg(before_id)
+ORDER BY a.id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: CountAuditLogSubjectEntries :one
+SELECT COUNT(*) FROM audit_logs a
+JOIN audit_log_subjects s ON s.audit_log_id = a.id
+WHERE a.entity_type = ? AND a.entity_id = ? AND s.user_id = ?;
+
+-- name: ListAuditLogs :many
+SELECT * FROM audit_logs
+WHERE entity_type = COALESCE(sqlc.narg(entity_type), entity_type)
+  AND actor_user_id = COALESCE(sqlc.narg(actor_user_id), actor_user_id)
+  AND id < sqlc.arg(before_id)
+ORDER BY id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens (
+    user_id,
+    name,
+    token_hash,
+    scopes,
+    expires_at
+) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+) RETURNING *;
+
+-- name: GetPersonalAccessTokenByHash :one
+SELECT * FROM personal_access_tokens
+WHERE token_hash = ?;
+
+-- name: GetPersonalAccessTokenByID :one
+SELECT * FROM personal_access_tokens
+WHERE id = ?;
+
+-- name: ListPersonalAccessTokensByUserID :many
+SELECT * FROM personal_access_tokens
+WHERE user_id = ?
+ORDER BY id;
+
+-- name: UpdatePersonalAccessTokenLastUsed :exec
+UPDATE personal_access_tokens
+SET last_used_at = ?
+WHERE id = ?;
+
+-- name: DeletePersonalAccessToken :exec
+DELETE FROM personal_access_tokens
+WHERE id = ?;
+
+-- name: DeletePersonalAccessTokensByUserID :exec
+DELETE FROM personal_access_tokens
+WHERE user_id = ?;
+
+-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (
+    user_id,
+    idempotency_key,
+    fingerprint,
+    created_at,
+    expires_at
+) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+ON CONFLICT (user_id, idempotency_key) DO NOTHING;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE user_id = ? AND idempotency_key = ?;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status_code = ?,
+    response_headers = ?,
+    response_body = ?
+WHERE user_id = ? AND idempotency_key = ?;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = ? AND idempotency_key = ?;
+
+-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE expires_at <= ?;
+
+-- name: CreateNotification :one
+INSERT INTO notifications (
+    user_id,
+    kind,
+    swap_request_id,
+    reason,
+    message
+) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+) RETURNING *;
+
+-- name: ListNotificationsByUserID :many
+SELECT * FROM notifications
+WHERE user_id = sqlc.arg(user_id)
+  AND (CAST(sqlc.arg(unread_only) AS BOOLEAN) = 0 OR read_at IS NULL)
+  AND id < sqlc.arg(before_id)
+ORDER BY id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: MarkNotificationRead :one
+UPDATE notifications
+SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+WHERE id = ? AND user_id = ?
+RETURNING *;
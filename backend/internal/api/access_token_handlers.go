@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"slotswapper/internal/services"
+)
+
+func (s *Server) handleCreateAccessToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input services.CreateAccessTokenInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	input.UserID = userID
+
+	token, err := s.accessTokenService.CreateAccessToken(r.Context(), input)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, r, token)
+}
+
+func (s *Server) handleListAccessTokens(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	tokens, err := s.accessTokenService.ListAccessTokens(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, tokens)
+}
+
+func (s *Server) handleRevokeAccessToken(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid Access Token ID")
+		return
+	}
+
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := s.accessTokenService.RevokeAccessToken(r.Context(), userID, tokenID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"slotswapper/internal/services"
+)
+
+func TestServer_handleCreateAccessToken(t *testing.T) {
+	ts, _, _ := setupTestServer(t)
+	defer ts.Close()
+	handler := ts.Config.Handler
+
+	_, _, cookie := signUpAndLogin(t, ts, "Script Owner", "scripts@example.com", "password123")
+
+	body, _ := json.Marshal(map[string]any{"name": "calendar sync", "scopes": []string{services.ScopeEventsRead}, "expires_in_days": 7})
+	req := httptest.NewRequest(http.MethodPost, "/api/access-tokens", bytes.NewReader(body))
+	req.AddCookie(cookie)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created services.CreatedAccessToken
+	json.NewDecoder(rr.Body).Decode(&created)
+
+	withToken := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{"granted scope", http.MethodGet, "/api/events/user", http.StatusOK},
+		{"missing scope", http.MethodGet, "/api/swappable-slots", http.StatusForbidden},
+		{"missing write scope", http.MethodDelete, "/api/events/1", http.StatusForbidden},
+		{"session-only route", http.MethodGet, "/api/access-tokens", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if rr := withToken(tt.method, tt.path, created.Token); rr.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.want, rr.Code, rr.Body.String())
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/access-tokens", nil)
+	req.AddCookie(cookie)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	var tokens []services.AccessToken
+	json.NewDecoder(rr.Body).Decode(&tokens)
+	if len(tokens) != 1 || tokens[0].LastUsedAt == nil || tokens[0].Name != "calendar sync" {
+		t.Errorf("expected the used token to be listed, got %+v", tokens)
+	}
+	if bytes.Contains(rr.Body.Bytes(), []byte(created.Token)) {
+		t.Error("expected the listing not to contain the token")
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/access-tokens/%d", created.ID), nil)
+	req.AddCookie(cookie)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rr.Code)
+	}
+	if rr := withToken(http.MethodGet, "/api/events/user", created.Token); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected a revoked token to be refused, got %d", rr.Code)
+	}
+}
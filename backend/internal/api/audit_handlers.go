@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"slotswapper/internal/services"
+)
+
+func (s *Server) handleGetMyAuditLogs(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	beforeID, limit, err := parseAuditPage(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := s.auditService.GetUserAuditLogs(r.Context(), userID, beforeID, limit)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, entries)
+}
+
+func (s *Server) handleGetEventAuditLogs(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid Event ID")
+		return
+	}
+
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	entries, err := s.auditService.GetEventAuditLogs(r.Context(), eventID, userID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, entries)
+}
+
+func (s *Server) handleListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	beforeID, limit, err := parseAuditPage(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	input := services.ListAuditLogsInput{
+		RequestingUserID: userID,
+		EntityType:       r.URL.Query().Get("entity_type"),
+		BeforeID:         beforeID,
+		Limit:            limit,
+	}
+	if actor := r.URL.Query().Get("actor_user_id"); actor != "" {
+		input.ActorUserID, err = strconv.ParseInt(actor, 10, 64)
+		if err != nil {
+			writeProblem(w, r, http.StatusBadRequest, "Invalid actor_user_id")
+			return
+		}
+	}
+
+	entries, err := s.auditService.ListAuditLogs(r.Context(), input)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, entries)
+}
+
+// parseAuditPage reads the optional "before" entry ID and "limit" query parameters.
+func parseAuditPage(r *http.Request) (beforeID, limit int64, err error) {
+	query := r.URL.Query()
+	if before := query.Get("before"); before != "" {
+		beforeID, err = strconv.ParseInt(before, 10, 64)
+		if err != nil {
+			return 0, 0, errors.New("Invalid before parameter")
+		}
+	}
+	if l := query.Get("limit"); l != "" {
+		limit, err = strconv.ParseInt(l, 10, 64)
+		if err != nil {
+			return 0, 0, errors.New("Invalid limit parameter")
+		}
+	}
+	return beforeID, limit, nil
+}
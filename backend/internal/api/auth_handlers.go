@@ -5,77 +5,91 @@ import (
 	"net/http"
 	"time"
 
+	"slotswapper/internal/db"
 	"slotswapper/internal/services"
 )
 
-func createCookie(key, value string) *http.Cookie {
+// accessTokenCookie returns the access_token cookie carrying token, with the
+// attributes set in the config.
+func (s *Server) accessTokenCookie(token string) *http.Cookie {
+	cfg := s.config
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	sameSite, err := cfg.SameSite()
+	if err != nil {
+		sameSite = http.SameSiteLaxMode
+	}
 	return &http.Cookie{
-		Name:     key,
-		Value:    value,
+		Name:     "access_token",
+		Value:    token,
 		Path:     "/",
-		Expires:  time.Now().Add(24 * time.Hour),
+		Domain:   cfg.CookieDomain,
+		Expires:  time.Now().Add(orDefault(cfg.AccessTokenTTL, defaultAccessTokenTTL)),
 		HttpOnly: true,
-		// Secure:   true, // Set to true in production
-		// SameSite: http.SameSiteLaxMode,
-		Secure:   false,
-		SameSite: http.SameSiteLaxMode,
+		Secure:   cfg.CookieSecure,
+		SameSite: sameSite,
 	}
 }
 
+// authResponse is returned by signup and login. The token is also set as the
+// access_token cookie.
+type authResponse struct {
+	User  *db.User `json:"user"`
+	Token string   `json:"token"`
+}
+
 func (s *Server) handleSignUp(w http.ResponseWriter, r *http.Request) {
 	var input services.RegisterUserInput
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	user, token, err := s.authService.Register(r.Context(), input)
 	if err != nil {
-		if err == services.ErrEmailExists {
-			http.Error(w, err.Error(), http.StatusConflict)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	http.SetCookie(w, createCookie("access_token", token))
+	http.SetCookie(w, s.accessTokenCookie(token))
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{"user": user, "token": token})
+	json.NewEncoder(w).Encode(authResponse{User: user, Token: token})
 }
 
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	var input services.LoginInput
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	user, token, err := s.authService.Login(r.Context(), input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		writeError(w, r, err)
 		return
 	}
 
-	http.SetCookie(w, createCookie("access_token", token))
+	http.SetCookie(w, s.accessTokenCookie(token))
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{"user": user, "token": token})
+	json.NewEncoder(w).Encode(authResponse{User: user, Token: token})
 }
 
 func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     "access_token",
-		Value:    "",
-		Path:     "/",
-		Expires:  time.Unix(0, 0),
-		HttpOnly: true,
-		Secure:   false,
-		SameSite: http.SameSiteLaxMode,
-	})
+	cookie := s.accessTokenCookie("")
+	cookie.Expires = time.Unix(0, 0)
+	http.SetCookie(w, cookie)
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// handleJWKS publishes the public keys that verify access tokens, so other
+// services can check our tokens without holding a secret.
+func (s *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, r, s.jwtManager.JWKS())
+}
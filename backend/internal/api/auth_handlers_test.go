@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,15 +18,17 @@ func TestServer_handleSignUp_DuplicateEmail(t *testing.T) {
 	queries := repository.SetupTestDB(t)
 
 	userRepo := repository.NewUserRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	transactor := repository.NewTransactor(queries)
 	eventRepo := repository.NewEventRepository(queries)
 	swapRepo := repository.NewSwapRequestRepository(queries)
 	passwordCrypto := crypto.NewPassword()
 	jwtManager := crypto.NewJWT("test-secret", time.Minute)
-	authService := services.NewAuthService(userRepo, passwordCrypto, jwtManager)
-	eventService := services.NewEventService(eventRepo, userRepo, swapRepo)
-	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo)
+	authService := services.NewAuthService(userRepo, auditRepo, transactor, passwordCrypto, jwtManager)
+	eventService := services.NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
+	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
 
-	server := NewServer(nil, authService, nil, eventService, swapRequestService, nil)
+	server := NewServer(nil, authService, nil, eventService, swapRequestService, nil, nil, nil, nil, nil)
 
 	// First registration should succeed
 	input := services.RegisterUserInput{
@@ -52,3 +55,29 @@ func TestServer_handleSignUp_DuplicateEmail(t *testing.T) {
 		t.Errorf("second signup returned wrong status code: got %v want %v", status, http.StatusConflict)
 	}
 }
+
+func TestServer_handleJWKS(t *testing.T) {
+	key, err := crypto.GenerateSigningKey(crypto.AlgEdDSA)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	server := NewServer(nil, nil, nil, nil, nil, nil, nil, nil, nil, crypto.NewKeySetJWT(&crypto.KeySet{Keys: []crypto.SigningKey{key}}, time.Minute))
+	router := http.NewServeMux()
+	server.RegisterRoutes(router)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if cc := rec.Header().Get("Cache-Control"); !strings.Contains(cc, "max-age") {
+		t.Errorf("expected the key set to be cacheable, got %q", cc)
+	}
+	var set crypto.JWKSet
+	if err := json.NewDecoder(rec.Body).Decode(&set); err != nil {
+		t.Fatalf("failed to decode key set: %v", err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].KeyID != key.ID || set.Keys[0].X == "" {
+		t.Errorf("unexpected key set %+v", set)
+	}
+}
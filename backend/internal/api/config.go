@@ -3,28 +3,129 @@ package api
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"slotswapper/internal/services"
 )
 
+// Config holds the HTTP server settings. The config package loads it as
+// part of the application configuration.
 type Config struct {
-	Addr           string   `json:"addr"`
+	Addr string `json:"addr"`
+	// MetricsAddr is where GET /metrics is served, apart from the public API
+	// so that it can be kept off the internet. Empty disables it.
+	MetricsAddr    string   `json:"metricsAddr"`
 	AllowedOrigins []string `json:"allowedOrigins"`
 	FrontendDir    string   `json:"frontendDir"`
 	TlsCertFile    string   `json:"tlsCertFile"`
 	TlsKeyFile     string   `json:"tlsKeyFile"`
+	// LogFormat is "text" (the default) or "json"; LogLevel is "debug",
+	// "info" (the default), "warn" or "error".
+	LogFormat string `json:"logFormat"`
+	LogLevel  string `json:"logLevel"`
+	// TracingExporter is "stdout", "otlp" or empty to disable tracing.
+	// OTLPEndpoint overrides the OTEL_EXPORTER_OTLP_* environment settings
+	// for the otlp exporter, e.g. "http://localhost:4318".
+	TracingExporter string `json:"tracingExporter"`
+	OTLPEndpoint    string `json:"otlpEndpoint"`
+	// Server timeouts are durations such as "15s"; unset ones take the
+	// defaults in lifecycle.go. DrainDelay is how long the server keeps
+	// serving while reporting not ready before it stops accepting
+	// connections, so that load balancers can take it out of rotation.
+	ReadHeaderTimeout Duration `json:"readHeaderTimeout"`
+	ReadTimeout       Duration `json:"readTimeout"`
+	WriteTimeout      Duration `json:"writeTimeout"`
+	IdleTimeout       Duration `json:"idleTimeout"`
+	ShutdownTimeout   Duration `json:"shutdownTimeout"`
+	DrainDelay        Duration `json:"drainDelay"`
+	// AccessTokenTTL is how long login tokens, and the access_token cookie
+	// carrying them, stay valid.
+	AccessTokenTTL Duration `json:"accessTokenTtl"`
+	// Attributes of the access_token cookie. CookieSameSite is "lax" (the
+	// default), "strict" or "none".
+	CookieSecure   bool   `json:"cookieSecure"`
+	CookieSameSite string `json:"cookieSameSite"`
+	CookieDomain   string `json:"cookieDomain"`
+	// IdempotencyKeyTTL is how long the response to a request sent with an
+	// Idempotency-Key header is kept for replay.
+	IdempotencyKeyTTL Duration `json:"idempotencyKeyTtl"`
+	// TrustedProxies lists the IPs or CIDR ranges of the reverse proxies in
+	// front of the server. X-Forwarded-For is only honoured on requests
+	// arriving from one of them.
+	TrustedProxies []string `json:"trustedProxies"`
 }
 
-func LoadConfig(path string) (*Config, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open config file: %w", err)
+// DefaultConfig returns the server settings used when nothing overrides them.
+func DefaultConfig() Config {
+	return Config{
+		Addr:              defaultAddr,
+		MetricsAddr:       defaultMetricsAddr,
+		LogFormat:         "text",
+		LogLevel:          "info",
+		ReadHeaderTimeout: Duration(defaultReadHeaderTimeout),
+		ReadTimeout:       Duration(defaultReadTimeout),
+		WriteTimeout:      Duration(defaultWriteTimeout),
+		IdleTimeout:       Duration(defaultIdleTimeout),
+		ShutdownTimeout:   Duration(defaultShutdownTimeout),
+		AccessTokenTTL:    Duration(defaultAccessTokenTTL),
+		CookieSameSite:    "lax",
+		IdempotencyKeyTTL: Duration(services.DefaultIdempotencyKeyTTL),
 	}
-	defer file.Close()
+}
 
-	var cfg Config
-	if err := json.NewDecoder(file).Decode(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to decode config: %w", err)
+// SameSite maps CookieSameSite to its http.SameSite mode.
+func (c *Config) SameSite() (http.SameSite, error) {
+	switch strings.ToLower(c.CookieSameSite) {
+	case "", "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
 	}
+	return 0, fmt.Errorf("invalid cookieSameSite %q: expected lax, strict or none", c.CookieSameSite)
+}
+
+// TrustedProxyPrefixes parses TrustedProxies, reading a bare IP as a
+// single-address range.
+func (c *Config) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(c.TrustedProxies))
+	for _, proxy := range c.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trustedProxies entry %q: expected an IP or CIDR range", proxy)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// Duration is a time.Duration written as a string such as "30s" in the
+// config file.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\"")
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
 
-	return &cfg, nil
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
 }
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/logging"
+	"slotswapper/internal/services"
+)
+
+// problem is an RFC 9457 problem details object. Code is an extension member
+// naming the error kind, since several kinds share a status code.
+// ConflictingEvents, CounterpartyConflict and Errors are extension members
+// set for overlap conflicts and validation failures. ConflictingEvents only
+// lists the caller's own events; a conflict of the other user in a swap is
+// reported by their role alone.
+type problem struct {
+	Type                 string                `json:"type"`
+	Title                string                `json:"title"`
+	Status               int                   `json:"status"`
+	Detail               string                `json:"detail,omitempty"`
+	Instance             string                `json:"instance,omitempty"`
+	Code                 string                `json:"code,omitempty"`
+	Errors               []services.FieldError `json:"errors,omitempty"`
+	ConflictingEvents    []db.Event            `json:"conflicting_events,omitempty"`
+	CounterpartyConflict string                `json:"counterparty_conflict,omitempty"`
+}
+
+// errorKinds maps each service error kind to its status code and problem code.
+var errorKinds = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{services.ErrValidation, http.StatusBadRequest, "validation_failed"},
+	{services.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{services.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{services.ErrNotFound, http.StatusNotFound, "not_found"},
+	{services.ErrConflict, http.StatusConflict, "conflict"},
+	{services.ErrInvalidState, http.StatusConflict, "invalid_state"},
+	{services.ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed"},
+}
+
+// writeError renders an error returned by a service. Errors of a known kind
+// become the matching 4xx problem; anything else is logged and reported as a
+// 500 without exposing its message.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	p := problem{Status: http.StatusInternalServerError, Detail: "An unexpected error occurred."}
+	for _, k := range errorKinds {
+		if errors.Is(err, k.kind) {
+			p = problem{Status: k.status, Code: k.code, Detail: err.Error()}
+			break
+		}
+	}
+	if p.Status == http.StatusInternalServerError {
+		logging.FromContext(r.Context()).Error("request failed", "error", err)
+	}
+
+	var validationErr *services.ValidationError
+	if errors.As(err, &validationErr) {
+		p.Detail = "The request has invalid fields."
+		p.Errors = validationErr.Fields
+	}
+	var conflictErr *services.ConflictError
+	if errors.As(err, &conflictErr) {
+		p.ConflictingEvents = conflictErr.Events
+		p.CounterpartyConflict = string(conflictErr.Counterparty)
+	}
+
+	renderProblem(w, r, p)
+}
+
+// writeProblem reports a failure detected by the handler itself, such as a
+// malformed path parameter or body.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	renderProblem(w, r, problem{Status: status, Detail: detail})
+}
+
+func renderProblem(w http.ResponseWriter, r *http.Request, p problem) {
+	p.Type = "about:blank"
+	p.Title = http.StatusText(p.Status)
+	p.Instance = r.URL.Path
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"slotswapper/internal/services"
+)
+
+// eventETag is the entity tag of an event: its version, which changes on
+// every write.
+func eventETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatchVersion returns the event version named by the If-Match header, or 0
+// when the header is absent or "*" and any version will do. It reports a
+// header that cannot match itself and returns false; the handler must stop.
+func ifMatchVersion(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	if strings.Contains(header, ",") {
+		writeProblem(w, r, http.StatusBadRequest, "If-Match must name a single event version")
+		return 0, false
+	}
+	// Weak tags fail to unquote; they never match under the strong
+	// comparison If-Match uses.
+	tag, err := strconv.Unquote(header)
+	if err != nil || !strings.HasPrefix(header, `"`) {
+		tag = ""
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		writeProblem(w, r, http.StatusPreconditionFailed, "If-Match does not name a version of the event")
+		return 0, false
+	}
+	return version, true
+}
+
+// pageETag is the entity tag of a page of events. It hashes the ID and
+// version of every item, the cursor of the next page and the zone the times
+// are rendered in, so a conditional GET still runs the list query but never
+// builds the body. key returns what the tag covers for one item; anything the
+// page shows that the event version does not track, such as the owner's
+// name, belongs there too.
+func pageETag[T any](r *http.Request, page *services.Page[T], key func(T) string) string {
+	h := sha256.New()
+	if loc := locationFromContext(r.Context()); loc != nil {
+		io.WriteString(h, loc.String())
+	}
+	io.WriteString(h, "\n"+page.NextCursor+"\n")
+	for _, item := range page.Items {
+		io.WriteString(h, key(item)+"\n")
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// writeTaggedJSON writes v like writeJSON with an ETag header. When the
+// request's If-None-Match already names the tag, it answers 304 Not Modified
+// without encoding v.
+func writeTaggedJSON(w http.ResponseWriter, r *http.Request, v any, etag string) {
+	w.Header().Set("ETag", etag)
+	if noneMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, r, v)
+}
+
+// noneMatch reports whether an If-None-Match header names etag, using the
+// weak comparison the header calls for.
+func noneMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
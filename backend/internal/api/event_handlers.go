@@ -1,24 +1,31 @@
 package api
 
 import (
+	"cmp"
 	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
 	"net/http"
+	"slices"
 	"strconv"
 
+	"slotswapper/internal/db"
 	"slotswapper/internal/services"
 )
 
 func (s *Server) handleCreateEvent(w http.ResponseWriter, r *http.Request) {
 	userID, ok := GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
 	var input services.CreateEventInput
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -26,108 +33,231 @@ func (s *Server) handleCreateEvent(w http.ResponseWriter, r *http.Request) {
 
 	event, err := s.eventService.CreateEvent(r.Context(), input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(event)
+	writeJSON(w, r, event)
+}
+
+// recurringEventRequest is the body of POST /api/events/recurring: the first
+// occurrence plus how often it repeats.
+type recurringEventRequest struct {
+	services.CreateEventInput
+	Recurrence services.RecurrenceInput `json:"recurrence"`
+}
+
+func (s *Server) handleCreateRecurringEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input recurringEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	input.UserID = userID
+
+	events, err := s.eventService.CreateRecurringEvents(r.Context(), input.CreateEventInput, input.Recurrence)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, r, events)
 }
 
 func (s *Server) handleGetEventByID(w http.ResponseWriter, r *http.Request) {
 	eventID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid Event ID", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "Invalid Event ID")
 		return
 	}
 
 	event, err := s.eventService.GetEventByID(r.Context(), eventID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(event)
+	writeTaggedJSON(w, r, event, eventETag(event.Version))
 }
 
 func (s *Server) handleUpdateEvent(w http.ResponseWriter, r *http.Request) {
 	eventID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid Event ID", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "Invalid Event ID")
 		return
 	}
 
 	userID, ok := GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
 	var input services.UpdateEventInput
 	err = json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 	input.ID = eventID
 	input.UserID = userID
+	input.Version = version
 
 	updatedEvent, err := s.eventService.UpdateEvent(r.Context(), input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(updatedEvent)
+	w.Header().Set("ETag", eventETag(updatedEvent.Version))
+	writeJSON(w, r, updatedEvent)
+}
+
+// eventPatchFields are the event fields PATCH /api/events/{id} can change.
+var eventPatchFields = map[string]bool{"title": true, "start_time": true, "end_time": true, "time_zone": true}
+
+// handlePatchEvent applies a JSON Merge Patch (RFC 7396) to an event. None of
+// the fields can be removed, so null values are refused.
+func (s *Server) handlePatchEvent(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid Event ID")
+		return
+	}
+
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+			writeProblem(w, r, http.StatusUnsupportedMediaType, "Send the patch as application/merge-patch+json")
+			return
+		}
+	}
+
+	allowOverlap, err := strconv.ParseBool(cmp.Or(r.URL.Query().Get("allow_overlap"), "false"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "allow_overlap must be true or false")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		writeProblem(w, r, http.StatusBadRequest, "The patch must be a JSON object")
+		return
+	}
+	for _, name := range slices.Sorted(maps.Keys(patch)) {
+		if !eventPatchFields[name] {
+			writeProblem(w, r, http.StatusBadRequest, name+" cannot be changed with PATCH")
+			return
+		}
+		if string(patch[name]) == "null" {
+			writeProblem(w, r, http.StatusBadRequest, name+" cannot be removed")
+			return
+		}
+	}
+
+	var input services.PatchEventInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	input.ID = eventID
+	input.UserID = userID
+	input.Version = version
+	input.AllowOverlap = allowOverlap
+
+	result, err := s.eventService.PatchEvent(r.Context(), input)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", eventETag(result.Event.Version))
+	writeJSON(w, r, result)
 }
 
 func (s *Server) handleUpdateEventStatus(w http.ResponseWriter, r *http.Request) {
 	eventID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid Event ID", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "Invalid Event ID")
 		return
 	}
 
 	userID, ok := GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
 	var input services.UpdateEventStatusInput
 	err = json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 	input.ID = eventID
 	input.UserID = userID
+	input.Version = version
 
 	updatedEvent, err := s.eventService.UpdateEventStatus(r.Context(), input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(updatedEvent)
+	w.Header().Set("ETag", eventETag(updatedEvent.Version))
+	writeJSON(w, r, updatedEvent)
 }
 
 func (s *Server) handleDeleteEvent(w http.ResponseWriter, r *http.Request) {
 	eventID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid Event ID", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "Invalid Event ID")
 		return
 	}
 
 	userID, ok := GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
-	err = s.eventService.DeleteEvent(r.Context(), eventID, userID)
+	err = s.eventService.DeleteEvent(r.Context(), eventID, userID, version)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -136,46 +266,47 @@ func (s *Server) handleDeleteEvent(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleGetEventsByUserID(w http.ResponseWriter, r *http.Request) {
 	userID, ok := GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
-	status := r.URL.Query().Get("status")
-
-	if status != "" {
-		events, err := s.eventService.GetEventsByUserIDAndStatus(r.Context(), userID, status)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(events)
+	filter, err := parseEventListFilter(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	events, err := s.eventService.GetEventsByUserID(r.Context(), userID)
+	page, err := s.eventService.ListEventsByUserID(r.Context(), userID, r.URL.Query().Get("status"), filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(events)
+	writeTaggedJSON(w, r, page, pageETag(r, page, func(event db.Event) string {
+		return fmt.Sprintf("%d:%d", event.ID, event.Version)
+	}))
 }
 
 func (s *Server) handleGetSwappableEvents(w http.ResponseWriter, r *http.Request) {
 	userID, ok := GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	filter, err := parseEventListFilter(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	events, err := s.eventService.GetSwappableEvents(r.Context(), userID)
+	page, err := s.eventService.ListSwappableEvents(r.Context(), userID, filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(events)
+	writeTaggedJSON(w, r, page, pageETag(r, page, func(event db.ListSwappableEventsRow) string {
+		return fmt.Sprintf("%d:%d:%q", event.ID, event.Version, event.OwnerName)
+	}))
 }
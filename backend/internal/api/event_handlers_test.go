@@ -3,10 +3,14 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"slotswapper/internal/db"
 	"slotswapper/internal/repository"
@@ -19,13 +23,15 @@ func TestServer_handleGetSwappableEvents(t *testing.T) {
 	// defer dbConn.Close()
 
 	userRepo := repository.NewUserRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	transactor := repository.NewTransactor(queries)
 	eventRepo := repository.NewEventRepository(queries)
 	swapRepo := repository.NewSwapRequestRepository(queries)
-	authService := services.NewAuthService(userRepo, nil, nil) // Mocks
-	eventService := services.NewEventService(eventRepo, userRepo, swapRepo)
-	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo)
+	authService := services.NewAuthService(userRepo, auditRepo, transactor, nil, nil) // Mocks
+	eventService := services.NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
+	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
 
-	server := NewServer(nil, authService, nil, eventService, swapRequestService, nil)
+	server := NewServer(nil, authService, nil, eventService, swapRequestService, nil, nil, nil, nil, nil)
 
 	// Create two users
 	user1, err := userRepo.CreateUser(context.Background(), db.CreateUserParams{Name: "User One", Email: "user1@test.com", Password: "password"})
@@ -61,22 +67,24 @@ func TestServer_handleGetSwappableEvents(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var events []db.GetSwappableEventsRow
-	if err := json.Unmarshal(rr.Body.Bytes(), &events); err != nil {
+	var page services.Page[db.ListSwappableEventsRow]
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
 		t.Fatalf("Failed to unmarshal response body: %v", err)
 	}
+	events := page.Items
 
 	if len(events) != 1 {
 		t.Fatalf("Expected 1 event, got %d", len(events))
 	}
 
-	expectedEvent := db.GetSwappableEventsRow{
+	expectedEvent := db.ListSwappableEventsRow{
 		ID:        event2.ID,
 		Title:     event2.Title,
 		StartTime: event2.StartTime,
 		EndTime:   event2.EndTime,
 		Status:    event2.Status,
 		UserID:    event2.UserID,
+		Version:   event2.Version,
 		CreatedAt: event2.CreatedAt,
 		UpdatedAt: event2.UpdatedAt,
 		OwnerName: user2.Name,
@@ -94,13 +102,15 @@ func TestServer_handleGetEventsByUserIDAndStatus(t *testing.T) {
 	// defer dbConn.Close()
 
 	userRepo := repository.NewUserRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	transactor := repository.NewTransactor(queries)
 	eventRepo := repository.NewEventRepository(queries)
 	swapRepo := repository.NewSwapRequestRepository(queries)
-	authService := services.NewAuthService(userRepo, nil, nil) // Mocks
-	eventService := services.NewEventService(eventRepo, userRepo, swapRepo)
-	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo)
+	authService := services.NewAuthService(userRepo, auditRepo, transactor, nil, nil) // Mocks
+	eventService := services.NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
+	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
 
-	server := NewServer(nil, authService, nil, eventService, swapRequestService, nil)
+	server := NewServer(nil, authService, nil, eventService, swapRequestService, nil, nil, nil, nil, nil)
 
 	// Create a user
 	user, err := userRepo.CreateUser(context.Background(), db.CreateUserParams{Name: "User One", Email: "user1@test.com", Password: "password"})
@@ -131,10 +141,11 @@ func TestServer_handleGetEventsByUserIDAndStatus(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var events []db.Event
-	if err := json.Unmarshal(rr.Body.Bytes(), &events); err != nil {
+	var page services.Page[db.Event]
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
 		t.Fatalf("Failed to unmarshal response body: %v", err)
 	}
+	events := page.Items
 
 	if len(events) != 1 {
 		t.Fatalf("Expected 1 event, got %d", len(events))
@@ -144,3 +155,359 @@ func TestServer_handleGetEventsByUserIDAndStatus(t *testing.T) {
 		t.Errorf("Expected event status to be SWAPPABLE, got %s", events[0].Status)
 	}
 }
+
+func TestServer_handleGetEventsByUserIDPagination(t *testing.T) {
+	queries := repository.SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	transactor := repository.NewTransactor(queries)
+	eventRepo := repository.NewEventRepository(queries)
+	swapRepo := repository.NewSwapRequestRepository(queries)
+	eventService := services.NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
+
+	server := NewServer(nil, nil, nil, eventService, nil, nil, nil, nil, nil, nil)
+
+	user, err := userRepo.CreateUser(context.Background(), db.CreateUserParams{Name: "User One", Email: "user1@test.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	start := time.Date(2030, time.January, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_, err := eventService.CreateEvent(context.Background(), services.CreateEventInput{
+			Title:     "Shift",
+			StartTime: start.AddDate(0, 0, i),
+			EndTime:   start.AddDate(0, 0, i).Add(time.Hour),
+			Status:    "BUSY",
+			UserID:    user.ID,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+
+	get := func(query string) (*httptest.ResponseRecorder, services.Page[db.Event]) {
+		req := httptest.NewRequest("GET", "/api/events/user?"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), userIDContextKey, user.ID))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(server.handleGetEventsByUserID).ServeHTTP(rr, req)
+
+		var page services.Page[db.Event]
+		if rr.Code == http.StatusOK {
+			if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+				t.Fatalf("Failed to unmarshal response body: %v", err)
+			}
+		}
+		return rr, page
+	}
+
+	rr, first := get("sort=-start_time&limit=2")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if len(first.Items) != 2 || first.NextCursor == "" {
+		t.Fatalf("Expected 2 events and a next cursor, got %d and %q", len(first.Items), first.NextCursor)
+	}
+	if !first.Items[0].StartTime.Equal(start.AddDate(0, 0, 2)) {
+		t.Errorf("Expected the latest event first, got %v", first.Items[0].StartTime)
+	}
+
+	rr, second := get("sort=-start_time&limit=2&cursor=" + url.QueryEscape(first.NextCursor))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if len(second.Items) != 1 || second.NextCursor != "" {
+		t.Fatalf("Expected the last event and no cursor, got %d and %q", len(second.Items), second.NextCursor)
+	}
+	if !second.Items[0].StartTime.Equal(start) {
+		t.Errorf("Expected the earliest event last, got %v", second.Items[0].StartTime)
+	}
+
+	rr, ranged := get("start_from=" + url.QueryEscape(start.Add(time.Hour).Format(time.RFC3339)) + "&end_to=" + url.QueryEscape(start.AddDate(0, 0, 1).Add(time.Hour).Format(time.RFC3339)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if len(ranged.Items) != 1 {
+		t.Errorf("Expected 1 event in range, got %d", len(ranged.Items))
+	}
+
+	for _, query := range []string{"cursor=garbage", "limit=abc", "limit=1000", "sort=title", "start_from=tomorrow"} {
+		if rr, _ := get(query); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", query, rr.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestServer_handleCreateEventConflict(t *testing.T) {
+	queries := repository.SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	transactor := repository.NewTransactor(queries)
+	eventRepo := repository.NewEventRepository(queries)
+	swapRepo := repository.NewSwapRequestRepository(queries)
+	eventService := services.NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
+
+	server := NewServer(nil, nil, nil, eventService, nil, nil, nil, nil, nil, nil)
+
+	user, err := userRepo.CreateUser(context.Background(), db.CreateUserParams{Name: "User One", Email: "user1@test.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/events", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), userIDContextKey, user.ID))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(server.handleCreateEvent).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post(`{"title":"Shift","start_time":"2030-01-01T09:00:00Z","end_time":"2030-01-01T17:00:00Z","status":"BUSY"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var existing db.Event
+	json.Unmarshal(rr.Body.Bytes(), &existing)
+
+	overlapping := `{"title":"Dentist","start_time":"2030-01-01T15:00:00+05:30","end_time":"2030-01-01T16:00:00+05:30","status":"BUSY"%s}`
+	rr = post(fmt.Sprintf(overlapping, ""))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+	var conflict struct {
+		Error             string     `json:"error"`
+		ConflictingEvents []db.Event `json:"conflicting_events"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &conflict); err != nil {
+		t.Fatalf("Failed to unmarshal response body: %v", err)
+	}
+	if len(conflict.ConflictingEvents) != 1 || conflict.ConflictingEvents[0].ID != existing.ID {
+		t.Errorf("Expected the existing shift as the conflict, got %+v", conflict.ConflictingEvents)
+	}
+
+	rr = post(fmt.Sprintf(overlapping, `,"allow_overlap":true`))
+	if rr.Code != http.StatusOK {
+		t.Errorf("handler returned wrong status code with allow_overlap: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+}
+
+func TestServer_TimeZoneRendering(t *testing.T) {
+	queries := repository.SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	transactor := repository.NewTransactor(queries)
+	eventRepo := repository.NewEventRepository(queries)
+	swapRepo := repository.NewSwapRequestRepository(queries)
+	eventService := services.NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
+
+	server := NewServer(nil, nil, nil, eventService, nil, nil, nil, nil, nil, nil)
+
+	user, err := userRepo.CreateUser(context.Background(), db.CreateUserParams{Name: "User One", Email: "user1@test.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	start := time.Date(2030, time.July, 1, 9, 0, 0, 0, time.UTC)
+	event, err := eventService.CreateEvent(context.Background(), services.CreateEventInput{Title: "Call", StartTime: start, EndTime: start.Add(time.Hour), Status: "BUSY", UserID: user.ID, TimeZone: "Asia/Kolkata"})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/events/%d%s", event.ID, query), nil)
+		req.SetPathValue("id", fmt.Sprint(event.ID))
+		rr := httptest.NewRecorder()
+		TimeZoneMiddleware(http.HandlerFunc(server.handleGetEventByID)).ServeHTTP(rr, req)
+		return rr
+	}
+
+	testCases := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantStart string
+	}{
+		{name: "UTC by default", query: "", wantCode: http.StatusOK, wantStart: "2030-07-01T09:00:00Z"},
+		{name: "caller zone", query: "?tz=Asia/Kolkata", wantCode: http.StatusOK, wantStart: "2030-07-01T14:30:00+05:30"},
+		{name: "daylight saving offset", query: "?tz=America/New_York", wantCode: http.StatusOK, wantStart: "2030-07-01T05:00:00-04:00"},
+		{name: "invalid zone", query: "?tz=Nowhere/Special", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := get(tc.query)
+			if rr.Code != tc.wantCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, tc.wantCode, rr.Body.String())
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				StartTime string `json:"start_time"`
+				TimeZone  string `json:"time_zone"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to unmarshal response body: %v", err)
+			}
+			if body.StartTime != tc.wantStart {
+				t.Errorf("Expected start_time %q, got %q", tc.wantStart, body.StartTime)
+			}
+			if body.TimeZone != "Asia/Kolkata" {
+				t.Errorf("Expected time_zone %q, got %q", "Asia/Kolkata", body.TimeZone)
+			}
+		})
+	}
+}
+
+func TestServer_EventETags(t *testing.T) {
+	ts, _, _ := setupTestServer(t)
+	defer ts.Close()
+	handler := TimeZoneMiddleware(ts.Config.Handler)
+
+	_, _, cookie := signUpAndLogin(t, ts, "Tab User", "tabs@example.com", "password123")
+
+	send := func(method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		req.AddCookie(cookie)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send(http.MethodPost, "/api/events", `{"title":"Focus","start_time":"2030-01-01T09:00:00Z","end_time":"2030-01-01T10:00:00Z","status":"BUSY"}`, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("failed to create event: %s", rr.Body.String())
+	}
+	var event db.Event
+	json.NewDecoder(rr.Body).Decode(&event)
+	path := fmt.Sprintf("/api/events/%d", event.ID)
+
+	rr = send(http.MethodGet, path, "", nil)
+	if etag := rr.Header().Get("ETag"); etag != `"1"` {
+		t.Fatalf("expected ETag \"1\", got %q", etag)
+	}
+	if rr := send(http.MethodGet, path, "", map[string]string{"If-None-Match": `W/"1"`}); rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("expected 304 without a body for a current ETag, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	list := send(http.MethodGet, "/api/events/user", "", nil)
+	listETag := list.Header().Get("ETag")
+	if rr := send(http.MethodGet, "/api/events/user", "", map[string]string{"If-None-Match": listETag}); rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("expected 304 without a body for an unchanged list, got %d", rr.Code)
+	}
+	// The same events rendered in another zone are a different representation.
+	if rr := send(http.MethodGet, "/api/events/user?tz=Europe/Berlin", "", map[string]string{"If-None-Match": listETag}); rr.Code != http.StatusOK {
+		t.Errorf("expected a list in another time zone to be sent, got %d", rr.Code)
+	}
+
+	update := `{"title":"Deep work","start_time":"2030-01-01T09:00:00Z","end_time":"2030-01-01T10:00:00Z"}`
+	rr = send(http.MethodPut, path, update, map[string]string{"If-Match": `"1"`})
+	if rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"2"` {
+		t.Fatalf("expected the update to succeed with ETag \"2\", got %d %q: %s", rr.Code, rr.Header().Get("ETag"), rr.Body.String())
+	}
+
+	// A second tab still holding version 1 must not overwrite the change.
+	stale := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		header string
+	}{
+		{"stale update", http.MethodPut, path, update, `"1"`},
+		{"stale status change", http.MethodPost, path + "/status", `{"status":"SWAPPABLE"}`, `"1"`},
+		{"stale delete", http.MethodDelete, path, "", `"1"`},
+		{"weak tag", http.MethodDelete, path, "", `W/"2"`},
+	}
+	for _, tt := range stale {
+		if rr := send(tt.method, tt.path, tt.body, map[string]string{"If-Match": tt.header}); rr.Code != http.StatusPreconditionFailed {
+			t.Errorf("%s: expected status 412, got %d: %s", tt.name, rr.Code, rr.Body.String())
+		}
+	}
+
+	if rr := send(http.MethodGet, path, "", map[string]string{"If-None-Match": `"1"`}); rr.Code != http.StatusOK {
+		t.Errorf("expected a changed event to be sent again, got %d", rr.Code)
+	}
+	if rr := send(http.MethodGet, "/api/events/user", "", map[string]string{"If-None-Match": listETag}); rr.Code != http.StatusOK {
+		t.Errorf("expected a changed list to be sent again, got %d", rr.Code)
+	}
+
+	if rr := send(http.MethodPost, path+"/status", `{"status":"SWAPPABLE"}`, nil); rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"3"` {
+		t.Errorf("expected a write without If-Match to succeed, got %d %q", rr.Code, rr.Header().Get("ETag"))
+	}
+	// Only the swap flow may put a slot into SWAP_PENDING.
+	if rr := send(http.MethodPost, path+"/status", `{"status":"SWAP_PENDING"}`, nil); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected setting SWAP_PENDING to be refused with 400, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := send(http.MethodDelete, path, "", map[string]string{"If-Match": `"3"`}); rr.Code != http.StatusNoContent {
+		t.Errorf("expected a delete with the current ETag to succeed, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestServer_handlePatchEvent(t *testing.T) {
+	ts, _, _ := setupTestServer(t)
+	defer ts.Close()
+	handler := ts.Config.Handler
+
+	_, _, cookie := signUpAndLogin(t, ts, "Patch User", "patch@example.com", "password123")
+
+	patch := func(path, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.AddCookie(cookie)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/events", strings.NewReader(`{"title":"Lunch","start_time":"2030-01-01T12:00:00Z","end_time":"2030-01-01T13:00:00Z","status":"SWAPPABLE"}`))
+	req.AddCookie(cookie)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	var event db.Event
+	json.NewDecoder(rr.Body).Decode(&event)
+	path := fmt.Sprintf("/api/events/%d", event.ID)
+
+	rr = patch(path, "application/merge-patch+json", `{"title":"Long lunch","end_time":"2030-01-01T14:00:00Z"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var result services.PatchEventResult
+	json.NewDecoder(rr.Body).Decode(&result)
+	if result.Event.Title != "Long lunch" || result.Event.Status != "SWAPPABLE" || !result.Event.StartTime.Equal(event.StartTime) {
+		t.Errorf("expected only the patched fields to change, got %+v", result.Event)
+	}
+	if result.SideEffects.CancelledSwapRequests == nil || result.SideEffects.StatusChanged {
+		t.Errorf("expected empty side effects, got %+v", result.SideEffects)
+	}
+	if etag := rr.Header().Get("ETag"); etag != eventETag(result.Event.Version) {
+		t.Errorf("expected the ETag of the patched event, got %q", etag)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"null removes a field", "application/merge-patch+json", `{"title":null}`, http.StatusBadRequest},
+		{"read-only field", "application/merge-patch+json", `{"status":"BUSY"}`, http.StatusBadRequest},
+		{"not an object", "application/merge-patch+json", `["title"]`, http.StatusBadRequest},
+		{"invalid changed field", "application/json", `{"time_zone":"Mars/Olympus"}`, http.StatusBadRequest},
+		{"end before start", "application/json", `{"end_time":"2030-01-01T11:00:00Z"}`, http.StatusBadRequest},
+		{"other media type", "text/plain", `{"title":"x"}`, http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		if rr := patch(path, tt.contentType, tt.body); rr.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.want, rr.Code, rr.Body.String())
+		}
+	}
+}
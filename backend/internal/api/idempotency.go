@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+
+	"slotswapper/internal/logging"
+	"slotswapper/internal/services"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// idempotent lets clients retry handler safely. The first request carrying an
+// Idempotency-Key header is handled as usual and its response stored; a retry
+// with the same key and body gets that response back, marked with an
+// Idempotent-Replayed header, without running handler again. Server errors and
+// panics are not stored, so the retry runs the handler. Requests without the
+// header are not affected. Must run after AuthMiddleware, as keys belong to a
+// user.
+//
+// The guarantee is best-effort: the handler's write commits in its own
+// transaction before the response exists, and the response is stored in a
+// second one afterwards. If the server dies between the two, the key stays
+// claimed until its lease runs out and the next retry runs the handler again.
+func (s *Server) idempotent(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || s.idempotencyService == nil {
+			handler(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writeProblem(w, r, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+		userID, ok := GetUserIDFromContext(r.Context())
+		if !ok {
+			writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeProblem(w, r, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		stored, err := s.idempotencyService.Begin(r.Context(), userID, key, requestFingerprint(r, body))
+		if errors.Is(err, services.ErrIdempotencyKeyReused) {
+			writeProblem(w, r, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+			return
+		}
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if stored != nil {
+			for name, values := range stored.Header {
+				w.Header()[name] = values
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.StatusCode)
+			w.Write(stored.Body)
+			return
+		}
+
+		logger := logging.FromContext(r.Context())
+		// Headers set before this point come from the outer middleware, which
+		// sets them again on a replay.
+		before := w.Header().Clone()
+		capture := &responseCapture{ResponseWriter: w}
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if err := s.idempotencyService.Release(r.Context(), userID, key); err != nil {
+					logger.Error("failed to release idempotency key", "error", err)
+				}
+				panic(recovered)
+			}
+		}()
+		handler(capture, r)
+		if capture.status == 0 {
+			capture.status = http.StatusOK
+		}
+
+		if capture.status >= http.StatusInternalServerError {
+			if err := s.idempotencyService.Release(r.Context(), userID, key); err != nil {
+				logger.Error("failed to release idempotency key", "error", err)
+			}
+			return
+		}
+		response := services.StoredResponse{StatusCode: capture.status, Header: changedHeaders(before, w.Header()), Body: capture.body.Bytes()}
+		if err := s.idempotencyService.Complete(r.Context(), userID, key, response); err != nil {
+			logger.Error("failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// changedHeaders returns the headers in after that are not in before with the
+// same values.
+func changedHeaders(before, after http.Header) http.Header {
+	changed := http.Header{}
+	for name, values := range after {
+		if !slices.Equal(before[name], values) {
+			changed[name] = values
+		}
+	}
+	return changed
+}
+
+// requestFingerprint identifies what a request asks for, so that a key reused
+// for a different request can be detected.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseCapture passes a response through and keeps a copy of it.
+type responseCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseCapture) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseCapture) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseCapture) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"slotswapper/internal/repository"
+	"slotswapper/internal/services"
+)
+
+func TestServer_idempotent(t *testing.T) {
+	ts, queries, _ := setupTestServer(t)
+	defer ts.Close()
+	handler := ts.Config.Handler
+
+	_, user, cookie := signUpAndLogin(t, ts, "Retry User", "retry@example.com", "password123")
+	_, _, otherCookie := signUpAndLogin(t, ts, "Other User", "other@example.com", "password123")
+
+	post := func(cookie *http.Cookie, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/events", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		req.AddCookie(cookie)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	countEvents := func() int {
+		events, err := queries.GetEventsByUserID(context.Background(), user.ID)
+		if err != nil {
+			t.Fatalf("failed to list events: %v", err)
+		}
+		return len(events)
+	}
+
+	body := `{"title":"Standup","start_time":"2030-01-01T09:00:00Z","end_time":"2030-01-01T09:15:00Z","status":"BUSY"}`
+	first := post(cookie, "create-standup", body)
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", first.Code, first.Body.String())
+	}
+	if first.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("expected the first response not to be marked as replayed")
+	}
+
+	retry := post(cookie, "create-standup", body)
+	if retry.Code != http.StatusOK {
+		t.Fatalf("expected the retry to replay status 200, got %d: %s", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("expected the retry to be marked as replayed")
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("expected the retry to replay %s, got %s", first.Body.String(), retry.Body.String())
+	}
+	if got := retry.Header().Get("Content-Type"); got != first.Header().Get("Content-Type") {
+		t.Errorf("expected the replayed Content-Type %q, got %q", first.Header().Get("Content-Type"), got)
+	}
+	if n := countEvents(); n != 1 {
+		t.Errorf("expected the retry not to create another event, got %d events", n)
+	}
+
+	changed := strings.Replace(body, "Standup", "Retro", 1)
+	if rr := post(cookie, "create-standup", changed); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected a reused key with another body to be refused with 422, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := post(otherCookie, "create-standup", body); rr.Code != http.StatusOK || rr.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("expected keys to be scoped to their user, got %d replayed=%q", rr.Code, rr.Header().Get("Idempotent-Replayed"))
+	}
+
+	overlapping := `{"title":"Standup","start_time":"2030-01-01T09:00:00Z","end_time":"2030-01-01T09:15:00Z","status":"BUSY","allow_overlap":true}`
+	if rr := post(cookie, "", overlapping); rr.Code != http.StatusOK {
+		t.Fatalf("expected a request without a key to be handled, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if n := countEvents(); n != 2 {
+		t.Errorf("expected a request without a key to create an event, got %d events", n)
+	}
+
+	// Client errors are stored too, so the retry sees the same outcome.
+	invalid := `{"title":"","status":"BUSY"}`
+	if rr := post(cookie, "create-invalid", invalid); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := post(cookie, "create-invalid", invalid); rr.Code != http.StatusBadRequest || rr.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected the failed request to be replayed, got %d replayed=%q", rr.Code, rr.Header().Get("Idempotent-Replayed"))
+	}
+
+	if rr := post(cookie, strings.Repeat("k", 256), body); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected an over-long key to be refused with 400, got %d", rr.Code)
+	}
+}
+
+func TestServer_idempotentReleasesKeyOnPanic(t *testing.T) {
+	queries, user := repository.SetupTestDBWithUser(t)
+	server := &Server{idempotencyService: services.NewIdempotencyService(repository.NewIdempotencyKeyRepository(queries), repository.NewTransactor(queries), time.Hour)}
+
+	panics := true
+	handler := server.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		if panics {
+			panic("handler failed")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/events", strings.NewReader(`{}`))
+		req.Header.Set("Idempotency-Key", "panicky")
+		req = req.WithContext(context.WithValue(req.Context(), userIDContextKey, user.ID))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected the panic to propagate")
+			}
+		}()
+		serve()
+	}()
+
+	panics = false
+	if rr := serve(); rr.Code != http.StatusNoContent || rr.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("expected the retry to run the handler, got %d replayed=%q", rr.Code, rr.Header().Get("Idempotent-Replayed"))
+	}
+}
+
+func TestServer_idempotentReplaysHeaders(t *testing.T) {
+	queries, user := repository.SetupTestDBWithUser(t)
+	server := &Server{idempotencyService: services.NewIdempotencyService(repository.NewIdempotencyKeyRepository(queries), repository.NewTransactor(queries), time.Hour)}
+
+	handler := server.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"1"`)
+		w.Header().Set("Location", "/api/events/1")
+		w.WriteHeader(http.StatusCreated)
+	})
+	serve := func(requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/events", strings.NewReader(`{}`))
+		req.Header.Set("Idempotency-Key", "with-headers")
+		req = req.WithContext(context.WithValue(req.Context(), userIDContextKey, user.ID))
+		rr := httptest.NewRecorder()
+		// Set by the outer middleware on every request.
+		rr.Header().Set("X-Request-ID", requestID)
+		handler(rr, req)
+		return rr
+	}
+
+	serve("first")
+	retry := serve("retry")
+	if retry.Code != http.StatusCreated || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected a replayed 201, got %d replayed=%q", retry.Code, retry.Header().Get("Idempotent-Replayed"))
+	}
+	if got := retry.Header().Get("ETag"); got != `"1"` {
+		t.Errorf("expected the replayed ETag, got %q", got)
+	}
+	if got := retry.Header().Get("Location"); got != "/api/events/1" {
+		t.Errorf("expected the replayed Location, got %q", got)
+	}
+	if got := retry.Header().Get("X-Request-ID"); got != "retry" {
+		t.Errorf("expected the retry's own request ID, got %q", got)
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Defaults for the server settings left unset in the config.
+const (
+	defaultAddr              = ":8080"
+	defaultMetricsAddr       = "localhost:9090"
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultReadTimeout       = 15 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 2 * time.Minute
+	defaultShutdownTimeout   = 20 * time.Second
+	defaultAccessTokenTTL    = 24 * time.Hour
+)
+
+func orDefault(d Duration, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return time.Duration(d)
+}
+
+// HTTPServer returns an http.Server serving handler on the configured address
+// with the configured timeouts.
+func (s *Server) HTTPServer(handler http.Handler) *http.Server {
+	cfg := s.config
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	addr := cfg.Addr
+	if addr == "" {
+		addr = defaultAddr
+	}
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: orDefault(cfg.ReadHeaderTimeout, defaultReadHeaderTimeout),
+		ReadTimeout:       orDefault(cfg.ReadTimeout, defaultReadTimeout),
+		WriteTimeout:      orDefault(cfg.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:       orDefault(cfg.IdleTimeout, defaultIdleTimeout),
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+}
+
+// ListenAndServe listens on the configured address and calls Serve.
+func (s *Server) ListenAndServe(ctx context.Context, handler http.Handler) error {
+	srv := s.HTTPServer(handler)
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return s.serve(ctx, srv, ln)
+}
+
+// Serve serves handler on ln, over TLS when the config names a certificate
+// and key, until ctx is done. It then reports not ready on /health/ready for
+// the drain delay, stops accepting connections and waits up to the shutdown
+// timeout for requests in flight. It returns nil after a clean shutdown.
+func (s *Server) Serve(ctx context.Context, ln net.Listener, handler http.Handler) error {
+	return s.serve(ctx, s.HTTPServer(handler), ln)
+}
+
+func (s *Server) serve(ctx context.Context, srv *http.Server, ln net.Listener) error {
+	cfg := s.config
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		if cfg.TlsCertFile != "" && cfg.TlsKeyFile != "" {
+			slog.Info("server listening", "addr", ln.Addr().String(), "tls", true)
+			errc <- srv.ServeTLS(ln, cfg.TlsCertFile, cfg.TlsKeyFile)
+			return
+		}
+		slog.Info("server listening", "addr", ln.Addr().String(), "tls", false)
+		errc <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.draining.Store(true)
+	slog.Info("server draining", "drain_delay", time.Duration(cfg.DrainDelay).String())
+	time.Sleep(time.Duration(cfg.DrainDelay))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), orDefault(cfg.ShutdownTimeout, defaultShutdownTimeout))
+	defer cancel()
+	err := srv.Shutdown(shutdownCtx)
+	if err != nil {
+		// Requests still running past the timeout are cut off.
+		srv.Close()
+	}
+	if serveErr := <-errc; !errors.Is(serveErr, http.ErrServerClosed) && err == nil {
+		err = serveErr
+	}
+	return err
+}
+
+// readinessCheck reports whether the server takes new requests. It fails
+// once shutdown has begun.
+func (s *Server) readinessCheck(w http.ResponseWriter, r *http.Request) {
+	if s.draining.Load() {
+		writeProblem(w, r, http.StatusServiceUnavailable, "Server is shutting down")
+		return
+	}
+	w.Write([]byte("OK"))
+}
+
+// ServeMetrics serves handler at GET /metrics on addr until ctx is done, then
+// shuts down. It returns nil after a clean shutdown.
+func ServeMetrics(ctx context.Context, addr string, handler http.Handler) error {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", handler)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: defaultReadHeaderTimeout,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		slog.Info("metrics listening", "addr", ln.Addr().String())
+		errc <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
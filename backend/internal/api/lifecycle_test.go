@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// startServing runs s.Serve on a local port and returns its base URL and a
+// channel receiving Serve's result.
+func startServing(t *testing.T, ctx context.Context, s *Server, handler http.Handler, scheme string) (string, <-chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, ln, handler) }()
+	return scheme + "://" + ln.Addr().String(), done
+}
+
+// writeSelfSignedCert writes a certificate for 127.0.0.1 and its key to dir.
+func writeSelfSignedCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+func TestServer_Serve(t *testing.T) {
+	t.Run("drains in-flight requests on shutdown", func(t *testing.T) {
+		s := NewServer(&Config{DrainDelay: Duration(300 * time.Millisecond)}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		started, release := make(chan struct{}), make(chan struct{})
+		router := http.NewServeMux()
+		s.RegisterRoutes(router)
+		router.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.Write([]byte("done"))
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		url, done := startServing(t, ctx, s, router, "http")
+
+		if resp, err := http.Get(url + "/health/ready"); err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected the server to be ready, got %v %v", resp, err)
+		}
+
+		slow := make(chan string, 1)
+		go func() {
+			resp, err := http.Get(url + "/slow")
+			if err != nil {
+				slow <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			slow <- string(body)
+		}()
+		<-started
+		cancel()
+
+		// During the drain delay the server still answers, but not ready.
+		time.Sleep(50 * time.Millisecond)
+		resp, err := http.Get(url + "/health/ready")
+		if err != nil {
+			t.Fatalf("readiness check failed: %v", err)
+		}
+		var p problem
+		json.NewDecoder(resp.Body).Decode(&p)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable || p.Detail != "Server is shutting down" {
+			t.Errorf("expected 503 while draining, got %d %+v", resp.StatusCode, p)
+		}
+		select {
+		case err := <-done:
+			t.Fatalf("Serve returned before the request finished: %v", err)
+		default:
+		}
+
+		close(release)
+		if got := <-slow; got != "done" {
+			t.Errorf("expected the in-flight request to complete, got %q", got)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("expected a clean shutdown, got %v", err)
+		}
+		if _, err := http.Get(url + "/health"); err == nil {
+			t.Error("expected the listener to be closed")
+		}
+	})
+
+	t.Run("cuts off requests past the shutdown timeout", func(t *testing.T) {
+		s := NewServer(&Config{ShutdownTimeout: Duration(100 * time.Millisecond)}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		started := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-r.Context().Done()
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		url, done := startServing(t, ctx, s, handler, "http")
+		go http.Get(url)
+		<-started
+		cancel()
+		if err := <-done; err != context.DeadlineExceeded {
+			t.Errorf("expected the shutdown to time out, got %v", err)
+		}
+	})
+
+	t.Run("serves TLS from the configured certificate", func(t *testing.T) {
+		certFile, keyFile := writeSelfSignedCert(t, t.TempDir())
+		s := NewServer(&Config{TlsCertFile: certFile, TlsKeyFile: keyFile}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		router := http.NewServeMux()
+		s.RegisterRoutes(router)
+		ctx, cancel := context.WithCancel(context.Background())
+		url, done := startServing(t, ctx, s, router, "https")
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+		resp, err := client.Get(url + "/health")
+		if err != nil {
+			t.Fatalf("TLS request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.TLS == nil || resp.StatusCode != http.StatusOK {
+			t.Errorf("expected a TLS response, got %+v", resp)
+		}
+		if resp, err := http.Get("http" + url[len("https"):] + "/health"); err == nil && resp.StatusCode == http.StatusOK {
+			t.Error("expected plain HTTP to be refused")
+		}
+
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("expected a clean shutdown, got %v", err)
+		}
+	})
+
+	t.Run("timeouts", func(t *testing.T) {
+		srv := NewServer(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).HTTPServer(nil)
+		if srv.Addr != ":8080" || srv.ReadHeaderTimeout != 5*time.Second || srv.WriteTimeout != 30*time.Second || srv.IdleTimeout != 2*time.Minute {
+			t.Errorf("unexpected default server %+v", srv)
+		}
+
+		var cfg Config
+		if err := json.Unmarshal([]byte(`{"addr": ":9000", "readTimeout": "3s", "writeTimeout": "1m"}`), &cfg); err != nil {
+			t.Fatalf("failed to decode config: %v", err)
+		}
+		srv = NewServer(&cfg, nil, nil, nil, nil, nil, nil, nil, nil, nil).HTTPServer(nil)
+		if srv.Addr != ":9000" || srv.ReadTimeout != 3*time.Second || srv.WriteTimeout != time.Minute {
+			t.Errorf("configured timeouts not applied: %+v", srv)
+		}
+		if err := json.Unmarshal([]byte(`{"readTimeout": 30}`), &cfg); err == nil {
+			t.Error("expected an error for a duration without a unit")
+		}
+	})
+}
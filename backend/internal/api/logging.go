@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"slotswapper/internal/logging"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+const requestLogContextKey contextKey = "requestLog"
+
+// validRequestID limits which caller-supplied request IDs are propagated, so
+// that logs and audit entries cannot be filled with arbitrary text.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RouteMatcher finds the route pattern serving a request, like
+// (*http.ServeMux).Handler.
+type RouteMatcher interface {
+	Handler(r *http.Request) (h http.Handler, pattern string)
+}
+
+// requestLog collects what the access log reports that is only known further
+// down the chain, such as the authenticated user.
+type requestLog struct {
+	userID int64
+}
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// LoggingMiddleware assigns each request an ID, reusing a well-formed
+// X-Request-ID from the caller, and echoes it in the response. Handlers and
+// services find a logger tagged with the ID in the request context. Once the
+// response is written it logs the method, route, status, latency and user.
+func LoggingMiddleware(logger *slog.Logger, routes RouteMatcher) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(requestIDHeader)
+			if !validRequestID.MatchString(requestID) {
+				requestID = rand.Text()
+			}
+			// Later middleware, such as the audit metadata, read the ID
+			// from the request.
+			r.Header.Set(requestIDHeader, requestID)
+			w.Header().Set(requestIDHeader, requestID)
+
+			_, route := routes.Handler(r)
+			reqLog := &requestLog{}
+			ctx := logging.WithLogger(r.Context(), logger.With("request_id", requestID))
+			ctx = context.WithValue(ctx, requestLogContextKey, reqLog)
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r.WithContext(ctx))
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+
+			level := slog.LevelInfo
+			if rec.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			attrs := []slog.Attr{
+				slog.String("request_id", requestID),
+				slog.String("method", r.Method),
+				slog.String("route", route),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", rec.status),
+				slog.Duration("latency", time.Since(start)),
+			}
+			if reqLog.userID != 0 {
+				attrs = append(attrs, slog.Int64("user_id", reqLog.userID))
+			}
+			logger.LogAttrs(r.Context(), level, "request", attrs...)
+		})
+	}
+}
+
+// setRequestUser records the authenticated user in the access log and tags
+// the request's logger with it.
+func setRequestUser(ctx context.Context, userID int64) context.Context {
+	if reqLog, ok := ctx.Value(requestLogContextKey).(*requestLog); ok {
+		reqLog.userID = userID
+	}
+	return logging.With(ctx, "user_id", userID)
+}
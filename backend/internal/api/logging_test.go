@@ -0,0 +1,156 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/services"
+)
+
+// logRecords decodes the JSON log lines written to buf.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func findRecord(records []map[string]any, msg string) map[string]any {
+	for _, record := range records {
+		if record["msg"] == msg {
+			return record
+		}
+	}
+	return nil
+}
+
+func TestServer_LoggingMiddleware(t *testing.T) {
+	ts, queries, _ := setupTestServer(t)
+	defer ts.Close()
+	mux := ts.Config.Handler.(*http.ServeMux)
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := LoggingMiddleware(logger, mux)(RequestMetadataMiddleware(nil)(mux))
+
+	token, alice, _ := signUpAndLogin(t, ts, "Alice", "alice@example.com", "password123")
+	bobToken, bob, _ := signUpAndLogin(t, ts, "Bob", "bob@example.com", "password123")
+
+	serve := func(method, path, requestID, token string, body any) *httptest.ResponseRecorder {
+		var payload bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&payload).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &payload)
+		req.Header.Set("Content-Type", "application/json")
+		if requestID != "" {
+			req.Header.Set("X-Request-ID", requestID)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("propagates the caller's request ID", func(t *testing.T) {
+		logs.Reset()
+		rr := serve("GET", "/api/me", "trace-123", token, nil)
+		if got := rr.Header().Get("X-Request-ID"); got != "trace-123" {
+			t.Errorf("expected the request ID to be echoed, got %q", got)
+		}
+
+		record := findRecord(logRecords(t, &logs), "request")
+		if record == nil {
+			t.Fatalf("no access log record in %s", logs.String())
+		}
+		want := map[string]any{"request_id": "trace-123", "method": "GET", "route": "GET /api/me", "path": "/api/me", "status": float64(200), "user_id": float64(alice.ID)}
+		for key, value := range want {
+			if record[key] != value {
+				t.Errorf("expected %s=%v, got %v", key, value, record[key])
+			}
+		}
+		if _, ok := record["latency"]; !ok {
+			t.Errorf("expected a latency attribute")
+		}
+	})
+
+	t.Run("assigns an ID when missing or malformed", func(t *testing.T) {
+		first := serve("GET", "/health", "", "", nil).Header().Get("X-Request-ID")
+		second := serve("GET", "/health", "not a valid id", "", nil).Header().Get("X-Request-ID")
+		if first == "" || second == "" || first == second || second == "not a valid id" {
+			t.Errorf("expected fresh request IDs, got %q and %q", first, second)
+		}
+	})
+
+	t.Run("logs failures without a user", func(t *testing.T) {
+		logs.Reset()
+		serve("GET", "/api/me", "anon-1", "", nil)
+		record := findRecord(logRecords(t, &logs), "request")
+		if record["status"] != float64(http.StatusUnauthorized) || record["user_id"] != nil {
+			t.Errorf("unexpected access log record %v", record)
+		}
+	})
+
+	t.Run("one swap is traceable across layers", func(t *testing.T) {
+		start := time.Date(2030, time.April, 1, 9, 0, 0, 0, time.UTC)
+		var aliceEvent, bobEvent db.Event
+		json.NewDecoder(serve("POST", "/api/events", "", token, map[string]any{"title": "A", "start_time": start, "end_time": start.Add(time.Hour), "status": "SWAPPABLE"}).Body).Decode(&aliceEvent)
+		json.NewDecoder(serve("POST", "/api/events", "", bobToken, map[string]any{"title": "B", "start_time": start.Add(2 * time.Hour), "end_time": start.Add(3 * time.Hour), "status": "SWAPPABLE"}).Body).Decode(&bobEvent)
+
+		logs.Reset()
+		rr := serve("POST", "/api/swap-request", "swap-trace", token, services.CreateSwapRequestInput{
+			ResponderUserID: bob.ID, RequesterSlotID: aliceEvent.ID, ResponderSlotID: bobEvent.ID,
+		})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("swap request failed: %s", rr.Body.String())
+		}
+		var swap db.SwapRequest
+		json.NewDecoder(rr.Body).Decode(&swap)
+
+		created := findRecord(logRecords(t, &logs), "swap request created")
+		if created == nil {
+			t.Fatalf("no service log record in %s", logs.String())
+		}
+		if created["request_id"] != "swap-trace" || created["user_id"] != float64(alice.ID) || created["swap_request_id"] != float64(swap.ID) {
+			t.Errorf("service log not tagged with the request: %v", created)
+		}
+
+		entries, err := queries.ListAuditLogsByEntity(context.Background(), db.ListAuditLogsByEntityParams{EntityType: services.AuditEntitySwapRequest, EntityID: swap.ID})
+		if err != nil {
+			t.Fatalf("failed to read audit log: %v", err)
+		}
+		if len(entries) != 1 || entries[0].RequestID != "swap-trace" {
+			t.Errorf("expected the audit entry to carry the request ID, got %+v", entries)
+		}
+
+		logs.Reset()
+		serve("POST", fmt.Sprintf("/api/swap-response/%d", swap.ID), "swap-accept", bobToken, map[string]any{"status": "ACCEPTED"})
+		records := logRecords(t, &logs)
+		for _, msg := range []string{"slot transferred", "swap request resolved", "request"} {
+			record := findRecord(records, msg)
+			if record == nil || record["request_id"] != "swap-accept" {
+				t.Errorf("expected %q to be logged with the request ID, got %v", msg, record)
+			}
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"slotswapper/internal/metrics"
+)
+
+// MetricsMiddleware counts and times every request under the route pattern
+// that serves it.
+func MetricsMiddleware(m *metrics.Metrics, routes RouteMatcher) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			_, route := routes.Handler(r)
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			m.ObserveRequest(r.Method, route, rec.status, time.Since(start))
+		})
+	}
+}
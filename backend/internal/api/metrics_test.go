@@ -0,0 +1,198 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"slotswapper/internal/crypto"
+	"slotswapper/internal/db"
+	"slotswapper/internal/metrics"
+	"slotswapper/internal/repository"
+	"slotswapper/internal/services"
+)
+
+// scrape reads /metrics and returns every sample keyed by its series as
+// printed, such as `slotswapper_swap_requests_resolved_total{outcome="accepted"}`.
+func scrape(t *testing.T, ts *httptest.Server) map[string]float64 {
+	t.Helper()
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("scrape returned %d: %s", resp.StatusCode, body)
+	}
+
+	samples := map[string]float64{}
+	for _, line := range strings.Split(string(body), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		value, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("invalid sample %q: %v", line, err)
+		}
+		samples[line[:i]] = value
+	}
+	return samples
+}
+
+func TestServer_MetricsMiddleware(t *testing.T) {
+	conn := repository.SetupTestConn(t)
+	m := metrics.New(db.New(conn))
+	queries := db.NewObserved(conn, m.ObserveQuery)
+
+	userRepo := repository.NewUserRepository(queries)
+	eventRepo := repository.NewEventRepository(queries)
+	swapRepo := repository.NewSwapRequestRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	transactor := repository.NewTransactor(queries)
+	jwtManager := crypto.NewJWT("test-jwt-secret", 10*time.Minute)
+	server := NewServer(nil,
+		services.NewAuthService(userRepo, auditRepo, transactor, crypto.NewPassword(), jwtManager),
+		services.NewUserService(userRepo, eventRepo, swapRepo, repository.NewAccessTokenRepository(queries), auditRepo, repository.NewNotificationRepository(queries), transactor, crypto.NewPassword()),
+		services.NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(queries), transactor),
+		services.NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(queries), transactor),
+		services.NewAuditService(auditRepo, userRepo),
+		services.NewAccessTokenService(repository.NewAccessTokenRepository(queries), auditRepo, transactor),
+		services.NewIdempotencyService(repository.NewIdempotencyKeyRepository(queries), transactor, 0),
+		services.NewNotificationService(repository.NewNotificationRepository(queries)),
+		jwtManager,
+	)
+	router := http.NewServeMux()
+	server.RegisterRoutes(router)
+	router.Handle("GET /metrics", m.Handler())
+	ts := httptest.NewServer(MetricsMiddleware(m, router)(router))
+	defer ts.Close()
+
+	call := func(method, path, token string, body any) *http.Response {
+		t.Helper()
+		var payload bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&payload).Encode(body)
+		}
+		req, _ := http.NewRequest(method, ts.URL+path, &payload)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	signUp := func(name, email string) (string, int64) {
+		var auth struct {
+			Token string  `json:"token"`
+			User  db.User `json:"user"`
+		}
+		json.NewDecoder(call("POST", "/api/signup", "", services.RegisterUserInput{Name: name, Email: email, Password: "password123"}).Body).Decode(&auth)
+		return auth.Token, auth.User.ID
+	}
+	createSlot := func(token string, start time.Time) db.Event {
+		var event db.Event
+		json.NewDecoder(call("POST", "/api/events", token, map[string]any{"title": "Shift", "start_time": start, "end_time": start.Add(time.Hour), "status": "SWAPPABLE"}).Body).Decode(&event)
+		return event
+	}
+
+	before := scrape(t, ts)
+
+	aliceToken, _ := signUp("Alice", "alice@example.com")
+	bobToken, bobID := signUp("Bob", "bob@example.com")
+	call("POST", "/api/login", "", services.LoginInput{Email: "alice@example.com", Password: "wrong-password"})
+	call("POST", "/api/login", "", services.LoginInput{Email: "nobody@example.com", Password: "password123"})
+
+	start := time.Date(2030, time.April, 1, 9, 0, 0, 0, time.UTC)
+	aliceSlot := createSlot(aliceToken, start)
+	bobSlot := createSlot(bobToken, start.Add(2*time.Hour))
+	createSlot(bobToken, start.Add(4*time.Hour))
+
+	var swap db.SwapRequest
+	json.NewDecoder(call("POST", "/api/swap-request", aliceToken, services.CreateSwapRequestInput{
+		ResponderUserID: bobID, RequesterSlotID: aliceSlot.ID, ResponderSlotID: bobSlot.ID,
+	}).Body).Decode(&swap)
+
+	t.Run("marketplace gauges", func(t *testing.T) {
+		samples := scrape(t, ts)
+		if got := samples["slotswapper_swap_requests_pending"]; got != 1 {
+			t.Errorf("expected 1 pending swap request, got %v", got)
+		}
+		// Bob's slot stays on offer while Alice's is reserved.
+		if got := samples["slotswapper_slots_swappable"]; got != 2 {
+			t.Errorf("expected 2 swappable slots, got %v", got)
+		}
+	})
+
+	t.Run("domain counters", func(t *testing.T) {
+		call("POST", fmt.Sprintf("/api/swap-response/%d", swap.ID), bobToken, map[string]any{"status": "ACCEPTED"})
+
+		// A second offer that lapses when the requested slot changes.
+		aliceSlot := createSlot(aliceToken, start.Add(6*time.Hour))
+		bobSlot := createSlot(bobToken, start.Add(8*time.Hour))
+		call("POST", "/api/swap-request", aliceToken, services.CreateSwapRequestInput{
+			ResponderUserID: bobID, RequesterSlotID: aliceSlot.ID, ResponderSlotID: bobSlot.ID,
+		})
+		call("PUT", fmt.Sprintf("/api/events/%d", bobSlot.ID), bobToken, map[string]any{"title": "Moved", "start_time": start.Add(10 * time.Hour), "end_time": start.Add(11 * time.Hour)})
+
+		after := scrape(t, ts)
+		for series, want := range map[string]float64{
+			`slotswapper_swap_requests_resolved_total{outcome="accepted"}`:  1,
+			`slotswapper_swap_requests_resolved_total{outcome="rejected"}`:  0,
+			`slotswapper_swap_requests_resolved_total{outcome="cancelled"}`: 1,
+			`slotswapper_login_failures_total`:                              2,
+		} {
+			if got := after[series] - before[series]; got != want {
+				t.Errorf("expected %s to grow by %v, got %v", series, want, got)
+			}
+		}
+		if got := after["slotswapper_swap_requests_pending"]; got != 0 {
+			t.Errorf("expected no pending swap requests, got %v", got)
+		}
+	})
+
+	t.Run("requests by route pattern", func(t *testing.T) {
+		call("GET", "/no-such-page", "", nil)
+		samples := scrape(t, ts)
+		for series, want := range map[string]float64{
+			`slotswapper_http_requests_total{code="200",method="POST",route="POST /api/signup"}`:             2,
+			`slotswapper_http_requests_total{code="401",method="POST",route="POST /api/login"}`:              2,
+			`slotswapper_http_requests_total{code="200",method="POST",route="POST /api/swap-response/{id}"}`: 1,
+			`slotswapper_http_requests_total{code="404",method="GET",route="unmatched"}`:                     1,
+			`slotswapper_http_request_duration_seconds_count{method="POST",route="POST /api/events"}`:        5,
+		} {
+			if got := samples[series]; got != want {
+				t.Errorf("expected %s = %v, got %v", series, want, got)
+			}
+		}
+		for series := range samples {
+			if strings.Contains(series, fmt.Sprintf("/api/swap-response/%d", swap.ID)) {
+				t.Errorf("expected route patterns instead of paths, found %s", series)
+			}
+		}
+	})
+
+	t.Run("query timings by name", func(t *testing.T) {
+		samples := scrape(t, ts)
+		for _, query := range []string{"CreateUser", "CreateSwapRequest", "ResolveSwapRequest", "CreateAuditLog"} {
+			if samples[fmt.Sprintf(`slotswapper_db_query_duration_seconds_count{query=%q}`, query)] == 0 {
+				t.Errorf("expected timings for %s", query)
+			}
+		}
+		if _, ok := samples[`slotswapper_db_query_duration_seconds_count{query="CountEventsByStatus"}`]; ok {
+			t.Errorf("expected the gauges' own queries to stay out of the timings")
+		}
+	})
+}
@@ -2,10 +2,13 @@ package api
 
 import (
 	"context"
+	"net"
 	"net/http"
+	"net/netip"
 	"strings"
 
 	"slotswapper/internal/crypto"
+	"slotswapper/internal/services"
 )
 
 type contextKey string
@@ -14,8 +17,14 @@ const (
 	userIDContextKey contextKey = "userID"
 )
 
-// AuthMiddleware is a middleware to authenticate requests using JWT from a cookie or Bearer token.
-func AuthMiddleware(jwtManager crypto.JWT) func(http.Handler) http.Handler {
+// sessionOnly is the scope of routes that personal access tokens cannot use.
+const sessionOnly = ""
+
+// AuthMiddleware is a middleware to authenticate requests using JWT from a
+// cookie or Bearer token. A personal access token may be sent as the Bearer
+// token instead, provided it grants scope; JWTs grant every scope. A JWT
+// stops working once its user has deactivated their account.
+func AuthMiddleware(jwtManager crypto.JWT, accessTokens services.AccessTokenService, users services.UserService, scope string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			var tokenString string
@@ -30,30 +39,46 @@ func AuthMiddleware(jwtManager crypto.JWT) func(http.Handler) http.Handler {
 			if tokenString == "" {
 				authHeader := r.Header.Get("Authorization")
 				if authHeader == "" {
-					http.Error(w, "Authorization required", http.StatusUnauthorized)
+					writeProblem(w, r, http.StatusUnauthorized, "Authorization required")
 					return
 				}
 
 				parts := strings.Split(authHeader, " ")
 				if len(parts) != 2 || parts[0] != "Bearer" {
-					http.Error(w, "Invalid Authorization header format", http.StatusUnauthorized)
+					writeProblem(w, r, http.StatusUnauthorized, "Invalid Authorization header format")
 					return
 				}
 				tokenString = parts[1]
 			}
 
 			if tokenString == "" {
-				http.Error(w, "Token not found", http.StatusUnauthorized)
+				writeProblem(w, r, http.StatusUnauthorized, "Token not found")
 				return
 			}
 
-			userID, err := jwtManager.Verify(tokenString)
-			if err != nil {
-				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
-				return
+			var userID int64
+			if crypto.IsAccessToken(tokenString) && accessTokens != nil {
+				userID, err = accessTokens.Authenticate(r.Context(), tokenString, scope)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+			} else {
+				userID, err = jwtManager.Verify(tokenString)
+				if err != nil {
+					writeProblem(w, r, http.StatusUnauthorized, "Invalid or expired token")
+					return
+				}
+				if users != nil {
+					if _, err := users.GetUserByID(r.Context(), userID); err != nil {
+						writeProblem(w, r, http.StatusUnauthorized, "Invalid or expired token")
+						return
+					}
+				}
 			}
 
 			ctx := context.WithValue(r.Context(), userIDContextKey, userID)
+			ctx = setRequestUser(ctx, userID)
 			r = r.WithContext(ctx)
 			next.ServeHTTP(w, r)
 		})
@@ -65,3 +90,56 @@ func GetUserIDFromContext(ctx context.Context) (int64, bool) {
 	userID, ok := ctx.Value(userIDContextKey).(int64)
 	return userID, ok
 }
+
+// RequestMetadataMiddleware records who sent a request so that audit entries
+// written while serving it can include the request ID, client IP and user agent.
+// X-Forwarded-For is only consulted when the request comes from one of
+// trustedProxies.
+func RequestMetadataMiddleware(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			meta := services.RequestMetadata{
+				RequestID: r.Header.Get("X-Request-ID"),
+				IPAddress: clientIP(r, trustedProxies),
+				UserAgent: r.UserAgent(),
+			}
+			next.ServeHTTP(w, r.WithContext(services.WithRequestMetadata(r.Context(), meta)))
+		})
+	}
+}
+
+// clientIP returns the socket address unless it belongs to a trusted proxy.
+// In that case it walks X-Forwarded-For from the right, skipping the trusted
+// proxies, and returns the first other hop: every hop to the left of it was
+// written by the client and cannot be relied on.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host, trustedProxies) {
+		return host
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop != "" && !isTrustedProxy(hop, trustedProxies) {
+			return hop
+		}
+	}
+	return host
+}
+
+func isTrustedProxy(ip string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.0.2.1/32")}
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{"no proxy", "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"forwarded header from an untrusted peer", "203.0.113.7:5000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"trusted proxy", "10.0.0.2:5000", []string{"203.0.113.7"}, "203.0.113.7"},
+		{"spoofed left-most hop", "10.0.0.2:5000", []string{"198.51.100.1, 203.0.113.7"}, "203.0.113.7"},
+		{"chain of trusted proxies", "10.0.0.2:5000", []string{"198.51.100.1, 203.0.113.7, 192.0.2.1"}, "203.0.113.7"},
+		{"repeated headers", "10.0.0.2:5000", []string{"198.51.100.1", "203.0.113.7"}, "203.0.113.7"},
+		{"trusted proxy without header", "10.0.0.2:5000", nil, "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remoteAddr
+		for _, value := range tt.forwarded {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		if got := clientIP(r, trusted); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
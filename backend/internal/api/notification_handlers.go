@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"slotswapper/internal/services"
+)
+
+func (s *Server) handleListNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+	var filter services.NotificationListFilter
+	var err error
+	if filter.PageRequest, err = parsePageRequest(query); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if v := query.Get("unread"); v != "" {
+		if filter.UnreadOnly, err = strconv.ParseBool(v); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, "Invalid unread parameter")
+			return
+		}
+	}
+
+	page, err := s.notificationService.ListNotifications(r.Context(), userID, filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, page)
+}
+
+func (s *Server) handleMarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	notificationID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid Notification ID")
+		return
+	}
+
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	notification, err := s.notificationService.MarkRead(r.Context(), userID, notificationID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, notification)
+}
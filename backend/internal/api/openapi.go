@@ -0,0 +1,399 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"slotswapper/internal/crypto"
+	"slotswapper/internal/db"
+	"slotswapper/internal/services"
+)
+
+// apiRoute describes one endpoint for the OpenAPI document. Body and Response
+// hold a value of the request and response body types; schemas are derived
+// from them, so the document follows the Go types as they change. Scope is the
+// scope a personal access token needs; routes without one need a session.
+type apiRoute struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tag      string
+	Public   bool
+	Scope    string
+	Query    []param
+	Headers  []param
+	Body     any
+	Status   int
+	Response any
+}
+
+// param is a query or header parameter.
+type param struct {
+	Name        string
+	Schema      map[string]any
+	Description string
+}
+
+var (
+	stringParam   = map[string]any{"type": "string"}
+	integerParam  = map[string]any{"type": "integer", "format": "int64"}
+	dateTimeParam = map[string]any{"type": "string", "format": "date-time"}
+)
+
+func enumParam(values ...string) map[string]any {
+	return map[string]any{"type": "string", "enum": values}
+}
+
+var pageParams = []param{
+	{"limit", map[string]any{"type": "integer", "minimum": 1, "maximum": 100}, "Page size, 50 by default."},
+	{"cursor", stringParam, "next_cursor from the previous page."},
+}
+
+var eventListParams = append([]param{
+	{"sort", enumParam("start_time", "-start_time"), "Sort order."},
+	{"start_from", dateTimeParam, "Only events starting at or after this time."},
+	{"start_to", dateTimeParam, "Only events starting at or before this time."},
+	{"end_from", dateTimeParam, "Only events ending at or after this time."},
+	{"end_to", dateTimeParam, "Only events ending at or before this time."},
+}, pageParams...)
+
+var swapListParams = append([]param{
+	{"sort", enumParam("created_at", "-created_at"), "Sort order."},
+}, pageParams...)
+
+var swapHistoryParams = append([]param{
+	{"status", enumParam(services.SwapStateMachine.Graph().States...), "Only requests with this status."},
+	{"counterparty_id", integerParam, "Only requests with this user on the other side."},
+	{"from", dateTimeParam, "Only requests created at or after this time."},
+	{"to", dateTimeParam, "Only requests created at or before this time."},
+	{"sort", enumParam("created_at", "-created_at", "resolved_at", "-resolved_at"), "Sort order."},
+}, pageParams...)
+
+var (
+	idempotencyKeyHeader = param{"Idempotency-Key", map[string]any{"type": "string", "maxLength": maxIdempotencyKeyLength}, "Makes retries safe: a retry with the same key and body replays the first response with an Idempotent-Replayed header."}
+	ifMatchHeader        = param{"If-Match", stringParam, "The event's ETag as last read; the request fails with 412 if the event has changed since."}
+	ifNoneMatchHeader    = param{"If-None-Match", stringParam, "An ETag from an earlier response; 304 Not Modified is returned while it is current."}
+)
+
+var auditPageParams = []param{
+	{"before", integerParam, "Only entries with a smaller id."},
+	{"limit", map[string]any{"type": "integer", "minimum": 1, "maximum": 200}, "Page size, 50 by default."},
+}
+
+// openAPIRoutes lists every API route registered by RegisterRoutes.
+var openAPIRoutes = []apiRoute{
+	{Method: "GET", Path: "/health", Summary: "Report that the server is up.", Tag: "meta", Public: true, Status: http.StatusOK, Response: ""},
+	{Method: "GET", Path: "/health/ready", Summary: "Report whether the server takes new requests; fails with 503 once shutdown begins.", Tag: "meta", Public: true, Status: http.StatusOK, Response: ""},
+	{Method: "GET", Path: "/api/openapi.json", Summary: "Get this OpenAPI document.", Tag: "meta", Public: true, Status: http.StatusOK, Response: map[string]any{}},
+	{Method: "GET", Path: "/.well-known/jwks.json", Summary: "Get the public keys that verify access tokens.", Tag: "auth", Public: true, Status: http.StatusOK, Response: crypto.JWKSet{}},
+
+	{Method: "POST", Path: "/api/signup", Summary: "Register a new user.", Tag: "auth", Public: true, Body: services.RegisterUserInput{}, Status: http.StatusOK, Response: authResponse{}},
+	{Method: "POST", Path: "/api/login", Summary: "Log in a user.", Tag: "auth", Public: true, Body: services.LoginInput{}, Status: http.StatusOK, Response: authResponse{}},
+	{Method: "POST", Path: "/api/logout", Summary: "Log out a user.", Tag: "auth", Public: true, Status: http.StatusOK},
+
+	{Method: "GET", Path: "/api/me", Summary: "Get the current user's profile.", Tag: "users", Scope: services.ScopeProfileRead, Status: http.StatusOK, Response: db.GetUserByIDRow{}},
+	{Method: "PUT", Path: "/api/me/time-zone", Summary: "Set the current user's preferred time zone.", Tag: "users", Scope: services.ScopeProfileWrite, Body: services.UpdateTimeZoneInput{}, Status: http.StatusOK, Response: db.GetUserByIDRow{}},
+	{Method: "DELETE", Path: "/api/me", Summary: "Close the current user's account: cancels their pending swaps, takes their slots off the marketplace and ends the session.", Tag: "users", Status: http.StatusNoContent},
+	{Method: "GET", Path: "/api/users/{id}", Summary: "Get a user's public profile.", Tag: "users", Scope: services.ScopeProfileRead, Status: http.StatusOK, Response: db.GetPublicUserByIDRow{}},
+
+	{Method: "POST", Path: "/api/events", Summary: "Create an event.", Tag: "events", Scope: services.ScopeEventsWrite, Headers: []param{idempotencyKeyHeader}, Body: services.CreateEventInput{}, Status: http.StatusOK, Response: db.Event{}},
+	{Method: "POST", Path: "/api/events/recurring", Summary: "Create a daily or weekly series of events.", Tag: "events", Scope: services.ScopeEventsWrite, Headers: []param{idempotencyKeyHeader}, Body: recurringEventRequest{}, Status: http.StatusCreated, Response: []db.Event{}},
+	{Method: "GET", Path: "/api/events/user", Summary: "List the current user's events.", Tag: "events", Scope: services.ScopeEventsRead, Query: append([]param{{"status", enumParam(services.EventStateMachine.Graph().States...), "Only events with this status."}}, eventListParams...), Headers: []param{ifNoneMatchHeader}, Status: http.StatusOK, Response: services.Page[db.Event]{}},
+	{Method: "GET", Path: "/api/events/{id}", Summary: "Get an event.", Tag: "events", Scope: services.ScopeEventsRead, Headers: []param{ifNoneMatchHeader}, Status: http.StatusOK, Response: db.Event{}},
+	{Method: "PUT", Path: "/api/events/{id}", Summary: "Update an event.", Tag: "events", Scope: services.ScopeEventsWrite, Headers: []param{ifMatchHeader}, Body: services.UpdateEventInput{}, Status: http.StatusOK, Response: db.Event{}},
+	{Method: "PATCH", Path: "/api/events/{id}", Summary: "Change some of an event's fields with a JSON Merge Patch; reports the swaps cancelled by moving it.", Tag: "events", Scope: services.ScopeEventsWrite, Query: []param{{"allow_overlap", map[string]any{"type": "boolean"}, "Skip the check against the user's other events. Unlike the other event writes, it is a query parameter, as the merge patch body only holds event fields."}}, Headers: []param{ifMatchHeader}, Body: services.PatchEventInput{}, Status: http.StatusOK, Response: services.PatchEventResult{}},
+	{Method: "POST", Path: "/api/events/{id}/status", Summary: "Update an event's status.", Tag: "events", Scope: services.ScopeEventsWrite, Headers: []param{ifMatchHeader}, Body: services.UpdateEventStatusInput{}, Status: http.StatusOK, Response: db.Event{}},
+	{Method: "DELETE", Path: "/api/events/{id}", Summary: "Delete an event.", Tag: "events", Scope: services.ScopeEventsWrite, Headers: []param{ifMatchHeader}, Status: http.StatusNoContent},
+
+	{Method: "GET", Path: "/api/swappable-slots", Summary: "List swappable slots owned by other users.", Tag: "swaps", Scope: services.ScopeMarketplaceRead, Query: eventListParams, Headers: []param{ifNoneMatchHeader}, Status: http.StatusOK, Response: services.Page[db.ListSwappableEventsRow]{}},
+	{Method: "POST", Path: "/api/swap-request", Summary: "Offer one of your slots for someone else's.", Tag: "swaps", Scope: services.ScopeSwapsWrite, Headers: []param{idempotencyKeyHeader}, Body: services.CreateSwapRequestInput{}, Status: http.StatusOK, Response: db.SwapRequest{}},
+	{Method: "GET", Path: "/api/swap-requests/incoming", Summary: "List pending swap requests sent to the current user.", Tag: "swaps", Scope: services.ScopeSwapsRead, Query: swapListParams, Status: http.StatusOK, Response: services.Page[db.ListIncomingSwapRequestsRow]{}},
+	{Method: "GET", Path: "/api/swap-requests/outgoing", Summary: "List pending swap requests sent by the current user.", Tag: "swaps", Scope: services.ScopeSwapsRead, Query: swapListParams, Status: http.StatusOK, Response: services.Page[db.ListOutgoingSwapRequestsRow]{}},
+	{Method: "GET", Path: "/api/swap-requests/incoming/history", Summary: "List every swap request sent to the current user.", Tag: "swaps", Scope: services.ScopeSwapsRead, Query: swapHistoryParams, Status: http.StatusOK, Response: services.Page[db.GetIncomingSwapRequestHistoryRow]{}},
+	{Method: "GET", Path: "/api/swap-requests/outgoing/history", Summary: "List every swap request sent by the current user.", Tag: "swaps", Scope: services.ScopeSwapsRead, Query: swapHistoryParams, Status: http.StatusOK, Response: services.Page[db.GetOutgoingSwapRequestHistoryRow]{}},
+	{Method: "POST", Path: "/api/swap-response/{id}", Summary: "Accept or reject a swap request.", Tag: "swaps", Scope: services.ScopeSwapsWrite, Headers: []param{idempotencyKeyHeader}, Body: services.UpdateSwapRequestStatusInput{}, Status: http.StatusOK, Response: db.SwapRequest{}},
+	{Method: "DELETE", Path: "/api/swap-requests/{id}", Summary: "Withdraw a pending swap request sent by the current user.", Tag: "swaps", Scope: services.ScopeSwapsWrite, Status: http.StatusOK, Response: db.SwapRequest{}},
+	{Method: "POST", Path: "/api/events/{id}/claim", Summary: "Claim a slot that is being given away; a first-come slot changes hands at once.", Tag: "swaps", Scope: services.ScopeSwapsWrite, Query: []param{{"allow_overlap", map[string]any{"type": "boolean"}, "Skip the check against the user's other events. A query parameter, as a claim has no body."}}, Headers: []param{idempotencyKeyHeader}, Status: http.StatusOK, Response: db.SwapRequest{}},
+
+	{Method: "GET", Path: "/api/notifications", Summary: "List the current user's notifications, newest first.", Tag: "notifications", Scope: services.ScopeNotificationsRead, Query: append([]param{{"unread", map[string]any{"type": "boolean"}, "Only notifications that have not been read."}}, pageParams...), Status: http.StatusOK, Response: services.Page[db.Notification]{}},
+	{Method: "POST", Path: "/api/notifications/{id}/read", Summary: "Mark a notification as read.", Tag: "notifications", Scope: services.ScopeNotificationsWrite, Status: http.StatusOK, Response: db.Notification{}},
+
+	{Method: "POST", Path: "/api/access-tokens", Summary: "Create a personal access token; the token is only returned here.", Tag: "auth", Body: services.CreateAccessTokenInput{}, Status: http.StatusCreated, Response: services.CreatedAccessToken{}},
+	{Method: "GET", Path: "/api/access-tokens", Summary: "List the current user's personal access tokens.", Tag: "auth", Status: http.StatusOK, Response: []services.AccessToken{}},
+	{Method: "DELETE", Path: "/api/access-tokens/{id}", Summary: "Revoke a personal access token.", Tag: "auth", Status: http.StatusNoContent},
+
+	{Method: "GET", Path: "/api/audit-logs", Summary: "List changes that affected the current user.", Tag: "audit", Scope: services.ScopeAuditRead, Query: auditPageParams, Status: http.StatusOK, Response: []services.AuditLogEntry{}},
+	{Method: "GET", Path: "/api/events/{id}/audit-logs", Summary: "List the changes made to an event.", Tag: "audit", Scope: services.ScopeAuditRead, Status: http.StatusOK, Response: []services.AuditLogEntry{}},
+	{Method: "GET", Path: "/api/admin/audit-logs", Summary: "List all audit log entries (admins only).", Tag: "audit", Scope: services.ScopeAuditRead, Query: append([]param{
+		{"entity_type", enumParam(services.AuditEntityUser, services.AuditEntityEvent, services.AuditEntitySwapRequest), "Only entries about this kind of entity."},
+		{"actor_user_id", integerParam, "Only entries made by this user."},
+	}, auditPageParams...), Status: http.StatusOK, Response: []services.AuditLogEntry{}},
+}
+
+var openAPIDocument = sync.OnceValue(func() []byte {
+	data, err := json.MarshalIndent(buildOpenAPI(openAPIRoutes), "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	return data
+})
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIDocument())
+}
+
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+func buildOpenAPI(routes []apiRoute) map[string]any {
+	schemas := schemaRegistry{}
+	schemas.add(problem{}, false)
+
+	paths := map[string]map[string]any{}
+	for _, route := range routes {
+		op := map[string]any{
+			"operationId": operationID(route),
+			"summary":     route.Summary,
+			"tags":        []string{route.Tag},
+		}
+		if !route.Public {
+			op["security"] = []map[string][]string{{"cookieAuth": {}}, {"bearerAuth": {}}}
+			if route.Scope != "" {
+				op["security"] = append(op["security"].([]map[string][]string), map[string][]string{"accessTokenAuth": {route.Scope}})
+			}
+		}
+
+		var params []map[string]any
+		for _, match := range pathParamPattern.FindAllStringSubmatch(route.Path, -1) {
+			params = append(params, map[string]any{"name": match[1], "in": "path", "required": true, "schema": integerParam})
+		}
+		for _, q := range route.Query {
+			params = append(params, map[string]any{"name": q.Name, "in": "query", "schema": q.Schema, "description": q.Description})
+		}
+		for _, h := range route.Headers {
+			params = append(params, map[string]any{"name": h.Name, "in": "header", "schema": h.Schema, "description": h.Description})
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+
+		if route.Body != nil {
+			mediaType := "application/json"
+			if route.Method == http.MethodPatch {
+				mediaType = "application/merge-patch+json"
+			}
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{mediaType: map[string]any{"schema": schemas.add(route.Body, true)}},
+			}
+		}
+
+		success := map[string]any{"description": http.StatusText(route.Status)}
+		switch route.Response.(type) {
+		case nil:
+		case string:
+			success["content"] = map[string]any{"text/plain": map[string]any{"schema": stringParam}}
+		default:
+			success["content"] = map[string]any{"application/json": map[string]any{"schema": schemas.add(route.Response, false)}}
+		}
+		op["responses"] = map[string]any{
+			strconv.Itoa(route.Status): success,
+			"default":                  map[string]any{"$ref": "#/components/responses/Problem"},
+		}
+
+		if paths[route.Path] == nil {
+			paths[route.Path] = map[string]any{}
+		}
+		paths[route.Path][strings.ToLower(route.Method)] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "SlotSwapper API",
+			"version":     "1.0.0",
+			"description": "Trade calendar slots with other users. Times are RFC 3339 and returned in UTC unless the tz query parameter names another IANA zone.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"responses": map[string]any{
+				"Problem": map[string]any{
+					"description": "An RFC 9457 problem document.",
+					"content":     map[string]any{"application/problem+json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Problem"}}},
+				},
+			},
+			"securitySchemes": map[string]any{
+				"cookieAuth": map[string]any{"type": "apiKey", "in": "cookie", "name": "access_token"},
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"accessTokenAuth": map[string]any{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "A personal access token (ssp_...) from POST /api/access-tokens. Each operation lists the scope the token needs.",
+				},
+			},
+		},
+	}
+}
+
+// operationID derives a stable identifier such as getApiEventsId from the
+// method and path.
+func operationID(route apiRoute) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(route.Method))
+	for _, part := range strings.FieldsFunc(route.Path, func(r rune) bool { return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9') }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// schemaRegistry collects the named schemas under components/schemas.
+type schemaRegistry map[string]any
+
+var rawMessageType = reflect.TypeFor[json.RawMessage]()
+
+// add returns the schema for v's type, registering named struct types as
+// components. In request schemas a property is required when its validate
+// tag says so; in responses when it is always present in the JSON.
+func (reg schemaRegistry) add(v any, request bool) map[string]any {
+	return reg.schemaFor(reflect.TypeOf(v), request)
+}
+
+func (reg schemaRegistry) schemaFor(t reflect.Type, request bool) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(reg.schemaFor(t.Elem(), request))
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		// A nil slice encodes as null.
+		return map[string]any{"type": []string{"array", "null"}, "items": reg.schemaFor(t.Elem(), request)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": reg.schemaFor(t.Elem(), request)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return reg.objectSchema(t, request)
+		}
+		name := schemaName(t)
+		if _, ok := reg[name]; !ok {
+			reg[name] = nil // Reserve the name so recursive types terminate.
+			reg[name] = reg.objectSchema(t, request)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]any{}
+}
+
+func (reg schemaRegistry) objectSchema(t reflect.Type, request bool) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	reg.collectFields(t, request, properties, &required)
+	slices.Sort(required)
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// collectFields adds the JSON properties of t, including those promoted from
+// embedded structs, the way encoding/json lays them out.
+func (reg schemaRegistry) collectFields(t reflect.Type, request bool, properties map[string]any, required *[]string) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			reg.collectFields(field.Type, request, properties, required)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		omitted := strings.Contains(opts, "omitempty") || strings.Contains(opts, "omitzero")
+		schema := reg.schemaFor(field.Type, request)
+		if request && omitted && field.Type.Kind() == reflect.Pointer {
+			// An optional field of a patch: leave it out rather than send null.
+			schema = reg.schemaFor(field.Type.Elem(), request)
+		}
+		rules := strings.Split(field.Tag.Get("validate"), ",")
+		// Rules after dive apply to the elements of a slice.
+		var elemRules []string
+		if i := slices.Index(rules, "dive"); i >= 0 {
+			rules, elemRules = rules[:i], rules[i+1:]
+		}
+		for _, rule := range rules {
+			if values, ok := strings.CutPrefix(rule, "oneof="); ok {
+				schema = map[string]any{"type": "string", "enum": strings.Fields(values)}
+			}
+		}
+		for _, rule := range elemRules {
+			if values, ok := strings.CutPrefix(rule, "oneof="); ok {
+				schema["items"] = map[string]any{"type": "string", "enum": strings.Fields(values)}
+			}
+		}
+		properties[name] = schema
+
+		if request && slices.Contains(rules, "required") || !request && !omitted {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// schemaName turns a Go type name into a component name. Unexported names
+// are capitalized, and generic instantiations such as
+// Page[slotswapper/internal/db.Event] become EventPage.
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	name = strings.ToUpper(name[:1]) + name[1:]
+	base, args, ok := strings.Cut(name, "[")
+	if !ok {
+		return name
+	}
+	var prefix string
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		prefix += arg[strings.LastIndex(arg, ".")+1:]
+	}
+	return prefix + base
+}
+
+// nullable allows null in addition to the values schema accepts.
+func nullable(schema map[string]any) map[string]any {
+	switch typ := schema["type"].(type) {
+	case string:
+		out := map[string]any{}
+		for k, v := range schema {
+			out[k] = v
+		}
+		out["type"] = []string{typ, "null"}
+		return out
+	case []string:
+		return schema
+	}
+	return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+}
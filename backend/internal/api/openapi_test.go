@@ -0,0 +1,352 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/services"
+)
+
+// recordingRouter collects the patterns passed to RegisterRoutes.
+type recordingRouter struct {
+	patterns []string
+}
+
+func (r *recordingRouter) Handle(pattern string, _ http.Handler) {
+	r.patterns = append(r.patterns, pattern)
+}
+
+func (r *recordingRouter) HandleFunc(pattern string, _ func(http.ResponseWriter, *http.Request)) {
+	r.patterns = append(r.patterns, pattern)
+}
+
+func TestOpenAPI_CoversRegisteredRoutes(t *testing.T) {
+	router := &recordingRouter{}
+	(&Server{}).RegisterRoutes(router)
+
+	var documented []string
+	for _, route := range openAPIRoutes {
+		documented = append(documented, route.Method+" "+route.Path)
+	}
+
+	for _, pattern := range router.patterns {
+		if !slices.Contains(documented, pattern) {
+			t.Errorf("route %q is registered but missing from the OpenAPI document", pattern)
+		}
+	}
+	for _, pattern := range documented {
+		if !slices.Contains(router.patterns, pattern) {
+			t.Errorf("route %q is documented but not registered", pattern)
+		}
+	}
+}
+
+// specClient calls the test server and checks every response against the
+// OpenAPI document, recording which operations returned their documented
+// success status.
+type specClient struct {
+	t         *testing.T
+	ts        *httptest.Server
+	spec      map[string]any
+	exercised map[string]bool
+}
+
+func (c *specClient) do(method, path string, body any, cookie *http.Cookie) *httptest.ResponseRecorder {
+	c.t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatalf("failed to marshal body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+
+	mux := c.ts.Config.Handler.(*http.ServeMux)
+	_, pattern := mux.Handler(req)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	patternMethod, patternPath, _ := strings.Cut(pattern, " ")
+	op, ok := lookup(c.spec, "paths", patternPath, strings.ToLower(patternMethod)).(map[string]any)
+	if !ok {
+		c.t.Fatalf("%s %s: no operation for %q in the OpenAPI document", method, path, pattern)
+	}
+
+	response, ok := lookup(op, "responses", fmt.Sprint(rr.Code)).(map[string]any)
+	if ok {
+		c.exercised[pattern] = true
+	} else {
+		response = resolve(c.spec, lookup(op, "responses", "default").(map[string]any))
+	}
+
+	content, _ := response["content"].(map[string]any)
+	if len(content) == 0 {
+		if rr.Body.Len() != 0 {
+			c.t.Errorf("%s %s: expected no body for status %d, got %s", method, path, rr.Code, rr.Body.String())
+		}
+		return rr
+	}
+
+	contentType, _, _ := strings.Cut(rr.Header().Get("Content-Type"), ";")
+	media, ok := content[contentType].(map[string]any)
+	if !ok {
+		c.t.Errorf("%s %s: content type %q not documented for status %d", method, path, contentType, rr.Code)
+		return rr
+	}
+	if contentType == "text/plain" {
+		return rr
+	}
+
+	var value any
+	if err := json.Unmarshal(rr.Body.Bytes(), &value); err != nil {
+		c.t.Fatalf("%s %s: invalid JSON response: %v", method, path, err)
+	}
+	for _, problem := range validateSchema(c.spec, media["schema"].(map[string]any), value, "$") {
+		c.t.Errorf("%s %s (%d): %s", method, path, rr.Code, problem)
+	}
+	return rr
+}
+
+func (c *specClient) decode(rr *httptest.ResponseRecorder, v any) {
+	c.t.Helper()
+	if err := json.Unmarshal(rr.Body.Bytes(), v); err != nil {
+		c.t.Fatalf("failed to decode response: %v", err)
+	}
+}
+
+func TestOpenAPI_ResponsesMatchSpec(t *testing.T) {
+	ts, queries, _ := setupTestServer(t)
+	defer ts.Close()
+
+	rr := httptest.NewRecorder()
+	ts.Config.Handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/openapi.json", nil))
+	var spec map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("failed to decode OpenAPI document: %v", err)
+	}
+	if spec["openapi"] != "3.1.0" {
+		t.Fatalf("expected an OpenAPI 3.1 document, got %v", spec["openapi"])
+	}
+
+	c := &specClient{t: t, ts: ts, spec: spec, exercised: map[string]bool{}}
+	c.do("GET", "/health", nil, nil)
+	c.do("GET", "/health/ready", nil, nil)
+	c.do("GET", "/api/openapi.json", nil, nil)
+	c.do("GET", "/.well-known/jwks.json", nil, nil)
+
+	_, alice, aliceCookie := signUpAndLogin(t, ts, "Alice", "alice@example.com", "password123")
+	_, bob, bobCookie := signUpAndLogin(t, ts, "Bob", "bob@example.com", "password123")
+	c.do("POST", "/api/signup", map[string]any{"name": "Carol", "email": "carol@example.com", "password": "password123", "time_zone": "Europe/Berlin"}, nil)
+	c.do("POST", "/api/login", map[string]any{"email": "alice@example.com", "password": "password123"}, nil)
+	c.do("POST", "/api/login", map[string]any{"email": "alice@example.com", "password": "wrong"}, nil)
+
+	c.do("GET", "/api/me", nil, aliceCookie)
+	c.do("GET", "/api/me", nil, nil)
+	c.do("PUT", "/api/me/time-zone", map[string]any{"time_zone": "America/New_York"}, aliceCookie)
+	c.do("GET", fmt.Sprintf("/api/users/%d", bob.ID), nil, aliceCookie)
+
+	start := time.Date(2030, time.March, 1, 9, 0, 0, 0, time.UTC)
+	var aliceEvent, bobEvent db.Event
+	c.decode(c.do("POST", "/api/events", map[string]any{"title": "Alice", "start_time": start, "end_time": start.Add(time.Hour), "status": "SWAPPABLE"}, aliceCookie), &aliceEvent)
+	c.decode(c.do("POST", "/api/events", map[string]any{"title": "Bob", "start_time": start.Add(2 * time.Hour), "end_time": start.Add(3 * time.Hour), "status": "SWAPPABLE"}, bobCookie), &bobEvent)
+	c.do("POST", "/api/events", map[string]any{"title": "Overlap", "start_time": start, "end_time": start.Add(time.Hour), "status": "BUSY"}, aliceCookie)
+	c.do("POST", "/api/events", map[string]any{"title": "", "start_time": start, "end_time": start, "status": "BUSY"}, aliceCookie)
+
+	var series []db.Event
+	c.decode(c.do("POST", "/api/events/recurring", map[string]any{"title": "Standup", "start_time": start.Add(24 * time.Hour), "end_time": start.Add(25 * time.Hour), "status": "BUSY", "recurrence": map[string]any{"frequency": "DAILY", "count": 2}}, aliceCookie), &series)
+	c.do("GET", "/api/events/user?limit=1", nil, aliceCookie)
+	c.do("GET", fmt.Sprintf("/api/events/%d?tz=Europe/Berlin", aliceEvent.ID), nil, aliceCookie)
+	c.do("GET", "/api/events/999999", nil, aliceCookie)
+	c.do("PUT", fmt.Sprintf("/api/events/%d", series[0].ID), map[string]any{"title": "Moved", "start_time": start.Add(26 * time.Hour), "end_time": start.Add(27 * time.Hour)}, aliceCookie)
+	c.do("PATCH", fmt.Sprintf("/api/events/%d", series[1].ID), map[string]any{"title": "Renamed"}, aliceCookie)
+	c.do("PATCH", fmt.Sprintf("/api/events/%d", series[1].ID), map[string]any{"title": nil}, aliceCookie)
+	c.do("POST", fmt.Sprintf("/api/events/%d/status", series[1].ID), map[string]any{"status": "SWAPPABLE"}, aliceCookie)
+	c.do("POST", fmt.Sprintf("/api/events/%d/status", bobEvent.ID), map[string]any{"status": "BUSY"}, aliceCookie)
+
+	c.do("GET", "/api/swappable-slots", nil, aliceCookie)
+	var swap db.SwapRequest
+	c.decode(c.do("POST", "/api/swap-request", map[string]any{"responder_user_id": bob.ID, "requester_slot_id": aliceEvent.ID, "responder_slot_id": bobEvent.ID}, aliceCookie), &swap)
+	c.do("GET", "/api/swap-requests/incoming", nil, bobCookie)
+	c.do("GET", "/api/swap-requests/outgoing", nil, aliceCookie)
+	c.do("GET", "/api/swap-requests/outgoing?cursor=bogus", nil, aliceCookie)
+	c.do("POST", fmt.Sprintf("/api/swap-response/%d", swap.ID), map[string]any{"status": "ACCEPTED"}, bobCookie)
+	c.do("POST", fmt.Sprintf("/api/swap-response/%d", swap.ID), map[string]any{"status": "ACCEPTED"}, bobCookie)
+	c.do("GET", "/api/swap-requests/incoming/history", nil, bobCookie)
+	c.do("GET", "/api/swap-requests/outgoing/history?status=ACCEPTED", nil, aliceCookie)
+
+	// Bob gives a shift away to whoever claims it first.
+	var giveaway db.Event
+	c.decode(c.do("POST", "/api/events", map[string]any{"title": "Spare", "start_time": start.Add(6 * time.Hour), "end_time": start.Add(7 * time.Hour), "status": "BUSY"}, bobCookie), &giveaway)
+	c.do("POST", fmt.Sprintf("/api/events/%d/status", giveaway.ID), map[string]any{"status": "BUSY", "giveaway": "FIRST_COME"}, bobCookie)
+	c.do("POST", fmt.Sprintf("/api/events/%d/status", giveaway.ID), map[string]any{"status": "SWAPPABLE", "giveaway": "FIRST_COME"}, bobCookie)
+	c.do("POST", fmt.Sprintf("/api/events/%d/claim", giveaway.ID), nil, aliceCookie)
+	c.do("POST", fmt.Sprintf("/api/events/%d/claim", giveaway.ID), nil, aliceCookie)
+
+	// Bob leaves with an offer from Alice pending, which tells Alice.
+	var bobSlot db.Event
+	c.decode(c.do("POST", "/api/events", map[string]any{"title": "Bob", "start_time": start.Add(4 * time.Hour), "end_time": start.Add(5 * time.Hour), "status": "SWAPPABLE"}, bobCookie), &bobSlot)
+	var withdrawn db.SwapRequest
+	c.decode(c.do("POST", "/api/swap-request", map[string]any{"responder_user_id": bob.ID, "requester_slot_id": series[1].ID, "responder_slot_id": bobSlot.ID}, aliceCookie), &withdrawn)
+	c.do("DELETE", fmt.Sprintf("/api/swap-requests/%d", withdrawn.ID), nil, bobCookie)
+	c.do("DELETE", fmt.Sprintf("/api/swap-requests/%d", withdrawn.ID), nil, aliceCookie)
+	c.do("POST", "/api/swap-request", map[string]any{"responder_user_id": bob.ID, "requester_slot_id": series[1].ID, "responder_slot_id": bobSlot.ID}, aliceCookie)
+	c.do("DELETE", "/api/me", nil, bobCookie)
+	c.do("GET", "/api/me", nil, bobCookie)
+	var notifications services.Page[db.Notification]
+	c.decode(c.do("GET", "/api/notifications?unread=true", nil, aliceCookie), &notifications)
+	if len(notifications.Items) != 1 {
+		t.Fatalf("expected Alice to be notified once, got %+v", notifications.Items)
+	}
+	c.do("POST", fmt.Sprintf("/api/notifications/%d/read", notifications.Items[0].ID), nil, aliceCookie)
+	c.do("POST", "/api/notifications/999999/read", nil, aliceCookie)
+
+	var accessToken services.CreatedAccessToken
+	c.decode(c.do("POST", "/api/access-tokens", map[string]any{"name": "script", "scopes": []string{"events:read"}}, aliceCookie), &accessToken)
+	c.do("POST", "/api/access-tokens", map[string]any{"name": "script", "scopes": []string{"everything"}}, aliceCookie)
+	c.do("GET", "/api/access-tokens", nil, aliceCookie)
+	c.do("DELETE", fmt.Sprintf("/api/access-tokens/%d", accessToken.ID), nil, aliceCookie)
+	c.do("DELETE", fmt.Sprintf("/api/access-tokens/%d", accessToken.ID), nil, aliceCookie)
+
+	c.do("GET", "/api/audit-logs", nil, aliceCookie)
+	c.do("GET", fmt.Sprintf("/api/events/%d/audit-logs", aliceEvent.ID), nil, aliceCookie)
+	c.do("GET", "/api/admin/audit-logs", nil, aliceCookie)
+	if err := queries.UpdateUserIsAdmin(context.Background(), db.UpdateUserIsAdminParams{IsAdmin: true, ID: alice.ID}); err != nil {
+		t.Fatalf("failed to promote alice: %v", err)
+	}
+	c.do("GET", "/api/admin/audit-logs?entity_type=event", nil, aliceCookie)
+
+	c.do("DELETE", fmt.Sprintf("/api/events/%d", series[0].ID), nil, aliceCookie)
+	c.do("POST", "/api/logout", nil, aliceCookie)
+
+	for _, route := range openAPIRoutes {
+		if pattern := route.Method + " " + route.Path; !c.exercised[pattern] {
+			t.Errorf("%s was never called with its documented %d response; extend this test", pattern, route.Status)
+		}
+	}
+}
+
+func lookup(v any, keys ...string) any {
+	for _, key := range keys {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// resolve follows a local $ref such as #/components/schemas/Event.
+func resolve(spec, schema map[string]any) map[string]any {
+	for {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema
+		}
+		schema = lookup(spec, strings.Split(strings.TrimPrefix(ref, "#/"), "/")...).(map[string]any)
+	}
+}
+
+// validateSchema checks value against the subset of JSON Schema the document
+// generator emits and returns a description of every mismatch.
+func validateSchema(spec, schema map[string]any, value any, at string) []string {
+	schema = resolve(spec, schema)
+
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		for _, branch := range anyOf {
+			if len(validateSchema(spec, branch.(map[string]any), value, at)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: %v matches no anyOf branch", at, value)}
+	}
+
+	if typ, ok := schema["type"]; ok {
+		types := []any{typ}
+		if list, ok := typ.([]any); ok {
+			types = list
+		}
+		if !slices.ContainsFunc(types, func(t any) bool { return jsonTypeMatches(t.(string), value) }) {
+			return []string{fmt.Sprintf("%s: expected type %v, got %T", at, typ, value)}
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		return []string{fmt.Sprintf("%s: %v is not one of %v", at, value, enum)}
+	}
+	if schema["format"] == "date-time" {
+		if s, ok := value.(string); ok {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return []string{fmt.Sprintf("%s: %q is not a date-time", at, s)}
+			}
+		}
+	}
+
+	var problems []string
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := v[name.(string)]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing required property %q", at, name))
+			}
+		}
+		for name, item := range v {
+			if propSchema, ok := properties[name].(map[string]any); ok {
+				problems = append(problems, validateSchema(spec, propSchema, item, at+"."+name)...)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					problems = append(problems, fmt.Sprintf("%s: undocumented property %q", at, name))
+				}
+			case map[string]any:
+				problems = append(problems, validateSchema(spec, extra, item, at+"."+name)...)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				problems = append(problems, validateSchema(spec, items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	}
+	return problems
+}
+
+func jsonTypeMatches(typ string, value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return typ == "null"
+	case bool:
+		return typ == "boolean"
+	case string:
+		return typ == "string"
+	case float64:
+		return typ == "number" || typ == "integer" && v == math.Trunc(v)
+	case []any:
+		return typ == "array"
+	case map[string]any:
+		return typ == "object"
+	}
+	return false
+}
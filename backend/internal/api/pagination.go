@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"slotswapper/internal/services"
+)
+
+// parsePageRequest reads the limit and cursor query parameters shared by the
+// list endpoints.
+func parsePageRequest(query url.Values) (services.PageRequest, error) {
+	page := services.PageRequest{Cursor: query.Get("cursor")}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return page, errors.New("Invalid limit parameter")
+		}
+		page.Limit = limit
+	}
+	return page, nil
+}
+
+// parseEventListFilter reads the sort, paging and start_from, start_to,
+// end_from and end_to query parameters. Times use RFC 3339.
+func parseEventListFilter(r *http.Request) (services.EventListFilter, error) {
+	query := r.URL.Query()
+	filter := services.EventListFilter{Sort: query.Get("sort")}
+
+	var err error
+	if filter.PageRequest, err = parsePageRequest(query); err != nil {
+		return filter, err
+	}
+	bounds := []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"start_from", &filter.StartFrom},
+		{"start_to", &filter.StartTo},
+		{"end_from", &filter.EndFrom},
+		{"end_to", &filter.EndTo},
+	}
+	for _, bound := range bounds {
+		if v := query.Get(bound.name); v != "" {
+			if *bound.dst, err = time.Parse(time.RFC3339, v); err != nil {
+				return filter, errors.New("Invalid " + bound.name + " parameter")
+			}
+		}
+	}
+	return filter, nil
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"sync/atomic"
 
 	"slotswapper/internal/crypto"
 	"slotswapper/internal/services"
@@ -11,29 +12,50 @@ import (
 )
 
 type Server struct {
-	config             *Config
-	authService        services.AuthService
-	userService        services.UserService
-	eventService       services.EventService
-	swapRequestService services.SwapRequestService
-	validator          *validator.Validate
-	jwtManager         crypto.JWT
+	config              *Config
+	authService         services.AuthService
+	userService         services.UserService
+	eventService        services.EventService
+	swapRequestService  services.SwapRequestService
+	auditService        services.AuditService
+	accessTokenService  services.AccessTokenService
+	idempotencyService  services.IdempotencyService
+	notificationService services.NotificationService
+	validator           *validator.Validate
+	jwtManager          crypto.JWT
+	// draining is set once shutdown begins; see Serve.
+	draining atomic.Bool
 }
 
-func NewServer(config *Config, authService services.AuthService, userService services.UserService, eventService services.EventService, swapRequestService services.SwapRequestService, jwtManager crypto.JWT) *Server {
+func NewServer(config *Config, authService services.AuthService, userService services.UserService, eventService services.EventService, swapRequestService services.SwapRequestService, auditService services.AuditService, accessTokenService services.AccessTokenService, idempotencyService services.IdempotencyService, notificationService services.NotificationService, jwtManager crypto.JWT) *Server {
 	return &Server{
-		config:             config,
-		authService:        authService,
-		userService:        userService,
-		eventService:       eventService,
-		swapRequestService: swapRequestService,
-		validator:          validator.New(),
-		jwtManager:         jwtManager,
+		config:              config,
+		authService:         authService,
+		userService:         userService,
+		eventService:        eventService,
+		swapRequestService:  swapRequestService,
+		auditService:        auditService,
+		accessTokenService:  accessTokenService,
+		idempotencyService:  idempotencyService,
+		notificationService: notificationService,
+		validator:           validator.New(),
+		jwtManager:          jwtManager,
 	}
 }
 
-func (s *Server) RegisterRoutes(router *http.ServeMux) {
+// Router is the part of *http.ServeMux that RegisterRoutes needs.
+type Router interface {
+	Handle(pattern string, handler http.Handler)
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// RegisterRoutes mounts every endpoint on router. Each API route must also be
+// described in openAPIRoutes.
+func (s *Server) RegisterRoutes(router Router) {
 	router.HandleFunc("GET /health", s.healthCheck)
+	router.HandleFunc("GET /health/ready", s.readinessCheck)
+	router.HandleFunc("GET /api/openapi.json", s.handleOpenAPI)
+	router.HandleFunc("GET /.well-known/jwks.json", s.handleJWKS)
 
 	// Auth routes
 	router.HandleFunc("POST /api/signup", s.handleSignUp)
@@ -42,23 +64,45 @@ func (s *Server) RegisterRoutes(router *http.ServeMux) {
 
 	// Protected routes
 	// User routes
-	router.Handle("GET /api/me", AuthMiddleware(s.jwtManager)(http.HandlerFunc(s.handleGetMe)))
-	router.Handle("GET /api/users/{id}", AuthMiddleware(s.jwtManager)(http.HandlerFunc(s.handleGetUserProfile)))
+	router.Handle("GET /api/me", s.authenticated(services.ScopeProfileRead, s.handleGetMe))
+	router.Handle("PUT /api/me/time-zone", s.authenticated(services.ScopeProfileWrite, s.handleUpdateTimeZone))
+	router.Handle("DELETE /api/me", s.authenticated(sessionOnly, s.handleDeactivateAccount))
+	router.Handle("GET /api/users/{id}", s.authenticated(services.ScopeProfileRead, s.handleGetUserProfile))
 
 	// Event routes
-	router.Handle("POST /api/events", AuthMiddleware(s.jwtManager)(http.HandlerFunc(s.handleCreateEvent)))
-	router.Handle("GET /api/events/user", AuthMiddleware(s.jwtManager)(http.HandlerFunc(s.handleGetEventsByUserID)))
-	router.Handle("GET /api/events/{id}", AuthMiddleware(s.jwtManager)(http.HandlerFunc(s.handleGetEventByID)))
-	router.Handle("PUT /api/events/{id}", AuthMiddleware(s.jwtManager)(http.HandlerFunc(s.handleUpdateEvent)))
-	router.Handle("POST /api/events/{id}/status", AuthMiddleware(s.jwtManager)(http.HandlerFunc(s.handleUpdateEventStatus)))
-	router.Handle("DELETE /api/events/{id}", AuthMiddleware(s.jwtManager)(http.HandlerFunc(s.handleDeleteEvent)))
+	router.Handle("POST /api/events", s.authenticated(services.ScopeEventsWrite, s.idempotent(s.handleCreateEvent)))
+	router.Handle("POST /api/events/recurring", s.authenticated(services.ScopeEventsWrite, s.idempotent(s.handleCreateRecurringEvents)))
+	router.Handle("GET /api/events/user", s.authenticated(services.ScopeEventsRead, s.handleGetEventsByUserID))
+	router.Handle("GET /api/events/{id}", s.authenticated(services.ScopeEventsRead, s.handleGetEventByID))
+	router.Handle("PUT /api/events/{id}", s.authenticated(services.ScopeEventsWrite, s.handleUpdateEvent))
+	router.Handle("PATCH /api/events/{id}", s.authenticated(services.ScopeEventsWrite, s.handlePatchEvent))
+	router.Handle("POST /api/events/{id}/status", s.authenticated(services.ScopeEventsWrite, s.handleUpdateEventStatus))
+	router.Handle("DELETE /api/events/{id}", s.authenticated(services.ScopeEventsWrite, s.handleDeleteEvent))
 
 	// Swap routes
-	router.Handle("GET /api/swappable-slots", AuthMiddleware(s.jwtManager)(http.HandlerFunc(s.handleGetSwappableEvents)))
-	router.Handle("POST /api/swap-request", AuthMiddleware(s.jwtManager)(http.HandlerFunc(s.handleCreateSwapRequest)))
-	router.Handle("GET /api/swap-requests/incoming", AuthMiddleware(s.jwtManager)(http.HandlerFunc(s.handleGetIncomingSwapRequests)))
-	router.Handle("GET /api/swap-requests/outgoing", AuthMiddleware(s.jwtManager)(http.HandlerFunc(s.handleGetOutgoingSwapRequests)))
-	router.Handle("POST /api/swap-response/{id}", AuthMiddleware(s.jwtManager)(http.HandlerFunc(s.handleUpdateSwapRequestStatus)))
+	router.Handle("GET /api/swappable-slots", s.authenticated(services.ScopeMarketplaceRead, s.handleGetSwappableEvents))
+	router.Handle("POST /api/swap-request", s.authenticated(services.ScopeSwapsWrite, s.idempotent(s.handleCreateSwapRequest)))
+	router.Handle("GET /api/swap-requests/incoming", s.authenticated(services.ScopeSwapsRead, s.handleGetIncomingSwapRequests))
+	router.Handle("GET /api/swap-requests/outgoing", s.authenticated(services.ScopeSwapsRead, s.handleGetOutgoingSwapRequests))
+	router.Handle("GET /api/swap-requests/incoming/history", s.authenticated(services.ScopeSwapsRead, s.handleGetIncomingSwapRequestHistory))
+	router.Handle("GET /api/swap-requests/outgoing/history", s.authenticated(services.ScopeSwapsRead, s.handleGetOutgoingSwapRequestHistory))
+	router.Handle("POST /api/swap-response/{id}", s.authenticated(services.ScopeSwapsWrite, s.idempotent(s.handleUpdateSwapRequestStatus)))
+	router.Handle("DELETE /api/swap-requests/{id}", s.authenticated(services.ScopeSwapsWrite, s.handleWithdrawSwapRequest))
+	router.Handle("POST /api/events/{id}/claim", s.authenticated(services.ScopeSwapsWrite, s.idempotent(s.handleClaimSlot)))
+
+	// Notification routes
+	router.Handle("GET /api/notifications", s.authenticated(services.ScopeNotificationsRead, s.handleListNotifications))
+	router.Handle("POST /api/notifications/{id}/read", s.authenticated(services.ScopeNotificationsWrite, s.handleMarkNotificationRead))
+
+	// Access token routes. Tokens cannot manage tokens: these need a session.
+	router.Handle("POST /api/access-tokens", s.authenticated(sessionOnly, s.handleCreateAccessToken))
+	router.Handle("GET /api/access-tokens", s.authenticated(sessionOnly, s.handleListAccessTokens))
+	router.Handle("DELETE /api/access-tokens/{id}", s.authenticated(sessionOnly, s.handleRevokeAccessToken))
+
+	// Audit routes
+	router.Handle("GET /api/audit-logs", s.authenticated(services.ScopeAuditRead, s.handleGetMyAuditLogs))
+	router.Handle("GET /api/events/{id}/audit-logs", s.authenticated(services.ScopeAuditRead, s.handleGetEventAuditLogs))
+	router.Handle("GET /api/admin/audit-logs", s.authenticated(services.ScopeAuditRead, s.handleListAuditLogs))
 
 	// React
 	if s.config != nil && s.config.FrontendDir != "" {
@@ -66,6 +110,12 @@ func (s *Server) RegisterRoutes(router *http.ServeMux) {
 	}
 }
 
+// authenticated wraps handler in AuthMiddleware, requiring scope of personal
+// access tokens.
+func (s *Server) authenticated(scope string, handler http.HandlerFunc) http.Handler {
+	return AuthMiddleware(s.jwtManager, s.accessTokenService, s.userService, scope)(handler)
+}
+
 func (s *Server) healthCheck(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "OK")
 }
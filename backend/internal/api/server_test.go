@@ -20,20 +20,25 @@ import (
 func setupTestServer(t *testing.T) (*httptest.Server, *db.Queries, crypto.JWT) {
 	testQueries := repository.SetupTestDB(t)
 	userRepo := repository.NewUserRepository(testQueries)
+	auditRepo := repository.NewAuditLogRepository(testQueries)
+	transactor := repository.NewTransactor(testQueries)
 	eventRepo := repository.NewEventRepository(testQueries)
 	swapRepo := repository.NewSwapRequestRepository(testQueries)
+	notificationRepo := repository.NewNotificationRepository(testQueries)
 
 	passwordCrypto := crypto.NewPassword()
 	jwtSecret := "test-jwt-secret"
 	jwtTTL := time.Minute * 10
 	jwtManager := crypto.NewJWT(jwtSecret, jwtTTL)
 
-	authService := services.NewAuthService(userRepo, passwordCrypto, jwtManager)
-	userService := services.NewUserService(userRepo, passwordCrypto)
-	eventService := services.NewEventService(eventRepo, userRepo, swapRepo)
-	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo)
+	authService := services.NewAuthService(userRepo, auditRepo, transactor, passwordCrypto, jwtManager)
+	userService := services.NewUserService(userRepo, eventRepo, swapRepo, repository.NewAccessTokenRepository(testQueries), auditRepo, notificationRepo, transactor, passwordCrypto)
+	eventService := services.NewEventService(eventRepo, userRepo, swapRepo, auditRepo, notificationRepo, transactor)
+	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, notificationRepo, transactor)
+	accessTokenService := services.NewAccessTokenService(repository.NewAccessTokenRepository(testQueries), auditRepo, transactor)
+	idempotencyService := services.NewIdempotencyService(repository.NewIdempotencyKeyRepository(testQueries), transactor, time.Hour)
 
-	server := NewServer(nil, authService, userService, eventService, swapRequestService, jwtManager)
+	server := NewServer(nil, authService, userService, eventService, swapRequestService, services.NewAuditService(auditRepo, userRepo), accessTokenService, idempotencyService, services.NewNotificationService(notificationRepo), jwtManager)
 	router := http.NewServeMux()
 	server.RegisterRoutes(router)
 
@@ -205,6 +210,36 @@ func TestUserAPI(t *testing.T) {
 		// Ensure email is not returned in public profile
 		// This is implicitly tested by the type db.GetPublicUserByIDRow not having an Email field
 	})
+
+	t.Run("DELETE /api/me", func(t *testing.T) {
+		_, _, leaverCookie := signUpAndLogin(t, ts, "Leaving User", "leaving@example.com", "leavingpassword")
+
+		req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/api/me", nil)
+		req.AddCookie(leaverCookie)
+		rr := httptest.NewRecorder()
+		ts.Config.Handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("DELETE /api/me: expected status %d, got %d: %s", http.StatusNoContent, rr.Code, rr.Body.String())
+		}
+
+		// The old session stops working, and so does signing in again.
+		req, _ = http.NewRequest(http.MethodGet, ts.URL+"/api/me", nil)
+		req.AddCookie(leaverCookie)
+		rr = httptest.NewRecorder()
+		ts.Config.Handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("GET /api/me after deactivation: expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+
+		body, _ := json.Marshal(services.LoginInput{Email: "leaving@example.com", Password: "leavingpassword"})
+		req, _ = http.NewRequest(http.MethodPost, ts.URL+"/api/login", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr = httptest.NewRecorder()
+		ts.Config.Handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("login after deactivation: expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+	})
 }
 
 func TestEventAPI(t *testing.T) {
@@ -267,8 +302,9 @@ func TestEventAPI(t *testing.T) {
 		if listRr.Code != http.StatusOK {
 			t.Fatalf("GetEventsByUserID: expected status %d, got %d: %s", http.StatusOK, listRr.Code, listRr.Body.String())
 		}
-		var events []db.Event
-		json.NewDecoder(listRr.Body).Decode(&events)
+		var page services.Page[db.Event]
+		json.NewDecoder(listRr.Body).Decode(&page)
+		events := page.Items
 		if len(events) == 0 {
 			t.Fatal("GetEventsByUserID: expected at least one event")
 		}
@@ -353,8 +389,9 @@ func TestSwapAPI(t *testing.T) {
 		if rr.Code != http.StatusOK {
 			t.Fatalf("GetSwappableEvents: expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
 		}
-		var events []db.Event
-		json.NewDecoder(rr.Body).Decode(&events)
+		var page services.Page[db.ListSwappableEventsRow]
+		json.NewDecoder(rr.Body).Decode(&page)
+		events := page.Items
 		if len(events) == 0 {
 			t.Fatal("GetSwappableEvents: expected at least one swappable event")
 		}
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"cmp"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"slotswapper/internal/services"
 )
@@ -11,14 +14,14 @@ import (
 func (s *Server) handleCreateSwapRequest(w http.ResponseWriter, r *http.Request) {
 	userID, ok := GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
 	var input services.CreateSwapRequestInput
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -26,80 +29,213 @@ func (s *Server) handleCreateSwapRequest(w http.ResponseWriter, r *http.Request)
 
 	swapRequest, err := s.swapRequestService.CreateSwapRequest(r.Context(), input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(swapRequest)
+	writeJSON(w, r, swapRequest)
 }
 
 func (s *Server) handleUpdateSwapRequestStatus(w http.ResponseWriter, r *http.Request) {
 	swapRequestID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid Swap Request ID", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "Invalid Swap Request ID")
 		return
 	}
 
 	userID, ok := GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
 	var input services.UpdateSwapRequestStatusInput
-	var status struct {
-		Status string `json:"status"`
-	}
-	err = json.NewDecoder(r.Body).Decode(&status)
+	err = json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 	input.ID = swapRequestID
-	input.Status = status.Status
 	input.UserID = userID
 
 	updatedSwapRequest, err := s.swapRequestService.UpdateSwapRequestStatus(r.Context(), input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(updatedSwapRequest)
+	writeJSON(w, r, updatedSwapRequest)
+}
+
+func (s *Server) handleWithdrawSwapRequest(w http.ResponseWriter, r *http.Request) {
+	swapRequestID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid Swap Request ID")
+		return
+	}
+
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	withdrawn, err := s.swapRequestService.WithdrawSwapRequest(r.Context(), swapRequestID, userID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, withdrawn)
+}
+
+func (s *Server) handleClaimSlot(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid Event ID")
+		return
+	}
+
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	allowOverlap, err := strconv.ParseBool(cmp.Or(r.URL.Query().Get("allow_overlap"), "false"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "allow_overlap must be true or false")
+		return
+	}
+
+	claim, err := s.swapRequestService.ClaimSlot(r.Context(), services.ClaimSlotInput{EventID: eventID, UserID: userID, AllowOverlap: allowOverlap})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, claim)
 }
 
 func (s *Server) handleGetIncomingSwapRequests(w http.ResponseWriter, r *http.Request) {
 	userID, ok := GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
-	requests, err := s.swapRequestService.GetIncomingSwapRequests(r.Context(), userID)
+	page, err := parsePageRequest(r.URL.Query())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
+	filter := services.SwapRequestListFilter{Sort: r.URL.Query().Get("sort"), PageRequest: page}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(requests)
+	requests, err := s.swapRequestService.ListIncomingSwapRequests(r.Context(), userID, filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, requests)
 }
 
 func (s *Server) handleGetOutgoingSwapRequests(w http.ResponseWriter, r *http.Request) {
 	userID, ok := GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	page, err := parsePageRequest(r.URL.Query())
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := services.SwapRequestListFilter{Sort: r.URL.Query().Get("sort"), PageRequest: page}
+
+	requests, err := s.swapRequestService.ListOutgoingSwapRequests(r.Context(), userID, filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, requests)
+}
+
+func (s *Server) handleGetIncomingSwapRequestHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	filter, err := parseSwapRequestHistoryFilter(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
+	filter.UserID = userID
 
-	requests, err := s.swapRequestService.GetOutgoingSwapRequests(r.Context(), userID)
+	requests, err := s.swapRequestService.GetIncomingSwapRequestHistory(r.Context(), filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(requests)
+	writeJSON(w, r, requests)
+}
+
+func (s *Server) handleGetOutgoingSwapRequestHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	filter, err := parseSwapRequestHistoryFilter(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.UserID = userID
+
+	requests, err := s.swapRequestService.GetOutgoingSwapRequestHistory(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, requests)
+}
+
+// parseSwapRequestHistoryFilter reads the status, counterparty_id, from, to,
+// sort and paging query parameters. Times use RFC 3339.
+func parseSwapRequestHistoryFilter(r *http.Request) (services.SwapRequestHistoryFilter, error) {
+	query := r.URL.Query()
+	filter := services.SwapRequestHistoryFilter{
+		Status: query.Get("status"),
+		Sort:   query.Get("sort"),
+	}
+
+	var err error
+	if v := query.Get("counterparty_id"); v != "" {
+		if filter.CounterpartyID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return filter, errors.New("Invalid counterparty_id parameter")
+		}
+	}
+	if v := query.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, errors.New("Invalid from parameter")
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, errors.New("Invalid to parameter")
+		}
+	}
+	if filter.PageRequest, err = parsePageRequest(query); err != nil {
+		return filter, err
+	}
+	return filter, nil
 }
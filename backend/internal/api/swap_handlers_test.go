@@ -3,8 +3,11 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	"slotswapper/internal/db"
@@ -16,13 +19,15 @@ func TestServer_handleGetIncomingSwapRequests(t *testing.T) {
 	queries := repository.SetupTestDB(t)
 
 	userRepo := repository.NewUserRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	transactor := repository.NewTransactor(queries)
 	eventRepo := repository.NewEventRepository(queries)
 	swapRepo := repository.NewSwapRequestRepository(queries)
-	authService := services.NewAuthService(userRepo, nil, nil) // Mocks
-	eventService := services.NewEventService(eventRepo, userRepo, swapRepo)
-	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo)
+	authService := services.NewAuthService(userRepo, auditRepo, transactor, nil, nil) // Mocks
+	eventService := services.NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
+	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
 
-	server := NewServer(nil, authService, nil, eventService, swapRequestService, nil)
+	server := NewServer(nil, authService, nil, eventService, swapRequestService, nil, nil, nil, nil, nil)
 
 	// Create two users
 	user1, err := userRepo.CreateUser(context.Background(), db.CreateUserParams{Name: "User One", Email: "user1@test.com", Password: "password"})
@@ -69,10 +74,11 @@ func TestServer_handleGetIncomingSwapRequests(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var requests []db.GetIncomingSwapRequestsRow
-	if err := json.Unmarshal(rr.Body.Bytes(), &requests); err != nil {
+	var page services.Page[db.ListIncomingSwapRequestsRow]
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
 		t.Fatalf("Failed to unmarshal response body: %v", err)
 	}
+	requests := page.Items
 
 	if len(requests) != 1 {
 		t.Fatalf("Expected 1 incoming request, got %d", len(requests))
@@ -87,13 +93,15 @@ func TestServer_handleGetOutgoingSwapRequests(t *testing.T) {
 	queries := repository.SetupTestDB(t)
 
 	userRepo := repository.NewUserRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	transactor := repository.NewTransactor(queries)
 	eventRepo := repository.NewEventRepository(queries)
 	swapRepo := repository.NewSwapRequestRepository(queries)
-	authService := services.NewAuthService(userRepo, nil, nil) // Mocks
-	eventService := services.NewEventService(eventRepo, userRepo, swapRepo)
-	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo)
+	authService := services.NewAuthService(userRepo, auditRepo, transactor, nil, nil) // Mocks
+	eventService := services.NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
+	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
 
-	server := NewServer(nil, authService, nil, eventService, swapRequestService, nil)
+	server := NewServer(nil, authService, nil, eventService, swapRequestService, nil, nil, nil, nil, nil)
 
 	// Create two users
 	user1, err := userRepo.CreateUser(context.Background(), db.CreateUserParams{Name: "User One", Email: "user1@test.com", Password: "password"})
@@ -140,10 +148,11 @@ func TestServer_handleGetOutgoingSwapRequests(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var requests []db.GetOutgoingSwapRequestsRow
-	if err := json.Unmarshal(rr.Body.Bytes(), &requests); err != nil {
+	var page services.Page[db.ListOutgoingSwapRequestsRow]
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
 		t.Fatalf("Failed to unmarshal response body: %v", err)
 	}
+	requests := page.Items
 
 	if len(requests) != 1 {
 		t.Fatalf("Expected 1 outgoing request, got %d", len(requests))
@@ -153,3 +162,193 @@ func TestServer_handleGetOutgoingSwapRequests(t *testing.T) {
 		t.Errorf("Expected responder name to be %s, got %s", user2.Name, requests[0].ResponderName)
 	}
 }
+
+func TestServer_handleGetIncomingSwapRequestHistory(t *testing.T) {
+	queries := repository.SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	transactor := repository.NewTransactor(queries)
+	eventRepo := repository.NewEventRepository(queries)
+	swapRepo := repository.NewSwapRequestRepository(queries)
+	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
+
+	server := NewServer(nil, nil, nil, nil, swapRequestService, nil, nil, nil, nil, nil)
+
+	user1, err := userRepo.CreateUser(context.Background(), db.CreateUserParams{Name: "User One", Email: "user1@test.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("Failed to create user1: %v", err)
+	}
+
+	user2, err := userRepo.CreateUser(context.Background(), db.CreateUserParams{Name: "User Two", Email: "user2@test.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("Failed to create user2: %v", err)
+	}
+
+	event1, err := eventRepo.CreateEvent(context.Background(), db.CreateEventParams{Title: "Event 1", UserID: user1.ID, Status: "SWAPPABLE"})
+	if err != nil {
+		t.Fatalf("Failed to create event1: %v", err)
+	}
+
+	event2, err := eventRepo.CreateEvent(context.Background(), db.CreateEventParams{Title: "Event 2", UserID: user2.ID, Status: "SWAPPABLE"})
+	if err != nil {
+		t.Fatalf("Failed to create event2: %v", err)
+	}
+
+	swapRequest, err := swapRequestService.CreateSwapRequest(context.Background(), services.CreateSwapRequestInput{
+		RequesterUserID: user1.ID,
+		ResponderUserID: user2.ID,
+		RequesterSlotID: event1.ID,
+		ResponderSlotID: event2.ID,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create swap request: %v", err)
+	}
+
+	_, err = swapRequestService.UpdateSwapRequestStatus(context.Background(), services.UpdateSwapRequestStatusInput{ID: swapRequest.ID, Status: "REJECTED", UserID: user2.ID})
+	if err != nil {
+		t.Fatalf("Failed to reject swap request: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/swap-requests/incoming/history?status=REJECTED&sort=-resolved_at&limit=10", nil)
+	ctx := context.WithValue(req.Context(), userIDContextKey, user2.ID)
+	req = req.WithContext(ctx)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(server.handleGetIncomingSwapRequestHistory)
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", status, http.StatusOK, rr.Body.String())
+	}
+
+	var page services.Page[db.GetIncomingSwapRequestHistoryRow]
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+		t.Fatalf("Failed to unmarshal response body: %v", err)
+	}
+	requests := page.Items
+
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 request in history, got %d", len(requests))
+	}
+	if requests[0].Status != "REJECTED" {
+		t.Errorf("Expected status REJECTED, got %s", requests[0].Status)
+	}
+	if requests[0].ResolvedByName != user2.Name {
+		t.Errorf("Expected resolver name to be %s, got %s", user2.Name, requests[0].ResolvedByName)
+	}
+
+	req = httptest.NewRequest("GET", "/api/swap-requests/incoming/history?from=yesterday", nil)
+	req = req.WithContext(context.WithValue(req.Context(), userIDContextKey, user2.ID))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code for invalid date: got %v want %v", status, http.StatusBadRequest)
+	}
+}
+
+func TestServer_handleCreateSwapRequestErrors(t *testing.T) {
+	queries := repository.SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(queries)
+	auditRepo := repository.NewAuditLogRepository(queries)
+	transactor := repository.NewTransactor(queries)
+	eventRepo := repository.NewEventRepository(queries)
+	swapRepo := repository.NewSwapRequestRepository(queries)
+	swapRequestService := services.NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(queries), transactor)
+
+	server := NewServer(nil, nil, nil, nil, swapRequestService, nil, nil, nil, nil, nil)
+
+	user1, err := userRepo.CreateUser(context.Background(), db.CreateUserParams{Name: "User One", Email: "user1@test.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("Failed to create user1: %v", err)
+	}
+	user2, err := userRepo.CreateUser(context.Background(), db.CreateUserParams{Name: "User Two", Email: "user2@test.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("Failed to create user2: %v", err)
+	}
+	busy, err := eventRepo.CreateEvent(context.Background(), db.CreateEventParams{Title: "Busy", UserID: user1.ID, Status: "BUSY"})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	theirs, err := eventRepo.CreateEvent(context.Background(), db.CreateEventParams{Title: "Theirs", UserID: user2.ID, Status: "SWAPPABLE"})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantFields []string
+	}{
+		{
+			name:       "malformed body",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing fields",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "validation_failed",
+			wantFields: []string{"responder_user_id", "requester_slot_id", "responder_slot_id"},
+		},
+		{
+			name:       "unknown slot",
+			body:       fmt.Sprintf(`{"responder_user_id":%d,"requester_slot_id":9999,"responder_slot_id":%d}`, user2.ID, theirs.ID),
+			wantStatus: http.StatusNotFound,
+			wantCode:   "not_found",
+		},
+		{
+			name:       "slot not swappable",
+			body:       fmt.Sprintf(`{"responder_user_id":%d,"requester_slot_id":%d,"responder_slot_id":%d}`, user2.ID, busy.ID, theirs.ID),
+			wantStatus: http.StatusConflict,
+			wantCode:   "invalid_state",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/swap-request", strings.NewReader(tc.body))
+			req = req.WithContext(context.WithValue(req.Context(), userIDContextKey, user1.ID))
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(server.handleCreateSwapRequest).ServeHTTP(rr, req)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("Expected problem content type, got %q", ct)
+			}
+
+			var body struct {
+				Type     string                `json:"type"`
+				Title    string                `json:"title"`
+				Status   int                   `json:"status"`
+				Detail   string                `json:"detail"`
+				Instance string                `json:"instance"`
+				Code     string                `json:"code"`
+				Errors   []services.FieldError `json:"errors"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to unmarshal problem: %v", err)
+			}
+			if body.Type != "about:blank" || body.Title != http.StatusText(tc.wantStatus) || body.Status != tc.wantStatus || body.Instance != "/api/swap-request" {
+				t.Errorf("Unexpected problem envelope: %+v", body)
+			}
+			if body.Code != tc.wantCode {
+				t.Errorf("Expected code %q, got %q", tc.wantCode, body.Code)
+			}
+			var fields []string
+			for _, fe := range body.Errors {
+				fields = append(fields, fe.Field)
+			}
+			if !reflect.DeepEqual(fields, tc.wantFields) {
+				t.Errorf("Expected invalid fields %v, got %v", tc.wantFields, fields)
+			}
+		})
+	}
+}
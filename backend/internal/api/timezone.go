@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+const locationContextKey contextKey = "location"
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// TimeZoneMiddleware reads the optional tz query parameter, an IANA zone name
+// such as Europe/Berlin, and stores its location in the request context so
+// that responses render times in that zone. Times are stored and returned in
+// UTC when tz is absent.
+func TimeZoneMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("tz")
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		loc, err := time.LoadLocation(name)
+		if err != nil || name == "Local" {
+			writeProblem(w, r, http.StatusBadRequest, "Invalid tz: expected an IANA time zone name")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), locationContextKey, loc)))
+	})
+}
+
+// locationFromContext returns the zone requested with tz, or nil.
+func locationFromContext(ctx context.Context) *time.Location {
+	loc, _ := ctx.Value(locationContextKey).(*time.Location)
+	return loc
+}
+
+// writeJSON encodes v as the response body, first converting every time it
+// contains to the zone the caller asked for.
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	if loc := locationFromContext(r.Context()); loc != nil && v != nil {
+		v = inLocation(reflect.ValueOf(v), loc).Interface()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// inLocation returns a copy of v with every time.Time reachable through
+// exported struct fields, pointers, slices and interfaces moved to loc. The
+// instants are unchanged; only the rendered offset differs.
+func inLocation(v reflect.Value, loc *time.Location) reflect.Value {
+	if v.Type() == timeType {
+		return reflect.ValueOf(v.Interface().(time.Time).In(loc))
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(inLocation(v.Elem(), loc))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(inLocation(v.Elem(), loc))
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range out.NumField() {
+			if field := out.Field(i); field.CanSet() {
+				field.Set(inLocation(v.Field(i), loc))
+			}
+		}
+		return out
+	case reflect.Slice:
+		// Byte slices such as json.RawMessage cannot hold times.
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(inLocation(v.Index(i), loc))
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"slotswapper/internal/logging"
+	"slotswapper/internal/tracing"
+)
+
+// TracingMiddleware starts a server span for every request, named after the
+// route pattern serving it. The span continues the trace of a W3C
+// traceparent header sent by the caller, and the request's logger is tagged
+// with the trace ID.
+func TracingMiddleware(routes RouteMatcher) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, route := routes.Handler(r)
+			name := route
+			if name == "" {
+				name = r.Method
+			}
+
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracing.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(r.URL.Path),
+				),
+			)
+			defer span.End()
+			if span.SpanContext().IsValid() {
+				ctx = logging.With(ctx, "trace_id", span.SpanContext().TraceID().String())
+			}
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r.WithContext(ctx))
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			span.SetAttributes(semconv.HTTPResponseStatusCode(rec.status))
+			if rec.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(rec.status))
+			}
+		})
+	}
+}
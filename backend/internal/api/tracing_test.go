@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/services"
+)
+
+// recordSpans installs a tracer provider keeping finished spans in memory
+// until the end of the test.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		provider.Shutdown(t.Context())
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return exporter
+}
+
+func TestServer_TracingMiddleware(t *testing.T) {
+	exporter := recordSpans(t)
+	ts, _, _ := setupTestServer(t)
+	defer ts.Close()
+	mux := ts.Config.Handler.(*http.ServeMux)
+	handler := TracingMiddleware(mux)(mux)
+
+	token, _, _ := signUpAndLogin(t, ts, "Alice", "alice@example.com", "password123")
+	bobToken, bob, _ := signUpAndLogin(t, ts, "Bob", "bob@example.com", "password123")
+
+	serve := func(method, path, traceparent, token string, body any) *httptest.ResponseRecorder {
+		var payload bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&payload).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &payload)
+		req.Header.Set("Content-Type", "application/json")
+		if traceparent != "" {
+			req.Header.Set("traceparent", traceparent)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	start := time.Date(2030, time.April, 1, 9, 0, 0, 0, time.UTC)
+	var aliceEvent, bobEvent db.Event
+	json.NewDecoder(serve("POST", "/api/events", "", token, map[string]any{"title": "A", "start_time": start, "end_time": start.Add(time.Hour), "status": "SWAPPABLE"}).Body).Decode(&aliceEvent)
+	json.NewDecoder(serve("POST", "/api/events", "", bobToken, map[string]any{"title": "B", "start_time": start.Add(2 * time.Hour), "end_time": start.Add(3 * time.Hour), "status": "SWAPPABLE"}).Body).Decode(&bobEvent)
+	var swap db.SwapRequest
+	json.NewDecoder(serve("POST", "/api/swap-request", "", token, services.CreateSwapRequestInput{
+		ResponderUserID: bob.ID, RequesterSlotID: aliceEvent.ID, ResponderSlotID: bobEvent.ID,
+	}).Body).Decode(&swap)
+
+	t.Run("a swap accept is traced through every layer", func(t *testing.T) {
+		exporter.Reset()
+		traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+		rr := serve("POST", fmt.Sprintf("/api/swap-response/%d", swap.ID), "00-"+traceID+"-00f067aa0ba902b7-01", bobToken, map[string]any{"status": "ACCEPTED"})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("accept failed: %s", rr.Body.String())
+		}
+
+		spans := exporter.GetSpans()
+		byID := map[trace.SpanID]tracetest.SpanStub{}
+		for _, span := range spans {
+			if span.SpanContext.TraceID().String() != traceID {
+				t.Errorf("span %s is not part of the caller's trace", span.Name)
+			}
+			byID[span.SpanContext.SpanID()] = span
+		}
+		// ancestry lists the names of a span's ancestors, nearest first.
+		ancestry := func(span tracetest.SpanStub) []string {
+			var names []string
+			for parent, ok := byID[span.Parent.SpanID()]; ok; parent, ok = byID[parent.Parent.SpanID()] {
+				names = append(names, parent.Name)
+			}
+			return names
+		}
+
+		var server *tracetest.SpanStub
+		var writes []string
+		for i, span := range spans {
+			switch {
+			case span.Name == "POST /api/swap-response/{id}":
+				server = &spans[i]
+			case strings.HasPrefix(span.Name, "EventRepository.Update") || span.Name == "SwapRequestRepository.ResolveSwapRequest" || span.Name == "AuditLogRepository.CreateAuditLog":
+				writes = append(writes, span.Name)
+				if want := []string{"Transactor.WithinTx", "SwapRequestService.UpdateSwapRequestStatus", "POST /api/swap-response/{id}"}; !slices.Equal(ancestry(span), want) {
+					t.Errorf("expected %s under %v, got %v", span.Name, want, ancestry(span))
+				}
+			}
+		}
+		if server == nil {
+			t.Fatalf("no server span in %d spans", len(spans))
+		}
+		if server.SpanKind != trace.SpanKindServer || server.Parent.SpanID().String() != "00f067aa0ba902b7" || !server.Parent.IsRemote() {
+			t.Errorf("expected a server span continuing the caller's span, got %+v", server)
+		}
+		attrs := map[string]any{}
+		for _, attr := range server.Attributes {
+			attrs[string(attr.Key)] = attr.Value.AsInterface()
+		}
+		if attrs["http.route"] != "POST /api/swap-response/{id}" || attrs["http.response.status_code"] != int64(http.StatusOK) {
+			t.Errorf("unexpected server span attributes %v", attrs)
+		}
+		// Two slot transfers, the resolution and their audit entries.
+		if len(writes) < 6 {
+			t.Errorf("expected a span per database write, got %v", writes)
+		}
+	})
+
+	t.Run("failures are recorded on the spans", func(t *testing.T) {
+		exporter.Reset()
+		serve("POST", fmt.Sprintf("/api/swap-response/%d", swap.ID), "", bobToken, map[string]any{"status": "ACCEPTED"})
+
+		var found bool
+		for _, span := range exporter.GetSpans() {
+			if span.Name == "SwapRequestService.UpdateSwapRequestStatus" {
+				found = true
+				if span.Status.Code != codes.Error || len(span.Events) == 0 {
+					t.Errorf("expected the service span to record the error, got %+v", span.Status)
+				}
+			}
+			if span.Name == "POST /api/swap-response/{id}" && span.Status.Code == codes.Error {
+				t.Errorf("client errors should not fail the server span")
+			}
+		}
+		if !found {
+			t.Error("no service span recorded")
+		}
+	})
+}
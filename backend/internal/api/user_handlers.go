@@ -4,38 +4,82 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
+
+	"slotswapper/internal/services"
 )
 
 func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request) {
 	userID, ok := GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
 	user, err := s.userService.GetUserByID(r.Context(), userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(user)
+	writeJSON(w, r, user)
 }
 
 func (s *Server) handleGetUserProfile(w http.ResponseWriter, r *http.Request) {
 	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid User ID", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "Invalid User ID")
 		return
 	}
 
 	user, err := s.userService.GetPublicUserByID(r.Context(), userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, user)
+}
+
+func (s *Server) handleUpdateTimeZone(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input services.UpdateTimeZoneInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	input.UserID = userID
+
+	user, err := s.userService.UpdateTimeZone(r.Context(), input)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, user)
+}
+
+// handleDeactivateAccount closes the current user's account and ends the
+// session.
+func (s *Server) handleDeactivateAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := s.userService.DeactivateAccount(r.Context(), userID); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(user)
+	cookie := s.accessTokenCookie("")
+	cookie.Expires = time.Unix(0, 0)
+	http.SetCookie(w, cookie)
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,315 @@
+// Package config loads the server configuration. Settings come from the
+// defaults, then a JSON file, then SLOTSWAPPER_* environment variables, then
+// command-line flags, each layer overriding the ones before it.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"slotswapper/internal/api"
+	"slotswapper/internal/crypto"
+)
+
+const (
+	envPrefix         = "SLOTSWAPPER_"
+	defaultConfigFile = "config.json"
+
+	// DevJWTSecret signs tokens when no secret is configured outside of
+	// production. Production refuses to start with it.
+	DevJWTSecret = "insecure-development-jwt-secret"
+
+	minProductionSecretLength = 32
+)
+
+// Config is the complete server configuration. The server settings are
+// embedded, so they sit at the top level of the JSON file.
+type Config struct {
+	// Env is "development" (the default) or "production". Production
+	// refuses insecure settings; see Validate.
+	Env string `json:"env"`
+	api.Config
+	// DatabaseDSN is the SQLite data source name: a file path, optionally
+	// followed by go-sqlite3 options.
+	DatabaseDSN string `json:"databaseDsn"`
+	// JWTKeysFile names a key set written by "slotswapper keys"; tokens are
+	// then signed with its newest key and verified with any of its keys.
+	// Without it, tokens are signed with the shared JWTSecret.
+	JWTKeysFile string `json:"jwtKeysFile"`
+	JWTSecret   string `json:"jwtSecret" secret:"true"`
+	// JWTIssuer and JWTAudience are the iss and aud claims of issued tokens;
+	// tokens with other values are rejected.
+	JWTIssuer   string `json:"jwtIssuer"`
+	JWTAudience string `json:"jwtAudience"`
+}
+
+// Default returns the configuration used when nothing overrides it.
+func Default() Config {
+	return Config{
+		Env:    "development",
+		Config: api.DefaultConfig(),
+		// Services write inside transactions; wait for the write lock
+		// instead of failing immediately when another request holds it.
+		DatabaseDSN: "db/slotswapper.db?_busy_timeout=5000&_txlock=immediate",
+		JWTSecret:   DevJWTSecret,
+		JWTIssuer:   crypto.DefaultIssuer,
+		JWTAudience: crypto.DefaultAudience,
+	}
+}
+
+// Production reports whether the server runs in production mode.
+func (c *Config) Production() bool {
+	return c.Env == "production"
+}
+
+// Load builds the configuration from the layers. It registers a flag for
+// every setting, plus -config naming the file, on fs and parses args with it;
+// the caller may have registered flags of its own on fs first. The file is
+// config.json unless -config or SLOTSWAPPER_CONFIG name another; only a file
+// named explicitly has to exist. getenv looks up environment variables.
+func Load(fs *flag.FlagSet, args []string, getenv func(string) string) (*Config, error) {
+	cfg := Default()
+	settings := cfg.settings()
+
+	configFile := fs.String("config", "", "path to the JSON configuration file (env "+envPrefix+"CONFIG, default "+defaultConfigFile+")")
+	flagValues := map[string]string{}
+	for _, s := range settings {
+		usage := fmt.Sprintf("sets %s (env %s)", s.key, s.envName())
+		if s.value.Kind() == reflect.Bool {
+			fs.BoolFunc(s.flagName(), usage, func(v string) error {
+				flagValues[s.key] = v
+				return nil
+			})
+			continue
+		}
+		fs.Func(s.flagName(), usage, func(v string) error {
+			flagValues[s.key] = v
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	path, required := *configFile, true
+	if path == "" {
+		path = getenv(envPrefix + "CONFIG")
+	}
+	if path == "" {
+		path, required = defaultConfigFile, false
+	}
+	if err := cfg.loadFile(path, required); err != nil {
+		return nil, err
+	}
+
+	cfg.applyPlatformEnv(getenv)
+	for _, s := range settings {
+		if v := getenv(s.envName()); v != "" {
+			if err := s.set(v); err != nil {
+				return nil, fmt.Errorf("%s: %w", s.envName(), err)
+			}
+		}
+	}
+
+	for _, s := range settings {
+		if v, ok := flagValues[s.key]; ok {
+			if err := s.set(v); err != nil {
+				return nil, fmt.Errorf("-%s: %w", s.flagName(), err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (c *Config) loadFile(path string, required bool) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	dec := json.NewDecoder(file)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("failed to decode config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyPlatformEnv honours the variables set by hosting platforms: PORT for
+// the listen address and, on Render, the public URL as an allowed origin.
+// SLOTSWAPPER_* variables and flags still override them.
+func (c *Config) applyPlatformEnv(getenv func(string) string) {
+	if port := getenv("PORT"); port != "" {
+		c.Addr = ":" + port
+	}
+	if getenv("RENDER") == "true" {
+		if url := getenv("RENDER_EXTERNAL_URL"); url != "" {
+			c.AllowedOrigins = append(c.AllowedOrigins, url)
+		}
+	}
+}
+
+// Validate checks the settings, and in production refuses the insecure
+// defaults that are convenient during development.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Env != "development" && c.Env != "production" {
+		errs = append(errs, fmt.Errorf("invalid env %q: expected development or production", c.Env))
+	}
+	if c.DatabaseDSN == "" {
+		errs = append(errs, errors.New("databaseDsn must be set"))
+	}
+	if c.JWTSecret == "" && c.JWTKeysFile == "" {
+		errs = append(errs, errors.New("jwtSecret or jwtKeysFile must be set"))
+	}
+	if c.JWTIssuer == "" || c.JWTAudience == "" {
+		errs = append(errs, errors.New("jwtIssuer and jwtAudience must be set"))
+	}
+	if c.AccessTokenTTL <= 0 {
+		errs = append(errs, errors.New("accessTokenTtl must be positive"))
+	}
+	if c.IdempotencyKeyTTL <= 0 {
+		errs = append(errs, errors.New("idempotencyKeyTtl must be positive"))
+	}
+	if c.MetricsAddr != "" && c.MetricsAddr == c.Addr {
+		errs = append(errs, errors.New("metricsAddr must differ from addr"))
+	}
+	if _, err := c.TrustedProxyPrefixes(); err != nil {
+		errs = append(errs, err)
+	}
+	sameSite, err := c.SameSite()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if err == nil && sameSite == http.SameSiteNoneMode && !c.CookieSecure {
+		errs = append(errs, errors.New("cookieSameSite none requires cookieSecure"))
+	}
+	if (c.TlsCertFile == "") != (c.TlsKeyFile == "") {
+		errs = append(errs, errors.New("tlsCertFile and tlsKeyFile must be set together"))
+	}
+
+	if c.Production() {
+		if c.JWTKeysFile == "" && (c.JWTSecret == DevJWTSecret || len(c.JWTSecret) < minProductionSecretLength) {
+			errs = append(errs, fmt.Errorf("production needs jwtKeysFile or a random jwtSecret of at least %d characters", minProductionSecretLength))
+		}
+		if !c.CookieSecure {
+			errs = append(errs, errors.New("cookieSecure must be true in production"))
+		}
+		if slices.Contains(c.AllowedOrigins, "*") {
+			errs = append(errs, errors.New("allowedOrigins must not contain * in production"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Redacted returns a copy of the configuration with secrets masked, for
+// printing.
+func (c Config) Redacted() Config {
+	c.AllowedOrigins = slices.Clone(c.AllowedOrigins)
+	c.TrustedProxies = slices.Clone(c.TrustedProxies)
+	for _, s := range c.settings() {
+		if s.secret && s.value.String() != "" {
+			s.value.SetString("REDACTED")
+		}
+	}
+	return c
+}
+
+// setting is one configurable field, addressed by its JSON key.
+type setting struct {
+	key    string
+	value  reflect.Value
+	secret bool
+}
+
+// settings lists the fields of c, including the embedded server settings.
+func (c *Config) settings() []setting {
+	var settings []setting
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if field.Anonymous {
+				walk(v.Field(i))
+				continue
+			}
+			key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			settings = append(settings, setting{key: key, value: v.Field(i), secret: field.Tag.Get("secret") == "true"})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem())
+	return settings
+}
+
+// words splits a camelCase key such as "accessTokenTtl" into its lower-case
+// words.
+func (s setting) words() []string {
+	var words []string
+	start := 0
+	for i, r := range s.key {
+		if i > 0 && unicode.IsUpper(r) {
+			words = append(words, strings.ToLower(s.key[start:i]))
+			start = i
+		}
+	}
+	return append(words, strings.ToLower(s.key[start:]))
+}
+
+// envName is the environment variable for the setting, such as
+// SLOTSWAPPER_ACCESS_TOKEN_TTL.
+func (s setting) envName() string {
+	return envPrefix + strings.ToUpper(strings.Join(s.words(), "_"))
+}
+
+// flagName is the flag for the setting, such as access-token-ttl.
+func (s setting) flagName() string {
+	return strings.Join(s.words(), "-")
+}
+
+// set parses v into the setting. Lists are comma separated.
+func (s setting) set(v string) error {
+	switch p := s.value.Addr().Interface().(type) {
+	case *string:
+		*p = v
+	case *bool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", v)
+		}
+		*p = b
+	case *[]string:
+		*p = nil
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*p = append(*p, item)
+			}
+		}
+	case *api.Duration:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*p = api.Duration(d)
+	default:
+		return fmt.Errorf("unsupported setting type %T", p)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"slotswapper/internal/api"
+)
+
+// load runs Load with a fresh flag set and the given environment.
+func load(t *testing.T, env map[string]string, args ...string) (*Config, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("slotswapper", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return Load(fs, args, func(key string) string { return env[key] })
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		cfg, err := load(t, nil)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.Env != "development" || cfg.Addr != ":8080" || cfg.JWTSecret != DevJWTSecret || time.Duration(cfg.AccessTokenTTL) != 24*time.Hour || cfg.CookieSameSite != "lax" {
+			t.Errorf("unexpected defaults %+v", cfg)
+		}
+		if !strings.Contains(cfg.DatabaseDSN, "_busy_timeout") {
+			t.Errorf("expected the default DSN to wait for locks, got %q", cfg.DatabaseDSN)
+		}
+	})
+
+	t.Run("layers override each other in order", func(t *testing.T) {
+		path := writeConfigFile(t, `{"addr": ":7000", "logLevel": "warn", "jwtSecret": "from-file", "accessTokenTtl": "2h", "allowedOrigins": ["https://file.example"]}`)
+		env := map[string]string{
+			"SLOTSWAPPER_CONFIG":           path,
+			"SLOTSWAPPER_LOG_LEVEL":        "debug",
+			"SLOTSWAPPER_JWT_SECRET":       "from-env",
+			"SLOTSWAPPER_ALLOWED_ORIGINS":  "https://a.example, https://b.example",
+			"SLOTSWAPPER_ACCESS_TOKEN_TTL": "30m",
+		}
+		cfg, err := load(t, env, "-jwt-secret", "from-flag", "-cookie-secure")
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.Addr != ":7000" {
+			t.Errorf("expected the file to override the default addr, got %q", cfg.Addr)
+		}
+		if cfg.LogLevel != "debug" || time.Duration(cfg.AccessTokenTTL) != 30*time.Minute {
+			t.Errorf("expected env to override the file, got %+v", cfg)
+		}
+		if !slices.Equal(cfg.AllowedOrigins, []string{"https://a.example", "https://b.example"}) {
+			t.Errorf("expected comma-separated origins from env, got %q", cfg.AllowedOrigins)
+		}
+		if cfg.JWTSecret != "from-flag" || !cfg.CookieSecure {
+			t.Errorf("expected flags to override everything, got %+v", cfg)
+		}
+	})
+
+	t.Run("platform variables", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		env := map[string]string{"PORT": "10000", "RENDER": "true", "RENDER_EXTERNAL_URL": "https://slotswapper.onrender.com"}
+		cfg, err := load(t, env)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.Addr != ":10000" || !slices.Contains(cfg.AllowedOrigins, "https://slotswapper.onrender.com") {
+			t.Errorf("platform variables not applied: %+v", cfg)
+		}
+		env["SLOTSWAPPER_ADDR"] = ":9999"
+		if cfg, _ := load(t, env); cfg.Addr != ":9999" {
+			t.Errorf("expected SLOTSWAPPER_ADDR to win over PORT, got %q", cfg.Addr)
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		tests := []struct {
+			name string
+			env  map[string]string
+			args []string
+			want string
+		}{
+			{"missing named file", nil, []string{"-config", "missing.json"}, "failed to open config file"},
+			{"unknown key", nil, []string{"-config", writeConfigFile(t, `{"jwtSecrett": "typo"}`)}, "unknown field"},
+			{"bad duration", map[string]string{"SLOTSWAPPER_READ_TIMEOUT": "soon"}, nil, "SLOTSWAPPER_READ_TIMEOUT"},
+			{"bad flag", nil, []string{"-access-token-ttl", "10"}, "-access-token-ttl"},
+			{"bad env", map[string]string{"SLOTSWAPPER_ENV": "staging"}, nil, "invalid env"},
+			{"bad same site", nil, []string{"-cookie-same-site", "loose"}, "invalid cookieSameSite"},
+			{"insecure same site none", nil, []string{"-cookie-same-site", "none"}, "requires cookieSecure"},
+			{"half a TLS pair", nil, []string{"-tls-cert-file", "cert.pem"}, "must be set together"},
+			{"metrics on the API address", nil, []string{"-metrics-addr", ":8080"}, "metricsAddr must differ"},
+			{"bad trusted proxy", map[string]string{"SLOTSWAPPER_TRUSTED_PROXIES": "10.0.0.0/8,proxy.internal"}, nil, "invalid trustedProxies"},
+		}
+		for _, tt := range tests {
+			_, err := load(t, tt.env, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+			}
+		}
+	})
+
+	t.Run("production refuses insecure defaults", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		_, err := load(t, map[string]string{"SLOTSWAPPER_ENV": "production", "SLOTSWAPPER_ALLOWED_ORIGINS": "*"})
+		if err == nil {
+			t.Fatal("expected production to refuse the defaults")
+		}
+		for _, want := range []string{"jwtKeysFile or a random jwtSecret", "cookieSecure", "allowedOrigins"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("expected the error to mention %s, got %v", want, err)
+			}
+		}
+
+		cfg, err := load(t, map[string]string{
+			"SLOTSWAPPER_ENV":             "production",
+			"SLOTSWAPPER_JWT_SECRET":      strings.Repeat("k", 32),
+			"SLOTSWAPPER_COOKIE_SECURE":   "true",
+			"SLOTSWAPPER_ALLOWED_ORIGINS": "https://slotswapper.example",
+		})
+		if err != nil || !cfg.Production() {
+			t.Errorf("expected a secure production config to load, got %v", err)
+		}
+
+		_, err = load(t, map[string]string{
+			"SLOTSWAPPER_ENV":           "production",
+			"SLOTSWAPPER_JWT_KEYS_FILE": "keys.json",
+			"SLOTSWAPPER_COOKIE_SECURE": "true",
+		})
+		if err != nil {
+			t.Errorf("expected a key set to replace the secret in production, got %v", err)
+		}
+	})
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := Default()
+	cfg.JWTSecret = "top-secret"
+	cfg.AllowedOrigins = []string{"https://a.example"}
+
+	redacted := cfg.Redacted()
+	if redacted.JWTSecret != "REDACTED" {
+		t.Errorf("expected the secret to be redacted, got %q", redacted.JWTSecret)
+	}
+	redacted.AllowedOrigins[0] = "changed"
+	if cfg.JWTSecret != "top-secret" || cfg.AllowedOrigins[0] != "https://a.example" {
+		t.Errorf("Redacted modified the original: %+v", cfg)
+	}
+	if redacted.Config.Addr != api.DefaultConfig().Addr {
+		t.Errorf("expected other settings to be kept, got %+v", redacted.Config)
+	}
+}
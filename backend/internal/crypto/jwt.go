@@ -2,47 +2,94 @@ package crypto
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Default claims identifying tokens issued for this API.
+const (
+	DefaultIssuer   = "slotswapper"
+	DefaultAudience = "slotswapper-api"
+)
+
 type JWT interface {
 	Generate(userID int64) (string, error)
 	Verify(tokenString string) (int64, error)
+	// JWKS returns the public keys that verify tokens. It is empty for a
+	// shared secret, which cannot be published.
+	JWKS() JWKSet
+}
+
+// JWTOption changes the claims a JWT manager issues and requires.
+type JWTOption func(*jwtManager)
+
+// WithIssuer sets the iss claim, DefaultIssuer unless given.
+func WithIssuer(issuer string) JWTOption {
+	return func(j *jwtManager) { j.issuer = issuer }
+}
+
+// WithAudience sets the aud claim, DefaultAudience unless given.
+func WithAudience(audience string) JWTOption {
+	return func(j *jwtManager) { j.audience = audience }
 }
 
 type jwtManager struct {
-	secret []byte
-	ttl    time.Duration
+	secret   []byte
+	keys     *KeySet
+	ttl      time.Duration
+	issuer   string
+	audience string
+}
+
+// NewJWT returns a manager signing HS256 tokens with a shared secret.
+func NewJWT(secret string, ttl time.Duration, opts ...JWTOption) JWT {
+	return newJWTManager(&jwtManager{secret: []byte(secret), ttl: ttl}, opts)
+}
+
+// NewKeySetJWT returns a manager signing tokens with the first key of keys
+// and verifying them with any key in the set, chosen by the kid header.
+func NewKeySetJWT(keys *KeySet, ttl time.Duration, opts ...JWTOption) JWT {
+	return newJWTManager(&jwtManager{keys: keys, ttl: ttl}, opts)
 }
 
-func NewJWT(secret string, ttl time.Duration) JWT {
-	return &jwtManager{
-		secret: []byte(secret),
-		ttl:    ttl,
+func newJWTManager(j *jwtManager, opts []JWTOption) *jwtManager {
+	j.issuer, j.audience = DefaultIssuer, DefaultAudience
+	for _, opt := range opts {
+		opt(j)
 	}
+	return j
 }
 
 func (j *jwtManager) Generate(userID int64) (string, error) {
 	claims := jwt.MapClaims{
 		"sub": userID,
+		"iss": j.issuer,
+		"aud": j.audience,
 		"exp": time.Now().Add(j.ttl).Unix(),
 		"iat": time.Now().Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if j.keys == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(j.secret)
+	}
 
-	return token.SignedString(j.secret)
+	key, err := j.keys.Signing(time.Now())
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
 }
 
 func (j *jwtManager) Verify(tokenString string) (int64, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return j.secret, nil
-	})
+	token, err := jwt.Parse(tokenString, j.verificationKey,
+		jwt.WithIssuer(j.issuer),
+		jwt.WithAudience(j.audience),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return 0, err
@@ -56,3 +103,31 @@ func (j *jwtManager) Verify(tokenString string) (int64, error) {
 
 	return 0, errors.New("invalid token")
 }
+
+// verificationKey finds the key for a token: the shared secret, or the key
+// named by the kid header, provided the token uses that key's algorithm.
+func (j *jwtManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	if j.keys == nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return j.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.keys.lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.Private.Public(), nil
+}
+
+func (j *jwtManager) JWKS() JWKSet {
+	if j.keys == nil {
+		return JWKSet{Keys: []JWK{}}
+	}
+	return j.keys.JWKS()
+}
@@ -1,8 +1,11 @@
 package crypto
 
 import (
+	"crypto/ed25519"
 	"testing"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 func TestJWT(t *testing.T) {
@@ -53,3 +56,122 @@ func TestJWT(t *testing.T) {
 		}
 	})
 }
+
+func TestJWT_Claims(t *testing.T) {
+	secret := "my-super-secret-key"
+	token, err := NewJWT(secret, time.Hour, WithIssuer("other"), WithAudience("other-api")).Generate(1)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	if _, err := NewJWT(secret, time.Hour).Verify(token); err == nil {
+		t.Error("expected a token with another issuer and audience to be rejected")
+	}
+	if _, err := NewJWT(secret, time.Hour, WithIssuer("other")).Verify(token); err == nil {
+		t.Error("expected a token with another audience to be rejected")
+	}
+	if _, err := NewJWT(secret, time.Hour, WithIssuer("other"), WithAudience("other-api")).Verify(token); err != nil {
+		t.Errorf("expected matching claims to verify, got %v", err)
+	}
+}
+
+func TestKeySetJWT(t *testing.T) {
+	for _, alg := range []string{AlgEdDSA, AlgRS256} {
+		t.Run(alg, func(t *testing.T) {
+			key, err := GenerateSigningKey(alg)
+			if err != nil {
+				t.Fatalf("failed to generate key: %v", err)
+			}
+			keys := &KeySet{Keys: []SigningKey{key}}
+			j := NewKeySetJWT(keys, time.Hour)
+
+			token, err := j.Generate(42)
+			if err != nil {
+				t.Fatalf("failed to generate token: %v", err)
+			}
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			if err != nil {
+				t.Fatalf("failed to parse token: %v", err)
+			}
+			if parsed.Header["kid"] != key.ID || parsed.Header["alg"] != alg {
+				t.Errorf("expected kid %s and alg %s, got %v", key.ID, alg, parsed.Header)
+			}
+			if userID, err := j.Verify(token); err != nil || userID != 42 {
+				t.Errorf("expected user 42, got %d %v", userID, err)
+			}
+		})
+	}
+
+	t.Run("rotation", func(t *testing.T) {
+		old, _ := GenerateSigningKey(AlgEdDSA)
+		keys := &KeySet{Keys: []SigningKey{old}}
+		oldToken, _ := NewKeySetJWT(keys, time.Hour).Generate(1)
+
+		next, _ := GenerateSigningKey(AlgRS256)
+		keys.Rotate(next, 2, 0)
+		j := NewKeySetJWT(keys, time.Hour)
+		if _, err := j.Verify(oldToken); err != nil {
+			t.Errorf("expected a token signed before the rotation to verify, got %v", err)
+		}
+		newToken, _ := j.Generate(1)
+		if parsed, _, _ := jwt.NewParser().ParseUnverified(newToken, jwt.MapClaims{}); parsed.Header["kid"] != next.ID {
+			t.Errorf("expected the new key to sign, got kid %v", parsed.Header["kid"])
+		}
+
+		latest, _ := GenerateSigningKey(AlgEdDSA)
+		keys.Rotate(latest, 2, 0)
+		if _, err := NewKeySetJWT(keys, time.Hour).Verify(oldToken); err == nil {
+			t.Error("expected a token signed by a dropped key to be rejected")
+		}
+	})
+
+	t.Run("rotation with a grace period", func(t *testing.T) {
+		current, _ := GenerateSigningKey(AlgEdDSA)
+		keys := &KeySet{Keys: []SigningKey{current}}
+		kid := func() any {
+			token, _ := NewKeySetJWT(keys, time.Hour).Generate(1)
+			parsed, _, _ := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			return parsed.Header["kid"]
+		}
+
+		pending, _ := GenerateSigningKey(AlgEdDSA)
+		keys.Rotate(pending, 1, time.Hour)
+		if len(keys.Keys) != 2 {
+			t.Fatalf("expected the signing key to be kept next to the pending one, got %d keys", len(keys.Keys))
+		}
+		if got := kid(); got != current.ID {
+			t.Errorf("expected the current key to sign during the grace period, got kid %v", got)
+		}
+		if jwks := keys.JWKS(); len(jwks.Keys) != 2 || jwks.Keys[0].KeyID != pending.ID {
+			t.Errorf("expected the pending key to be published, got %+v", jwks.Keys)
+		}
+		if key, err := keys.Signing(time.Now().Add(2 * time.Hour)); err != nil || key.ID != pending.ID {
+			t.Errorf("expected the pending key to sign after the grace period, got %s %v", key.ID, err)
+		}
+
+		latest, _ := GenerateSigningKey(AlgEdDSA)
+		keys.Rotate(latest, 3, time.Hour)
+		if got := kid(); got != pending.ID {
+			t.Errorf("expected the next rotation to activate the pending key, got kid %v", got)
+		}
+	})
+
+	t.Run("rejects other signing methods", func(t *testing.T) {
+		key, _ := GenerateSigningKey(AlgEdDSA)
+		j := NewKeySetJWT(&KeySet{Keys: []SigningKey{key}}, time.Hour)
+
+		hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": 1, "iss": DefaultIssuer, "aud": DefaultAudience, "exp": time.Now().Add(time.Hour).Unix(),
+		})
+		hmac.Header["kid"] = key.ID
+		token, _ := hmac.SignedString([]byte(key.Private.Public().(ed25519.PublicKey)))
+		if _, err := j.Verify(token); err == nil {
+			t.Error("expected an HS256 token naming an EdDSA key to be rejected")
+		}
+
+		shared, _ := NewJWT("my-super-secret-key", time.Hour).Generate(1)
+		if _, err := j.Verify(shared); err == nil {
+			t.Error("expected a token without a known kid to be rejected")
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Signing algorithms supported by KeySet.
+const (
+	AlgEdDSA = "EdDSA"
+	AlgRS256 = "RS256"
+)
+
+const rsaKeyBits = 3072
+
+// SigningKey is a private key used to sign tokens, identified in their kid
+// header.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	CreatedAt time.Time
+	// ActivatesAt is when the key starts signing. Until then it only
+	// verifies, so that it is in the JWKS before any token needs it. Zero
+	// means it signs as soon as it is in the set.
+	ActivatesAt time.Time
+	Private     crypto.Signer
+}
+
+// KeySet holds the keys that sign and verify tokens, newest first. The newest
+// active key signs new tokens. Newer keys are waiting to take over, and older
+// ones were rotated out and only verify tokens issued before the rotation,
+// until those expire.
+type KeySet struct {
+	Keys []SigningKey
+}
+
+// keySetFile is the JSON form of a KeySet, with private keys as PKCS #8 PEM.
+type keySetFile struct {
+	Keys []keyFile `json:"keys"`
+}
+
+type keyFile struct {
+	ID          string    `json:"kid"`
+	Algorithm   string    `json:"alg"`
+	CreatedAt   time.Time `json:"created_at"`
+	ActivatesAt time.Time `json:"activates_at,omitzero"`
+	PrivateKey  string    `json:"private_key"`
+}
+
+// GenerateSigningKey creates a key for alg, AlgEdDSA or AlgRS256.
+func GenerateSigningKey(alg string) (SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch alg {
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	default:
+		return SigningKey{}, fmt.Errorf("unsupported signing algorithm %q: expected %s or %s", alg, AlgEdDSA, AlgRS256)
+	}
+	if err != nil {
+		return SigningKey{}, err
+	}
+	return SigningKey{ID: rand.Text(), Algorithm: alg, CreatedAt: time.Now().UTC(), Private: private}, nil
+}
+
+// Rotate adds key in front. It is published in the JWKS at once but only
+// starts signing new tokens after grace, or at the next rotation if that comes
+// first, so that verifiers caching the JWKS have time to fetch it. At most keep
+// keys are kept, the oldest dropped first; keep is at least 1, or 2 when grace
+// is positive so that the key signing until then stays.
+func (ks *KeySet) Rotate(key SigningKey, keep int, grace time.Duration) {
+	now := time.Now().UTC()
+	for i := range ks.Keys {
+		if ks.Keys[i].ActivatesAt.After(now) {
+			ks.Keys[i].ActivatesAt = now
+		}
+	}
+	key.ActivatesAt = now.Add(grace)
+	ks.Keys = append([]SigningKey{key}, ks.Keys...)
+	if keep < 1 {
+		keep = 1
+	}
+	if grace > 0 && keep < 2 {
+		keep = 2
+	}
+	if len(ks.Keys) > keep {
+		ks.Keys = ks.Keys[:keep]
+	}
+}
+
+// Signing returns the key that signs new tokens at now: the newest key that
+// has activated.
+func (ks *KeySet) Signing(now time.Time) (SigningKey, error) {
+	if len(ks.Keys) == 0 {
+		return SigningKey{}, errors.New("key set is empty")
+	}
+	for _, key := range ks.Keys {
+		if !key.ActivatesAt.After(now) {
+			return key, nil
+		}
+	}
+	return SigningKey{}, errors.New("no key in the set has activated yet")
+}
+
+// lookup returns the key with the given kid.
+func (ks *KeySet) lookup(kid string) (SigningKey, bool) {
+	for _, key := range ks.Keys {
+		if key.ID == kid {
+			return key, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+// LoadKeySet reads a key set written by SaveKeySet.
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file keySetFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid key set %s: %w", path, err)
+	}
+
+	ks := &KeySet{}
+	for _, k := range file.Keys {
+		block, _ := pem.Decode([]byte(k.PrivateKey))
+		if block == nil {
+			return nil, fmt.Errorf("key %s: no PEM private key", k.ID)
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", k.ID, err)
+		}
+		private, ok := parsed.(crypto.Signer)
+		if !ok || !algorithmMatches(k.Algorithm, private) {
+			return nil, fmt.Errorf("key %s: private key does not match algorithm %q", k.ID, k.Algorithm)
+		}
+		ks.Keys = append(ks.Keys, SigningKey{ID: k.ID, Algorithm: k.Algorithm, CreatedAt: k.CreatedAt, ActivatesAt: k.ActivatesAt, Private: private})
+	}
+	if len(ks.Keys) == 0 {
+		return nil, fmt.Errorf("key set %s has no keys", path)
+	}
+	return ks, nil
+}
+
+// SaveKeySet writes ks to path, readable only by the owner. The file is
+// replaced atomically, so a running rotation never leaves it half written.
+func SaveKeySet(path string, ks *KeySet) error {
+	file := keySetFile{}
+	for _, key := range ks.Keys {
+		der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", key.ID, err)
+		}
+		file.Keys = append(file.Keys, keyFile{
+			ID:          key.ID,
+			Algorithm:   key.Algorithm,
+			CreatedAt:   key.CreatedAt,
+			ActivatesAt: key.ActivatesAt,
+			PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		})
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".keys-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func algorithmMatches(alg string, private crypto.Signer) bool {
+	switch private.(type) {
+	case ed25519.PrivateKey:
+		return alg == AlgEdDSA
+	case *rsa.PrivateKey:
+		return alg == AlgRS256
+	}
+	return false
+}
+
+// JWK is a public key in JSON Web Key form (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	// Curve and X describe an Ed25519 key (RFC 8037).
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	// N and E describe an RSA key (RFC 7518).
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public halves of every key in the set, including those
+// that have not started signing yet.
+func (ks *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range ks.Keys {
+		jwk := JWK{KeyID: key.ID, Algorithm: key.Algorithm, Use: "sig"}
+		switch public := key.Private.Public().(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType, jwk.Curve, jwk.X = "OKP", "Ed25519", base64.RawURLEncoding.EncodeToString(public)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
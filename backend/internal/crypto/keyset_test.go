@@ -0,0 +1,85 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeySet_SaveAndLoad(t *testing.T) {
+	edKey, err := GenerateSigningKey(AlgEdDSA)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	rsaKey, err := GenerateSigningKey(AlgRS256)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := SaveKeySet(path, &KeySet{Keys: []SigningKey{edKey, rsaKey}}); err != nil {
+		t.Fatalf("SaveKeySet: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected the key file to be private, got %v", perm)
+	}
+
+	loaded, err := LoadKeySet(path)
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+	if len(loaded.Keys) != 2 || loaded.Keys[0].ID != edKey.ID || loaded.Keys[1].Algorithm != AlgRS256 {
+		t.Fatalf("unexpected keys %+v", loaded.Keys)
+	}
+
+	// A token signed before saving verifies with the loaded set.
+	token, _ := NewKeySetJWT(&KeySet{Keys: []SigningKey{edKey}}, time.Hour).Generate(7)
+	if userID, err := NewKeySetJWT(loaded, time.Hour).Verify(token); err != nil || userID != 7 {
+		t.Errorf("expected the loaded key to verify, got %d %v", userID, err)
+	}
+
+	if _, err := GenerateSigningKey("HS256"); err == nil {
+		t.Error("expected an unsupported algorithm to be refused")
+	}
+	os.WriteFile(path, []byte(`{"keys": []}`), 0o600)
+	if _, err := LoadKeySet(path); err == nil {
+		t.Error("expected an empty key set to be refused")
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	edKey, _ := GenerateSigningKey(AlgEdDSA)
+	rsaKey, _ := GenerateSigningKey(AlgRS256)
+	set := (&KeySet{Keys: []SigningKey{edKey, rsaKey}}).JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %+v", set)
+	}
+
+	ed := set.Keys[0]
+	x, _ := base64.RawURLEncoding.DecodeString(ed.X)
+	if ed.KeyType != "OKP" || ed.Curve != "Ed25519" || ed.KeyID != edKey.ID || ed.Use != "sig" ||
+		!ed25519.PublicKey(x).Equal(edKey.Private.Public()) {
+		t.Errorf("unexpected Ed25519 JWK %+v", ed)
+	}
+
+	r := set.Keys[1]
+	n, _ := base64.RawURLEncoding.DecodeString(r.N)
+	e, _ := base64.RawURLEncoding.DecodeString(r.E)
+	public := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	if r.KeyType != "RSA" || r.Algorithm != AlgRS256 || !public.Equal(rsaKey.Private.Public()) {
+		t.Errorf("unexpected RSA JWK %+v", r)
+	}
+
+	if keys := NewJWT("secret", time.Hour).JWKS().Keys; len(keys) != 0 {
+		t.Errorf("expected no published keys for a shared secret, got %+v", keys)
+	}
+}
@@ -8,6 +8,25 @@ import (
 	"time"
 )
 
+type AuditLog struct {
+	ID          int64     `json:"id"`
+	ActorUserID int64     `json:"actor_user_id"`
+	Action      string    `json:"action"`
+	EntityType  string    `json:"entity_type"`
+	EntityID    int64     `json:"entity_id"`
+	BeforeValue string    `json:"before_value"`
+	AfterValue  string    `json:"after_value"`
+	RequestID   string    `json:"request_id"`
+	IpAddress   string    `json:"ip_address"`
+	UserAgent   string    `json:"user_agent"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type AuditLogSubject struct {
+	AuditLogID int64 `json:"audit_log_id"`
+	UserID     int64 `json:"user_id"`
+}
+
 type Event struct {
 	ID        int64     `json:"id"`
 	Title     string    `json:"title"`
@@ -17,24 +36,67 @@ type Event struct {
 	UserID    int64     `json:"user_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	TimeZone  string    `json:"time_zone"`
+	Version   int64     `json:"version"`
+	Giveaway  *string   `json:"giveaway"`
 }
 
-type SwapRequest struct {
-	ID              int64     `json:"id"`
-	RequesterUserID int64     `json:"requester_user_id"`
-	ResponderUserID int64     `json:"responder_user_id"`
-	RequesterSlotID int64     `json:"requester_slot_id"`
-	ResponderSlotID int64     `json:"responder_slot_id"`
-	Status          string    `json:"status"`
+type IdempotencyKey struct {
+	UserID          int64     `json:"user_id"`
+	IdempotencyKey  string    `json:"idempotency_key"`
+	Fingerprint     string    `json:"fingerprint"`
+	StatusCode      int64     `json:"status_code"`
+	ResponseBody    string    `json:"response_body"`
 	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	ResponseHeaders string    `json:"response_headers"`
+}
+
+type Notification struct {
+	ID            int64      `json:"id"`
+	UserID        int64      `json:"user_id"`
+	Kind          string     `json:"kind"`
+	SwapRequestID *int64     `json:"swap_request_id"`
+	Reason        string     `json:"reason"`
+	Message       string     `json:"message"`
+	ReadAt        *time.Time `json:"read_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type PersonalAccessToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"token_hash"`
+	Scopes     string     `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type SwapRequest struct {
+	ID               int64      `json:"id"`
+	RequesterUserID  int64      `json:"requester_user_id"`
+	ResponderUserID  int64      `json:"responder_user_id"`
+	RequesterSlotID  *int64     `json:"requester_slot_id"`
+	ResponderSlotID  int64      `json:"responder_slot_id"`
+	Status           string     `json:"status"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	ResolvedByUserID *int64     `json:"resolved_by_user_id"`
+	ResolvedAt       *time.Time `json:"resolved_at"`
+	CancelReason     *string    `json:"cancel_reason"`
+	Kind             string     `json:"kind"`
 }
 
 type User struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Password  string    `json:"password"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            int64      `json:"id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Password      string     `json:"password"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	IsAdmin       bool       `json:"is_admin"`
+	TimeZone      string     `json:"time_zone"`
+	DeactivatedAt *time.Time `json:"deactivated_at"`
 }
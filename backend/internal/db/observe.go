@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// QueryObserver is told the name and duration of every query run through
+// queries created with NewObserved. The name is the one given to the query
+// in queries.sql.
+type QueryObserver func(name string, duration time.Duration)
+
+// NewObserved returns queries running on conn that report each query to
+// observe, including queries run in transactions started with BeginTx and
+// bound with Bind.
+func NewObserved(conn DBTX, observe QueryObserver) *Queries {
+	return &Queries{db: &observedDBTX{DBTX: conn, observe: observe}}
+}
+
+// Bind returns queries running on tx. Unlike WithTx, it keeps reporting to
+// the observer of q.
+func (q *Queries) Bind(tx *sql.Tx) *Queries {
+	if o, ok := q.db.(*observedDBTX); ok {
+		return &Queries{db: &observedDBTX{DBTX: tx, observe: o.observe}}
+	}
+	return q.WithTx(tx)
+}
+
+type observedDBTX struct {
+	DBTX
+	observe QueryObserver
+}
+
+func (o *observedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer o.done(query, time.Now())
+	return o.DBTX.ExecContext(ctx, query, args...)
+}
+
+func (o *observedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer o.done(query, time.Now())
+	return o.DBTX.QueryContext(ctx, query, args...)
+}
+
+func (o *observedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer o.done(query, time.Now())
+	return o.DBTX.QueryRowContext(ctx, query, args...)
+}
+
+func (o *observedDBTX) done(query string, start time.Time) {
+	o.observe(queryName(query), time.Since(start))
+}
+
+// queryName extracts the name from the "-- name: CreateUser :one" comment
+// sqlc puts in front of every generated query.
+func queryName(query string) string {
+	rest, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return "unknown"
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
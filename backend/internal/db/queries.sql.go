@@ -7,23 +7,218 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
+const addAuditLogSubject = `-- name: AddAuditLogSubject :exec
+INSERT OR IGNORE INTO audit_log_subjects (
+    audit_log_id,
+    user_id
+) VALUES (
+    ?,
+    ?
+)
+`
+
+type AddAuditLogSubjectParams struct {
+	AuditLogID int64 `json:"audit_log_id"`
+	UserID     int64 `json:"user_id"`
+}
+
+func (q *Queries) AddAuditLogSubject(ctx context.Context, arg AddAuditLogSubjectParams) error {
+	_, err := q.db.ExecContext(ctx, addAuditLogSubject, arg.AuditLogID, arg.UserID)
+	return err
+}
+
+const cancelSwapRequest = `-- name: CancelSwapRequest :one
+UPDATE swap_requests
+SET status = 'CANCELLED',
+    cancel_reason = ?,
+    resolved_by_user_id = ?,
+    resolved_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at, resolved_by_user_id, resolved_at, cancel_reason, kind
+`
+
+type CancelSwapRequestParams struct {
+	CancelReason     *string `json:"cancel_reason"`
+	ResolvedByUserID *int64  `json:"resolved_by_user_id"`
+	ID               int64   `json:"id"`
+}
+
+func (q *Queries) CancelSwapRequest(ctx context.Context, arg CancelSwapRequestParams) (SwapRequest, error) {
+	row := q.db.QueryRowContext(ctx, cancelSwapRequest, arg.CancelReason, arg.ResolvedByUserID, arg.ID)
+	var i SwapRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterUserID,
+		&i.ResponderUserID,
+		&i.RequesterSlotID,
+		&i.ResponderSlotID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ResolvedByUserID,
+		&i.ResolvedAt,
+		&i.CancelReason,
+		&i.Kind,
+	)
+	return i, err
+}
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status_code = ?,
+    response_headers = ?,
+    response_body = ?
+WHERE user_id = ? AND idempotency_key = ?
+`
+
+type CompleteIdempotencyKeyParams struct {
+	StatusCode      int64  `json:"status_code"`
+	ResponseHeaders string `json:"response_headers"`
+	ResponseBody    string `json:"response_body"`
+	UserID          int64  `json:"user_id"`
+	IdempotencyKey  string `json:"idempotency_key"`
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, completeIdempotencyKey,
+		arg.StatusCode,
+		arg.ResponseHeaders,
+		arg.ResponseBody,
+		arg.UserID,
+		arg.IdempotencyKey,
+	)
+	return err
+}
+
+const countAuditLogSubjectEntries = `-- name: CountAuditLogSubjectEntries :one
+SELECT COUNT(*) FROM audit_logs a
+JOIN audit_log_subjects s ON s.audit_log_id = a.id
+WHERE a.entity_type = ? AND a.entity_id = ? AND s.user_id = ?
+`
+
+type CountAuditLogSubjectEntriesParams struct {
+	EntityType string `json:"entity_type"`
+	EntityID   int64  `json:"entity_id"`
+	UserID     int64  `json:"user_id"`
+}
+
+func (q *Queries) CountAuditLogSubjectEntries(ctx context.Context, arg CountAuditLogSubjectEntriesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAuditLogSubjectEntries, arg.EntityType, arg.EntityID, arg.UserID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countEventsByStatus = `-- name: CountEventsByStatus :one
+SELECT COUNT(*) FROM events
+WHERE status = ?
+`
+
+func (q *Queries) CountEventsByStatus(ctx context.Context, status string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countEventsByStatus, status)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSwapRequestsByStatus = `-- name: CountSwapRequestsByStatus :one
+SELECT COUNT(*) FROM swap_requests
+WHERE status = ?
+`
+
+func (q *Queries) CountSwapRequestsByStatus(ctx context.Context, status string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSwapRequestsByStatus, status)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAuditLog = `-- name: CreateAuditLog :one
+INSERT INTO audit_logs (
+    actor_user_id,
+    action,
+    entity_type,
+    entity_id,
+    before_value,
+    after_value,
+    request_id,
+    ip_address,
+    user_agent
+) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+) RETURNING id, actor_user_id, "action", entity_type, entity_id, before_value, after_value, request_id, ip_address, user_agent, created_at
+`
+
+type CreateAuditLogParams struct {
+	ActorUserID int64  `json:"actor_user_id"`
+	Action      string `json:"action"`
+	EntityType  string `json:"entity_type"`
+	EntityID    int64  `json:"entity_id"`
+	BeforeValue string `json:"before_value"`
+	AfterValue  string `json:"after_value"`
+	RequestID   string `json:"request_id"`
+	IpAddress   string `json:"ip_address"`
+	UserAgent   string `json:"user_agent"`
+}
+
+func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error) {
+	row := q.db.QueryRowContext(ctx, createAuditLog,
+		arg.ActorUserID,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.BeforeValue,
+		arg.AfterValue,
+		arg.RequestID,
+		arg.IpAddress,
+		arg.UserAgent,
+	)
+	var i AuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.ActorUserID,
+		&i.Action,
+		&i.EntityType,
+		&i.EntityID,
+		&i.BeforeValue,
+		&i.AfterValue,
+		&i.RequestID,
+		&i.IpAddress,
+		&i.UserAgent,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createEvent = `-- name: CreateEvent :one
 INSERT INTO events (
     title,
     start_time,
     end_time,
     status,
-    user_id
+    user_id,
+    time_zone
 ) VALUES (
     ?,
     ?,
     ?,
     ?,
+    ?,
     ?
-) RETURNING id, title, start_time, end_time, status, user_id, created_at, updated_at
+) RETURNING id, title, start_time, end_time, status, user_id, created_at, updated_at, time_zone, version, giveaway
 `
 
 type CreateEventParams struct {
@@ -32,6 +227,7 @@ type CreateEventParams struct {
 	EndTime   time.Time `json:"end_time"`
 	Status    string    `json:"status"`
 	UserID    int64     `json:"user_id"`
+	TimeZone  string    `json:"time_zone"`
 }
 
 func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event, error) {
@@ -41,6 +237,7 @@ func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event
 		arg.EndTime,
 		arg.Status,
 		arg.UserID,
+		arg.TimeZone,
 	)
 	var i Event
 	err := row.Scan(
@@ -52,6 +249,140 @@ func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TimeZone,
+		&i.Version,
+		&i.Giveaway,
+	)
+	return i, err
+}
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (
+    user_id,
+    idempotency_key,
+    fingerprint,
+    created_at,
+    expires_at
+) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+ON CONFLICT (user_id, idempotency_key) DO NOTHING
+`
+
+type CreateIdempotencyKeyParams struct {
+	UserID         int64     `json:"user_id"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	Fingerprint    string    `json:"fingerprint"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createIdempotencyKey,
+		arg.UserID,
+		arg.IdempotencyKey,
+		arg.Fingerprint,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createNotification = `-- name: CreateNotification :one
+INSERT INTO notifications (
+    user_id,
+    kind,
+    swap_request_id,
+    reason,
+    message
+) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+) RETURNING id, user_id, kind, swap_request_id, reason, message, read_at, created_at
+`
+
+type CreateNotificationParams struct {
+	UserID        int64  `json:"user_id"`
+	Kind          string `json:"kind"`
+	SwapRequestID *int64 `json:"swap_request_id"`
+	Reason        string `json:"reason"`
+	Message       string `json:"message"`
+}
+
+func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error) {
+	row := q.db.QueryRowContext(ctx, createNotification,
+		arg.UserID,
+		arg.Kind,
+		arg.SwapRequestID,
+		arg.Reason,
+		arg.Message,
+	)
+	var i Notification
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Kind,
+		&i.SwapRequestID,
+		&i.Reason,
+		&i.Message,
+		&i.ReadAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createPersonalAccessToken = `-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens (
+    user_id,
+    name,
+    token_hash,
+    scopes,
+    expires_at
+) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+) RETURNING id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at
+`
+
+type CreatePersonalAccessTokenParams struct {
+	UserID    int64     `json:"user_id"`
+	Name      string    `json:"name"`
+	TokenHash string    `json:"token_hash"`
+	Scopes    string    `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) (PersonalAccessToken, error) {
+	row := q.db.QueryRowContext(ctx, createPersonalAccessToken,
+		arg.UserID,
+		arg.Name,
+		arg.TokenHash,
+		arg.Scopes,
+		arg.ExpiresAt,
+	)
+	var i PersonalAccessToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenHash,
+		&i.Scopes,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -62,22 +393,25 @@ INSERT INTO swap_requests (
     responder_user_id,
     requester_slot_id,
     responder_slot_id,
-    status
+    status,
+    kind
 ) VALUES (
     ?,
     ?,
     ?,
     ?,
+    ?,
     ?
-) RETURNING id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at
+) RETURNING id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at, resolved_by_user_id, resolved_at, cancel_reason, kind
 `
 
 type CreateSwapRequestParams struct {
 	RequesterUserID int64  `json:"requester_user_id"`
 	ResponderUserID int64  `json:"responder_user_id"`
-	RequesterSlotID int64  `json:"requester_slot_id"`
+	RequesterSlotID *int64 `json:"requester_slot_id"`
 	ResponderSlotID int64  `json:"responder_slot_id"`
 	Status          string `json:"status"`
+	Kind            string `json:"kind"`
 }
 
 func (q *Queries) CreateSwapRequest(ctx context.Context, arg CreateSwapRequestParams) (SwapRequest, error) {
@@ -87,6 +421,7 @@ func (q *Queries) CreateSwapRequest(ctx context.Context, arg CreateSwapRequestPa
 		arg.RequesterSlotID,
 		arg.ResponderSlotID,
 		arg.Status,
+		arg.Kind,
 	)
 	var i SwapRequest
 	err := row.Scan(
@@ -98,6 +433,10 @@ func (q *Queries) CreateSwapRequest(ctx context.Context, arg CreateSwapRequestPa
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ResolvedByUserID,
+		&i.ResolvedAt,
+		&i.CancelReason,
+		&i.Kind,
 	)
 	return i, err
 }
@@ -111,7 +450,7 @@ INSERT INTO users (
     ?,
     ?,
     ?
-) RETURNING id, name, email, password, created_at, updated_at
+) RETURNING id, name, email, password, created_at, updated_at, is_admin, time_zone, deactivated_at
 `
 
 type CreateUserParams struct {
@@ -130,10 +469,25 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsAdmin,
+		&i.TimeZone,
+		&i.DeactivatedAt,
 	)
 	return i, err
 }
 
+const deactivateUser = `-- name: DeactivateUser :exec
+UPDATE users
+SET deactivated_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+func (q *Queries) DeactivateUser(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deactivateUser, id)
+	return err
+}
+
 const deleteEvent = `-- name: DeleteEvent :exec
 DELETE FROM events
 WHERE id = ?
@@ -144,18 +498,53 @@ func (q *Queries) DeleteEvent(ctx context.Context, id int64) error {
 	return err
 }
 
-const deleteSwapRequest = `-- name: DeleteSwapRequest :exec
-DELETE FROM swap_requests
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE expires_at <= ?
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, expiresAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredIdempotencyKeys, expiresAt)
+	return err
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = ? AND idempotency_key = ?
+`
+
+type DeleteIdempotencyKeyParams struct {
+	UserID         int64  `json:"user_id"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKey, arg.UserID, arg.IdempotencyKey)
+	return err
+}
+
+const deletePersonalAccessToken = `-- name: DeletePersonalAccessToken :exec
+DELETE FROM personal_access_tokens
 WHERE id = ?
 `
 
-func (q *Queries) DeleteSwapRequest(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteSwapRequest, id)
+func (q *Queries) DeletePersonalAccessToken(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deletePersonalAccessToken, id)
+	return err
+}
+
+const deletePersonalAccessTokensByUserID = `-- name: DeletePersonalAccessTokensByUserID :exec
+DELETE FROM personal_access_tokens
+WHERE user_id = ?
+`
+
+func (q *Queries) DeletePersonalAccessTokensByUserID(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deletePersonalAccessTokensByUserID, userID)
 	return err
 }
 
 const getEventByID = `-- name: GetEventByID :one
-SELECT id, title, start_time, end_time, status, user_id, created_at, updated_at FROM events
+SELECT id, title, start_time, end_time, status, user_id, created_at, updated_at, time_zone, version, giveaway FROM events
 WHERE id = ?
 `
 
@@ -171,33 +560,1215 @@ func (q *Queries) GetEventByID(ctx context.Context, id int64) (Event, error) {
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TimeZone,
+		&i.Version,
+		&i.Giveaway,
+	)
+	return i, err
+}
+
+const getEventsByUserID = `-- name: GetEventsByUserID :many
+SELECT id, title, start_time, end_time, status, user_id, created_at, updated_at, time_zone, version, giveaway FROM events
+WHERE user_id = ?
+`
+
+func (q *Queries) GetEventsByUserID(ctx context.Context, userID int64) ([]Event, error) {
+	rows, err := q.db.QueryContext(ctx, getEventsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Status,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TimeZone,
+			&i.Version,
+			&i.Giveaway,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEventsByUserIDAndStatus = `-- name: GetEventsByUserIDAndStatus :many
+SELECT id, title, start_time, end_time, status, user_id, created_at, updated_at, time_zone, version, giveaway FROM events
+WHERE user_id = ? AND status = ?
+`
+
+type GetEventsByUserIDAndStatusParams struct {
+	UserID int64  `json:"user_id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) GetEventsByUserIDAndStatus(ctx context.Context, arg GetEventsByUserIDAndStatusParams) ([]Event, error) {
+	rows, err := q.db.QueryContext(ctx, getEventsByUserIDAndStatus, arg.UserID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Status,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TimeZone,
+			&i.Version,
+			&i.Giveaway,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT user_id, idempotency_key, fingerprint, status_code, response_body, created_at, expires_at, response_headers FROM idempotency_keys
+WHERE user_id = ? AND idempotency_key = ?
+`
+
+type GetIdempotencyKeyParams struct {
+	UserID         int64  `json:"user_id"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, getIdempotencyKey, arg.UserID, arg.IdempotencyKey)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.UserID,
+		&i.IdempotencyKey,
+		&i.Fingerprint,
+		&i.StatusCode,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.ResponseHeaders,
 	)
 	return i, err
 }
 
-const getEventsByUserID = `-- name: GetEventsByUserID :many
-SELECT id, title, start_time, end_time, status, user_id, created_at, updated_at FROM events
-WHERE user_id = ?
-`
+const getIncomingSwapRequestHistory = `-- name: GetIncomingSwapRequestHistory :many
+SELECT
+    id,
+    status,
+    kind,
+    requester_user_id,
+    requester_name,
+    requester_slot_id,
+    requester_event_title,
+    requester_event_start_time,
+    requester_event_end_time,
+    responder_slot_id,
+    responder_event_title,
+    responder_event_start_time,
+    responder_event_end_time,
+    resolved_by_user_id,
+    resolved_by_name,
+    resolved_at,
+    cancel_reason,
+    created_at,
+    updated_at,
+    sort_key
+FROM (
+    SELECT
+        sr.id,
+        sr.status,
+        sr.kind,
+        sr.requester_user_id,
+        requester.name AS requester_name,
+        sr.requester_slot_id,
+        COALESCE(requester_event.title, '') AS requester_event_title,
+        requester_event.start_time AS requester_event_start_time,
+        requester_event.end_time AS requester_event_end_time,
+        sr.responder_slot_id,
+        COALESCE(responder_event.title, '') AS responder_event_title,
+        responder_event.start_time AS responder_event_start_time,
+        responder_event.end_time AS responder_event_end_time,
+        sr.resolved_by_user_id,
+        COALESCE(resolver.name, '') AS resolved_by_name,
+        sr.resolved_at,
+        sr.cancel_reason,
+        sr.created_at,
+        sr.updated_at,
+        CAST(CASE
+            WHEN CAST(?1 AS TEXT) = 'created_at' THEN julianday(sr.created_at)
+            WHEN CAST(?1 AS TEXT) = '-created_at' THEN -julianday(sr.created_at)
+            WHEN CAST(?1 AS TEXT) = 'resolved_at' THEN COALESCE(julianday(sr.resolved_at), 1e7)
+            ELSE COALESCE(-julianday(sr.resolved_at), 1e7)
+        END AS REAL) AS sort_key
+    FROM
+        swap_requests sr
+    JOIN
+        users requester ON sr.requester_user_id = requester.id
+    LEFT JOIN
+        events requester_event ON sr.requester_slot_id = requester_event.id
+    LEFT JOIN
+        events responder_event ON sr.responder_slot_id = responder_event.id
+    LEFT JOIN
+        users resolver ON sr.resolved_by_user_id = resolver.id
+    WHERE
+        sr.responder_user_id = ?2
+        AND sr.status = COALESCE(?3, sr.status)
+        AND sr.requester_user_id = COALESCE(?4, sr.requester_user_id)
+        AND sr.created_at >= COALESCE(?5, sr.created_at)
+        AND sr.created_at <= COALESCE(?6, sr.created_at)
+        -- Keyset condition: rows strictly after (after_sort_key, after_id) in
+        -- the "sort_key ASC, id DESC" order below.
+        AND (
+            CASE
+                WHEN CAST(?1 AS TEXT) = 'created_at' THEN julianday(sr.created_at)
+                WHEN CAST(?1 AS TEXT) = '-created_at' THEN -julianday(sr.created_at)
+                WHEN CAST(?1 AS TEXT) = 'resolved_at' THEN COALESCE(julianday(sr.resolved_at), 1e7)
+                ELSE COALESCE(-julianday(sr.resolved_at), 1e7)
+            END > CAST(?7 AS REAL)
+            OR (
+                CASE
+                WHEN CAST(?1 AS TEXT) = 'created_at' THEN julianday(sr.created_at)
+                WHEN CAST(?1 AS TEXT) = '-created_at' THEN -julianday(sr.created_at)
+                WHEN CAST(?1 AS TEXT) = 'resolved_at' THEN COALESCE(julianday(sr.resolved_at), 1e7)
+                ELSE COALESCE(-julianday(sr.resolved_at), 1e7)
+            END = CAST(?7 AS REAL)
+                AND sr.id < ?8
+            )
+        )
+) AS history
+ORDER BY history.sort_key, history.id DESC
+LIMIT ?9
+`
+
+type GetIncomingSwapRequestHistoryParams struct {
+	Sort           string         `json:"sort"`
+	UserID         int64          `json:"user_id"`
+	Status         sql.NullString `json:"status"`
+	CounterpartyID sql.NullInt64  `json:"counterparty_id"`
+	CreatedFrom    *time.Time     `json:"created_from"`
+	CreatedTo      *time.Time     `json:"created_to"`
+	AfterSortKey   float64        `json:"after_sort_key"`
+	AfterID        int64          `json:"after_id"`
+	Limit          int64          `json:"limit"`
+}
+
+type GetIncomingSwapRequestHistoryRow struct {
+	ID                      int64      `json:"id"`
+	Status                  string     `json:"status"`
+	Kind                    string     `json:"kind"`
+	RequesterUserID         int64      `json:"requester_user_id"`
+	RequesterName           string     `json:"requester_name"`
+	RequesterSlotID         *int64     `json:"requester_slot_id"`
+	RequesterEventTitle     string     `json:"requester_event_title"`
+	RequesterEventStartTime *time.Time `json:"requester_event_start_time"`
+	RequesterEventEndTime   *time.Time `json:"requester_event_end_time"`
+	ResponderSlotID         int64      `json:"responder_slot_id"`
+	ResponderEventTitle     string     `json:"responder_event_title"`
+	ResponderEventStartTime *time.Time `json:"responder_event_start_time"`
+	ResponderEventEndTime   *time.Time `json:"responder_event_end_time"`
+	ResolvedByUserID        *int64     `json:"resolved_by_user_id"`
+	ResolvedByName          string     `json:"resolved_by_name"`
+	ResolvedAt              *time.Time `json:"resolved_at"`
+	CancelReason            *string    `json:"cancel_reason"`
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
+	SortKey                 float64    `json:"sort_key"`
+}
+
+func (q *Queries) GetIncomingSwapRequestHistory(ctx context.Context, arg GetIncomingSwapRequestHistoryParams) ([]GetIncomingSwapRequestHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getIncomingSwapRequestHistory,
+		arg.Sort,
+		arg.UserID,
+		arg.Status,
+		arg.CounterpartyID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.AfterSortKey,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetIncomingSwapRequestHistoryRow
+	for rows.Next() {
+		var i GetIncomingSwapRequestHistoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.Kind,
+			&i.RequesterUserID,
+			&i.RequesterName,
+			&i.RequesterSlotID,
+			&i.RequesterEventTitle,
+			&i.RequesterEventStartTime,
+			&i.RequesterEventEndTime,
+			&i.ResponderSlotID,
+			&i.ResponderEventTitle,
+			&i.ResponderEventStartTime,
+			&i.ResponderEventEndTime,
+			&i.ResolvedByUserID,
+			&i.ResolvedByName,
+			&i.ResolvedAt,
+			&i.CancelReason,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SortKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOutgoingSwapRequestHistory = `-- name: GetOutgoingSwapRequestHistory :many
+SELECT
+    id,
+    status,
+    kind,
+    responder_user_id,
+    responder_name,
+    requester_slot_id,
+    requester_event_title,
+    requester_event_start_time,
+    requester_event_end_time,
+    responder_slot_id,
+    responder_event_title,
+    responder_event_start_time,
+    responder_event_end_time,
+    resolved_by_user_id,
+    resolved_by_name,
+    resolved_at,
+    cancel_reason,
+    created_at,
+    updated_at,
+    sort_key
+FROM (
+    SELECT
+        sr.id,
+        sr.status,
+        sr.kind,
+        sr.responder_user_id,
+        responder.name AS responder_name,
+        sr.requester_slot_id,
+        COALESCE(requester_event.title, '') AS requester_event_title,
+        requester_event.start_time AS requester_event_start_time,
+        requester_event.end_time AS requester_event_end_time,
+        sr.responder_slot_id,
+        COALESCE(responder_event.title, '') AS responder_event_title,
+        responder_event.start_time AS responder_event_start_time,
+        responder_event.end_time AS responder_event_end_time,
+        sr.resolved_by_user_id,
+        COALESCE(resolver.name, '') AS resolved_by_name,
+        sr.resolved_at,
+        sr.cancel_reason,
+        sr.created_at,
+        sr.updated_at,
+        CAST(CASE
+            WHEN CAST(?1 AS TEXT) = 'created_at' THEN julianday(sr.created_at)
+            WHEN CAST(?1 AS TEXT) = '-created_at' THEN -julianday(sr.created_at)
+            WHEN CAST(?1 AS TEXT) = 'resolved_at' THEN COALESCE(julianday(sr.resolved_at), 1e7)
+            ELSE COALESCE(-julianday(sr.resolved_at), 1e7)
+        END AS REAL) AS sort_key
+    FROM
+        swap_requests sr
+    JOIN
+        users responder ON sr.responder_user_id = responder.id
+    LEFT JOIN
+        events requester_event ON sr.requester_slot_id = requester_event.id
+    LEFT JOIN
+        events responder_event ON sr.responder_slot_id = responder_event.id
+    LEFT JOIN
+        users resolver ON sr.resolved_by_user_id = resolver.id
+    WHERE
+        sr.requester_user_id = ?2
+        AND sr.status = COALESCE(?3, sr.status)
+        AND sr.responder_user_id = COALESCE(?4, sr.responder_user_id)
+        AND sr.created_at >= COALESCE(?5, sr.created_at)
+        AND sr.created_at <= COALESCE(?6, sr.created_at)
+        -- Keyset condition: rows strictly after (after_sort_key, after_id) in
+        -- the "sort_key ASC, id DESC" order below.
+        AND (
+            CASE
+                WHEN CAST(?1 AS TEXT) = 'created_at' THEN julianday(sr.created_at)
+                WHEN CAST(?1 AS TEXT) = '-created_at' THEN -julianday(sr.created_at)
+                WHEN CAST(?1 AS TEXT) = 'resolved_at' THEN COALESCE(julianday(sr.resolved_at), 1e7)
+                ELSE COALESCE(-julianday(sr.resolved_at), 1e7)
+            END > CAST(?7 AS REAL)
+            OR (
+                CASE
+                WHEN CAST(?1 AS TEXT) = 'created_at' THEN julianday(sr.created_at)
+                WHEN CAST(?1 AS TEXT) = '-created_at' THEN -julianday(sr.created_at)
+                WHEN CAST(?1 AS TEXT) = 'resolved_at' THEN COALESCE(julianday(sr.resolved_at), 1e7)
+                ELSE COALESCE(-julianday(sr.resolved_at), 1e7)
+            END = CAST(?7 AS REAL)
+                AND sr.id < ?8
+            )
+        )
+) AS history
+ORDER BY history.sort_key, history.id DESC
+LIMIT ?9
+`
+
+type GetOutgoingSwapRequestHistoryParams struct {
+	Sort           string         `json:"sort"`
+	UserID         int64          `json:"user_id"`
+	Status         sql.NullString `json:"status"`
+	CounterpartyID sql.NullInt64  `json:"counterparty_id"`
+	CreatedFrom    *time.Time     `json:"created_from"`
+	CreatedTo      *time.Time     `json:"created_to"`
+	AfterSortKey   float64        `json:"after_sort_key"`
+	AfterID        int64          `json:"after_id"`
+	Limit          int64          `json:"limit"`
+}
+
+type GetOutgoingSwapRequestHistoryRow struct {
+	ID                      int64      `json:"id"`
+	Status                  string     `json:"status"`
+	Kind                    string     `json:"kind"`
+	ResponderUserID         int64      `json:"responder_user_id"`
+	ResponderName           string     `json:"responder_name"`
+	RequesterSlotID         *int64     `json:"requester_slot_id"`
+	RequesterEventTitle     string     `json:"requester_event_title"`
+	RequesterEventStartTime *time.Time `json:"requester_event_start_time"`
+	RequesterEventEndTime   *time.Time `json:"requester_event_end_time"`
+	ResponderSlotID         int64      `json:"responder_slot_id"`
+	ResponderEventTitle     string     `json:"responder_event_title"`
+	ResponderEventStartTime *time.Time `json:"responder_event_start_time"`
+	ResponderEventEndTime   *time.Time `json:"responder_event_end_time"`
+	ResolvedByUserID        *int64     `json:"resolved_by_user_id"`
+	ResolvedByName          string     `json:"resolved_by_name"`
+	ResolvedAt              *time.Time `json:"resolved_at"`
+	CancelReason            *string    `json:"cancel_reason"`
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
+	SortKey                 float64    `json:"sort_key"`
+}
+
+func (q *Queries) GetOutgoingSwapRequestHistory(ctx context.Context, arg GetOutgoingSwapRequestHistoryParams) ([]GetOutgoingSwapRequestHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOutgoingSwapRequestHistory,
+		arg.Sort,
+		arg.UserID,
+		arg.Status,
+		arg.CounterpartyID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.AfterSortKey,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOutgoingSwapRequestHistoryRow
+	for rows.Next() {
+		var i GetOutgoingSwapRequestHistoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.Kind,
+			&i.ResponderUserID,
+			&i.ResponderName,
+			&i.RequesterSlotID,
+			&i.RequesterEventTitle,
+			&i.RequesterEventStartTime,
+			&i.RequesterEventEndTime,
+			&i.ResponderSlotID,
+			&i.ResponderEventTitle,
+			&i.ResponderEventStartTime,
+			&i.ResponderEventEndTime,
+			&i.ResolvedByUserID,
+			&i.ResolvedByName,
+			&i.ResolvedAt,
+			&i.CancelReason,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SortKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingSwapRequestsByUserID = `-- name: GetPendingSwapRequestsByUserID :many
+SELECT id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at, resolved_by_user_id, resolved_at, cancel_reason, kind FROM swap_requests
+WHERE status = 'PENDING'
+    AND (requester_user_id = ?1 OR responder_user_id = ?1)
+ORDER BY id
+`
+
+func (q *Queries) GetPendingSwapRequestsByUserID(ctx context.Context, userID int64) ([]SwapRequest, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingSwapRequestsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SwapRequest
+	for rows.Next() {
+		var i SwapRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.RequesterUserID,
+			&i.ResponderUserID,
+			&i.RequesterSlotID,
+			&i.ResponderSlotID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ResolvedByUserID,
+			&i.ResolvedAt,
+			&i.CancelReason,
+			&i.Kind,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPersonalAccessTokenByHash = `-- name: GetPersonalAccessTokenByHash :one
+SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at FROM personal_access_tokens
+WHERE token_hash = ?
+`
+
+func (q *Queries) GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (PersonalAccessToken, error) {
+	row := q.db.QueryRowContext(ctx, getPersonalAccessTokenByHash, tokenHash)
+	var i PersonalAccessToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenHash,
+		&i.Scopes,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPersonalAccessTokenByID = `-- name: GetPersonalAccessTokenByID :one
+SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at FROM personal_access_tokens
+WHERE id = ?
+`
+
+func (q *Queries) GetPersonalAccessTokenByID(ctx context.Context, id int64) (PersonalAccessToken, error) {
+	row := q.db.QueryRowContext(ctx, getPersonalAccessTokenByID, id)
+	var i PersonalAccessToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenHash,
+		&i.Scopes,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPublicUserByID = `-- name: GetPublicUserByID :one
+SELECT id, name, created_at, updated_at FROM users
+WHERE id = ? AND deactivated_at IS NULL
+`
+
+type GetPublicUserByIDRow struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) GetPublicUserByID(ctx context.Context, id int64) (GetPublicUserByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getPublicUserByID, id)
+	var i GetPublicUserByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getSwapRequestByID = `-- name: GetSwapRequestByID :one
+SELECT id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at, resolved_by_user_id, resolved_at, cancel_reason, kind FROM swap_requests
+WHERE id = ?
+`
+
+func (q *Queries) GetSwapRequestByID(ctx context.Context, id int64) (SwapRequest, error) {
+	row := q.db.QueryRowContext(ctx, getSwapRequestByID, id)
+	var i SwapRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterUserID,
+		&i.ResponderUserID,
+		&i.RequesterSlotID,
+		&i.ResponderSlotID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ResolvedByUserID,
+		&i.ResolvedAt,
+		&i.CancelReason,
+		&i.Kind,
+	)
+	return i, err
+}
+
+const getSwapRequestsByEventID = `-- name: GetSwapRequestsByEventID :many
+SELECT id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at, resolved_by_user_id, resolved_at, cancel_reason, kind FROM swap_requests
+WHERE requester_slot_id = ? OR responder_slot_id = ?
+`
+
+type GetSwapRequestsByEventIDParams struct {
+	RequesterSlotID *int64 `json:"requester_slot_id"`
+	ResponderSlotID int64  `json:"responder_slot_id"`
+}
+
+func (q *Queries) GetSwapRequestsByEventID(ctx context.Context, arg GetSwapRequestsByEventIDParams) ([]SwapRequest, error) {
+	rows, err := q.db.QueryContext(ctx, getSwapRequestsByEventID, arg.RequesterSlotID, arg.ResponderSlotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SwapRequest
+	for rows.Next() {
+		var i SwapRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.RequesterUserID,
+			&i.ResponderUserID,
+			&i.RequesterSlotID,
+			&i.ResponderSlotID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ResolvedByUserID,
+			&i.ResolvedAt,
+			&i.CancelReason,
+			&i.Kind,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSwappableEvents = `-- name: GetSwappableEvents :many
+SELECT
+    e.id, e.title, e.start_time, e.end_time, e.status, e.giveaway, e.user_id, e.time_zone, e.created_at, e.updated_at,
+    u.name as owner_name
+FROM events e
+JOIN users u ON e.user_id = u.id
+WHERE e.status = 'SWAPPABLE' AND e.user_id != ?
+`
+
+type GetSwappableEventsRow struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Status    string    `json:"status"`
+	Giveaway  *string   `json:"giveaway"`
+	UserID    int64     `json:"user_id"`
+	TimeZone  string    `json:"time_zone"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	OwnerName string    `json:"owner_name"`
+}
+
+func (q *Queries) GetSwappableEvents(ctx context.Context, userID int64) ([]GetSwappableEventsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSwappableEvents, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSwappableEventsRow
+	for rows.Next() {
+		var i GetSwappableEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Status,
+			&i.Giveaway,
+			&i.UserID,
+			&i.TimeZone,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OwnerName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, name, email, password, created_at, updated_at, is_admin, time_zone, deactivated_at FROM users
+WHERE email = ?
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsAdmin,
+		&i.TimeZone,
+		&i.DeactivatedAt,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, name, email, is_admin, time_zone, created_at, updated_at FROM users
+WHERE id = ? AND deactivated_at IS NULL
+`
+
+type GetUserByIDRow struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	IsAdmin   bool      `json:"is_admin"`
+	TimeZone  string    `json:"time_zone"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) GetUserByID(ctx context.Context, id int64) (GetUserByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i GetUserByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.IsAdmin,
+		&i.TimeZone,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listAuditLogs = `-- name: ListAuditLogs :many
+SELECT id, actor_user_id, "action", entity_type, entity_id, before_value, after_value, request_id, ip_address, user_agent, created_at FROM audit_logs
+WHERE entity_type = COALESCE(?1, entity_type)
+  AND actor_user_id = COALESCE(?2, actor_user_id)
+  AND id < ?3
+ORDER BY id DESC
+LIMIT ?4
+`
+
+type ListAuditLogsParams struct {
+	EntityType  sql.NullString `json:"entity_type"`
+	ActorUserID sql.NullInt64  `json:"actor_user_id"`
+	BeforeID    int64          `json:"before_id"`
+	Limit       int64          `json:"limit"`
+}
+
+func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditLogs,
+		arg.EntityType,
+		arg.ActorUserID,
+		arg.BeforeID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActorUserID,
+			&i.Action,
+			&i.EntityType,
+			&i.EntityID,
+			&i.BeforeValue,
+			&i.AfterValue,
+			&i.RequestID,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditLogsByEntity = `-- name: ListAuditLogsByEntity :many
+SELECT id, actor_user_id, "action", entity_type, entity_id, before_value, after_value, request_id, ip_address, user_agent, created_at FROM audit_logs
+WHERE entity_type = ? AND entity_id = ?
+ORDER BY id
+`
+
+type ListAuditLogsByEntityParams struct {
+	EntityType string `json:"entity_type"`
+	EntityID   int64  `json:"entity_id"`
+}
+
+func (q *Queries) ListAuditLogsByEntity(ctx context.Context, arg ListAuditLogsByEntityParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditLogsByEntity, arg.EntityType, arg.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActorUserID,
+			&i.Action,
+			&i.EntityType,
+			&i.EntityID,
+			&i.BeforeValue,
+			&i.AfterValue,
+			&i.RequestID,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditLogsBySubject = `-- name: ListAuditLogsBySubject :many
+SELECT a.id, a.actor_user_id, a."action", a.entity_type, a.entity_id, a.before_value, a.after_value, a.request_id, a.ip_address, a.user_agent, a.created_at FROM audit_logs a
+JOIN audit_log_subjects s ON s.audit_log_id = a.id
+WHERE s.user_id = ?1 AND a.id < ?2
+ORDER BY a.id DESC
+LIMIT ?3
+`
+
+type ListAuditLogsBySubjectParams struct {
+	UserID   int64 `json:"user_id"`
+	BeforeID int64 `json:"before_id"`
+	Limit    int64 `json:"limit"`
+}
+
+func (q *Queries) ListAuditLogsBySubject(ctx context.Context, arg ListAuditLogsBySubjectParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditLogsBySubject, arg.UserID, arg.BeforeID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActorUserID,
+			&i.Action,
+			&i.EntityType,
+			&i.EntityID,
+			&i.BeforeValue,
+			&i.AfterValue,
+			&i.RequestID,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventsByUserID = `-- name: ListEventsByUserID :many
+SELECT id, title, start_time, end_time, status, user_id, created_at, updated_at, time_zone, version, giveaway FROM events
+WHERE user_id = ?1
+    AND status = COALESCE(?2, status)
+    AND start_time >= COALESCE(?3, start_time)
+    AND start_time <= COALESCE(?4, start_time)
+    AND end_time >= COALESCE(?5, end_time)
+    AND end_time <= COALESCE(?6, end_time)
+    AND start_time >= ?7
+    AND (start_time > ?7 OR id > ?8)
+ORDER BY start_time, id
+LIMIT ?9
+`
+
+type ListEventsByUserIDParams struct {
+	UserID         int64          `json:"user_id"`
+	Status         sql.NullString `json:"status"`
+	StartFrom      *time.Time     `json:"start_from"`
+	StartTo        *time.Time     `json:"start_to"`
+	EndFrom        *time.Time     `json:"end_from"`
+	EndTo          *time.Time     `json:"end_to"`
+	AfterStartTime time.Time      `json:"after_start_time"`
+	AfterID        int64          `json:"after_id"`
+	Limit          int64          `json:"limit"`
+}
+
+func (q *Queries) ListEventsByUserID(ctx context.Context, arg ListEventsByUserIDParams) ([]Event, error) {
+	rows, err := q.db.QueryContext(ctx, listEventsByUserID,
+		arg.UserID,
+		arg.Status,
+		arg.StartFrom,
+		arg.StartTo,
+		arg.EndFrom,
+		arg.EndTo,
+		arg.AfterStartTime,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Status,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TimeZone,
+			&i.Version,
+			&i.Giveaway,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventsByUserIDDesc = `-- name: ListEventsByUserIDDesc :many
+SELECT id, title, start_time, end_time, status, user_id, created_at, updated_at, time_zone, version, giveaway FROM events
+WHERE user_id = ?1
+    AND status = COALESCE(?2, status)
+    AND start_time >= COALESCE(?3, start_time)
+    AND start_time <= COALESCE(?4, start_time)
+    AND end_time >= COALESCE(?5, end_time)
+    AND end_time <= COALESCE(?6, end_time)
+    AND start_time <= ?7
+    AND (start_time < ?7 OR id < ?8)
+ORDER BY start_time DESC, id DESC
+LIMIT ?9
+`
+
+type ListEventsByUserIDDescParams struct {
+	UserID         int64          `json:"user_id"`
+	Status         sql.NullString `json:"status"`
+	StartFrom      *time.Time     `json:"start_from"`
+	StartTo        *time.Time     `json:"start_to"`
+	EndFrom        *time.Time     `json:"end_from"`
+	EndTo          *time.Time     `json:"end_to"`
+	AfterStartTime time.Time      `json:"after_start_time"`
+	AfterID        int64          `json:"after_id"`
+	Limit          int64          `json:"limit"`
+}
+
+func (q *Queries) ListEventsByUserIDDesc(ctx context.Context, arg ListEventsByUserIDDescParams) ([]Event, error) {
+	rows, err := q.db.QueryContext(ctx, listEventsByUserIDDesc,
+		arg.UserID,
+		arg.Status,
+		arg.StartFrom,
+		arg.StartTo,
+		arg.EndFrom,
+		arg.EndTo,
+		arg.AfterStartTime,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Status,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TimeZone,
+			&i.Version,
+			&i.Giveaway,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listIncomingSwapRequests = `-- name: ListIncomingSwapRequests :many
+SELECT
+    sr.id,
+    sr.status,
+    sr.kind,
+    sr.requester_user_id,
+    requester.name AS requester_name,
+    COALESCE(requester_event.title, '') AS requester_event_title,
+    requester_event.start_time AS requester_event_start_time,
+    requester_event.end_time AS requester_event_end_time,
+    responder_event.title AS responder_event_title,
+    responder_event.start_time AS responder_event_start_time,
+    responder_event.end_time AS responder_event_end_time
+FROM
+    swap_requests sr
+JOIN
+    users requester ON sr.requester_user_id = requester.id
+JOIN
+    events responder_event ON sr.responder_slot_id = responder_event.id
+LEFT JOIN
+    events requester_event ON sr.requester_slot_id = requester_event.id
+WHERE
+    sr.responder_user_id = ?1 AND sr.status = 'PENDING'
+    AND sr.id > ?2
+ORDER BY sr.id
+LIMIT ?3
+`
+
+type ListIncomingSwapRequestsParams struct {
+	UserID  int64 `json:"user_id"`
+	AfterID int64 `json:"after_id"`
+	Limit   int64 `json:"limit"`
+}
+
+type ListIncomingSwapRequestsRow struct {
+	ID                      int64      `json:"id"`
+	Status                  string     `json:"status"`
+	Kind                    string     `json:"kind"`
+	RequesterUserID         int64      `json:"requester_user_id"`
+	RequesterName           string     `json:"requester_name"`
+	RequesterEventTitle     string     `json:"requester_event_title"`
+	RequesterEventStartTime *time.Time `json:"requester_event_start_time"`
+	RequesterEventEndTime   *time.Time `json:"requester_event_end_time"`
+	ResponderEventTitle     string     `json:"responder_event_title"`
+	ResponderEventStartTime time.Time  `json:"responder_event_start_time"`
+	ResponderEventEndTime   time.Time  `json:"responder_event_end_time"`
+}
+
+func (q *Queries) ListIncomingSwapRequests(ctx context.Context, arg ListIncomingSwapRequestsParams) ([]ListIncomingSwapRequestsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listIncomingSwapRequests, arg.UserID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListIncomingSwapRequestsRow
+	for rows.Next() {
+		var i ListIncomingSwapRequestsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.Kind,
+			&i.RequesterUserID,
+			&i.RequesterName,
+			&i.RequesterEventTitle,
+			&i.RequesterEventStartTime,
+			&i.RequesterEventEndTime,
+			&i.ResponderEventTitle,
+			&i.ResponderEventStartTime,
+			&i.ResponderEventEndTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listIncomingSwapRequestsDesc = `-- name: ListIncomingSwapRequestsDesc :many
+SELECT
+    sr.id,
+    sr.status,
+    sr.kind,
+    sr.requester_user_id,
+    requester.name AS requester_name,
+    COALESCE(requester_event.title, '') AS requester_event_title,
+    requester_event.start_time AS requester_event_start_time,
+    requester_event.end_time AS requester_event_end_time,
+    responder_event.title AS responder_event_title,
+    responder_event.start_time AS responder_event_start_time,
+    responder_event.end_time AS responder_event_end_time
+FROM
+    swap_requests sr
+JOIN
+    users requester ON sr.requester_user_id = requester.id
+JOIN
+    events responder_event ON sr.responder_slot_id = responder_event.id
+LEFT JOIN
+    events requester_event ON sr.requester_slot_id = requester_event.id
+WHERE
+    sr.responder_user_id = ?1 AND sr.status = 'PENDING'
+    AND sr.id < ?2
+ORDER BY sr.id DESC
+LIMIT ?3
+`
+
+type ListIncomingSwapRequestsDescParams struct {
+	UserID  int64 `json:"user_id"`
+	AfterID int64 `json:"after_id"`
+	Limit   int64 `json:"limit"`
+}
+
+type ListIncomingSwapRequestsDescRow struct {
+	ID                      int64      `json:"id"`
+	Status                  string     `json:"status"`
+	Kind                    string     `json:"kind"`
+	RequesterUserID         int64      `json:"requester_user_id"`
+	RequesterName           string     `json:"requester_name"`
+	RequesterEventTitle     string     `json:"requester_event_title"`
+	RequesterEventStartTime *time.Time `json:"requester_event_start_time"`
+	RequesterEventEndTime   *time.Time `json:"requester_event_end_time"`
+	ResponderEventTitle     string     `json:"responder_event_title"`
+	ResponderEventStartTime time.Time  `json:"responder_event_start_time"`
+	ResponderEventEndTime   time.Time  `json:"responder_event_end_time"`
+}
 
-func (q *Queries) GetEventsByUserID(ctx context.Context, userID int64) ([]Event, error) {
-	rows, err := q.db.QueryContext(ctx, getEventsByUserID, userID)
+func (q *Queries) ListIncomingSwapRequestsDesc(ctx context.Context, arg ListIncomingSwapRequestsDescParams) ([]ListIncomingSwapRequestsDescRow, error) {
+	rows, err := q.db.QueryContext(ctx, listIncomingSwapRequestsDesc, arg.UserID, arg.AfterID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Event
+	var items []ListIncomingSwapRequestsDescRow
 	for rows.Next() {
-		var i Event
+		var i ListIncomingSwapRequestsDescRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.Title,
-			&i.StartTime,
-			&i.EndTime,
 			&i.Status,
-			&i.UserID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.Kind,
+			&i.RequesterUserID,
+			&i.RequesterName,
+			&i.RequesterEventTitle,
+			&i.RequesterEventStartTime,
+			&i.RequesterEventEndTime,
+			&i.ResponderEventTitle,
+			&i.ResponderEventStartTime,
+			&i.ResponderEventEndTime,
 		); err != nil {
 			return nil, err
 		}
@@ -212,34 +1783,45 @@ func (q *Queries) GetEventsByUserID(ctx context.Context, userID int64) ([]Event,
 	return items, nil
 }
 
-const getEventsByUserIDAndStatus = `-- name: GetEventsByUserIDAndStatus :many
-SELECT id, title, start_time, end_time, status, user_id, created_at, updated_at FROM events
-WHERE user_id = ? AND status = ?
+const listNotificationsByUserID = `-- name: ListNotificationsByUserID :many
+SELECT id, user_id, kind, swap_request_id, reason, message, read_at, created_at FROM notifications
+WHERE user_id = ?1
+  AND (CAST(?2 AS BOOLEAN) = 0 OR read_at IS NULL)
+  AND id < ?3
+ORDER BY id DESC
+LIMIT ?4
 `
 
-type GetEventsByUserIDAndStatusParams struct {
-	UserID int64  `json:"user_id"`
-	Status string `json:"status"`
+type ListNotificationsByUserIDParams struct {
+	UserID     int64 `json:"user_id"`
+	UnreadOnly bool  `json:"unread_only"`
+	BeforeID   int64 `json:"before_id"`
+	Limit      int64 `json:"limit"`
 }
 
-func (q *Queries) GetEventsByUserIDAndStatus(ctx context.Context, arg GetEventsByUserIDAndStatusParams) ([]Event, error) {
-	rows, err := q.db.QueryContext(ctx, getEventsByUserIDAndStatus, arg.UserID, arg.Status)
+func (q *Queries) ListNotificationsByUserID(ctx context.Context, arg ListNotificationsByUserIDParams) ([]Notification, error) {
+	rows, err := q.db.QueryContext(ctx, listNotificationsByUserID,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.BeforeID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Event
+	var items []Notification
 	for rows.Next() {
-		var i Event
+		var i Notification
 		if err := rows.Scan(
 			&i.ID,
-			&i.Title,
-			&i.StartTime,
-			&i.EndTime,
-			&i.Status,
 			&i.UserID,
+			&i.Kind,
+			&i.SwapRequestID,
+			&i.Reason,
+			&i.Message,
+			&i.ReadAt,
 			&i.CreatedAt,
-			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -254,13 +1836,14 @@ func (q *Queries) GetEventsByUserIDAndStatus(ctx context.Context, arg GetEventsB
 	return items, nil
 }
 
-const getIncomingSwapRequests = `-- name: GetIncomingSwapRequests :many
+const listOutgoingSwapRequests = `-- name: ListOutgoingSwapRequests :many
 SELECT
     sr.id,
     sr.status,
-    sr.requester_user_id,
-    requester.name AS requester_name,
-    requester_event.title AS requester_event_title,
+    sr.kind,
+    sr.responder_user_id,
+    responder.name AS responder_name,
+    COALESCE(requester_event.title, '') AS requester_event_title,
     requester_event.start_time AS requester_event_start_time,
     requester_event.end_time AS requester_event_end_time,
     responder_event.title AS responder_event_title,
@@ -269,42 +1852,53 @@ SELECT
 FROM
     swap_requests sr
 JOIN
-    users requester ON sr.requester_user_id = requester.id
-JOIN
-    events requester_event ON sr.requester_slot_id = requester_event.id
+    users responder ON sr.responder_user_id = responder.id
 JOIN
     events responder_event ON sr.responder_slot_id = responder_event.id
+LEFT JOIN
+    events requester_event ON sr.requester_slot_id = requester_event.id
 WHERE
-    sr.responder_user_id = ? AND sr.status = 'PENDING'
+    sr.requester_user_id = ?1 AND sr.status = 'PENDING'
+    AND sr.id > ?2
+ORDER BY sr.id
+LIMIT ?3
 `
 
-type GetIncomingSwapRequestsRow struct {
-	ID                      int64     `json:"id"`
-	Status                  string    `json:"status"`
-	RequesterUserID         int64     `json:"requester_user_id"`
-	RequesterName           string    `json:"requester_name"`
-	RequesterEventTitle     string    `json:"requester_event_title"`
-	RequesterEventStartTime time.Time `json:"requester_event_start_time"`
-	RequesterEventEndTime   time.Time `json:"requester_event_end_time"`
-	ResponderEventTitle     string    `json:"responder_event_title"`
-	ResponderEventStartTime time.Time `json:"responder_event_start_time"`
-	ResponderEventEndTime   time.Time `json:"responder_event_end_time"`
+type ListOutgoingSwapRequestsParams struct {
+	UserID  int64 `json:"user_id"`
+	AfterID int64 `json:"after_id"`
+	Limit   int64 `json:"limit"`
 }
 
-func (q *Queries) GetIncomingSwapRequests(ctx context.Context, responderUserID int64) ([]GetIncomingSwapRequestsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getIncomingSwapRequests, responderUserID)
+type ListOutgoingSwapRequestsRow struct {
+	ID                      int64      `json:"id"`
+	Status                  string     `json:"status"`
+	Kind                    string     `json:"kind"`
+	ResponderUserID         int64      `json:"responder_user_id"`
+	ResponderName           string     `json:"responder_name"`
+	RequesterEventTitle     string     `json:"requester_event_title"`
+	RequesterEventStartTime *time.Time `json:"requester_event_start_time"`
+	RequesterEventEndTime   *time.Time `json:"requester_event_end_time"`
+	ResponderEventTitle     string     `json:"responder_event_title"`
+	ResponderEventStartTime time.Time  `json:"responder_event_start_time"`
+	ResponderEventEndTime   time.Time  `json:"responder_event_end_time"`
+}
+
+func (q *Queries) ListOutgoingSwapRequests(ctx context.Context, arg ListOutgoingSwapRequestsParams) ([]ListOutgoingSwapRequestsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOutgoingSwapRequests, arg.UserID, arg.AfterID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetIncomingSwapRequestsRow
+	var items []ListOutgoingSwapRequestsRow
 	for rows.Next() {
-		var i GetIncomingSwapRequestsRow
+		var i ListOutgoingSwapRequestsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Status,
-			&i.RequesterUserID,
-			&i.RequesterName,
+			&i.Kind,
+			&i.ResponderUserID,
+			&i.ResponderName,
 			&i.RequesterEventTitle,
 			&i.RequesterEventStartTime,
 			&i.RequesterEventEndTime,
@@ -325,13 +1919,14 @@ func (q *Queries) GetIncomingSwapRequests(ctx context.Context, responderUserID i
 	return items, nil
 }
 
-const getOutgoingSwapRequests = `-- name: GetOutgoingSwapRequests :many
+const listOutgoingSwapRequestsDesc = `-- name: ListOutgoingSwapRequestsDesc :many
 SELECT
     sr.id,
     sr.status,
+    sr.kind,
     sr.responder_user_id,
     responder.name AS responder_name,
-    requester_event.title AS requester_event_title,
+    COALESCE(requester_event.title, '') AS requester_event_title,
     requester_event.start_time AS requester_event_start_time,
     requester_event.end_time AS requester_event_end_time,
     responder_event.title AS responder_event_title,
@@ -341,39 +1936,50 @@ FROM
     swap_requests sr
 JOIN
     users responder ON sr.responder_user_id = responder.id
-JOIN
-    events requester_event ON sr.requester_slot_id = requester_event.id
 JOIN
     events responder_event ON sr.responder_slot_id = responder_event.id
+LEFT JOIN
+    events requester_event ON sr.requester_slot_id = requester_event.id
 WHERE
-    sr.requester_user_id = ? AND sr.status = 'PENDING'
+    sr.requester_user_id = ?1 AND sr.status = 'PENDING'
+    AND sr.id < ?2
+ORDER BY sr.id DESC
+LIMIT ?3
 `
 
-type GetOutgoingSwapRequestsRow struct {
-	ID                      int64     `json:"id"`
-	Status                  string    `json:"status"`
-	ResponderUserID         int64     `json:"responder_user_id"`
-	ResponderName           string    `json:"responder_name"`
-	RequesterEventTitle     string    `json:"requester_event_title"`
-	RequesterEventStartTime time.Time `json:"requester_event_start_time"`
-	RequesterEventEndTime   time.Time `json:"requester_event_end_time"`
-	ResponderEventTitle     string    `json:"responder_event_title"`
-	ResponderEventStartTime time.Time `json:"responder_event_start_time"`
-	ResponderEventEndTime   time.Time `json:"responder_event_end_time"`
+type ListOutgoingSwapRequestsDescParams struct {
+	UserID  int64 `json:"user_id"`
+	AfterID int64 `json:"after_id"`
+	Limit   int64 `json:"limit"`
+}
+
+type ListOutgoingSwapRequestsDescRow struct {
+	ID                      int64      `json:"id"`
+	Status                  string     `json:"status"`
+	Kind                    string     `json:"kind"`
+	ResponderUserID         int64      `json:"responder_user_id"`
+	ResponderName           string     `json:"responder_name"`
+	RequesterEventTitle     string     `json:"requester_event_title"`
+	RequesterEventStartTime *time.Time `json:"requester_event_start_time"`
+	RequesterEventEndTime   *time.Time `json:"requester_event_end_time"`
+	ResponderEventTitle     string     `json:"responder_event_title"`
+	ResponderEventStartTime time.Time  `json:"responder_event_start_time"`
+	ResponderEventEndTime   time.Time  `json:"responder_event_end_time"`
 }
 
-func (q *Queries) GetOutgoingSwapRequests(ctx context.Context, requesterUserID int64) ([]GetOutgoingSwapRequestsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getOutgoingSwapRequests, requesterUserID)
+func (q *Queries) ListOutgoingSwapRequestsDesc(ctx context.Context, arg ListOutgoingSwapRequestsDescParams) ([]ListOutgoingSwapRequestsDescRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOutgoingSwapRequestsDesc, arg.UserID, arg.AfterID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetOutgoingSwapRequestsRow
+	var items []ListOutgoingSwapRequestsDescRow
 	for rows.Next() {
-		var i GetOutgoingSwapRequestsRow
+		var i ListOutgoingSwapRequestsDescRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Status,
+			&i.Kind,
 			&i.ResponderUserID,
 			&i.ResponderName,
 			&i.RequesterEventTitle,
@@ -396,79 +2002,174 @@ func (q *Queries) GetOutgoingSwapRequests(ctx context.Context, requesterUserID i
 	return items, nil
 }
 
-const getPublicUserByID = `-- name: GetPublicUserByID :one
-SELECT id, name, created_at, updated_at FROM users
-WHERE id = ?
+const listOverlappingEvents = `-- name: ListOverlappingEvents :many
+SELECT id, title, start_time, end_time, status, user_id, created_at, updated_at, time_zone, version, giveaway FROM events
+WHERE user_id = ?1
+    AND start_time < ?2
+    AND end_time > ?3
+    AND id != ?4
+ORDER BY start_time, id
 `
 
-type GetPublicUserByIDRow struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+type ListOverlappingEventsParams struct {
+	UserID    int64     `json:"user_id"`
+	EndTime   time.Time `json:"end_time"`
+	StartTime time.Time `json:"start_time"`
+	ExcludeID int64     `json:"exclude_id"`
 }
 
-func (q *Queries) GetPublicUserByID(ctx context.Context, id int64) (GetPublicUserByIDRow, error) {
-	row := q.db.QueryRowContext(ctx, getPublicUserByID, id)
-	var i GetPublicUserByIDRow
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+func (q *Queries) ListOverlappingEvents(ctx context.Context, arg ListOverlappingEventsParams) ([]Event, error) {
+	rows, err := q.db.QueryContext(ctx, listOverlappingEvents,
+		arg.UserID,
+		arg.EndTime,
+		arg.StartTime,
+		arg.ExcludeID,
 	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Status,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TimeZone,
+			&i.Version,
+			&i.Giveaway,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getSwapRequestByID = `-- name: GetSwapRequestByID :one
-SELECT id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at FROM swap_requests
-WHERE id = ?
+const listPersonalAccessTokensByUserID = `-- name: ListPersonalAccessTokensByUserID :many
+SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at FROM personal_access_tokens
+WHERE user_id = ?
+ORDER BY id
 `
 
-func (q *Queries) GetSwapRequestByID(ctx context.Context, id int64) (SwapRequest, error) {
-	row := q.db.QueryRowContext(ctx, getSwapRequestByID, id)
-	var i SwapRequest
-	err := row.Scan(
-		&i.ID,
-		&i.RequesterUserID,
-		&i.ResponderUserID,
-		&i.RequesterSlotID,
-		&i.ResponderSlotID,
-		&i.Status,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+func (q *Queries) ListPersonalAccessTokensByUserID(ctx context.Context, userID int64) ([]PersonalAccessToken, error) {
+	rows, err := q.db.QueryContext(ctx, listPersonalAccessTokensByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PersonalAccessToken
+	for rows.Next() {
+		var i PersonalAccessToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.TokenHash,
+			&i.Scopes,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getSwapRequestsByEventID = `-- name: GetSwapRequestsByEventID :many
-SELECT id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at FROM swap_requests
-WHERE requester_slot_id = ? OR responder_slot_id = ?
+const listSwappableEvents = `-- name: ListSwappableEvents :many
+SELECT
+    e.id, e.title, e.start_time, e.end_time, e.status, e.giveaway, e.user_id, e.time_zone, e.version, e.created_at, e.updated_at,
+    u.name as owner_name
+FROM events e
+JOIN users u ON e.user_id = u.id
+WHERE e.status = 'SWAPPABLE' AND e.user_id != ?1
+    AND e.start_time >= COALESCE(?2, e.start_time)
+    AND e.start_time <= COALESCE(?3, e.start_time)
+    AND e.end_time >= COALESCE(?4, e.end_time)
+    AND e.end_time <= COALESCE(?5, e.end_time)
+    AND e.start_time >= ?6
+    AND (e.start_time > ?6 OR e.id > ?7)
+ORDER BY e.start_time, e.id
+LIMIT ?8
 `
 
-type GetSwapRequestsByEventIDParams struct {
-	RequesterSlotID int64 `json:"requester_slot_id"`
-	ResponderSlotID int64 `json:"responder_slot_id"`
+type ListSwappableEventsParams struct {
+	UserID         int64      `json:"user_id"`
+	StartFrom      *time.Time `json:"start_from"`
+	StartTo        *time.Time `json:"start_to"`
+	EndFrom        *time.Time `json:"end_from"`
+	EndTo          *time.Time `json:"end_to"`
+	AfterStartTime time.Time  `json:"after_start_time"`
+	AfterID        int64      `json:"after_id"`
+	Limit          int64      `json:"limit"`
 }
 
-func (q *Queries) GetSwapRequestsByEventID(ctx context.Context, arg GetSwapRequestsByEventIDParams) ([]SwapRequest, error) {
-	rows, err := q.db.QueryContext(ctx, getSwapRequestsByEventID, arg.RequesterSlotID, arg.ResponderSlotID)
+type ListSwappableEventsRow struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Status    string    `json:"status"`
+	Giveaway  *string   `json:"giveaway"`
+	UserID    int64     `json:"user_id"`
+	TimeZone  string    `json:"time_zone"`
+	Version   int64     `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	OwnerName string    `json:"owner_name"`
+}
+
+func (q *Queries) ListSwappableEvents(ctx context.Context, arg ListSwappableEventsParams) ([]ListSwappableEventsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSwappableEvents,
+		arg.UserID,
+		arg.StartFrom,
+		arg.StartTo,
+		arg.EndFrom,
+		arg.EndTo,
+		arg.AfterStartTime,
+		arg.AfterID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []SwapRequest
+	var items []ListSwappableEventsRow
 	for rows.Next() {
-		var i SwapRequest
+		var i ListSwappableEventsRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.RequesterUserID,
-			&i.ResponderUserID,
-			&i.RequesterSlotID,
-			&i.ResponderSlotID,
+			&i.Title,
+			&i.StartTime,
+			&i.EndTime,
 			&i.Status,
+			&i.Giveaway,
+			&i.UserID,
+			&i.TimeZone,
+			&i.Version,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.OwnerName,
 		); err != nil {
 			return nil, err
 		}
@@ -483,43 +2184,77 @@ func (q *Queries) GetSwapRequestsByEventID(ctx context.Context, arg GetSwapReque
 	return items, nil
 }
 
-const getSwappableEvents = `-- name: GetSwappableEvents :many
+const listSwappableEventsDesc = `-- name: ListSwappableEventsDesc :many
 SELECT
-    e.id, e.title, e.start_time, e.end_time, e.status, e.user_id, e.created_at, e.updated_at,
+    e.id, e.title, e.start_time, e.end_time, e.status, e.giveaway, e.user_id, e.time_zone, e.version, e.created_at, e.updated_at,
     u.name as owner_name
 FROM events e
 JOIN users u ON e.user_id = u.id
-WHERE e.status = 'SWAPPABLE' AND e.user_id != ?
+WHERE e.status = 'SWAPPABLE' AND e.user_id != ?1
+    AND e.start_time >= COALESCE(?2, e.start_time)
+    AND e.start_time <= COALESCE(?3, e.start_time)
+    AND e.end_time >= COALESCE(?4, e.end_time)
+    AND e.end_time <= COALESCE(?5, e.end_time)
+    AND e.start_time <= ?6
+    AND (e.start_time < ?6 OR e.id < ?7)
+ORDER BY e.start_time DESC, e.id DESC
+LIMIT ?8
 `
 
-type GetSwappableEventsRow struct {
+type ListSwappableEventsDescParams struct {
+	UserID         int64      `json:"user_id"`
+	StartFrom      *time.Time `json:"start_from"`
+	StartTo        *time.Time `json:"start_to"`
+	EndFrom        *time.Time `json:"end_from"`
+	EndTo          *time.Time `json:"end_to"`
+	AfterStartTime time.Time  `json:"after_start_time"`
+	AfterID        int64      `json:"after_id"`
+	Limit          int64      `json:"limit"`
+}
+
+type ListSwappableEventsDescRow struct {
 	ID        int64     `json:"id"`
 	Title     string    `json:"title"`
 	StartTime time.Time `json:"start_time"`
 	EndTime   time.Time `json:"end_time"`
 	Status    string    `json:"status"`
+	Giveaway  *string   `json:"giveaway"`
 	UserID    int64     `json:"user_id"`
+	TimeZone  string    `json:"time_zone"`
+	Version   int64     `json:"version"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	OwnerName string    `json:"owner_name"`
 }
 
-func (q *Queries) GetSwappableEvents(ctx context.Context, userID int64) ([]GetSwappableEventsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getSwappableEvents, userID)
+func (q *Queries) ListSwappableEventsDesc(ctx context.Context, arg ListSwappableEventsDescParams) ([]ListSwappableEventsDescRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSwappableEventsDesc,
+		arg.UserID,
+		arg.StartFrom,
+		arg.StartTo,
+		arg.EndFrom,
+		arg.EndTo,
+		arg.AfterStartTime,
+		arg.AfterID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetSwappableEventsRow
+	var items []ListSwappableEventsDescRow
 	for rows.Next() {
-		var i GetSwappableEventsRow
+		var i ListSwappableEventsDescRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Title,
 			&i.StartTime,
 			&i.EndTime,
 			&i.Status,
+			&i.Giveaway,
 			&i.UserID,
+			&i.TimeZone,
+			&i.Version,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.OwnerName,
@@ -537,47 +2272,66 @@ func (q *Queries) GetSwappableEvents(ctx context.Context, userID int64) ([]GetSw
 	return items, nil
 }
 
-const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, name, email, password, created_at, updated_at FROM users
-WHERE email = ?
+const markNotificationRead = `-- name: MarkNotificationRead :one
+UPDATE notifications
+SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+WHERE id = ? AND user_id = ?
+RETURNING id, user_id, kind, swap_request_id, reason, message, read_at, created_at
 `
 
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
-	var i User
+type MarkNotificationReadParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (Notification, error) {
+	row := q.db.QueryRowContext(ctx, markNotificationRead, arg.ID, arg.UserID)
+	var i Notification
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
-		&i.Email,
-		&i.Password,
+		&i.UserID,
+		&i.Kind,
+		&i.SwapRequestID,
+		&i.Reason,
+		&i.Message,
+		&i.ReadAt,
 		&i.CreatedAt,
-		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getUserByID = `-- name: GetUserByID :one
-SELECT id, name, email, created_at, updated_at FROM users
+const resolveSwapRequest = `-- name: ResolveSwapRequest :one
+UPDATE swap_requests
+SET status = ?,
+    resolved_by_user_id = ?,
+    resolved_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
+RETURNING id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at, resolved_by_user_id, resolved_at, cancel_reason, kind
 `
 
-type GetUserByIDRow struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+type ResolveSwapRequestParams struct {
+	Status           string `json:"status"`
+	ResolvedByUserID *int64 `json:"resolved_by_user_id"`
+	ID               int64  `json:"id"`
 }
 
-func (q *Queries) GetUserByID(ctx context.Context, id int64) (GetUserByIDRow, error) {
-	row := q.db.QueryRowContext(ctx, getUserByID, id)
-	var i GetUserByIDRow
+func (q *Queries) ResolveSwapRequest(ctx context.Context, arg ResolveSwapRequestParams) (SwapRequest, error) {
+	row := q.db.QueryRowContext(ctx, resolveSwapRequest, arg.Status, arg.ResolvedByUserID, arg.ID)
+	var i SwapRequest
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
-		&i.Email,
+		&i.RequesterUserID,
+		&i.ResponderUserID,
+		&i.RequesterSlotID,
+		&i.ResponderSlotID,
+		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ResolvedByUserID,
+		&i.ResolvedAt,
+		&i.CancelReason,
+		&i.Kind,
 	)
 	return i, err
 }
@@ -587,9 +2341,12 @@ UPDATE events
 SET title = ?,
     start_time = ?,
     end_time = ?,
-    status = ?
+    status = ?,
+    giveaway = ?,
+    time_zone = ?,
+    version = version + 1
 WHERE id = ?
-RETURNING id, title, start_time, end_time, status, user_id, created_at, updated_at
+RETURNING id, title, start_time, end_time, status, user_id, created_at, updated_at, time_zone, version, giveaway
 `
 
 type UpdateEventParams struct {
@@ -597,6 +2354,8 @@ type UpdateEventParams struct {
 	StartTime time.Time `json:"start_time"`
 	EndTime   time.Time `json:"end_time"`
 	Status    string    `json:"status"`
+	Giveaway  *string   `json:"giveaway"`
+	TimeZone  string    `json:"time_zone"`
 	ID        int64     `json:"id"`
 }
 
@@ -606,6 +2365,8 @@ func (q *Queries) UpdateEvent(ctx context.Context, arg UpdateEventParams) (Event
 		arg.StartTime,
 		arg.EndTime,
 		arg.Status,
+		arg.Giveaway,
+		arg.TimeZone,
 		arg.ID,
 	)
 	var i Event
@@ -618,24 +2379,30 @@ func (q *Queries) UpdateEvent(ctx context.Context, arg UpdateEventParams) (Event
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TimeZone,
+		&i.Version,
+		&i.Giveaway,
 	)
 	return i, err
 }
 
 const updateEventStatus = `-- name: UpdateEventStatus :one
 UPDATE events
-SET status = ?
+SET status = ?,
+    giveaway = ?,
+    version = version + 1
 WHERE id = ?
-RETURNING id, title, start_time, end_time, status, user_id, created_at, updated_at
+RETURNING id, title, start_time, end_time, status, user_id, created_at, updated_at, time_zone, version, giveaway
 `
 
 type UpdateEventStatusParams struct {
-	Status string `json:"status"`
-	ID     int64  `json:"id"`
+	Status   string  `json:"status"`
+	Giveaway *string `json:"giveaway"`
+	ID       int64   `json:"id"`
 }
 
 func (q *Queries) UpdateEventStatus(ctx context.Context, arg UpdateEventStatusParams) (Event, error) {
-	row := q.db.QueryRowContext(ctx, updateEventStatus, arg.Status, arg.ID)
+	row := q.db.QueryRowContext(ctx, updateEventStatus, arg.Status, arg.Giveaway, arg.ID)
 	var i Event
 	err := row.Scan(
 		&i.ID,
@@ -646,15 +2413,19 @@ func (q *Queries) UpdateEventStatus(ctx context.Context, arg UpdateEventStatusPa
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TimeZone,
+		&i.Version,
+		&i.Giveaway,
 	)
 	return i, err
 }
 
 const updateEventUserID = `-- name: UpdateEventUserID :one
 UPDATE events
-SET user_id = ?
+SET user_id = ?,
+    version = version + 1
 WHERE id = ?
-RETURNING id, title, start_time, end_time, status, user_id, created_at, updated_at
+RETURNING id, title, start_time, end_time, status, user_id, created_at, updated_at, time_zone, version, giveaway
 `
 
 type UpdateEventUserIDParams struct {
@@ -674,15 +2445,34 @@ func (q *Queries) UpdateEventUserID(ctx context.Context, arg UpdateEventUserIDPa
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TimeZone,
+		&i.Version,
+		&i.Giveaway,
 	)
 	return i, err
 }
 
+const updatePersonalAccessTokenLastUsed = `-- name: UpdatePersonalAccessTokenLastUsed :exec
+UPDATE personal_access_tokens
+SET last_used_at = ?
+WHERE id = ?
+`
+
+type UpdatePersonalAccessTokenLastUsedParams struct {
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ID         int64      `json:"id"`
+}
+
+func (q *Queries) UpdatePersonalAccessTokenLastUsed(ctx context.Context, arg UpdatePersonalAccessTokenLastUsedParams) error {
+	_, err := q.db.ExecContext(ctx, updatePersonalAccessTokenLastUsed, arg.LastUsedAt, arg.ID)
+	return err
+}
+
 const updateSwapRequestStatus = `-- name: UpdateSwapRequestStatus :one
 UPDATE swap_requests
 SET status = ?
 WHERE id = ?
-RETURNING id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at
+RETURNING id, requester_user_id, responder_user_id, requester_slot_id, responder_slot_id, status, created_at, updated_at, resolved_by_user_id, resolved_at, cancel_reason, kind
 `
 
 type UpdateSwapRequestStatusParams struct {
@@ -702,6 +2492,43 @@ func (q *Queries) UpdateSwapRequestStatus(ctx context.Context, arg UpdateSwapReq
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ResolvedByUserID,
+		&i.ResolvedAt,
+		&i.CancelReason,
+		&i.Kind,
 	)
 	return i, err
 }
+
+const updateUserIsAdmin = `-- name: UpdateUserIsAdmin :exec
+UPDATE users
+SET is_admin = ?
+WHERE id = ?
+`
+
+type UpdateUserIsAdminParams struct {
+	IsAdmin bool  `json:"is_admin"`
+	ID      int64 `json:"id"`
+}
+
+func (q *Queries) UpdateUserIsAdmin(ctx context.Context, arg UpdateUserIsAdminParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserIsAdmin, arg.IsAdmin, arg.ID)
+	return err
+}
+
+const updateUserTimeZone = `-- name: UpdateUserTimeZone :exec
+UPDATE users
+SET time_zone = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateUserTimeZoneParams struct {
+	TimeZone string `json:"time_zone"`
+	ID       int64  `json:"id"`
+}
+
+func (q *Queries) UpdateUserTimeZone(ctx context.Context, arg UpdateUserTimeZoneParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserTimeZone, arg.TimeZone, arg.ID)
+	return err
+}
@@ -0,0 +1,21 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// BeginTx starts a transaction on the connection the queries were created
+// with. It fails for Queries that are already bound to a transaction.
+func (q *Queries) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	target := q.db
+	if o, ok := target.(*observedDBTX); ok {
+		target = o.DBTX
+	}
+	conn, ok := target.(*sql.DB)
+	if !ok {
+		return nil, errors.New("db: queries are not bound to a *sql.DB")
+	}
+	return conn.BeginTx(ctx, opts)
+}
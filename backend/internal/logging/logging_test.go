@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(&buf, "json", "info")
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		logger.Debug("hidden")
+		logger.Info("shown", "key", "value")
+
+		var record map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+			t.Fatalf("expected a single JSON record, got %q: %v", buf.String(), err)
+		}
+		if record["msg"] != "shown" || record["key"] != "value" {
+			t.Errorf("unexpected record %v", record)
+		}
+	})
+
+	t.Run("text with debug level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(&buf, "", "debug")
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		logger.Debug("details")
+		if !strings.Contains(buf.String(), "level=DEBUG msg=details") {
+			t.Errorf("unexpected output %q", buf.String())
+		}
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		if _, err := New(&bytes.Buffer{}, "xml", ""); err == nil {
+			t.Error("expected an error for an unknown format")
+		}
+		if _, err := New(&bytes.Buffer{}, "json", "loud"); err == nil {
+			t.Error("expected an error for an unknown level")
+		}
+	})
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("expected the default logger outside of a request")
+	}
+
+	var buf bytes.Buffer
+	logger, _ := New(&buf, "text", "")
+	ctx := With(WithLogger(context.Background(), logger), "request_id", "abc")
+	FromContext(ctx).Info("hello")
+	if !strings.Contains(buf.String(), "request_id=abc") {
+		t.Errorf("expected the request ID on the record, got %q", buf.String())
+	}
+}
@@ -0,0 +1,170 @@
+// Package metrics collects Prometheus metrics about HTTP requests, database
+// queries and the swap marketplace, and serves them for scraping.
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "slotswapper"
+
+// Outcomes of a swap request, as reported by SwapRequestsResolved. There is
+// no expired outcome: nothing closes a request on a timer, so a pending
+// request only leaves PENDING through one of these.
+const (
+	OutcomeAccepted = "accepted"
+	OutcomeRejected = "rejected"
+	// OutcomeWithdrawn is a pending request taken back by its requester.
+	OutcomeWithdrawn = "withdrawn"
+	// OutcomeSuperseded is a pending request closed because another offer
+	// for the same slot was accepted.
+	OutcomeSuperseded = "superseded"
+	// OutcomeCancelled is a pending request closed as CANCELLED because one
+	// of its slots was changed or deleted, or one of its users deactivated
+	// their account, before the responder answered.
+	OutcomeCancelled = "cancelled"
+)
+
+// Domain counters are incremented by the services. They are shared by every
+// registry created with New.
+var (
+	SwapRequestsResolved = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "swap_requests_resolved_total",
+		Help:      "Swap requests that left PENDING, by outcome.",
+	}, []string{"outcome"})
+
+	LoginFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Login attempts rejected for an unknown email or a wrong password.",
+	})
+)
+
+func init() {
+	for _, outcome := range []string{OutcomeAccepted, OutcomeRejected, OutcomeWithdrawn, OutcomeSuperseded, OutcomeCancelled} {
+		SwapRequestsResolved.WithLabelValues(outcome)
+	}
+}
+
+// Store counts the rows behind the marketplace gauges. *db.Queries
+// implements it.
+type Store interface {
+	CountEventsByStatus(ctx context.Context, status string) (int64, error)
+	CountSwapRequestsByStatus(ctx context.Context, status string) (int64, error)
+}
+
+// Metrics owns a registry with the HTTP, database and domain metrics.
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+}
+
+// New returns metrics whose marketplace gauges are read from store on every
+// scrape.
+func New(store Store) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by method, route pattern and status code.",
+		}, []string{"method", "route", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by method and route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Time taken by database queries, by query name.",
+			Buckets:   []float64{.0001, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"query"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.queryDuration,
+		SwapRequestsResolved,
+		LoginFailures,
+		newMarketplaceCollector(store),
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+		ErrorLog:      slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+	})
+}
+
+// ObserveRequest records a served HTTP request. route is the pattern that
+// matched the request, so that path parameters do not multiply the series.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.requestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveQuery records a database query. It has the signature of
+// db.QueryObserver.
+func (m *Metrics) ObserveQuery(name string, duration time.Duration) {
+	m.queryDuration.WithLabelValues(name).Observe(duration.Seconds())
+}
+
+// marketplaceCollector reads the marketplace gauges from the database when
+// scraped, so they stay right whichever code path changed the rows.
+type marketplaceCollector struct {
+	store        Store
+	pendingSwaps *prometheus.Desc
+	swappable    *prometheus.Desc
+}
+
+func newMarketplaceCollector(store Store) *marketplaceCollector {
+	return &marketplaceCollector{
+		store: store,
+		pendingSwaps: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "swap_requests_pending"),
+			"Swap requests waiting for a response.", nil, nil),
+		swappable: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "slots_swappable"),
+			"Slots offered in the marketplace.", nil, nil),
+	}
+}
+
+func (c *marketplaceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.pendingSwaps
+	ch <- c.swappable
+}
+
+func (c *marketplaceCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	gauge := func(desc *prometheus.Desc, count func(context.Context, string) (int64, error), status string) {
+		n, err := count(ctx, status)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(desc, err)
+			return
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(n))
+	}
+	gauge(c.pendingSwaps, c.store.CountSwapRequestsByStatus, "PENDING")
+	gauge(c.swappable, c.store.CountEventsByStatus, "SWAPPABLE")
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"slotswapper/internal/db"
+)
+
+type AccessTokenRepository interface {
+	CreatePersonalAccessToken(ctx context.Context, arg db.CreatePersonalAccessTokenParams) (db.PersonalAccessToken, error)
+	GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (db.PersonalAccessToken, error)
+	GetPersonalAccessTokenByID(ctx context.Context, id int64) (db.PersonalAccessToken, error)
+	ListPersonalAccessTokensByUserID(ctx context.Context, userID int64) ([]db.PersonalAccessToken, error)
+	UpdatePersonalAccessTokenLastUsed(ctx context.Context, arg db.UpdatePersonalAccessTokenLastUsedParams) error
+	DeletePersonalAccessToken(ctx context.Context, id int64) error
+	DeletePersonalAccessTokensByUserID(ctx context.Context, userID int64) error
+}
+
+type accessTokenRepository struct {
+	queries *db.Queries
+}
+
+func NewAccessTokenRepository(queries *db.Queries) AccessTokenRepository {
+	return &tracedAccessTokenRepository{next: &accessTokenRepository{queries: queries}}
+}
+
+func (r *accessTokenRepository) CreatePersonalAccessToken(ctx context.Context, arg db.CreatePersonalAccessTokenParams) (db.PersonalAccessToken, error) {
+	return queriesFor(ctx, r.queries).CreatePersonalAccessToken(ctx, arg)
+}
+
+func (r *accessTokenRepository) GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (db.PersonalAccessToken, error) {
+	return queriesFor(ctx, r.queries).GetPersonalAccessTokenByHash(ctx, tokenHash)
+}
+
+func (r *accessTokenRepository) GetPersonalAccessTokenByID(ctx context.Context, id int64) (db.PersonalAccessToken, error) {
+	return queriesFor(ctx, r.queries).GetPersonalAccessTokenByID(ctx, id)
+}
+
+func (r *accessTokenRepository) ListPersonalAccessTokensByUserID(ctx context.Context, userID int64) ([]db.PersonalAccessToken, error) {
+	return queriesFor(ctx, r.queries).ListPersonalAccessTokensByUserID(ctx, userID)
+}
+
+func (r *accessTokenRepository) UpdatePersonalAccessTokenLastUsed(ctx context.Context, arg db.UpdatePersonalAccessTokenLastUsedParams) error {
+	return queriesFor(ctx, r.queries).UpdatePersonalAccessTokenLastUsed(ctx, arg)
+}
+
+func (r *accessTokenRepository) DeletePersonalAccessToken(ctx context.Context, id int64) error {
+	return queriesFor(ctx, r.queries).DeletePersonalAccessToken(ctx, id)
+}
+
+func (r *accessTokenRepository) DeletePersonalAccessTokensByUserID(ctx context.Context, userID int64) error {
+	return queriesFor(ctx, r.queries).DeletePersonalAccessTokensByUserID(ctx, userID)
+}
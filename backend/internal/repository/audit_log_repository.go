@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"slotswapper/internal/db"
+)
+
+type AuditLogRepository interface {
+	CreateAuditLog(ctx context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error)
+	AddAuditLogSubject(ctx context.Context, arg db.AddAuditLogSubjectParams) error
+	ListAuditLogsByEntity(ctx context.Context, arg db.ListAuditLogsByEntityParams) ([]db.AuditLog, error)
+	ListAuditLogsBySubject(ctx context.Context, arg db.ListAuditLogsBySubjectParams) ([]db.AuditLog, error)
+	CountAuditLogSubjectEntries(ctx context.Context, arg db.CountAuditLogSubjectEntriesParams) (int64, error)
+	ListAuditLogs(ctx context.Context, arg db.ListAuditLogsParams) ([]db.AuditLog, error)
+}
+
+type auditLogRepository struct {
+	queries *db.Queries
+}
+
+func NewAuditLogRepository(queries *db.Queries) AuditLogRepository {
+	return &tracedAuditLogRepository{next: &auditLogRepository{queries: queries}}
+}
+
+func (r *auditLogRepository) CreateAuditLog(ctx context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+	return queriesFor(ctx, r.queries).CreateAuditLog(ctx, arg)
+}
+
+func (r *auditLogRepository) AddAuditLogSubject(ctx context.Context, arg db.AddAuditLogSubjectParams) error {
+	return queriesFor(ctx, r.queries).AddAuditLogSubject(ctx, arg)
+}
+
+func (r *auditLogRepository) ListAuditLogsByEntity(ctx context.Context, arg db.ListAuditLogsByEntityParams) ([]db.AuditLog, error) {
+	return queriesFor(ctx, r.queries).ListAuditLogsByEntity(ctx, arg)
+}
+
+func (r *auditLogRepository) ListAuditLogsBySubject(ctx context.Context, arg db.ListAuditLogsBySubjectParams) ([]db.AuditLog, error) {
+	return queriesFor(ctx, r.queries).ListAuditLogsBySubject(ctx, arg)
+}
+
+func (r *auditLogRepository) CountAuditLogSubjectEntries(ctx context.Context, arg db.CountAuditLogSubjectEntriesParams) (int64, error) {
+	return queriesFor(ctx, r.queries).CountAuditLogSubjectEntries(ctx, arg)
+}
+
+func (r *auditLogRepository) ListAuditLogs(ctx context.Context, arg db.ListAuditLogsParams) ([]db.AuditLog, error) {
+	return queriesFor(ctx, r.queries).ListAuditLogs(ctx, arg)
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"slotswapper/internal/db"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestAuditLogRepository(t *testing.T) {
+	t.Run("CreateAndListByEntity", func(t *testing.T) {
+		testQueries, user := SetupTestDBWithUser(t)
+		auditRepo := NewAuditLogRepository(testQueries)
+
+		entry, err := auditRepo.CreateAuditLog(context.Background(), db.CreateAuditLogParams{
+			ActorUserID: user.ID,
+			Action:      "event.create",
+			EntityType:  "event",
+			EntityID:    42,
+			BeforeValue: "null",
+			AfterValue:  `{"id":42}`,
+		})
+		if err != nil {
+			t.Fatalf("failed to create audit log: %v", err)
+		}
+		if err := auditRepo.AddAuditLogSubject(context.Background(), db.AddAuditLogSubjectParams{AuditLogID: entry.ID, UserID: user.ID}); err != nil {
+			t.Fatalf("failed to add audit log subject: %v", err)
+		}
+
+		entries, err := auditRepo.ListAuditLogsByEntity(context.Background(), db.ListAuditLogsByEntityParams{EntityType: "event", EntityID: 42})
+		if err != nil {
+			t.Fatalf("failed to list audit logs: %v", err)
+		}
+		if len(entries) != 1 || entries[0].ID != entry.ID {
+			t.Fatalf("expected the created entry, got %+v", entries)
+		}
+
+		count, err := auditRepo.CountAuditLogSubjectEntries(context.Background(), db.CountAuditLogSubjectEntriesParams{EntityType: "event", EntityID: 42, UserID: user.ID})
+		if err != nil {
+			t.Fatalf("failed to count subject entries: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("expected 1 subject entry, got %d", count)
+		}
+	})
+
+	t.Run("AppendOnly", func(t *testing.T) {
+		testQueries, user := SetupTestDBWithUser(t)
+		auditRepo := NewAuditLogRepository(testQueries)
+
+		entry, err := auditRepo.CreateAuditLog(context.Background(), db.CreateAuditLogParams{
+			ActorUserID: user.ID,
+			Action:      "event.create",
+			EntityType:  "event",
+			EntityID:    1,
+			BeforeValue: "null",
+			AfterValue:  "null",
+		})
+		if err != nil {
+			t.Fatalf("failed to create audit log: %v", err)
+		}
+
+		// Tamper with the table directly; the triggers must refuse it.
+		conn, err := testQueries.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("failed to begin transaction: %v", err)
+		}
+		defer conn.Rollback()
+		if _, err := conn.Exec("UPDATE audit_logs SET action = 'tampered' WHERE id = ?", entry.ID); err == nil {
+			t.Error("expected update of audit_logs to fail")
+		}
+		if _, err := conn.Exec("DELETE FROM audit_logs WHERE id = ?", entry.ID); err == nil {
+			t.Error("expected delete from audit_logs to fail")
+		}
+	})
+
+	t.Run("TransactionRollback", func(t *testing.T) {
+		testQueries, user := SetupTestDBWithUser(t)
+		auditRepo := NewAuditLogRepository(testQueries)
+		transactor := NewTransactor(testQueries)
+
+		errAbort := context.Canceled
+		err := transactor.WithinTx(context.Background(), func(ctx context.Context) error {
+			_, err := auditRepo.CreateAuditLog(ctx, db.CreateAuditLogParams{
+				ActorUserID: user.ID,
+				Action:      "event.create",
+				EntityType:  "event",
+				EntityID:    7,
+				BeforeValue: "null",
+				AfterValue:  "null",
+			})
+			if err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if err != errAbort {
+			t.Fatalf("expected abort error, got %v", err)
+		}
+
+		entries, err := auditRepo.ListAuditLogsByEntity(context.Background(), db.ListAuditLogsByEntityParams{EntityType: "event", EntityID: 7})
+		if err != nil {
+			t.Fatalf("failed to list audit logs: %v", err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("expected rolled back entry to be absent, got %d entries", len(entries))
+		}
+	})
+}
@@ -16,10 +16,15 @@ type EventRepository interface {
 	DeleteEvent(ctx context.Context, id int64) error
 	GetSwappableEvents(ctx context.Context, userID int64) ([]db.GetSwappableEventsRow, error)
 	UpdateEvent(ctx context.Context, arg db.UpdateEventParams) (db.Event, error)
+	ListEventsByUserID(ctx context.Context, arg db.ListEventsByUserIDParams) ([]db.Event, error)
+	ListEventsByUserIDDesc(ctx context.Context, arg db.ListEventsByUserIDDescParams) ([]db.Event, error)
+	ListSwappableEvents(ctx context.Context, arg db.ListSwappableEventsParams) ([]db.ListSwappableEventsRow, error)
+	ListSwappableEventsDesc(ctx context.Context, arg db.ListSwappableEventsDescParams) ([]db.ListSwappableEventsDescRow, error)
+	ListOverlappingEvents(ctx context.Context, arg db.ListOverlappingEventsParams) ([]db.Event, error)
 }
 
 func (r *eventRepository) DeleteEvent(ctx context.Context, id int64) error {
-	return r.queries.DeleteEvent(ctx, id)
+	return queriesFor(ctx, r.queries).DeleteEvent(ctx, id)
 }
 
 type eventRepository struct {
@@ -27,37 +32,57 @@ type eventRepository struct {
 }
 
 func NewEventRepository(queries *db.Queries) EventRepository {
-	return &eventRepository{queries: queries}
+	return &tracedEventRepository{next: &eventRepository{queries: queries}}
 }
 
 func (r *eventRepository) CreateEvent(ctx context.Context, arg db.CreateEventParams) (db.Event, error) {
-	return r.queries.CreateEvent(ctx, arg)
+	return queriesFor(ctx, r.queries).CreateEvent(ctx, arg)
 }
 
 func (r *eventRepository) GetEventByID(ctx context.Context, id int64) (db.Event, error) {
-	return r.queries.GetEventByID(ctx, id)
+	return queriesFor(ctx, r.queries).GetEventByID(ctx, id)
 }
 
 func (r *eventRepository) GetEventsByUserID(ctx context.Context, userID int64) ([]db.Event, error) {
-	return r.queries.GetEventsByUserID(ctx, userID)
+	return queriesFor(ctx, r.queries).GetEventsByUserID(ctx, userID)
 }
 
 func (r *eventRepository) GetEventsByUserIDAndStatus(ctx context.Context, params db.GetEventsByUserIDAndStatusParams) ([]db.Event, error) {
-	return r.queries.GetEventsByUserIDAndStatus(ctx, params)
+	return queriesFor(ctx, r.queries).GetEventsByUserIDAndStatus(ctx, params)
 }
 
 func (r *eventRepository) UpdateEventStatus(ctx context.Context, arg db.UpdateEventStatusParams) (db.Event, error) {
-	return r.queries.UpdateEventStatus(ctx, arg)
+	return queriesFor(ctx, r.queries).UpdateEventStatus(ctx, arg)
 }
 
 func (r *eventRepository) UpdateEventUserID(ctx context.Context, arg db.UpdateEventUserIDParams) (db.Event, error) {
-	return r.queries.UpdateEventUserID(ctx, arg)
+	return queriesFor(ctx, r.queries).UpdateEventUserID(ctx, arg)
 }
 
 func (r *eventRepository) GetSwappableEvents(ctx context.Context, userID int64) ([]db.GetSwappableEventsRow, error) {
-	return r.queries.GetSwappableEvents(ctx, userID)
+	return queriesFor(ctx, r.queries).GetSwappableEvents(ctx, userID)
 }
 
 func (r *eventRepository) UpdateEvent(ctx context.Context, arg db.UpdateEventParams) (db.Event, error) {
-	return r.queries.UpdateEvent(ctx, arg)
+	return queriesFor(ctx, r.queries).UpdateEvent(ctx, arg)
+}
+
+func (r *eventRepository) ListEventsByUserID(ctx context.Context, arg db.ListEventsByUserIDParams) ([]db.Event, error) {
+	return queriesFor(ctx, r.queries).ListEventsByUserID(ctx, arg)
+}
+
+func (r *eventRepository) ListEventsByUserIDDesc(ctx context.Context, arg db.ListEventsByUserIDDescParams) ([]db.Event, error) {
+	return queriesFor(ctx, r.queries).ListEventsByUserIDDesc(ctx, arg)
+}
+
+func (r *eventRepository) ListSwappableEvents(ctx context.Context, arg db.ListSwappableEventsParams) ([]db.ListSwappableEventsRow, error) {
+	return queriesFor(ctx, r.queries).ListSwappableEvents(ctx, arg)
+}
+
+func (r *eventRepository) ListSwappableEventsDesc(ctx context.Context, arg db.ListSwappableEventsDescParams) ([]db.ListSwappableEventsDescRow, error) {
+	return queriesFor(ctx, r.queries).ListSwappableEventsDesc(ctx, arg)
+}
+
+func (r *eventRepository) ListOverlappingEvents(ctx context.Context, arg db.ListOverlappingEventsParams) ([]db.Event, error) {
+	return queriesFor(ctx, r.queries).ListOverlappingEvents(ctx, arg)
 }
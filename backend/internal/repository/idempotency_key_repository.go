@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"slotswapper/internal/db"
+)
+
+type IdempotencyKeyRepository interface {
+	// CreateIdempotencyKey returns the number of rows inserted, which is 0
+	// if the key already exists.
+	CreateIdempotencyKey(ctx context.Context, arg db.CreateIdempotencyKeyParams) (int64, error)
+	GetIdempotencyKey(ctx context.Context, arg db.GetIdempotencyKeyParams) (db.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, arg db.CompleteIdempotencyKeyParams) error
+	DeleteIdempotencyKey(ctx context.Context, arg db.DeleteIdempotencyKeyParams) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error
+}
+
+type idempotencyKeyRepository struct {
+	queries *db.Queries
+}
+
+func NewIdempotencyKeyRepository(queries *db.Queries) IdempotencyKeyRepository {
+	return &tracedIdempotencyKeyRepository{next: &idempotencyKeyRepository{queries: queries}}
+}
+
+func (r *idempotencyKeyRepository) CreateIdempotencyKey(ctx context.Context, arg db.CreateIdempotencyKeyParams) (int64, error) {
+	return queriesFor(ctx, r.queries).CreateIdempotencyKey(ctx, arg)
+}
+
+func (r *idempotencyKeyRepository) GetIdempotencyKey(ctx context.Context, arg db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	return queriesFor(ctx, r.queries).GetIdempotencyKey(ctx, arg)
+}
+
+func (r *idempotencyKeyRepository) CompleteIdempotencyKey(ctx context.Context, arg db.CompleteIdempotencyKeyParams) error {
+	return queriesFor(ctx, r.queries).CompleteIdempotencyKey(ctx, arg)
+}
+
+func (r *idempotencyKeyRepository) DeleteIdempotencyKey(ctx context.Context, arg db.DeleteIdempotencyKeyParams) error {
+	return queriesFor(ctx, r.queries).DeleteIdempotencyKey(ctx, arg)
+}
+
+func (r *idempotencyKeyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error {
+	return queriesFor(ctx, r.queries).DeleteExpiredIdempotencyKeys(ctx, now)
+}
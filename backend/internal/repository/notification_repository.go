@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"slotswapper/internal/db"
+)
+
+type NotificationRepository interface {
+	CreateNotification(ctx context.Context, arg db.CreateNotificationParams) (db.Notification, error)
+	ListNotificationsByUserID(ctx context.Context, arg db.ListNotificationsByUserIDParams) ([]db.Notification, error)
+	MarkNotificationRead(ctx context.Context, arg db.MarkNotificationReadParams) (db.Notification, error)
+}
+
+type notificationRepository struct {
+	queries *db.Queries
+}
+
+func NewNotificationRepository(queries *db.Queries) NotificationRepository {
+	return &tracedNotificationRepository{next: &notificationRepository{queries: queries}}
+}
+
+func (r *notificationRepository) CreateNotification(ctx context.Context, arg db.CreateNotificationParams) (db.Notification, error) {
+	return queriesFor(ctx, r.queries).CreateNotification(ctx, arg)
+}
+
+func (r *notificationRepository) ListNotificationsByUserID(ctx context.Context, arg db.ListNotificationsByUserIDParams) ([]db.Notification, error) {
+	return queriesFor(ctx, r.queries).ListNotificationsByUserID(ctx, arg)
+}
+
+func (r *notificationRepository) MarkNotificationRead(ctx context.Context, arg db.MarkNotificationReadParams) (db.Notification, error) {
+	return queriesFor(ctx, r.queries).MarkNotificationRead(ctx, arg)
+}
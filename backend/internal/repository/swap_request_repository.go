@@ -9,11 +9,17 @@ import (
 type SwapRequestRepository interface {
 	CreateSwapRequest(ctx context.Context, arg db.CreateSwapRequestParams) (db.SwapRequest, error)
 	GetSwapRequestByID(ctx context.Context, id int64) (db.SwapRequest, error)
-	GetIncomingSwapRequests(ctx context.Context, userID int64) ([]db.GetIncomingSwapRequestsRow, error)
-	GetOutgoingSwapRequests(ctx context.Context, requesterUserID int64) ([]db.GetOutgoingSwapRequestsRow, error)
 	UpdateSwapRequestStatus(ctx context.Context, arg db.UpdateSwapRequestStatusParams) (db.SwapRequest, error)
-	DeleteSwapRequest(ctx context.Context, id int64) error
+	ResolveSwapRequest(ctx context.Context, arg db.ResolveSwapRequestParams) (db.SwapRequest, error)
+	GetIncomingSwapRequestHistory(ctx context.Context, arg db.GetIncomingSwapRequestHistoryParams) ([]db.GetIncomingSwapRequestHistoryRow, error)
+	GetOutgoingSwapRequestHistory(ctx context.Context, arg db.GetOutgoingSwapRequestHistoryParams) ([]db.GetOutgoingSwapRequestHistoryRow, error)
+	CancelSwapRequest(ctx context.Context, arg db.CancelSwapRequestParams) (db.SwapRequest, error)
 	GetSwapRequestsByEventID(ctx context.Context, eventID int64) ([]db.SwapRequest, error)
+	GetPendingSwapRequestsByUserID(ctx context.Context, userID int64) ([]db.SwapRequest, error)
+	ListIncomingSwapRequests(ctx context.Context, arg db.ListIncomingSwapRequestsParams) ([]db.ListIncomingSwapRequestsRow, error)
+	ListIncomingSwapRequestsDesc(ctx context.Context, arg db.ListIncomingSwapRequestsDescParams) ([]db.ListIncomingSwapRequestsDescRow, error)
+	ListOutgoingSwapRequests(ctx context.Context, arg db.ListOutgoingSwapRequestsParams) ([]db.ListOutgoingSwapRequestsRow, error)
+	ListOutgoingSwapRequestsDesc(ctx context.Context, arg db.ListOutgoingSwapRequestsDescParams) ([]db.ListOutgoingSwapRequestsDescRow, error)
 }
 
 type swapRequestRepository struct {
@@ -21,33 +27,57 @@ type swapRequestRepository struct {
 }
 
 func NewSwapRequestRepository(queries *db.Queries) SwapRequestRepository {
-	return &swapRequestRepository{queries: queries}
+	return &tracedSwapRequestRepository{next: &swapRequestRepository{queries: queries}}
 }
 
 func (r *swapRequestRepository) CreateSwapRequest(ctx context.Context, arg db.CreateSwapRequestParams) (db.SwapRequest, error) {
-	return r.queries.CreateSwapRequest(ctx, arg)
+	return queriesFor(ctx, r.queries).CreateSwapRequest(ctx, arg)
 }
 
 func (r *swapRequestRepository) GetSwapRequestByID(ctx context.Context, id int64) (db.SwapRequest, error) {
-	return r.queries.GetSwapRequestByID(ctx, id)
+	return queriesFor(ctx, r.queries).GetSwapRequestByID(ctx, id)
 }
 
-func (r *swapRequestRepository) GetIncomingSwapRequests(ctx context.Context, userID int64) ([]db.GetIncomingSwapRequestsRow, error) {
-	return r.queries.GetIncomingSwapRequests(ctx, userID)
+func (r *swapRequestRepository) UpdateSwapRequestStatus(ctx context.Context, arg db.UpdateSwapRequestStatusParams) (db.SwapRequest, error) {
+	return queriesFor(ctx, r.queries).UpdateSwapRequestStatus(ctx, arg)
 }
 
-func (r *swapRequestRepository) GetOutgoingSwapRequests(ctx context.Context, requesterUserID int64) ([]db.GetOutgoingSwapRequestsRow, error) {
-	return r.queries.GetOutgoingSwapRequests(ctx, requesterUserID)
+func (r *swapRequestRepository) ResolveSwapRequest(ctx context.Context, arg db.ResolveSwapRequestParams) (db.SwapRequest, error) {
+	return queriesFor(ctx, r.queries).ResolveSwapRequest(ctx, arg)
 }
 
-func (r *swapRequestRepository) UpdateSwapRequestStatus(ctx context.Context, arg db.UpdateSwapRequestStatusParams) (db.SwapRequest, error) {
-	return r.queries.UpdateSwapRequestStatus(ctx, arg)
+func (r *swapRequestRepository) GetIncomingSwapRequestHistory(ctx context.Context, arg db.GetIncomingSwapRequestHistoryParams) ([]db.GetIncomingSwapRequestHistoryRow, error) {
+	return queriesFor(ctx, r.queries).GetIncomingSwapRequestHistory(ctx, arg)
+}
+
+func (r *swapRequestRepository) GetOutgoingSwapRequestHistory(ctx context.Context, arg db.GetOutgoingSwapRequestHistoryParams) ([]db.GetOutgoingSwapRequestHistoryRow, error) {
+	return queriesFor(ctx, r.queries).GetOutgoingSwapRequestHistory(ctx, arg)
 }
 
-func (r *swapRequestRepository) DeleteSwapRequest(ctx context.Context, id int64) error {
-	return r.queries.DeleteSwapRequest(ctx, id)
+func (r *swapRequestRepository) CancelSwapRequest(ctx context.Context, arg db.CancelSwapRequestParams) (db.SwapRequest, error) {
+	return queriesFor(ctx, r.queries).CancelSwapRequest(ctx, arg)
 }
 
 func (r *swapRequestRepository) GetSwapRequestsByEventID(ctx context.Context, eventID int64) ([]db.SwapRequest, error) {
-	return r.queries.GetSwapRequestsByEventID(ctx, db.GetSwapRequestsByEventIDParams{RequesterSlotID: eventID, ResponderSlotID: eventID})
+	return queriesFor(ctx, r.queries).GetSwapRequestsByEventID(ctx, db.GetSwapRequestsByEventIDParams{RequesterSlotID: &eventID, ResponderSlotID: eventID})
+}
+
+func (r *swapRequestRepository) GetPendingSwapRequestsByUserID(ctx context.Context, userID int64) ([]db.SwapRequest, error) {
+	return queriesFor(ctx, r.queries).GetPendingSwapRequestsByUserID(ctx, userID)
+}
+
+func (r *swapRequestRepository) ListIncomingSwapRequests(ctx context.Context, arg db.ListIncomingSwapRequestsParams) ([]db.ListIncomingSwapRequestsRow, error) {
+	return queriesFor(ctx, r.queries).ListIncomingSwapRequests(ctx, arg)
+}
+
+func (r *swapRequestRepository) ListIncomingSwapRequestsDesc(ctx context.Context, arg db.ListIncomingSwapRequestsDescParams) ([]db.ListIncomingSwapRequestsDescRow, error) {
+	return queriesFor(ctx, r.queries).ListIncomingSwapRequestsDesc(ctx, arg)
+}
+
+func (r *swapRequestRepository) ListOutgoingSwapRequests(ctx context.Context, arg db.ListOutgoingSwapRequestsParams) ([]db.ListOutgoingSwapRequestsRow, error) {
+	return queriesFor(ctx, r.queries).ListOutgoingSwapRequests(ctx, arg)
+}
+
+func (r *swapRequestRepository) ListOutgoingSwapRequestsDesc(ctx context.Context, arg db.ListOutgoingSwapRequestsDescParams) ([]db.ListOutgoingSwapRequestsDescRow, error) {
+	return queriesFor(ctx, r.queries).ListOutgoingSwapRequestsDesc(ctx, arg)
 }
@@ -51,9 +51,10 @@ func TestSwapRequestRepository(t *testing.T) {
 		arg := db.CreateSwapRequestParams{
 			RequesterUserID: user1.ID,
 			ResponderUserID: user2.ID,
-			RequesterSlotID: event1.ID,
+			RequesterSlotID: &event1.ID,
 			ResponderSlotID: event2.ID,
 			Status:          "PENDING",
+			Kind:            "SWAP",
 		}
 
 		swapRequest, err := swapRepo.CreateSwapRequest(context.Background(), arg)
@@ -107,9 +108,10 @@ func TestSwapRequestRepository(t *testing.T) {
 		createArg := db.CreateSwapRequestParams{
 			RequesterUserID: user1.ID,
 			ResponderUserID: user2.ID,
-			RequesterSlotID: event1.ID,
+			RequesterSlotID: &event1.ID,
 			ResponderSlotID: event2.ID,
 			Status:          "PENDING",
+			Kind:            "SWAP",
 		}
 
 		createdSwapRequest, err := swapRepo.CreateSwapRequest(context.Background(), createArg)
@@ -127,7 +129,7 @@ func TestSwapRequestRepository(t *testing.T) {
 		}
 	})
 
-	t.Run("GetIncomingSwapRequests", func(t *testing.T) {
+	t.Run("ListIncomingSwapRequests", func(t *testing.T) {
 		testQueries, user1 := SetupTestDBWithUser(t)
 		user2, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
 			Name:     "user2_incoming",
@@ -165,16 +167,17 @@ func TestSwapRequestRepository(t *testing.T) {
 		createArg := db.CreateSwapRequestParams{
 			RequesterUserID: user1.ID,
 			ResponderUserID: user2.ID,
-			RequesterSlotID: event1.ID,
+			RequesterSlotID: &event1.ID,
 			ResponderSlotID: event2.ID,
 			Status:          "PENDING",
+			Kind:            "SWAP",
 		}
 		_, err = swapRepo.CreateSwapRequest(context.Background(), createArg)
 		if err != nil {
 			t.Fatalf("failed to create swap request: %v", err)
 		}
 
-		incomingRequests, err := swapRepo.GetIncomingSwapRequests(context.Background(), user2.ID)
+		incomingRequests, err := swapRepo.ListIncomingSwapRequests(context.Background(), db.ListIncomingSwapRequestsParams{UserID: user2.ID, Limit: 10})
 		if err != nil {
 			t.Fatalf("failed to get incoming swap requests: %v", err)
 		}
@@ -187,7 +190,7 @@ func TestSwapRequestRepository(t *testing.T) {
 		}
 	})
 
-	t.Run("GetOutgoingSwapRequests", func(t *testing.T) {
+	t.Run("ListOutgoingSwapRequests", func(t *testing.T) {
 		testQueries, user1 := SetupTestDBWithUser(t)
 		user2, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
 			Name:     "user2_outgoing",
@@ -225,16 +228,17 @@ func TestSwapRequestRepository(t *testing.T) {
 		createArg := db.CreateSwapRequestParams{
 			RequesterUserID: user1.ID,
 			ResponderUserID: user2.ID,
-			RequesterSlotID: event1.ID,
+			RequesterSlotID: &event1.ID,
 			ResponderSlotID: event2.ID,
 			Status:          "PENDING",
+			Kind:            "SWAP",
 		}
 		_, err = swapRepo.CreateSwapRequest(context.Background(), createArg)
 		if err != nil {
 			t.Fatalf("failed to create swap request: %v", err)
 		}
 
-		outgoingRequests, err := swapRepo.GetOutgoingSwapRequests(context.Background(), user1.ID)
+		outgoingRequests, err := swapRepo.ListOutgoingSwapRequests(context.Background(), db.ListOutgoingSwapRequestsParams{UserID: user1.ID, Limit: 10})
 		if err != nil {
 			t.Fatalf("failed to get outgoing swap requests: %v", err)
 		}
@@ -285,9 +289,10 @@ func TestSwapRequestRepository(t *testing.T) {
 		createArg := db.CreateSwapRequestParams{
 			RequesterUserID: user1.ID,
 			ResponderUserID: user2.ID,
-			RequesterSlotID: event1.ID,
+			RequesterSlotID: &event1.ID,
 			ResponderSlotID: event2.ID,
 			Status:          "PENDING",
+			Kind:            "SWAP",
 		}
 
 		createdSwapRequest, err := swapRepo.CreateSwapRequest(context.Background(), createArg)
@@ -4,16 +4,22 @@ import (
 	"context"
 	"database/sql"
 	"log"
-	"os"
 	"path/filepath"
 	"testing"
 
+	"slotswapper/db/migrations"
 	"slotswapper/internal/db"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 func SetupTestDB(t *testing.T) *db.Queries {
+	return db.New(SetupTestConn(t))
+}
+
+// SetupTestConn opens a migrated database for t, for tests that build their
+// own queries on the connection.
+func SetupTestConn(t *testing.T) *sql.DB {
 	tempDir := t.TempDir()
 	dbPath := filepath.Join(tempDir, "test.db")
 
@@ -22,13 +28,7 @@ func SetupTestDB(t *testing.T) *db.Queries {
 		log.Fatalf("failed to open database: %v", err)
 	}
 
-	migrations, err := os.ReadFile("../../db/migrations/001_initial_schema.sql")
-	if err != nil {
-		log.Fatalf("failed to read migrations: %v", err)
-	}
-
-	_, err = dbConn.Exec(string(migrations))
-	if err != nil {
+	if err := migrations.Apply(context.Background(), dbConn); err != nil {
 		log.Fatalf("failed to run migrations: %v", err)
 	}
 
@@ -36,7 +36,7 @@ func SetupTestDB(t *testing.T) *db.Queries {
 		dbConn.Close()
 	})
 
-	return db.New(dbConn)
+	return dbConn
 }
 
 func SetupTestDBWithUser(t *testing.T) (*db.Queries, db.User) {
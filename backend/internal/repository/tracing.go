@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/tracing"
+)
+
+// The repositories returned by the constructors are wrapped in the traced
+// types below, so every repository call shows up as a span of its own.
+
+// startSpan starts a client span for a call to the database.
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(semconv.DBSystemNameSQLite))
+}
+
+type tracedUserRepository struct {
+	next UserRepository
+}
+
+func (r *tracedUserRepository) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	ctx, span := startSpan(ctx, "UserRepository.CreateUser")
+	result, err := r.next.CreateUser(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedUserRepository) GetUserByEmail(ctx context.Context, email string) (db.User, error) {
+	ctx, span := startSpan(ctx, "UserRepository.GetUserByEmail")
+	result, err := r.next.GetUserByEmail(ctx, email)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedUserRepository) GetUserByID(ctx context.Context, id int64) (db.GetUserByIDRow, error) {
+	ctx, span := startSpan(ctx, "UserRepository.GetUserByID")
+	result, err := r.next.GetUserByID(ctx, id)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedUserRepository) GetPublicUserByID(ctx context.Context, id int64) (db.GetPublicUserByIDRow, error) {
+	ctx, span := startSpan(ctx, "UserRepository.GetPublicUserByID")
+	result, err := r.next.GetPublicUserByID(ctx, id)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedUserRepository) UpdateUserIsAdmin(ctx context.Context, arg db.UpdateUserIsAdminParams) error {
+	ctx, span := startSpan(ctx, "UserRepository.UpdateUserIsAdmin")
+	return tracing.End(span, r.next.UpdateUserIsAdmin(ctx, arg))
+}
+
+func (r *tracedUserRepository) UpdateUserTimeZone(ctx context.Context, arg db.UpdateUserTimeZoneParams) error {
+	ctx, span := startSpan(ctx, "UserRepository.UpdateUserTimeZone")
+	return tracing.End(span, r.next.UpdateUserTimeZone(ctx, arg))
+}
+
+func (r *tracedUserRepository) DeactivateUser(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "UserRepository.DeactivateUser")
+	return tracing.End(span, r.next.DeactivateUser(ctx, id))
+}
+
+type tracedEventRepository struct {
+	next EventRepository
+}
+
+func (r *tracedEventRepository) CreateEvent(ctx context.Context, arg db.CreateEventParams) (db.Event, error) {
+	ctx, span := startSpan(ctx, "EventRepository.CreateEvent")
+	result, err := r.next.CreateEvent(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedEventRepository) GetEventByID(ctx context.Context, id int64) (db.Event, error) {
+	ctx, span := startSpan(ctx, "EventRepository.GetEventByID")
+	result, err := r.next.GetEventByID(ctx, id)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedEventRepository) GetEventsByUserID(ctx context.Context, userID int64) ([]db.Event, error) {
+	ctx, span := startSpan(ctx, "EventRepository.GetEventsByUserID")
+	result, err := r.next.GetEventsByUserID(ctx, userID)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedEventRepository) GetEventsByUserIDAndStatus(ctx context.Context, params db.GetEventsByUserIDAndStatusParams) ([]db.Event, error) {
+	ctx, span := startSpan(ctx, "EventRepository.GetEventsByUserIDAndStatus")
+	result, err := r.next.GetEventsByUserIDAndStatus(ctx, params)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedEventRepository) UpdateEventStatus(ctx context.Context, arg db.UpdateEventStatusParams) (db.Event, error) {
+	ctx, span := startSpan(ctx, "EventRepository.UpdateEventStatus")
+	result, err := r.next.UpdateEventStatus(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedEventRepository) UpdateEventUserID(ctx context.Context, arg db.UpdateEventUserIDParams) (db.Event, error) {
+	ctx, span := startSpan(ctx, "EventRepository.UpdateEventUserID")
+	result, err := r.next.UpdateEventUserID(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedEventRepository) DeleteEvent(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "EventRepository.DeleteEvent")
+	return tracing.End(span, r.next.DeleteEvent(ctx, id))
+}
+
+func (r *tracedEventRepository) GetSwappableEvents(ctx context.Context, userID int64) ([]db.GetSwappableEventsRow, error) {
+	ctx, span := startSpan(ctx, "EventRepository.GetSwappableEvents")
+	result, err := r.next.GetSwappableEvents(ctx, userID)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedEventRepository) UpdateEvent(ctx context.Context, arg db.UpdateEventParams) (db.Event, error) {
+	ctx, span := startSpan(ctx, "EventRepository.UpdateEvent")
+	result, err := r.next.UpdateEvent(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedEventRepository) ListEventsByUserID(ctx context.Context, arg db.ListEventsByUserIDParams) ([]db.Event, error) {
+	ctx, span := startSpan(ctx, "EventRepository.ListEventsByUserID")
+	result, err := r.next.ListEventsByUserID(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedEventRepository) ListEventsByUserIDDesc(ctx context.Context, arg db.ListEventsByUserIDDescParams) ([]db.Event, error) {
+	ctx, span := startSpan(ctx, "EventRepository.ListEventsByUserIDDesc")
+	result, err := r.next.ListEventsByUserIDDesc(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedEventRepository) ListSwappableEvents(ctx context.Context, arg db.ListSwappableEventsParams) ([]db.ListSwappableEventsRow, error) {
+	ctx, span := startSpan(ctx, "EventRepository.ListSwappableEvents")
+	result, err := r.next.ListSwappableEvents(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedEventRepository) ListSwappableEventsDesc(ctx context.Context, arg db.ListSwappableEventsDescParams) ([]db.ListSwappableEventsDescRow, error) {
+	ctx, span := startSpan(ctx, "EventRepository.ListSwappableEventsDesc")
+	result, err := r.next.ListSwappableEventsDesc(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedEventRepository) ListOverlappingEvents(ctx context.Context, arg db.ListOverlappingEventsParams) ([]db.Event, error) {
+	ctx, span := startSpan(ctx, "EventRepository.ListOverlappingEvents")
+	result, err := r.next.ListOverlappingEvents(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+type tracedSwapRequestRepository struct {
+	next SwapRequestRepository
+}
+
+func (r *tracedSwapRequestRepository) CreateSwapRequest(ctx context.Context, arg db.CreateSwapRequestParams) (db.SwapRequest, error) {
+	ctx, span := startSpan(ctx, "SwapRequestRepository.CreateSwapRequest")
+	result, err := r.next.CreateSwapRequest(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedSwapRequestRepository) GetSwapRequestByID(ctx context.Context, id int64) (db.SwapRequest, error) {
+	ctx, span := startSpan(ctx, "SwapRequestRepository.GetSwapRequestByID")
+	result, err := r.next.GetSwapRequestByID(ctx, id)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedSwapRequestRepository) UpdateSwapRequestStatus(ctx context.Context, arg db.UpdateSwapRequestStatusParams) (db.SwapRequest, error) {
+	ctx, span := startSpan(ctx, "SwapRequestRepository.UpdateSwapRequestStatus")
+	result, err := r.next.UpdateSwapRequestStatus(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedSwapRequestRepository) ResolveSwapRequest(ctx context.Context, arg db.ResolveSwapRequestParams) (db.SwapRequest, error) {
+	ctx, span := startSpan(ctx, "SwapRequestRepository.ResolveSwapRequest")
+	result, err := r.next.ResolveSwapRequest(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedSwapRequestRepository) GetIncomingSwapRequestHistory(ctx context.Context, arg db.GetIncomingSwapRequestHistoryParams) ([]db.GetIncomingSwapRequestHistoryRow, error) {
+	ctx, span := startSpan(ctx, "SwapRequestRepository.GetIncomingSwapRequestHistory")
+	result, err := r.next.GetIncomingSwapRequestHistory(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedSwapRequestRepository) GetOutgoingSwapRequestHistory(ctx context.Context, arg db.GetOutgoingSwapRequestHistoryParams) ([]db.GetOutgoingSwapRequestHistoryRow, error) {
+	ctx, span := startSpan(ctx, "SwapRequestRepository.GetOutgoingSwapRequestHistory")
+	result, err := r.next.GetOutgoingSwapRequestHistory(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedSwapRequestRepository) CancelSwapRequest(ctx context.Context, arg db.CancelSwapRequestParams) (db.SwapRequest, error) {
+	ctx, span := startSpan(ctx, "SwapRequestRepository.CancelSwapRequest")
+	result, err := r.next.CancelSwapRequest(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedSwapRequestRepository) GetSwapRequestsByEventID(ctx context.Context, eventID int64) ([]db.SwapRequest, error) {
+	ctx, span := startSpan(ctx, "SwapRequestRepository.GetSwapRequestsByEventID")
+	result, err := r.next.GetSwapRequestsByEventID(ctx, eventID)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedSwapRequestRepository) GetPendingSwapRequestsByUserID(ctx context.Context, userID int64) ([]db.SwapRequest, error) {
+	ctx, span := startSpan(ctx, "SwapRequestRepository.GetPendingSwapRequestsByUserID")
+	result, err := r.next.GetPendingSwapRequestsByUserID(ctx, userID)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedSwapRequestRepository) ListIncomingSwapRequests(ctx context.Context, arg db.ListIncomingSwapRequestsParams) ([]db.ListIncomingSwapRequestsRow, error) {
+	ctx, span := startSpan(ctx, "SwapRequestRepository.ListIncomingSwapRequests")
+	result, err := r.next.ListIncomingSwapRequests(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedSwapRequestRepository) ListIncomingSwapRequestsDesc(ctx context.Context, arg db.ListIncomingSwapRequestsDescParams) ([]db.ListIncomingSwapRequestsDescRow, error) {
+	ctx, span := startSpan(ctx, "SwapRequestRepository.ListIncomingSwapRequestsDesc")
+	result, err := r.next.ListIncomingSwapRequestsDesc(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedSwapRequestRepository) ListOutgoingSwapRequests(ctx context.Context, arg db.ListOutgoingSwapRequestsParams) ([]db.ListOutgoingSwapRequestsRow, error) {
+	ctx, span := startSpan(ctx, "SwapRequestRepository.ListOutgoingSwapRequests")
+	result, err := r.next.ListOutgoingSwapRequests(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedSwapRequestRepository) ListOutgoingSwapRequestsDesc(ctx context.Context, arg db.ListOutgoingSwapRequestsDescParams) ([]db.ListOutgoingSwapRequestsDescRow, error) {
+	ctx, span := startSpan(ctx, "SwapRequestRepository.ListOutgoingSwapRequestsDesc")
+	result, err := r.next.ListOutgoingSwapRequestsDesc(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+type tracedAuditLogRepository struct {
+	next AuditLogRepository
+}
+
+func (r *tracedAuditLogRepository) CreateAuditLog(ctx context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+	ctx, span := startSpan(ctx, "AuditLogRepository.CreateAuditLog")
+	result, err := r.next.CreateAuditLog(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedAuditLogRepository) AddAuditLogSubject(ctx context.Context, arg db.AddAuditLogSubjectParams) error {
+	ctx, span := startSpan(ctx, "AuditLogRepository.AddAuditLogSubject")
+	return tracing.End(span, r.next.AddAuditLogSubject(ctx, arg))
+}
+
+func (r *tracedAuditLogRepository) ListAuditLogsByEntity(ctx context.Context, arg db.ListAuditLogsByEntityParams) ([]db.AuditLog, error) {
+	ctx, span := startSpan(ctx, "AuditLogRepository.ListAuditLogsByEntity")
+	result, err := r.next.ListAuditLogsByEntity(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedAuditLogRepository) ListAuditLogsBySubject(ctx context.Context, arg db.ListAuditLogsBySubjectParams) ([]db.AuditLog, error) {
+	ctx, span := startSpan(ctx, "AuditLogRepository.ListAuditLogsBySubject")
+	result, err := r.next.ListAuditLogsBySubject(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedAuditLogRepository) CountAuditLogSubjectEntries(ctx context.Context, arg db.CountAuditLogSubjectEntriesParams) (int64, error) {
+	ctx, span := startSpan(ctx, "AuditLogRepository.CountAuditLogSubjectEntries")
+	result, err := r.next.CountAuditLogSubjectEntries(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedAuditLogRepository) ListAuditLogs(ctx context.Context, arg db.ListAuditLogsParams) ([]db.AuditLog, error) {
+	ctx, span := startSpan(ctx, "AuditLogRepository.ListAuditLogs")
+	result, err := r.next.ListAuditLogs(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+type tracedAccessTokenRepository struct {
+	next AccessTokenRepository
+}
+
+func (r *tracedAccessTokenRepository) CreatePersonalAccessToken(ctx context.Context, arg db.CreatePersonalAccessTokenParams) (db.PersonalAccessToken, error) {
+	ctx, span := startSpan(ctx, "AccessTokenRepository.CreatePersonalAccessToken")
+	result, err := r.next.CreatePersonalAccessToken(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedAccessTokenRepository) GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (db.PersonalAccessToken, error) {
+	ctx, span := startSpan(ctx, "AccessTokenRepository.GetPersonalAccessTokenByHash")
+	result, err := r.next.GetPersonalAccessTokenByHash(ctx, tokenHash)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedAccessTokenRepository) GetPersonalAccessTokenByID(ctx context.Context, id int64) (db.PersonalAccessToken, error) {
+	ctx, span := startSpan(ctx, "AccessTokenRepository.GetPersonalAccessTokenByID")
+	result, err := r.next.GetPersonalAccessTokenByID(ctx, id)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedAccessTokenRepository) ListPersonalAccessTokensByUserID(ctx context.Context, userID int64) ([]db.PersonalAccessToken, error) {
+	ctx, span := startSpan(ctx, "AccessTokenRepository.ListPersonalAccessTokensByUserID")
+	result, err := r.next.ListPersonalAccessTokensByUserID(ctx, userID)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedAccessTokenRepository) UpdatePersonalAccessTokenLastUsed(ctx context.Context, arg db.UpdatePersonalAccessTokenLastUsedParams) error {
+	ctx, span := startSpan(ctx, "AccessTokenRepository.UpdatePersonalAccessTokenLastUsed")
+	return tracing.End(span, r.next.UpdatePersonalAccessTokenLastUsed(ctx, arg))
+}
+
+func (r *tracedAccessTokenRepository) DeletePersonalAccessToken(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "AccessTokenRepository.DeletePersonalAccessToken")
+	return tracing.End(span, r.next.DeletePersonalAccessToken(ctx, id))
+}
+
+func (r *tracedAccessTokenRepository) DeletePersonalAccessTokensByUserID(ctx context.Context, userID int64) error {
+	ctx, span := startSpan(ctx, "AccessTokenRepository.DeletePersonalAccessTokensByUserID")
+	return tracing.End(span, r.next.DeletePersonalAccessTokensByUserID(ctx, userID))
+}
+
+type tracedIdempotencyKeyRepository struct {
+	next IdempotencyKeyRepository
+}
+
+func (r *tracedIdempotencyKeyRepository) CreateIdempotencyKey(ctx context.Context, arg db.CreateIdempotencyKeyParams) (int64, error) {
+	ctx, span := startSpan(ctx, "IdempotencyKeyRepository.CreateIdempotencyKey")
+	result, err := r.next.CreateIdempotencyKey(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedIdempotencyKeyRepository) GetIdempotencyKey(ctx context.Context, arg db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	ctx, span := startSpan(ctx, "IdempotencyKeyRepository.GetIdempotencyKey")
+	result, err := r.next.GetIdempotencyKey(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedIdempotencyKeyRepository) CompleteIdempotencyKey(ctx context.Context, arg db.CompleteIdempotencyKeyParams) error {
+	ctx, span := startSpan(ctx, "IdempotencyKeyRepository.CompleteIdempotencyKey")
+	return tracing.End(span, r.next.CompleteIdempotencyKey(ctx, arg))
+}
+
+func (r *tracedIdempotencyKeyRepository) DeleteIdempotencyKey(ctx context.Context, arg db.DeleteIdempotencyKeyParams) error {
+	ctx, span := startSpan(ctx, "IdempotencyKeyRepository.DeleteIdempotencyKey")
+	return tracing.End(span, r.next.DeleteIdempotencyKey(ctx, arg))
+}
+
+func (r *tracedIdempotencyKeyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error {
+	ctx, span := startSpan(ctx, "IdempotencyKeyRepository.DeleteExpiredIdempotencyKeys")
+	return tracing.End(span, r.next.DeleteExpiredIdempotencyKeys(ctx, now))
+}
+
+type tracedNotificationRepository struct {
+	next NotificationRepository
+}
+
+func (r *tracedNotificationRepository) CreateNotification(ctx context.Context, arg db.CreateNotificationParams) (db.Notification, error) {
+	ctx, span := startSpan(ctx, "NotificationRepository.CreateNotification")
+	result, err := r.next.CreateNotification(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedNotificationRepository) ListNotificationsByUserID(ctx context.Context, arg db.ListNotificationsByUserIDParams) ([]db.Notification, error) {
+	ctx, span := startSpan(ctx, "NotificationRepository.ListNotificationsByUserID")
+	result, err := r.next.ListNotificationsByUserID(ctx, arg)
+	return result, tracing.End(span, err)
+}
+
+func (r *tracedNotificationRepository) MarkNotificationRead(ctx context.Context, arg db.MarkNotificationReadParams) (db.Notification, error) {
+	ctx, span := startSpan(ctx, "NotificationRepository.MarkNotificationRead")
+	result, err := r.next.MarkNotificationRead(ctx, arg)
+	return result, tracing.End(span, err)
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/logging"
+	"slotswapper/internal/tracing"
+)
+
+type txContextKey struct{}
+
+// Transactor runs a unit of work inside a single database transaction.
+// Repositories pick the transaction up from the context passed to fn, so
+// services keep calling them exactly as they would outside a transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type transactor struct {
+	queries *db.Queries
+}
+
+func NewTransactor(queries *db.Queries) Transactor {
+	return &transactor{queries: queries}
+}
+
+func (t *transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	// Nested units of work join the outer transaction.
+	if _, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	ctx, span := startSpan(ctx, "Transactor.WithinTx")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := t.queries.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, tx)); err != nil {
+		tx.Rollback()
+		logging.FromContext(ctx).Debug("transaction rolled back", "error", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logging.FromContext(ctx).Error("transaction commit failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+// queriesFor returns queries bound to the transaction carried by ctx, if any.
+func queriesFor(ctx context.Context, queries *db.Queries) *db.Queries {
+	if tx, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return queries.Bind(tx)
+	}
+	return queries
+}
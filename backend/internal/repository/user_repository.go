@@ -11,6 +11,9 @@ type UserRepository interface {
 	GetUserByEmail(ctx context.Context, email string) (db.User, error)
 	GetUserByID(ctx context.Context, id int64) (db.GetUserByIDRow, error)
 	GetPublicUserByID(ctx context.Context, id int64) (db.GetPublicUserByIDRow, error)
+	UpdateUserIsAdmin(ctx context.Context, arg db.UpdateUserIsAdminParams) error
+	UpdateUserTimeZone(ctx context.Context, arg db.UpdateUserTimeZoneParams) error
+	DeactivateUser(ctx context.Context, id int64) error
 }
 
 type userRepository struct {
@@ -18,21 +21,33 @@ type userRepository struct {
 }
 
 func NewUserRepository(queries *db.Queries) UserRepository {
-	return &userRepository{queries: queries}
+	return &tracedUserRepository{next: &userRepository{queries: queries}}
 }
 
 func (r *userRepository) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
-	return r.queries.CreateUser(ctx, arg)
+	return queriesFor(ctx, r.queries).CreateUser(ctx, arg)
 }
 
 func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (db.User, error) {
-	return r.queries.GetUserByEmail(ctx, email)
+	return queriesFor(ctx, r.queries).GetUserByEmail(ctx, email)
 }
 
 func (r *userRepository) GetUserByID(ctx context.Context, id int64) (db.GetUserByIDRow, error) {
-	return r.queries.GetUserByID(ctx, id)
+	return queriesFor(ctx, r.queries).GetUserByID(ctx, id)
 }
 
 func (r *userRepository) GetPublicUserByID(ctx context.Context, id int64) (db.GetPublicUserByIDRow, error) {
-	return r.queries.GetPublicUserByID(ctx, id)
+	return queriesFor(ctx, r.queries).GetPublicUserByID(ctx, id)
+}
+
+func (r *userRepository) UpdateUserIsAdmin(ctx context.Context, arg db.UpdateUserIsAdminParams) error {
+	return queriesFor(ctx, r.queries).UpdateUserIsAdmin(ctx, arg)
+}
+
+func (r *userRepository) UpdateUserTimeZone(ctx context.Context, arg db.UpdateUserTimeZoneParams) error {
+	return queriesFor(ctx, r.queries).UpdateUserTimeZone(ctx, arg)
+}
+
+func (r *userRepository) DeactivateUser(ctx context.Context, id int64) error {
+	return queriesFor(ctx, r.queries).DeactivateUser(ctx, id)
 }
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"slotswapper/internal/crypto"
+	"slotswapper/internal/db"
+	"slotswapper/internal/logging"
+	"slotswapper/internal/repository"
+)
+
+// Scopes a personal access token can be granted. Every authenticated route
+// requires one of them; a session from logging in holds them all.
+const (
+	ScopeProfileRead     = "profile:read"
+	ScopeProfileWrite    = "profile:write"
+	ScopeEventsRead      = "events:read"
+	ScopeEventsWrite     = "events:write"
+	ScopeMarketplaceRead = "marketplace:read"
+	ScopeSwapsRead       = "swaps:read"
+	ScopeSwapsWrite      = "swaps:write"
+	ScopeAuditRead       = "audit:read"
+	// Notifications: reading them, and marking them as read.
+	ScopeNotificationsRead  = "notifications:read"
+	ScopeNotificationsWrite = "notifications:write"
+)
+
+const defaultAccessTokenDays = 30
+
+var (
+	ErrAccessTokenInvalid = newError(ErrUnauthorized, "invalid or expired access token")
+	ErrAccessTokenRefused = newError(ErrForbidden, "personal access tokens cannot be used for this endpoint")
+)
+
+type CreateAccessTokenInput struct {
+	UserID int64    `json:"-" validate:"required"`
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=profile:read profile:write events:read events:write marketplace:read swaps:read swaps:write audit:read notifications:read notifications:write"`
+	// ExpiresInDays defaults to 30.
+	ExpiresInDays int `json:"expires_in_days,omitempty" validate:"omitempty,min=1,max=365"`
+}
+
+// AccessToken describes a personal access token. The token itself is never
+// stored, so it only appears in CreatedAccessToken.
+type AccessToken struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedAccessToken is returned once, when the token is created.
+type CreatedAccessToken struct {
+	AccessToken
+	Token string `json:"token"`
+}
+
+type AccessTokenService interface {
+	CreateAccessToken(ctx context.Context, input CreateAccessTokenInput) (*CreatedAccessToken, error)
+	ListAccessTokens(ctx context.Context, userID int64) ([]AccessToken, error)
+	RevokeAccessToken(ctx context.Context, userID, tokenID int64) error
+	// Authenticate returns the owner of token if it is valid and grants
+	// scope, and records that it was used.
+	Authenticate(ctx context.Context, token, scope string) (int64, error)
+}
+
+type accessTokenService struct {
+	tokenRepo  repository.AccessTokenRepository
+	auditRepo  repository.AuditLogRepository
+	transactor repository.Transactor
+}
+
+func NewAccessTokenService(tokenRepo repository.AccessTokenRepository, auditRepo repository.AuditLogRepository, transactor repository.Transactor) AccessTokenService {
+	return &accessTokenService{tokenRepo: tokenRepo, auditRepo: auditRepo, transactor: transactor}
+}
+
+func (s *accessTokenService) CreateAccessToken(ctx context.Context, input CreateAccessTokenInput) (*CreatedAccessToken, error) {
+	if err := validate(input); err != nil {
+		return nil, err
+	}
+	days := input.ExpiresInDays
+	if days == 0 {
+		days = defaultAccessTokenDays
+	}
+	scopes := slices.Clone(input.Scopes)
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+
+	token, hash := crypto.NewAccessToken()
+	var created AccessToken
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		row, err := s.tokenRepo.CreatePersonalAccessToken(ctx, db.CreatePersonalAccessTokenParams{
+			UserID:    input.UserID,
+			Name:      input.Name,
+			TokenHash: hash,
+			Scopes:    strings.Join(scopes, " "),
+			ExpiresAt: time.Now().UTC().AddDate(0, 0, days),
+		})
+		if err != nil {
+			return err
+		}
+		created = toAccessToken(row)
+
+		return recordAudit(ctx, s.auditRepo, auditRecord{
+			ActorUserID: input.UserID,
+			Action:      AuditActionAccessTokenCreate,
+			EntityType:  AuditEntityUser,
+			EntityID:    input.UserID,
+			After:       created,
+			Subjects:    []int64{input.UserID},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Info("access token created", "user_id", input.UserID, "token_id", created.ID)
+	return &CreatedAccessToken{AccessToken: created, Token: token}, nil
+}
+
+func (s *accessTokenService) ListAccessTokens(ctx context.Context, userID int64) ([]AccessToken, error) {
+	rows, err := s.tokenRepo.ListPersonalAccessTokensByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]AccessToken, len(rows))
+	for i, row := range rows {
+		tokens[i] = toAccessToken(row)
+	}
+	return tokens, nil
+}
+
+func (s *accessTokenService) RevokeAccessToken(ctx context.Context, userID, tokenID int64) error {
+	return s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		row, err := s.tokenRepo.GetPersonalAccessTokenByID(ctx, tokenID)
+		if err != nil {
+			return notFound(err, "access token not found")
+		}
+		// Someone else's token is reported as missing, so IDs cannot be probed.
+		if row.UserID != userID {
+			return newError(ErrNotFound, "access token not found")
+		}
+		if err := s.tokenRepo.DeletePersonalAccessToken(ctx, tokenID); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo, auditRecord{
+			ActorUserID: userID,
+			Action:      AuditActionAccessTokenRevoke,
+			EntityType:  AuditEntityUser,
+			EntityID:    userID,
+			Before:      toAccessToken(row),
+			Subjects:    []int64{userID},
+		})
+	})
+}
+
+func (s *accessTokenService) Authenticate(ctx context.Context, token, scope string) (int64, error) {
+	row, err := s.tokenRepo.GetPersonalAccessTokenByHash(ctx, crypto.HashAccessToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrAccessTokenInvalid
+	}
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	if !now.Before(row.ExpiresAt) {
+		return 0, ErrAccessTokenInvalid
+	}
+	if scope == "" {
+		return 0, ErrAccessTokenRefused
+	}
+	if !slices.Contains(strings.Fields(row.Scopes), scope) {
+		return 0, newError(ErrForbidden, fmt.Sprintf("access token lacks the %s scope", scope))
+	}
+
+	// Failing to record the use must not fail the request.
+	if err := s.tokenRepo.UpdatePersonalAccessTokenLastUsed(ctx, db.UpdatePersonalAccessTokenLastUsedParams{LastUsedAt: &now, ID: row.ID}); err != nil {
+		logging.FromContext(ctx).Warn("failed to record access token use", "token_id", row.ID, "error", err)
+	}
+	return row.UserID, nil
+}
+
+func toAccessToken(row db.PersonalAccessToken) AccessToken {
+	return AccessToken{
+		ID:         row.ID,
+		Name:       row.Name,
+		Scopes:     strings.Fields(row.Scopes),
+		ExpiresAt:  row.ExpiresAt,
+		LastUsedAt: row.LastUsedAt,
+		CreatedAt:  row.CreatedAt,
+	}
+}
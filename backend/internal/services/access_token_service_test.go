@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"slotswapper/internal/crypto"
+	"slotswapper/internal/db"
+	"slotswapper/internal/repository"
+)
+
+func TestAccessTokenService(t *testing.T) {
+	setup := func(t *testing.T) (AccessTokenService, *db.Queries, db.User) {
+		testQueries, user := repository.SetupTestDBWithUser(t)
+		tokenService := NewAccessTokenService(repository.NewAccessTokenRepository(testQueries), repository.NewAuditLogRepository(testQueries), repository.NewTransactor(testQueries))
+		return tokenService, testQueries, user
+	}
+	ctx := context.Background()
+
+	t.Run("Create, authenticate and revoke", func(t *testing.T) {
+		tokenService, testQueries, user := setup(t)
+
+		created, err := tokenService.CreateAccessToken(ctx, CreateAccessTokenInput{UserID: user.ID, Name: "backup script", Scopes: []string{ScopeEventsRead, ScopeEventsRead, ScopeSwapsWrite}})
+		if err != nil {
+			t.Fatalf("failed to create access token: %v", err)
+		}
+		if !crypto.IsAccessToken(created.Token) {
+			t.Errorf("expected a prefixed token, got %q", created.Token)
+		}
+		if len(created.Scopes) != 2 || created.ExpiresAt.Sub(time.Now()) < 29*24*time.Hour {
+			t.Errorf("expected deduplicated scopes and a 30 day expiry, got %+v", created.AccessToken)
+		}
+
+		stored, err := testQueries.GetPersonalAccessTokenByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("failed to load token: %v", err)
+		}
+		if stored.TokenHash == created.Token || stored.TokenHash != crypto.HashAccessToken(created.Token) {
+			t.Errorf("expected only the hash to be stored, got %q", stored.TokenHash)
+		}
+
+		userID, err := tokenService.Authenticate(ctx, created.Token, ScopeEventsRead)
+		if err != nil || userID != user.ID {
+			t.Fatalf("expected the token to authenticate user %d, got %d %v", user.ID, userID, err)
+		}
+		tokens, err := tokenService.ListAccessTokens(ctx, user.ID)
+		if err != nil || len(tokens) != 1 || tokens[0].LastUsedAt == nil {
+			t.Errorf("expected the token to be listed with its last use, got %+v %v", tokens, err)
+		}
+
+		if _, err := tokenService.Authenticate(ctx, created.Token, ScopeEventsWrite); !errors.Is(err, ErrForbidden) || !strings.Contains(err.Error(), ScopeEventsWrite) {
+			t.Errorf("expected a missing scope to be forbidden, got %v", err)
+		}
+		if _, err := tokenService.Authenticate(ctx, created.Token, ""); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected session-only routes to refuse tokens, got %v", err)
+		}
+
+		if err := tokenService.RevokeAccessToken(ctx, user.ID+1, created.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected another user's revoke to find nothing, got %v", err)
+		}
+		if err := tokenService.RevokeAccessToken(ctx, user.ID, created.ID); err != nil {
+			t.Fatalf("failed to revoke token: %v", err)
+		}
+		if _, err := tokenService.Authenticate(ctx, created.Token, ScopeEventsRead); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("expected a revoked token to be refused, got %v", err)
+		}
+
+		logs, err := testQueries.ListAuditLogsByEntity(ctx, db.ListAuditLogsByEntityParams{EntityType: AuditEntityUser, EntityID: user.ID})
+		if err != nil {
+			t.Fatalf("failed to list audit logs: %v", err)
+		}
+		var actions []string
+		for _, entry := range logs {
+			actions = append(actions, entry.Action)
+			if strings.Contains(entry.AfterValue, created.Token) || strings.Contains(entry.BeforeValue, stored.TokenHash) {
+				t.Errorf("audit entry %s leaks the token", entry.Action)
+			}
+		}
+		if strings.Join(actions, ",") != AuditActionAccessTokenCreate+","+AuditActionAccessTokenRevoke {
+			t.Errorf("unexpected audit actions %v", actions)
+		}
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		tokenService, testQueries, user := setup(t)
+		token, hash := crypto.NewAccessToken()
+		_, err := testQueries.CreatePersonalAccessToken(ctx, db.CreatePersonalAccessTokenParams{
+			UserID: user.ID, Name: "old", TokenHash: hash, Scopes: ScopeEventsRead, ExpiresAt: time.Now().UTC().Add(-time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("failed to create token: %v", err)
+		}
+		if _, err := tokenService.Authenticate(ctx, token, ScopeEventsRead); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("expected an expired token to be refused, got %v", err)
+		}
+		if _, err := tokenService.Authenticate(ctx, crypto.AccessTokenPrefix+"unknown", ScopeEventsRead); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("expected an unknown token to be refused, got %v", err)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		tokenService, _, user := setup(t)
+		_, err := tokenService.CreateAccessToken(ctx, CreateAccessTokenInput{UserID: user.ID, Name: "bad", Scopes: []string{"events:delete"}, ExpiresInDays: 400})
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || len(validationErr.Fields) != 2 {
+			t.Fatalf("expected two invalid fields, got %v", err)
+		}
+		if validationErr.Fields[0].Field != "scopes[0]" {
+			t.Errorf("expected the scope to be reported by index, got %+v", validationErr.Fields)
+		}
+		if _, err := tokenService.CreateAccessToken(ctx, CreateAccessTokenInput{UserID: user.ID, Name: "none"}); !errors.As(err, &validationErr) {
+			t.Errorf("expected scopes to be required, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"math"
+	"time"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/repository"
+)
+
+// Audit actions recorded by the services.
+const (
+	AuditActionUserCreate           = "user.create"
+	AuditActionUserUpdate           = "user.update"
+	AuditActionUserDeactivate       = "user.deactivate"
+	AuditActionAccessTokenCreate    = "user.access_token_create"
+	AuditActionAccessTokenRevoke    = "user.access_token_revoke"
+	AuditActionEventCreate          = "event.create"
+	AuditActionEventUpdate          = "event.update"
+	AuditActionEventStatusUpdate    = "event.status_update"
+	AuditActionEventTransfer        = "event.transfer"
+	AuditActionEventDelete          = "event.delete"
+	AuditActionSwapRequestCreate    = "swap_request.create"
+	AuditActionSwapRequestResolve   = "swap_request.status_update"
+	AuditActionSwapRequestCancel    = "swap_request.cancel"
+	AuditActionSwapRequestWithdraw  = "swap_request.withdraw"
+	AuditActionSwapRequestSupersede = "swap_request.supersede"
+)
+
+// Audited entity types.
+const (
+	AuditEntityUser        = "user"
+	AuditEntityEvent       = "event"
+	AuditEntitySwapRequest = "swap_request"
+)
+
+const (
+	defaultAuditLogLimit = 50
+	maxAuditLogLimit     = 200
+)
+
+var ErrAuditLogForbidden = newError(ErrForbidden, "user is not authorized to view this audit log")
+
+// RequestMetadata describes the request that triggered a mutation.
+type RequestMetadata struct {
+	RequestID string
+	IPAddress string
+	UserAgent string
+}
+
+type requestMetadataKey struct{}
+
+// WithRequestMetadata attaches request metadata to ctx so that audit entries
+// written further down the call chain can record it.
+func WithRequestMetadata(ctx context.Context, meta RequestMetadata) context.Context {
+	return context.WithValue(ctx, requestMetadataKey{}, meta)
+}
+
+// RequestMetadataFromContext returns the metadata attached by WithRequestMetadata.
+func RequestMetadataFromContext(ctx context.Context) RequestMetadata {
+	meta, _ := ctx.Value(requestMetadataKey{}).(RequestMetadata)
+	return meta
+}
+
+// AuditLogEntry is the API representation of an audit log row.
+type AuditLogEntry struct {
+	ID          int64           `json:"id"`
+	ActorUserID int64           `json:"actor_user_id"`
+	Action      string          `json:"action"`
+	EntityType  string          `json:"entity_type"`
+	EntityID    int64           `json:"entity_id"`
+	Before      json.RawMessage `json:"before"`
+	After       json.RawMessage `json:"after"`
+	RequestID   string          `json:"request_id"`
+	IPAddress   string          `json:"ip_address"`
+	UserAgent   string          `json:"user_agent"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type ListAuditLogsInput struct {
+	RequestingUserID int64
+	EntityType       string
+	ActorUserID      int64
+	BeforeID         int64
+	Limit            int64
+}
+
+type AuditService interface {
+	GetUserAuditLogs(ctx context.Context, userID, beforeID, limit int64) ([]AuditLogEntry, error)
+	GetEventAuditLogs(ctx context.Context, eventID, userID int64) ([]AuditLogEntry, error)
+	ListAuditLogs(ctx context.Context, input ListAuditLogsInput) ([]AuditLogEntry, error)
+}
+
+type auditService struct {
+	auditRepo repository.AuditLogRepository
+	userRepo  repository.UserRepository
+}
+
+func NewAuditService(auditRepo repository.AuditLogRepository, userRepo repository.UserRepository) AuditService {
+	return &auditService{auditRepo: auditRepo, userRepo: userRepo}
+}
+
+// GetUserAuditLogs returns entries that touched the user's data, newest first.
+func (s *auditService) GetUserAuditLogs(ctx context.Context, userID, beforeID, limit int64) ([]AuditLogEntry, error) {
+	rows, err := s.auditRepo.ListAuditLogsBySubject(ctx, db.ListAuditLogsBySubjectParams{
+		UserID:   userID,
+		BeforeID: auditCursor(beforeID),
+		Limit:    auditLimit(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toAuditLogEntries(rows), nil
+}
+
+// GetEventAuditLogs returns the full history of an event. Admins and anyone
+// who has ever owned the event may read it.
+func (s *auditService) GetEventAuditLogs(ctx context.Context, eventID, userID int64) ([]AuditLogEntry, error) {
+	isAdmin, err := s.isAdmin(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isAdmin {
+		count, err := s.auditRepo.CountAuditLogSubjectEntries(ctx, db.CountAuditLogSubjectEntriesParams{
+			EntityType: AuditEntityEvent,
+			EntityID:   eventID,
+			UserID:     userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, ErrAuditLogForbidden
+		}
+	}
+
+	rows, err := s.auditRepo.ListAuditLogsByEntity(ctx, db.ListAuditLogsByEntityParams{
+		EntityType: AuditEntityEvent,
+		EntityID:   eventID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toAuditLogEntries(rows), nil
+}
+
+// ListAuditLogs returns every entry matching the filters. Admin only.
+func (s *auditService) ListAuditLogs(ctx context.Context, input ListAuditLogsInput) ([]AuditLogEntry, error) {
+	isAdmin, err := s.isAdmin(ctx, input.RequestingUserID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, ErrAuditLogForbidden
+	}
+
+	arg := db.ListAuditLogsParams{
+		EntityType:  sql.NullString{String: input.EntityType, Valid: input.EntityType != ""},
+		ActorUserID: sql.NullInt64{Int64: input.ActorUserID, Valid: input.ActorUserID != 0},
+		BeforeID:    auditCursor(input.BeforeID),
+		Limit:       auditLimit(input.Limit),
+	}
+	rows, err := s.auditRepo.ListAuditLogs(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	return toAuditLogEntries(rows), nil
+}
+
+func (s *auditService) isAdmin(ctx context.Context, userID int64) (bool, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return user.IsAdmin, nil
+}
+
+func auditCursor(beforeID int64) int64 {
+	if beforeID <= 0 {
+		return math.MaxInt64
+	}
+	return beforeID
+}
+
+func auditLimit(limit int64) int64 {
+	if limit <= 0 {
+		return defaultAuditLogLimit
+	}
+	if limit > maxAuditLogLimit {
+		return maxAuditLogLimit
+	}
+	return limit
+}
+
+func toAuditLogEntries(rows []db.AuditLog) []AuditLogEntry {
+	entries := make([]AuditLogEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, AuditLogEntry{
+			ID:          row.ID,
+			ActorUserID: row.ActorUserID,
+			Action:      row.Action,
+			EntityType:  row.EntityType,
+			EntityID:    row.EntityID,
+			Before:      json.RawMessage(row.BeforeValue),
+			After:       json.RawMessage(row.AfterValue),
+			RequestID:   row.RequestID,
+			IPAddress:   row.IpAddress,
+			UserAgent:   row.UserAgent,
+			CreatedAt:   row.CreatedAt,
+		})
+	}
+	return entries
+}
+
+// auditRecord describes a single mutation to be written to the audit log.
+type auditRecord struct {
+	ActorUserID int64
+	Action      string
+	EntityType  string
+	EntityID    int64
+	Before      any
+	After       any
+	// Subjects are the users whose data the mutation touched.
+	Subjects []int64
+}
+
+// recordAudit appends an entry to the audit log. Callers run it inside the
+// same transaction as the mutation it describes.
+func recordAudit(ctx context.Context, auditRepo repository.AuditLogRepository, record auditRecord) error {
+	before, err := json.Marshal(record.Before)
+	if err != nil {
+		return err
+	}
+	after, err := json.Marshal(record.After)
+	if err != nil {
+		return err
+	}
+
+	meta := RequestMetadataFromContext(ctx)
+	entry, err := auditRepo.CreateAuditLog(ctx, db.CreateAuditLogParams{
+		ActorUserID: record.ActorUserID,
+		Action:      record.Action,
+		EntityType:  record.EntityType,
+		EntityID:    record.EntityID,
+		BeforeValue: string(before),
+		AfterValue:  string(after),
+		RequestID:   meta.RequestID,
+		IpAddress:   meta.IPAddress,
+		UserAgent:   meta.UserAgent,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range record.Subjects {
+		err := auditRepo.AddAuditLogSubject(ctx, db.AddAuditLogSubjectParams{AuditLogID: entry.ID, UserID: userID})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
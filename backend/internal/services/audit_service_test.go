@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/repository"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestAuditService(t *testing.T) {
+	setup := func(t *testing.T) (*db.Queries, db.User, db.User, SwapRequestService, AuditService) {
+		testQueries, user1 := repository.SetupTestDBWithUser(t)
+		user2, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
+			Name:     "user2_audit",
+			Email:    "user2_audit@example.com",
+			Password: "password",
+		})
+		if err != nil {
+			t.Fatalf("failed to create user2: %v", err)
+		}
+
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+		auditService := NewAuditService(auditRepo, userRepo)
+		return testQueries, user1, user2, swapService, auditService
+	}
+
+	createEvent := func(t *testing.T, queries *db.Queries, userID int64) db.Event {
+		event, err := queries.CreateEvent(context.Background(), db.CreateEventParams{
+			Title:     "Audited Event",
+			StartTime: time.Now(),
+			EndTime:   time.Now().Add(time.Hour),
+			Status:    "SWAPPABLE",
+			UserID:    userID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		return event
+	}
+
+	t.Run("AcceptedSwapIsVisibleToPreviousOwner", func(t *testing.T) {
+		testQueries, user1, user2, swapService, auditService := setup(t)
+		event1 := createEvent(t, testQueries, user1.ID)
+		event2 := createEvent(t, testQueries, user2.ID)
+
+		ctx := WithRequestMetadata(context.Background(), RequestMetadata{RequestID: "req-1", IPAddress: "203.0.113.7", UserAgent: "test"})
+		swapRequest, err := swapService.CreateSwapRequest(ctx, CreateSwapRequestInput{
+			RequesterUserID: user1.ID,
+			ResponderUserID: user2.ID,
+			RequesterSlotID: event1.ID,
+			ResponderSlotID: event2.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create swap request: %v", err)
+		}
+		_, err = swapService.UpdateSwapRequestStatus(ctx, UpdateSwapRequestStatusInput{ID: swapRequest.ID, Status: "ACCEPTED", UserID: user2.ID})
+		if err != nil {
+			t.Fatalf("failed to accept swap request: %v", err)
+		}
+
+		entries, err := auditService.GetEventAuditLogs(context.Background(), event1.ID, user1.ID)
+		if err != nil {
+			t.Fatalf("failed to get event audit logs: %v", err)
+		}
+
+		var transfer *AuditLogEntry
+		for i := range entries {
+			if entries[i].Action == AuditActionEventTransfer {
+				transfer = &entries[i]
+			}
+		}
+		if transfer == nil {
+			t.Fatalf("expected a %s entry, got %+v", AuditActionEventTransfer, entries)
+		}
+		if transfer.ActorUserID != user2.ID {
+			t.Errorf("expected actor %d, got %d", user2.ID, transfer.ActorUserID)
+		}
+		if transfer.RequestID != "req-1" || transfer.IPAddress != "203.0.113.7" {
+			t.Errorf("expected request metadata to be recorded, got %q %q", transfer.RequestID, transfer.IPAddress)
+		}
+
+		var before, after db.Event
+		if err := json.Unmarshal(transfer.Before, &before); err != nil {
+			t.Fatalf("failed to decode before value: %v", err)
+		}
+		if err := json.Unmarshal(transfer.After, &after); err != nil {
+			t.Fatalf("failed to decode after value: %v", err)
+		}
+		if before.UserID != user1.ID || after.UserID != user2.ID {
+			t.Errorf("expected ownership %d -> %d, got %d -> %d", user1.ID, user2.ID, before.UserID, after.UserID)
+		}
+
+		mine, err := auditService.GetUserAuditLogs(context.Background(), user1.ID, 0, 0)
+		if err != nil {
+			t.Fatalf("failed to get user audit logs: %v", err)
+		}
+		if len(mine) == 0 {
+			t.Error("expected user history to include the swap")
+		}
+	})
+
+	t.Run("EventHistoryForbiddenForStrangers", func(t *testing.T) {
+		testQueries, user1, user2, _, auditService := setup(t)
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		eventService := NewEventService(eventRepo, userRepo, repository.NewSwapRequestRepository(testQueries), repository.NewAuditLogRepository(testQueries), repository.NewNotificationRepository(testQueries), repository.NewTransactor(testQueries))
+
+		event, err := eventService.CreateEvent(context.Background(), CreateEventInput{
+			Title:     "Private Event",
+			StartTime: time.Now(),
+			EndTime:   time.Now().Add(time.Hour),
+			Status:    "BUSY",
+			UserID:    user1.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+
+		_, err = auditService.GetEventAuditLogs(context.Background(), event.ID, user2.ID)
+		if err != ErrAuditLogForbidden {
+			t.Fatalf("expected ErrAuditLogForbidden, got %v", err)
+		}
+
+		if err := userRepo.UpdateUserIsAdmin(context.Background(), db.UpdateUserIsAdminParams{IsAdmin: true, ID: user2.ID}); err != nil {
+			t.Fatalf("failed to promote user2: %v", err)
+		}
+		entries, err := auditService.GetEventAuditLogs(context.Background(), event.ID, user2.ID)
+		if err != nil {
+			t.Fatalf("expected admin to read event history, got %v", err)
+		}
+		if len(entries) != 1 || entries[0].Action != AuditActionEventCreate {
+			t.Errorf("expected a single %s entry, got %+v", AuditActionEventCreate, entries)
+		}
+	})
+
+	t.Run("ListAuditLogsRequiresAdmin", func(t *testing.T) {
+		testQueries, user1, _, _, auditService := setup(t)
+
+		_, err := auditService.ListAuditLogs(context.Background(), ListAuditLogsInput{RequestingUserID: user1.ID})
+		if err != ErrAuditLogForbidden {
+			t.Fatalf("expected ErrAuditLogForbidden, got %v", err)
+		}
+
+		userRepo := repository.NewUserRepository(testQueries)
+		if err := userRepo.UpdateUserIsAdmin(context.Background(), db.UpdateUserIsAdminParams{IsAdmin: true, ID: user1.ID}); err != nil {
+			t.Fatalf("failed to promote user1: %v", err)
+		}
+		if _, err := auditService.ListAuditLogs(context.Background(), ListAuditLogsInput{RequestingUserID: user1.ID, EntityType: AuditEntityEvent}); err != nil {
+			t.Fatalf("expected admin to list audit logs, got %v", err)
+		}
+	})
+}
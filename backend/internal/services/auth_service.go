@@ -2,19 +2,22 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"strings"
 
 	"slotswapper/internal/crypto"
 	"slotswapper/internal/db"
+	"slotswapper/internal/logging"
+	"slotswapper/internal/metrics"
 	"slotswapper/internal/repository"
-	"slotswapper/internal/validation"
 )
 
 type RegisterUserInput struct {
 	Name     string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
+	TimeZone string `json:"time_zone" validate:"omitempty,timezone"`
 }
 
 type LoginInput struct {
@@ -29,18 +32,23 @@ type AuthService interface {
 
 type authService struct {
 	userRepo   repository.UserRepository
+	auditRepo  repository.AuditLogRepository
+	transactor repository.Transactor
 	password   crypto.Password
 	jwtManager crypto.JWT
 }
 
-func NewAuthService(userRepo repository.UserRepository, password crypto.Password, jwtManager crypto.JWT) AuthService {
-	return &authService{userRepo: userRepo, password: password, jwtManager: jwtManager}
+func NewAuthService(userRepo repository.UserRepository, auditRepo repository.AuditLogRepository, transactor repository.Transactor, password crypto.Password, jwtManager crypto.JWT) AuthService {
+	return &authService{userRepo: userRepo, auditRepo: auditRepo, transactor: transactor, password: password, jwtManager: jwtManager}
 }
 
-var ErrEmailExists = errors.New("user with this email already exists")
+var (
+	ErrEmailExists        = newError(ErrConflict, "user with this email already exists")
+	ErrInvalidCredentials = newError(ErrUnauthorized, "invalid email or password")
+)
 
 func (s *authService) Register(ctx context.Context, input RegisterUserInput) (*db.User, string, error) {
-	if err := validation.Validate.Struct(input); err != nil {
+	if err := validate(input); err != nil {
 		return nil, "", err
 	}
 
@@ -61,7 +69,7 @@ func (s *authService) Register(ctx context.Context, input RegisterUserInput) (*d
 		Password: hashedPassword,
 	}
 
-	user, err := s.userRepo.CreateUser(ctx, arg)
+	user, err := createUserAudited(ctx, s.userRepo, s.auditRepo, s.transactor, arg, input.TimeZone)
 	if err != nil {
 		// Check for unique constraint violation
 		if strings.Contains(err.Error(), "UNIQUE constraint failed: users.email") {
@@ -75,21 +83,34 @@ func (s *authService) Register(ctx context.Context, input RegisterUserInput) (*d
 		return nil, "", err
 	}
 	user.Password = ""
+	logging.FromContext(ctx).Info("user registered", "user_id", user.ID)
 	return &user, token, nil
 }
 
 func (s *authService) Login(ctx context.Context, input LoginInput) (*db.User, string, error) {
-	if err := validation.Validate.Struct(input); err != nil {
+	if err := validate(input); err != nil {
 		return nil, "", err
 	}
 
 	user, err := s.userRepo.GetUserByEmail(ctx, input.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		metrics.LoginFailures.Inc()
+		return nil, "", ErrInvalidCredentials
+	}
 	if err != nil {
-		return nil, "", errors.New("invalid email or password")
+		return nil, "", err
+	}
+
+	// A deactivated account cannot sign in again.
+	if user.DeactivatedAt != nil {
+		metrics.LoginFailures.Inc()
+		return nil, "", ErrInvalidCredentials
 	}
 
 	if err := s.password.Verify(user.Password, input.Password); err != nil {
-		return nil, "", errors.New("invalid email or password")
+		metrics.LoginFailures.Inc()
+		logging.FromContext(ctx).Info("login failed: wrong password", "user_id", user.ID)
+		return nil, "", ErrInvalidCredentials
 	}
 
 	token, err := s.jwtManager.Generate(user.ID)
@@ -97,5 +118,6 @@ func (s *authService) Login(ctx context.Context, input LoginInput) (*db.User, st
 		return nil, "", err
 	}
 	user.Password = ""
+	logging.FromContext(ctx).Info("user logged in", "user_id", user.ID)
 	return &user, token, nil
 }
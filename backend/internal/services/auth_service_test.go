@@ -18,9 +18,11 @@ func TestAuthService(t *testing.T) {
 	t.Run("Register and Login", func(t *testing.T) {
 		testQueries := repository.SetupTestDB(t)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		passwordCrypto := crypto.NewPassword()
 		jwtManager := crypto.NewJWT(jwtSecret, jwtTTL)
-		authService := NewAuthService(userRepo, passwordCrypto, jwtManager)
+		authService := NewAuthService(userRepo, auditRepo, transactor, passwordCrypto, jwtManager)
 
 		password := "password123"
 		registerInput := RegisterUserInput{
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/repository"
+)
+
+// ConflictError is returned when a change would leave a user owning events
+// that overlap. Events lists the caller's existing events that collide.
+// Counterparty, when set, is the role of the other user in a swap whose
+// events collide too; their events are private and not listed.
+type ConflictError struct {
+	Events       []db.Event
+	Counterparty Actor
+}
+
+func (e *ConflictError) Error() string {
+	var message string
+	switch len(e.Events) {
+	case 0:
+	case 1:
+		message = "event overlaps an existing event"
+	default:
+		message = fmt.Sprintf("event overlaps %d existing events", len(e.Events))
+	}
+	if e.Counterparty == "" {
+		return message
+	}
+	if message == "" {
+		return fmt.Sprintf("the %s has a conflicting event", e.Counterparty)
+	}
+	return fmt.Sprintf("%s, and the %s has a conflicting event", message, e.Counterparty)
+}
+
+func (e *ConflictError) Unwrap() error { return ErrConflict }
+
+// slotClaim describes a slot a user is about to own. ExcludeID is an event
+// that should not count as a conflict: the event being edited, or the slot
+// the user gives away in a swap.
+type slotClaim struct {
+	UserID    int64
+	StartTime time.Time
+	EndTime   time.Time
+	ExcludeID int64
+}
+
+// checkConflicts returns a *ConflictError listing every existing event that
+// overlaps one of the claims. Intervals are half-open, so back-to-back events
+// do not conflict.
+func checkConflicts(ctx context.Context, eventRepo repository.EventRepository, claims ...slotClaim) error {
+	var conflicts []db.Event
+	for _, claim := range claims {
+		events, err := eventRepo.ListOverlappingEvents(ctx, db.ListOverlappingEventsParams{
+			UserID:    claim.UserID,
+			StartTime: claim.StartTime.UTC(),
+			EndTime:   claim.EndTime.UTC(),
+			ExcludeID: claim.ExcludeID,
+		})
+		if err != nil {
+			return err
+		}
+		conflicts = append(conflicts, events...)
+	}
+
+	if len(conflicts) > 0 {
+		return &ConflictError{Events: conflicts}
+	}
+	return nil
+}
+
+// checkCounterpartyConflicts adds the claims of the other user in a swap,
+// in role counterparty, to own, the result of checking the caller's claims.
+// A conflict of theirs is reported without their events.
+func checkCounterpartyConflicts(ctx context.Context, eventRepo repository.EventRepository, own error, counterparty Actor, claims ...slotClaim) error {
+	var ownConflict *ConflictError
+	if own != nil && !errors.As(own, &ownConflict) {
+		return own
+	}
+	err := checkConflicts(ctx, eventRepo, claims...)
+	var theirs *ConflictError
+	if !errors.As(err, &theirs) {
+		if err != nil {
+			return err
+		}
+		return own
+	}
+	conflict := &ConflictError{Counterparty: counterparty}
+	if ownConflict != nil {
+		conflict.Events = ownConflict.Events
+	}
+	return conflict
+}
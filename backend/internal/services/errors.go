@@ -0,0 +1,171 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"slotswapper/internal/validation"
+)
+
+// Error kinds. Every error a service returns because of the caller's request,
+// rather than an internal failure, wraps exactly one of these so that callers
+// can classify it with errors.Is.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrInvalidState = errors.New("invalid state")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrPreconditionFailed means the resource changed since the caller last
+	// read it.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Error is a domain error: a message for the caller classified by one of the
+// error kinds above.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func (e *Error) Unwrap() error { return e.Kind }
+
+func newError(kind error, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// notFound turns a missing row into an ErrNotFound error with message and
+// passes any other error through.
+func notFound(err error, message string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return newError(ErrNotFound, message)
+	}
+	return err
+}
+
+// FieldError describes why one input field was rejected. Field is the name
+// the client used, i.e. the JSON or query parameter name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field of an input.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error { return ErrValidation }
+
+func newValidationError(field, rule, message string) *ValidationError {
+	return &ValidationError{Fields: []FieldError{{Field: field, Rule: rule, Message: message}}}
+}
+
+// validate checks input against its validate tags and reports failures as a
+// *ValidationError.
+func validate(input any) error {
+	err := validation.Validate.Struct(input)
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	inputType := reflect.TypeOf(input)
+	fields := make([]FieldError, len(validationErrors))
+	for i, fe := range validationErrors {
+		param := fe.Param()
+		if strings.HasSuffix(fe.Tag(), "field") {
+			// Cross-field rules name the other field by its Go name.
+			param = jsonFieldName(inputType, param)
+		}
+		fields[i] = fieldError(fe.Field(), fe.Tag(), param, fe.Kind())
+	}
+	return &ValidationError{Fields: fields}
+}
+
+// validateVar checks a single value, such as a query parameter, reporting
+// failures under the given field name.
+func validateVar(field string, value any, tag string) error {
+	err := validation.Validate.Var(value, tag)
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	fields := make([]FieldError, len(validationErrors))
+	for i, fe := range validationErrors {
+		fields[i] = fieldError(field, fe.Tag(), fe.Param(), fe.Kind())
+	}
+	return &ValidationError{Fields: fields}
+}
+
+func fieldError(field, rule, param string, kind reflect.Kind) FieldError {
+	var message string
+	switch rule {
+	case "required":
+		message = "is required"
+	case "email":
+		message = "must be a valid email address"
+	case "timezone":
+		message = "must be an IANA time zone name"
+	case "oneof":
+		message = "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "min", "max":
+		bound := "at least"
+		if rule == "max" {
+			bound = "at most"
+		}
+		if kind == reflect.String {
+			message = fmt.Sprintf("must be %s %s characters long", bound, param)
+		} else {
+			message = fmt.Sprintf("must be %s %s", bound, param)
+		}
+	case "gtfield":
+		message = "must be after " + param
+	case "ltfield":
+		message = "must be before " + param
+	case "excluded_unless":
+		other, value, _ := strings.Cut(param, " ")
+		message = fmt.Sprintf("is only allowed when %s is %s", other, value)
+	default:
+		message = fmt.Sprintf("failed the %q rule", rule)
+	}
+	return FieldError{Field: field, Rule: rule, Param: param, Message: field + " " + message}
+}
+
+// jsonFieldName returns the JSON name of the struct field called name, or
+// name itself if t has no such field.
+func jsonFieldName(t reflect.Type, name string) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return name
+	}
+	field, ok := t.FieldByName(name)
+	if !ok {
+		return name
+	}
+	if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag != "" && tag != "-" {
+		return tag
+	}
+	return name
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name  string
+		input any
+		want  []FieldError
+	}{
+		{
+			name:  "valid",
+			input: CreateEventInput{Title: "Event", StartTime: now, EndTime: now.Add(time.Hour), Status: "BUSY", UserID: 1},
+		},
+		{
+			name:  "fields use JSON names",
+			input: CreateEventInput{StartTime: now, EndTime: now.Add(-time.Hour), Status: "FREE", UserID: 1},
+			want: []FieldError{
+				{Field: "title", Rule: "required", Message: "title is required"},
+				{Field: "end_time", Rule: "gtfield", Param: "start_time", Message: "end_time must be after start_time"},
+				{Field: "status", Rule: "oneof", Param: "BUSY SWAPPABLE", Message: "status must be one of BUSY, SWAPPABLE"},
+			},
+		},
+		{
+			name:  "string length",
+			input: RegisterUserInput{Name: "Name", Email: "not-an-email", Password: "short"},
+			want: []FieldError{
+				{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+				{Field: "password", Rule: "min", Param: "8", Message: "password must be at least 8 characters long"},
+			},
+		},
+		{
+			name:  "fields hidden from JSON keep their Go name",
+			input: UpdateTimeZoneInput{TimeZone: "Europe/Berlin"},
+			want:  []FieldError{{Field: "UserID", Rule: "required", Message: "UserID is required"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validate(tc.input)
+			if tc.want == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a *ValidationError, got %v", err)
+			}
+			if !errors.Is(err, ErrValidation) {
+				t.Error("expected the error to wrap ErrValidation")
+			}
+			if !reflect.DeepEqual(validationErr.Fields, tc.want) {
+				t.Errorf("expected fields %+v, got %+v", tc.want, validationErr.Fields)
+			}
+		})
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"event not owned", ErrEventNotOwned, ErrForbidden},
+		{"audit log forbidden", ErrAuditLogForbidden, ErrForbidden},
+		{"email exists", ErrEmailExists, ErrConflict},
+		{"invalid credentials", ErrInvalidCredentials, ErrUnauthorized},
+		{"invalid cursor", ErrInvalidCursor, ErrValidation},
+		{"overlap", &ConflictError{}, ErrConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if !errors.Is(tc.err, tc.kind) {
+				t.Errorf("expected %v to be of kind %v", tc.err, tc.kind)
+			}
+		})
+	}
+}
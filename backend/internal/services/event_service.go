@@ -2,95 +2,264 @@ package services
 
 import (
 	"context"
-	"errors"
+	"database/sql"
+	"math"
+	"reflect"
+	"slices"
 	"time"
 
 	"slotswapper/internal/db"
+	"slotswapper/internal/logging"
+	"slotswapper/internal/metrics"
 	"slotswapper/internal/repository"
-	"slotswapper/internal/validation"
 )
 
 type CreateEventInput struct {
 	Title     string    `json:"title" validate:"required"`
 	StartTime time.Time `json:"start_time" validate:"required"`
 	EndTime   time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
-	Status    string    `json:"status" validate:"required,oneof=BUSY SWAPPABLE SWAP_PENDING"`
-	UserID    int64     `json:"user_id" validate:"required"`
+	Status    string    `json:"status" validate:"required,oneof=BUSY SWAPPABLE"`
+	UserID    int64     `json:"-" validate:"required"` // Owner, set from the authenticated user
+	// TimeZone is the IANA zone the event was planned in. It defaults to the
+	// owner's preferred zone.
+	TimeZone string `json:"time_zone" validate:"omitempty,timezone"`
+	// AllowOverlap skips the check against the user's other events.
+	AllowOverlap bool `json:"allow_overlap"`
 }
 
 type UpdateEventStatusInput struct {
-	ID     int64  `json:"id" validate:"required"`
-	Status string `json:"status" validate:"required,oneof=BUSY SWAPPABLE SWAP_PENDING"`
-	UserID int64  `json:"user_id" validate:"required"` // User performing the update
+	ID int64 `json:"-" validate:"required"`
+	// Status is BUSY or SWAPPABLE; only the swap flow sets SWAP_PENDING.
+	Status string `json:"status" validate:"required,oneof=BUSY SWAPPABLE"`
+	// Giveaway, with the SWAPPABLE status, gives the event away instead of
+	// only offering it for swaps. Leaving it out ends a giveaway.
+	Giveaway string `json:"giveaway,omitempty" validate:"omitempty,excluded_unless=Status SWAPPABLE,oneof=FIRST_COME OWNER_PICKS"`
+	UserID   int64  `json:"-" validate:"required"` // User performing the update
+	// Version, when set, is the version of the event the user last saw.
+	Version int64 `json:"-"`
 }
 
 type UpdateEventInput struct {
-	ID        int64     `json:"id"`
+	ID        int64     `json:"-"`
 	Title     string    `json:"title" validate:"required"`
 	StartTime time.Time `json:"start_time" validate:"required"`
 	EndTime   time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
-	UserID    int64     `json:"user_id"`
+	UserID    int64     `json:"-"`
+	// Version, when set, is the version of the event the user last saw.
+	Version int64 `json:"-"`
+	// TimeZone replaces the event's zone when set.
+	TimeZone string `json:"time_zone" validate:"omitempty,timezone"`
+	// AllowOverlap skips the check against the user's other events.
+	AllowOverlap bool `json:"allow_overlap"`
 }
 
+// PatchEventInput is a partial update of an event, applied with JSON Merge
+// Patch semantics: nil fields are left as they are, and only the fields that
+// are set are validated.
+type PatchEventInput struct {
+	ID        int64      `json:"-"`
+	UserID    int64      `json:"-"`
+	Version   int64      `json:"-"`
+	Title     *string    `json:"title,omitempty"`
+	StartTime *time.Time `json:"start_time,omitempty"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+	TimeZone  *string    `json:"time_zone,omitempty" validate:"omitnil,timezone"`
+	// AllowOverlap skips the check against the user's other events.
+	AllowOverlap bool `json:"-"`
+}
+
+// PatchEventResult is a patched event and what else the patch changed.
+type PatchEventResult struct {
+	Event       db.Event         `json:"event"`
+	SideEffects EventSideEffects `json:"side_effects"`
+}
+
+// EventSideEffects lists the changes an edit made beyond the event's fields.
+// Moving an event that is part of pending swaps cancels them.
+type EventSideEffects struct {
+	// CancelledSwapRequests are the pending swap requests that were cancelled.
+	CancelledSwapRequests []int64 `json:"cancelled_swap_requests"`
+	// ReleasedSlots are the slots other users had reserved for those
+	// requests, which are back on the marketplace.
+	ReleasedSlots []int64 `json:"released_slots"`
+	// StatusChanged is set when the event's status changed too, from
+	// SWAP_PENDING back to BUSY.
+	StatusChanged bool `json:"status_changed"`
+}
+
+// EventListFilter narrows and orders an event listing. Zero times leave that
+// bound open; all bounds are inclusive.
+type EventListFilter struct {
+	StartFrom time.Time `json:"start_from"`
+	StartTo   time.Time `json:"start_to"`
+	EndFrom   time.Time `json:"end_from"`
+	EndTo     time.Time `json:"end_to"`
+	Sort      string    `json:"sort" validate:"omitempty,oneof=start_time -start_time"`
+	PageRequest
+}
+
+const defaultEventSort = "start_time"
+
+var (
+	ErrEventNotOwned = newError(ErrForbidden, "user does not own this event")
+	ErrEventModified = newError(ErrPreconditionFailed, "event has been modified since it was read")
+)
+
 type EventService interface {
 	CreateEvent(ctx context.Context, input CreateEventInput) (*db.Event, error)
+	CreateRecurringEvents(ctx context.Context, input CreateEventInput, recurrence RecurrenceInput) ([]db.Event, error)
 	GetEventByID(ctx context.Context, id int64) (*db.Event, error)
 	GetEventsByUserID(ctx context.Context, userID int64) ([]db.Event, error)
 	GetEventsByUserIDAndStatus(ctx context.Context, userID int64, status string) ([]db.Event, error)
 	UpdateEventStatus(ctx context.Context, input UpdateEventStatusInput) (*db.Event, error)
 	UpdateEvent(ctx context.Context, input UpdateEventInput) (*db.Event, error)
-	DeleteEvent(ctx context.Context, eventID, userID int64) error
+	PatchEvent(ctx context.Context, input PatchEventInput) (*PatchEventResult, error)
+	// DeleteEvent deletes the event. Its pending swap requests are cancelled
+	// and the other users' slots released in the same transaction. A non-zero
+	// version makes the deletion conditional on the event still being at that
+	// version.
+	DeleteEvent(ctx context.Context, eventID, userID, version int64) error
 	GetSwappableEvents(ctx context.Context, userID int64) ([]db.GetSwappableEventsRow, error)
+	ListEventsByUserID(ctx context.Context, userID int64, status string, filter EventListFilter) (*Page[db.Event], error)
+	ListSwappableEvents(ctx context.Context, userID int64, filter EventListFilter) (*Page[db.ListSwappableEventsRow], error)
 }
 
-func (s *eventService) DeleteEvent(ctx context.Context, eventID, userID int64) error {
-	event, err := s.eventRepo.GetEventByID(ctx, eventID)
+type eventService struct {
+	eventRepo        repository.EventRepository
+	userRepo         repository.UserRepository
+	swapRepo         repository.SwapRequestRepository
+	auditRepo        repository.AuditLogRepository
+	notificationRepo repository.NotificationRepository
+	transactor       repository.Transactor
+}
+
+func NewEventService(eventRepo repository.EventRepository, userRepo repository.UserRepository, swapRepo repository.SwapRequestRepository, auditRepo repository.AuditLogRepository, notificationRepo repository.NotificationRepository, transactor repository.Transactor) EventService {
+	return &tracedEventService{next: &eventService{eventRepo: eventRepo, userRepo: userRepo, swapRepo: swapRepo, auditRepo: auditRepo, notificationRepo: notificationRepo, transactor: transactor}}
+}
+
+func (s *eventService) CreateEvent(ctx context.Context, input CreateEventInput) (*db.Event, error) {
+	if err := validate(input); err != nil {
+		return nil, err
+	}
+
+	timeZone, err := s.eventTimeZone(ctx, input)
 	if err != nil {
-		return errors.New("event not found")
+		return nil, err
 	}
 
-	if event.UserID != userID {
-		return errors.New("user does not own this event")
+	var event db.Event
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		event, err = s.createEvent(ctx, input, occurrence{StartTime: input.StartTime.UTC(), EndTime: input.EndTime.UTC()}, timeZone)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return s.eventRepo.DeleteEvent(ctx, eventID)
+	logging.FromContext(ctx).Info("event created", "event_id", event.ID, "status", event.Status)
+	return &event, nil
 }
 
-type eventService struct {
-	eventRepo repository.EventRepository
-	userRepo  repository.UserRepository
-	swapRepo  repository.SwapRequestRepository
+// CreateRecurringEvents creates every occurrence of a recurring event in one
+// transaction. If any occurrence conflicts with an existing event, or with an
+// earlier occurrence, none are created.
+func (s *eventService) CreateRecurringEvents(ctx context.Context, input CreateEventInput, recurrence RecurrenceInput) ([]db.Event, error) {
+	if err := validate(input); err != nil {
+		return nil, err
+	}
+	if err := validate(recurrence); err != nil {
+		return nil, err
+	}
+
+	timeZone, err := s.eventTimeZone(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := loadLocation(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []db.Event
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		for _, o := range occurrences(input.StartTime, input.EndTime, loc, recurrence) {
+			event, err := s.createEvent(ctx, input, o, timeZone)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Info("recurring events created", "first_event_id", events[0].ID, "count", len(events))
+	return events, nil
 }
 
-func NewEventService(eventRepo repository.EventRepository, userRepo repository.UserRepository, swapRepo repository.SwapRequestRepository) EventService {
-	return &eventService{eventRepo: eventRepo, userRepo: userRepo, swapRepo: swapRepo}
+// eventTimeZone returns the zone a new event is stored with: the one in the
+// input, or else the owner's preference.
+func (s *eventService) eventTimeZone(ctx context.Context, input CreateEventInput) (string, error) {
+	if input.TimeZone != "" {
+		return input.TimeZone, nil
+	}
+	user, err := s.userRepo.GetUserByID(ctx, input.UserID)
+	if err != nil {
+		return "", err
+	}
+	if user.TimeZone == "" {
+		return defaultTimeZone, nil
+	}
+	return user.TimeZone, nil
 }
 
-func (s *eventService) CreateEvent(ctx context.Context, input CreateEventInput) (*db.Event, error) {
-	if err := validation.Validate.Struct(input); err != nil {
-		return nil, err
+// createEvent inserts a single event at slot and records it in the audit log.
+// It must run inside a transaction so the overlap check and the insert are
+// atomic.
+func (s *eventService) createEvent(ctx context.Context, input CreateEventInput, slot occurrence, timeZone string) (db.Event, error) {
+	if err := EventStateMachine.Transition("", EventStatus(input.Status), ActorOwner, db.Event{}); err != nil {
+		return db.Event{}, err
 	}
 
 	arg := db.CreateEventParams{
 		Title:     input.Title,
-		StartTime: input.StartTime,
-		EndTime:   input.EndTime,
+		StartTime: slot.StartTime,
+		EndTime:   slot.EndTime,
 		Status:    input.Status,
 		UserID:    input.UserID,
+		TimeZone:  timeZone,
+	}
+
+	if !input.AllowOverlap {
+		claim := slotClaim{UserID: input.UserID, StartTime: arg.StartTime, EndTime: arg.EndTime}
+		if err := checkConflicts(ctx, s.eventRepo, claim); err != nil {
+			return db.Event{}, err
+		}
 	}
 
 	event, err := s.eventRepo.CreateEvent(ctx, arg)
 	if err != nil {
-		return nil, err
+		return db.Event{}, err
 	}
 
-	return &event, nil
+	err = recordAudit(ctx, s.auditRepo, auditRecord{
+		ActorUserID: input.UserID,
+		Action:      AuditActionEventCreate,
+		EntityType:  AuditEntityEvent,
+		EntityID:    event.ID,
+		After:       event,
+		Subjects:    []int64{event.UserID},
+	})
+	return event, err
 }
 
 func (s *eventService) GetEventByID(ctx context.Context, id int64) (*db.Event, error) {
 	event, err := s.eventRepo.GetEventByID(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, "event not found")
 	}
 	return &event, nil
 }
@@ -103,30 +272,156 @@ func (s *eventService) GetEventsByUserIDAndStatus(ctx context.Context, userID in
 	return s.eventRepo.GetEventsByUserIDAndStatus(ctx, db.GetEventsByUserIDAndStatusParams{UserID: userID, Status: status})
 }
 
-func (s *eventService) UpdateEventStatus(ctx context.Context, input UpdateEventStatusInput) (*db.Event, error) {
-	if err := validation.Validate.Struct(input); err != nil {
+// ListEventsByUserID returns one page of the user's events, optionally
+// restricted to a single status.
+func (s *eventService) ListEventsByUserID(ctx context.Context, userID int64, status string, filter EventListFilter) (*Page[db.Event], error) {
+	if err := validateVar("status", status, "omitempty,oneof=BUSY SWAPPABLE SWAP_PENDING"); err != nil {
+		return nil, err
+	}
+	filter, after, err := prepareEventListFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageLimit(filter.Limit)
+	arg := db.ListEventsByUserIDParams{
+		UserID:         userID,
+		Status:         sql.NullString{String: status, Valid: status != ""},
+		StartFrom:      nullTime(filter.StartFrom),
+		StartTo:        nullTime(filter.StartTo),
+		EndFrom:        nullTime(filter.EndFrom),
+		EndTo:          nullTime(filter.EndTo),
+		AfterStartTime: after.Time,
+		AfterID:        after.ID,
+		Limit:          limit + 1,
+	}
+
+	var events []db.Event
+	if filter.Sort == "-start_time" {
+		events, err = s.eventRepo.ListEventsByUserIDDesc(ctx, db.ListEventsByUserIDDescParams(arg))
+	} else {
+		events, err = s.eventRepo.ListEventsByUserID(ctx, arg)
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	event, err := s.eventRepo.GetEventByID(ctx, input.ID)
+	return newPage(events, limit, func(event db.Event) pageCursor {
+		return pageCursor{Sort: filter.Sort, Time: event.StartTime, ID: event.ID}
+	}), nil
+}
+
+// ListSwappableEvents returns one page of the marketplace: swappable slots
+// owned by anyone but userID.
+func (s *eventService) ListSwappableEvents(ctx context.Context, userID int64, filter EventListFilter) (*Page[db.ListSwappableEventsRow], error) {
+	filter, after, err := prepareEventListFilter(filter)
 	if err != nil {
 		return nil, err
 	}
 
-	if event.UserID != input.UserID {
-		return nil, errors.New("user does not own this event")
+	limit := pageLimit(filter.Limit)
+	arg := db.ListSwappableEventsParams{
+		UserID:         userID,
+		StartFrom:      nullTime(filter.StartFrom),
+		StartTo:        nullTime(filter.StartTo),
+		EndFrom:        nullTime(filter.EndFrom),
+		EndTo:          nullTime(filter.EndTo),
+		AfterStartTime: after.Time,
+		AfterID:        after.ID,
+		Limit:          limit + 1,
+	}
+
+	var events []db.ListSwappableEventsRow
+	if filter.Sort == "-start_time" {
+		rows, err := s.eventRepo.ListSwappableEventsDesc(ctx, db.ListSwappableEventsDescParams(arg))
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			events = append(events, db.ListSwappableEventsRow(row))
+		}
+	} else {
+		events, err = s.eventRepo.ListSwappableEvents(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newPage(events, limit, func(event db.ListSwappableEventsRow) pageCursor {
+		return pageCursor{Sort: filter.Sort, Time: event.StartTime, ID: event.ID}
+	}), nil
+}
+
+// prepareEventListFilter validates filter, applies the default sort and
+// returns the keyset position to continue from. Without a cursor that
+// position sorts before every event in the requested direction.
+func prepareEventListFilter(filter EventListFilter) (EventListFilter, pageCursor, error) {
+	if err := validate(filter); err != nil {
+		return filter, pageCursor{}, err
+	}
+	if filter.Sort == "" {
+		filter.Sort = defaultEventSort
+	}
+
+	after, ok, err := decodeCursor(filter.Cursor, filter.Sort)
+	if err != nil {
+		return filter, pageCursor{}, err
+	}
+	if !ok && filter.Sort == "-start_time" {
+		after = pageCursor{Time: time.Date(9999, time.December, 31, 23, 59, 59, 0, time.UTC), ID: math.MaxInt64}
 	}
+	after.Time = after.Time.UTC()
+	return filter, after, nil
+}
 
-	arg := db.UpdateEventStatusParams{
-		ID:     input.ID,
-		Status: input.Status,
+func (s *eventService) UpdateEventStatus(ctx context.Context, input UpdateEventStatusInput) (*db.Event, error) {
+	if err := validate(input); err != nil {
+		return nil, err
 	}
+	var giveaway *string
+	if input.Giveaway != "" {
+		giveaway = &input.Giveaway
+	}
+
+	var updatedEvent db.Event
+	var effects EventSideEffects
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		event, err := s.ownedEvent(ctx, input.ID, input.UserID, input.Version)
+		if err != nil {
+			return err
+		}
+
+		// Ending a giveaway cancels the claims waiting for the owner.
+		if event.Giveaway != nil && giveaway == nil && EventStatus(input.Status) == EventSwappable {
+			effects, err = s.cancelPendingSwaps(ctx, event, input.UserID, CancelReasonSlotModified, SwapKindTransfer)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Taking a slot off the marketplace cancels the offers made for it and
+		// the one it is reserved for. Check the transition first so that a
+		// refused one cancels nothing.
+		status := EventStatus(input.Status)
+		if EventStatus(event.Status) != EventBusy && status == EventBusy {
+			if err := EventStateMachine.Transition(EventStatus(event.Status), status, ActorOwner, event); err != nil {
+				return err
+			}
+			effects, err = s.cancelPendingSwaps(ctx, event, input.UserID, CancelReasonSlotModified)
+			if err != nil {
+				return err
+			}
+		}
 
-	updatedEvent, err := s.eventRepo.UpdateEventStatus(ctx, arg)
+		updatedEvent, err = setEventListing(ctx, s.eventRepo, s.auditRepo, event, status, giveaway, ActorOwner, input.UserID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	metrics.SwapRequestsResolved.WithLabelValues(metrics.OutcomeCancelled).Add(float64(len(effects.CancelledSwapRequests)))
+	logging.FromContext(ctx).Info("event status updated", "event_id", updatedEvent.ID, "status", updatedEvent.Status)
 	return &updatedEvent, nil
 }
 
@@ -135,58 +430,259 @@ func (s *eventService) GetSwappableEvents(ctx context.Context, userID int64) ([]
 }
 
 func (s *eventService) UpdateEvent(ctx context.Context, input UpdateEventInput) (*db.Event, error) {
-	if err := validation.Validate.Struct(input); err != nil {
+	if err := validate(input); err != nil {
 		return nil, err
 	}
 
-	event, err := s.eventRepo.GetEventByID(ctx, input.ID)
+	var updatedEvent db.Event
+	var effects EventSideEffects
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		event, err := s.ownedEvent(ctx, input.ID, input.UserID, input.Version)
+		if err != nil {
+			return err
+		}
+
+		timeZone := event.TimeZone
+		if input.TimeZone != "" {
+			timeZone = input.TimeZone
+		}
+
+		if err := EventStateMachine.Transition(EventStatus(event.Status), EventBusy, ActorOwner, event); err != nil {
+			return err
+		}
+		arg := db.UpdateEventParams{
+			ID:        input.ID,
+			Title:     input.Title,
+			StartTime: input.StartTime.UTC(),
+			EndTime:   input.EndTime.UTC(),
+			Status:    string(EventBusy),
+			TimeZone:  timeZone,
+		}
+
+		if !input.AllowOverlap {
+			claim := slotClaim{UserID: event.UserID, StartTime: arg.StartTime, EndTime: arg.EndTime, ExcludeID: event.ID}
+			if err := checkConflicts(ctx, s.eventRepo, claim); err != nil {
+				return err
+			}
+		}
+
+		// If the event is part of a pending swap, cancel the swap
+		if EventStatus(event.Status) != EventBusy {
+			effects, err = s.cancelPendingSwaps(ctx, event, input.UserID, CancelReasonSlotModified)
+			if err != nil {
+				return err
+			}
+		}
+
+		updatedEvent, err = s.eventRepo.UpdateEvent(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo, auditRecord{
+			ActorUserID: input.UserID,
+			Action:      AuditActionEventUpdate,
+			EntityType:  AuditEntityEvent,
+			EntityID:    event.ID,
+			Before:      event,
+			After:       updatedEvent,
+			Subjects:    []int64{event.UserID},
+		})
+	})
 	if err != nil {
-		return nil, errors.New("event not found")
+		return nil, err
 	}
 
-	if event.UserID != input.UserID {
-		return nil, errors.New("user does not own this event")
+	metrics.SwapRequestsResolved.WithLabelValues(metrics.OutcomeCancelled).Add(float64(len(effects.CancelledSwapRequests)))
+	logging.FromContext(ctx).Info("event updated", "event_id", updatedEvent.ID)
+	return &updatedEvent, nil
+}
+
+func (s *eventService) PatchEvent(ctx context.Context, input PatchEventInput) (*PatchEventResult, error) {
+	if err := validate(input); err != nil {
+		return nil, err
+	}
+	if input.Title != nil && *input.Title == "" {
+		return nil, &ValidationError{Fields: []FieldError{fieldError("title", "required", "", reflect.String)}}
 	}
 
-	// If the event is part of a pending swap, cancel the swap
-	if event.Status == "SWAP_PENDING" {
-		swapRequests, err := s.swapRepo.GetSwapRequestsByEventID(ctx, event.ID)
+	var result PatchEventResult
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		event, err := s.ownedEvent(ctx, input.ID, input.UserID, input.Version)
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		arg := db.UpdateEventParams{
+			ID:        event.ID,
+			Title:     event.Title,
+			StartTime: event.StartTime,
+			EndTime:   event.EndTime,
+			Status:    event.Status,
+			Giveaway:  event.Giveaway,
+			TimeZone:  event.TimeZone,
+		}
+		if input.Title != nil {
+			arg.Title = *input.Title
+		}
+		if input.StartTime != nil {
+			arg.StartTime = input.StartTime.UTC()
+		}
+		if input.EndTime != nil {
+			arg.EndTime = input.EndTime.UTC()
+		}
+		if input.TimeZone != nil {
+			arg.TimeZone = *input.TimeZone
 		}
 
-		for _, req := range swapRequests {
-			if req.Status == "PENDING" {
-				// Reset the status of the other event in the swap
-				otherEventID := req.RequesterSlotID
-				if otherEventID == event.ID {
-					otherEventID = req.ResponderSlotID
+		result.Event = event
+		result.SideEffects = EventSideEffects{CancelledSwapRequests: []int64{}, ReleasedSlots: []int64{}}
+		timeChanged := !arg.StartTime.Equal(event.StartTime) || !arg.EndTime.Equal(event.EndTime)
+		if !timeChanged && arg.Title == event.Title && arg.TimeZone == event.TimeZone {
+			return nil
+		}
+
+		if timeChanged {
+			if !arg.EndTime.After(arg.StartTime) {
+				if input.EndTime == nil {
+					return &ValidationError{Fields: []FieldError{fieldError("start_time", "ltfield", "end_time", reflect.Struct)}}
 				}
-				_, err := s.eventRepo.UpdateEventStatus(ctx, db.UpdateEventStatusParams{ID: otherEventID, Status: "SWAPPABLE"})
-				if err != nil {
-					return nil, err
+				return &ValidationError{Fields: []FieldError{fieldError("end_time", "gtfield", "start_time", reflect.Struct)}}
+			}
+			if !input.AllowOverlap {
+				claim := slotClaim{UserID: event.UserID, StartTime: arg.StartTime, EndTime: arg.EndTime, ExcludeID: event.ID}
+				if err := checkConflicts(ctx, s.eventRepo, claim); err != nil {
+					return err
+				}
+			}
+			// The other side agreed to swap for the old time. A reserved
+			// slot also leaves the marketplace; a swappable one stays on it
+			// for new offers.
+			if EventStatus(event.Status) == EventSwapPending {
+				if err := EventStateMachine.Transition(EventSwapPending, EventBusy, ActorOwner, event); err != nil {
+					return err
 				}
-				// Delete the swap request
-				err = s.swapRepo.DeleteSwapRequest(ctx, req.ID)
+				arg.Status = string(EventBusy)
+			}
+			if EventStatus(event.Status) != EventBusy {
+				result.SideEffects, err = s.cancelPendingSwaps(ctx, event, input.UserID, CancelReasonSlotModified)
 				if err != nil {
-					return nil, err
+					return err
 				}
+				result.SideEffects.StatusChanged = arg.Status != event.Status
 			}
 		}
+
+		result.Event, err = s.eventRepo.UpdateEvent(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo, auditRecord{
+			ActorUserID: input.UserID,
+			Action:      AuditActionEventUpdate,
+			EntityType:  AuditEntityEvent,
+			EntityID:    event.ID,
+			Before:      event,
+			After:       result.Event,
+			Subjects:    []int64{event.UserID},
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	arg := db.UpdateEventParams{
-		ID:        input.ID,
-		Title:     input.Title,
-		StartTime: input.StartTime,
-		EndTime:   input.EndTime,
-		Status:    "BUSY",
+	metrics.SwapRequestsResolved.WithLabelValues(metrics.OutcomeCancelled).Add(float64(len(result.SideEffects.CancelledSwapRequests)))
+	logging.FromContext(ctx).Info("event patched", "event_id", result.Event.ID, "cancelled_swap_requests", len(result.SideEffects.CancelledSwapRequests))
+	return &result, nil
+}
+
+func (s *eventService) DeleteEvent(ctx context.Context, eventID, userID, version int64) error {
+	var effects EventSideEffects
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		event, err := s.ownedEvent(ctx, eventID, userID, version)
+		if err != nil {
+			return err
+		}
+
+		// Close the event's pending requests first, whatever its status, so
+		// that no other slot is left SWAP_PENDING on a request that can no
+		// longer be answered. The closed requests stay in the history.
+		effects, err = s.cancelPendingSwaps(ctx, event, userID, CancelReasonSlotDeleted)
+		if err != nil {
+			return err
+		}
+
+		if err := s.eventRepo.DeleteEvent(ctx, eventID); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo, auditRecord{
+			ActorUserID: userID,
+			Action:      AuditActionEventDelete,
+			EntityType:  AuditEntityEvent,
+			EntityID:    event.ID,
+			Before:      event,
+			Subjects:    []int64{event.UserID},
+		})
+	})
+	if err != nil {
+		return err
 	}
 
-	updatedEvent, err := s.eventRepo.UpdateEvent(ctx, arg)
+	metrics.SwapRequestsResolved.WithLabelValues(metrics.OutcomeCancelled).Add(float64(len(effects.CancelledSwapRequests)))
+	logging.FromContext(ctx).Info("event deleted", "event_id", eventID, "cancelled_swap_requests", len(effects.CancelledSwapRequests))
+	return nil
+}
+
+// ownedEvent loads an event that userID is about to change. A non-zero
+// version must match the event's current one. Call it inside the transaction
+// that makes the change, so that no other write can come in between.
+func (s *eventService) ownedEvent(ctx context.Context, eventID, userID, version int64) (db.Event, error) {
+	event, err := s.eventRepo.GetEventByID(ctx, eventID)
 	if err != nil {
-		return nil, err
+		return db.Event{}, notFound(err, "event not found")
+	}
+	if event.UserID != userID {
+		return db.Event{}, ErrEventNotOwned
+	}
+	if version != 0 && event.Version != version {
+		return db.Event{}, ErrEventModified
+	}
+	return event, nil
+}
+
+// cancelPendingSwaps cancels the pending swap requests that involve event,
+// recording reason, and hands the slots other users reserved for them back to
+// the marketplace. Given kinds, it cancels only requests of those kinds. It
+// reports the requests it cancelled and the slots it released.
+func (s *eventService) cancelPendingSwaps(ctx context.Context, event db.Event, actorUserID int64, reason string, kinds ...SwapKind) (EventSideEffects, error) {
+	effects := EventSideEffects{CancelledSwapRequests: []int64{}, ReleasedSlots: []int64{}}
+	swapRequests, err := s.swapRepo.GetSwapRequestsByEventID(ctx, event.ID)
+	if err != nil {
+		return effects, err
 	}
 
-	return &updatedEvent, nil
+	canceller := swapCanceller{eventRepo: s.eventRepo, swapRepo: s.swapRepo, auditRepo: s.auditRepo, notificationRepo: s.notificationRepo}
+	for _, req := range swapRequests {
+		if SwapStatus(req.Status) != SwapPending || len(kinds) > 0 && !slices.Contains(kinds, SwapKind(req.Kind)) {
+			continue
+		}
+
+		// Only the requester's slot, if any, is reserved by a request. When
+		// that is event itself, the caller decides what becomes of it.
+		var releaseSlotID int64
+		if req.RequesterSlotID != nil && *req.RequesterSlotID != event.ID {
+			releaseSlotID = *req.RequesterSlotID
+		}
+		if err := canceller.cancel(ctx, req, reason, releaseSlotID, actorUserID); err != nil {
+			return effects, err
+		}
+		effects.CancelledSwapRequests = append(effects.CancelledSwapRequests, req.ID)
+		if releaseSlotID != 0 {
+			effects.ReleasedSlots = append(effects.ReleasedSlots, releaseSlotID)
+		}
+	}
+
+	return effects, nil
 }
@@ -3,6 +3,9 @@ package services
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"math"
+	"reflect"
 	"testing"
 	"time"
 
@@ -17,8 +20,10 @@ func TestEventService(t *testing.T) {
 		testQueries, user := repository.SetupTestDBWithUser(t)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
-		eventService := NewEventService(eventRepo, userRepo, swapRepo)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		startTime := time.Now()
 		endTime := startTime.Add(time.Hour)
@@ -52,8 +57,10 @@ func TestEventService(t *testing.T) {
 		testQueries, user := repository.SetupTestDBWithUser(t)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
-		eventService := NewEventService(eventRepo, userRepo, swapRepo)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		startTime := time.Now()
 		endTime := startTime.Add(time.Hour)
@@ -107,8 +114,10 @@ func TestEventService(t *testing.T) {
 		testQueries, user := repository.SetupTestDBWithUser(t)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
-		eventService := NewEventService(eventRepo, userRepo, swapRepo)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		startTime := time.Now()
 		endTime := startTime.Add(time.Hour)
@@ -144,16 +153,18 @@ func TestEventService(t *testing.T) {
 		testQueries, user := repository.SetupTestDBWithUser(t)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
-		eventService := NewEventService(eventRepo, userRepo, swapRepo)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		startTime := time.Now()
 		endTime := startTime.Add(time.Hour)
 		for i := 0; i < 3; i++ {
 			input := CreateEventInput{
 				Title:     "Service User Event",
-				StartTime: startTime,
-				EndTime:   endTime,
+				StartTime: startTime.Add(time.Duration(i) * time.Hour),
+				EndTime:   endTime.Add(time.Duration(i) * time.Hour),
 				Status:    "BUSY",
 				UserID:    user.ID,
 			}
@@ -177,8 +188,10 @@ func TestEventService(t *testing.T) {
 		testQueries, user := repository.SetupTestDBWithUser(t)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
-		eventService := NewEventService(eventRepo, userRepo, swapRepo)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		startTime := time.Now()
 		endTime := startTime.Add(time.Hour)
@@ -216,12 +229,91 @@ func TestEventService(t *testing.T) {
 		}
 	})
 
+	t.Run("UpdateEventStatus_Transitions", func(t *testing.T) {
+		testQueries, user1 := repository.SetupTestDBWithUser(t)
+		user2, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{Name: "user2", Email: "user2@example.com", Password: "password"})
+		if err != nil {
+			t.Fatalf("failed to create user2: %v", err)
+		}
+
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+		ctx := context.Background()
+
+		start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+		busy, err := eventService.CreateEvent(ctx, CreateEventInput{Title: "Busy", StartTime: start.Add(-4 * time.Hour), EndTime: start.Add(-3 * time.Hour), Status: "BUSY", UserID: user1.ID})
+		if err != nil {
+			t.Fatalf("failed to create busy event: %v", err)
+		}
+		event1, err := eventService.CreateEvent(ctx, CreateEventInput{Title: "Event 1", StartTime: start, EndTime: start.Add(time.Hour), Status: "SWAPPABLE", UserID: user1.ID})
+		if err != nil {
+			t.Fatalf("failed to create event1: %v", err)
+		}
+		event2, err := eventService.CreateEvent(ctx, CreateEventInput{Title: "Event 2", StartTime: start, EndTime: start.Add(time.Hour), Status: "SWAPPABLE", UserID: user2.ID})
+		if err != nil {
+			t.Fatalf("failed to create event2: %v", err)
+		}
+
+		// Only the swap flow puts a slot into SWAP_PENDING.
+		_, err = eventService.UpdateEventStatus(ctx, UpdateEventStatusInput{ID: event1.ID, Status: "SWAP_PENDING", UserID: user1.ID})
+		if !errors.Is(err, ErrValidation) {
+			t.Errorf("expected ErrValidation for setting SWAP_PENDING on a swappable event, got %v", err)
+		}
+		_, err = eventService.UpdateEventStatus(ctx, UpdateEventStatusInput{ID: busy.ID, Status: "SWAP_PENDING", UserID: user1.ID})
+		if !errors.Is(err, ErrValidation) {
+			t.Errorf("expected ErrValidation for setting SWAP_PENDING on a busy event, got %v", err)
+		}
+		_, err = eventService.CreateEvent(ctx, CreateEventInput{Title: "Pending", StartTime: start.Add(-2 * time.Hour), EndTime: start.Add(-time.Hour), Status: "SWAP_PENDING", UserID: user1.ID})
+		if !errors.Is(err, ErrValidation) {
+			t.Errorf("expected ErrValidation for creating a SWAP_PENDING event, got %v", err)
+		}
+
+		swap, err := swapService.CreateSwapRequest(ctx, CreateSwapRequestInput{RequesterUserID: user1.ID, ResponderUserID: user2.ID, RequesterSlotID: event1.ID, ResponderSlotID: event2.ID})
+		if err != nil {
+			t.Fatalf("failed to create swap request: %v", err)
+		}
+		_, err = eventService.UpdateEventStatus(ctx, UpdateEventStatusInput{ID: event1.ID, Status: "SWAPPABLE", UserID: user1.ID})
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden for re-offering a SWAP_PENDING slot, got %v", err)
+		}
+
+		// Withdrawing the slot cancels the request and releases the other one.
+		withdrawn, err := eventService.UpdateEventStatus(ctx, UpdateEventStatusInput{ID: event1.ID, Status: "BUSY", UserID: user1.ID})
+		if err != nil {
+			t.Fatalf("failed to withdraw event1: %v", err)
+		}
+		if withdrawn.Status != "BUSY" {
+			t.Errorf("expected event1 to be BUSY, got %q", withdrawn.Status)
+		}
+		released, err := eventRepo.GetEventByID(ctx, event2.ID)
+		if err != nil {
+			t.Fatalf("failed to get event2: %v", err)
+		}
+		if released.Status != "SWAPPABLE" {
+			t.Errorf("expected event2 to be SWAPPABLE again, got %q", released.Status)
+		}
+		cancelled, err := swapRepo.GetSwapRequestByID(ctx, swap.ID)
+		if err != nil {
+			t.Fatalf("failed to get swap request: %v", err)
+		}
+		if cancelled.Status != "CANCELLED" || cancelled.CancelReason == nil || *cancelled.CancelReason != CancelReasonSlotModified {
+			t.Errorf("expected the swap request to be cancelled as %q, got %+v", CancelReasonSlotModified, cancelled)
+		}
+	})
+
 	t.Run("UpdateEventStatus_Unauthorized", func(t *testing.T) {
 		testQueries, user := repository.SetupTestDBWithUser(t)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
-		eventService := NewEventService(eventRepo, userRepo, swapRepo)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		otherUser, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
 			Name:     "unauthorized user",
@@ -268,8 +360,10 @@ func TestEventService(t *testing.T) {
 		testQueries, user := repository.SetupTestDBWithUser(t)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
-		eventService := NewEventService(eventRepo, userRepo, swapRepo)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		startTime := time.Now()
 		endTime := startTime.Add(time.Hour)
@@ -287,7 +381,7 @@ func TestEventService(t *testing.T) {
 			t.Fatalf("failed to create event: %v", err)
 		}
 
-		err = eventService.DeleteEvent(context.Background(), createdEvent.ID, user.ID)
+		err = eventService.DeleteEvent(context.Background(), createdEvent.ID, user.ID, 0)
 		if err != nil {
 			t.Fatalf("failed to delete event: %v", err)
 		}
@@ -301,12 +395,91 @@ func TestEventService(t *testing.T) {
 		}
 	})
 
+	t.Run("DeleteEvent_ReleasesCounterpartSlot", func(t *testing.T) {
+		testQueries, user1 := repository.SetupTestDBWithUser(t)
+		user2, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{Name: "user2", Email: "user2@example.com", Password: "password"})
+		if err != nil {
+			t.Fatalf("failed to create user2: %v", err)
+		}
+
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+		ctx := context.Background()
+
+		start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+		event1, err := eventService.CreateEvent(ctx, CreateEventInput{Title: "Event 1", StartTime: start, EndTime: start.Add(time.Hour), Status: "SWAPPABLE", UserID: user1.ID})
+		if err != nil {
+			t.Fatalf("failed to create event1: %v", err)
+		}
+		event2, err := eventService.CreateEvent(ctx, CreateEventInput{Title: "Event 2", StartTime: start, EndTime: start.Add(time.Hour), Status: "SWAPPABLE", UserID: user2.ID})
+		if err != nil {
+			t.Fatalf("failed to create event2: %v", err)
+		}
+		swap, err := swapService.CreateSwapRequest(ctx, CreateSwapRequestInput{RequesterUserID: user1.ID, ResponderUserID: user2.ID, RequesterSlotID: event1.ID, ResponderSlotID: event2.ID})
+		if err != nil {
+			t.Fatalf("failed to create swap request: %v", err)
+		}
+
+		// The responder's slot was taken off the market without cancelling the
+		// request, as setting the status directly once did. The deletion used
+		// to skip the swap requests of an event that was no longer
+		// SWAP_PENDING, leaving the requester's slot stuck.
+		if _, err := eventRepo.UpdateEventStatus(ctx, db.UpdateEventStatusParams{ID: event2.ID, Status: "BUSY"}); err != nil {
+			t.Fatalf("failed to update event2 status: %v", err)
+		}
+		if err := eventService.DeleteEvent(ctx, event2.ID, user2.ID, 0); err != nil {
+			t.Fatalf("failed to delete event2: %v", err)
+		}
+
+		released, err := eventRepo.GetEventByID(ctx, event1.ID)
+		if err != nil {
+			t.Fatalf("failed to get event1: %v", err)
+		}
+		if released.Status != "SWAPPABLE" {
+			t.Errorf("expected the requester's slot to be SWAPPABLE again, got %q", released.Status)
+		}
+
+		// The request is kept, with its reason, in the requester's history.
+		history, err := swapService.GetOutgoingSwapRequestHistory(ctx, SwapRequestHistoryFilter{UserID: user1.ID})
+		if err != nil {
+			t.Fatalf("failed to get outgoing history: %v", err)
+		}
+		if len(history.Items) != 1 {
+			t.Fatalf("expected the cancelled request in the history, got %+v", history.Items)
+		}
+		entry := history.Items[0]
+		if entry.ID != swap.ID || entry.Status != "CANCELLED" || entry.CancelReason == nil || *entry.CancelReason != CancelReasonSlotDeleted {
+			t.Errorf("expected request %d cancelled with %q, got %+v", swap.ID, CancelReasonSlotDeleted, entry)
+		}
+		if entry.ResponderSlotID != event2.ID || entry.ResponderEventTitle != "" || entry.ResponderEventStartTime != nil {
+			t.Errorf("expected the deleted slot without details, got %+v", entry)
+		}
+		if entry.RequesterEventTitle != "Event 1" {
+			t.Errorf("expected the requester's slot title, got %q", entry.RequesterEventTitle)
+		}
+
+		notifications, err := testQueries.ListNotificationsByUserID(ctx, db.ListNotificationsByUserIDParams{UserID: user1.ID, BeforeID: math.MaxInt64, Limit: 10})
+		if err != nil {
+			t.Fatalf("failed to list notifications: %v", err)
+		}
+		if len(notifications) != 1 || notifications[0].Reason != CancelReasonSlotDeleted {
+			t.Errorf("expected the requester to be notified, got %+v", notifications)
+		}
+	})
+
 	t.Run("DeleteEvent_Unauthorized", func(t *testing.T) {
 		testQueries, user := repository.SetupTestDBWithUser(t)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
-		eventService := NewEventService(eventRepo, userRepo, swapRepo)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		otherUser, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
 			Name:     "unauthorized deleter",
@@ -333,7 +506,7 @@ func TestEventService(t *testing.T) {
 			t.Fatalf("failed to create event: %v", err)
 		}
 
-		err = eventService.DeleteEvent(context.Background(), createdEvent.ID, otherUser.ID)
+		err = eventService.DeleteEvent(context.Background(), createdEvent.ID, otherUser.ID, 0)
 		if err == nil {
 			t.Fatal("expected an error for unauthorized delete, got nil")
 		}
@@ -343,12 +516,62 @@ func TestEventService(t *testing.T) {
 		}
 	})
 
+	t.Run("Versions", func(t *testing.T) {
+		testQueries, user := repository.SetupTestDBWithUser(t)
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+		ctx := context.Background()
+
+		startTime := time.Now()
+		event, err := eventService.CreateEvent(ctx, CreateEventInput{Title: "Versioned", StartTime: startTime, EndTime: startTime.Add(time.Hour), Status: "BUSY", UserID: user.ID})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		if event.Version != 1 {
+			t.Fatalf("expected a new event to have version 1, got %d", event.Version)
+		}
+
+		updated, err := eventService.UpdateEvent(ctx, UpdateEventInput{ID: event.ID, Title: "Renamed", StartTime: event.StartTime, EndTime: event.EndTime, UserID: user.ID, Version: 1})
+		if err != nil {
+			t.Fatalf("failed to update event: %v", err)
+		}
+		if updated.Version != 2 {
+			t.Errorf("expected the update to bump the version to 2, got %d", updated.Version)
+		}
+
+		_, err = eventService.UpdateEvent(ctx, UpdateEventInput{ID: event.ID, Title: "Lost update", StartTime: event.StartTime, EndTime: event.EndTime, UserID: user.ID, Version: 1})
+		if !errors.Is(err, ErrEventModified) || !errors.Is(err, ErrPreconditionFailed) {
+			t.Errorf("expected ErrEventModified for a stale version, got %v", err)
+		}
+		_, err = eventService.UpdateEventStatus(ctx, UpdateEventStatusInput{ID: event.ID, Status: "SWAPPABLE", UserID: user.ID, Version: 1})
+		if !errors.Is(err, ErrEventModified) {
+			t.Errorf("expected ErrEventModified for a stale status change, got %v", err)
+		}
+		if err := eventService.DeleteEvent(ctx, event.ID, user.ID, 1); !errors.Is(err, ErrEventModified) {
+			t.Errorf("expected ErrEventModified for a stale delete, got %v", err)
+		}
+
+		current, err := eventService.GetEventByID(ctx, event.ID)
+		if err != nil {
+			t.Fatalf("failed to get event: %v", err)
+		}
+		if current.Title != "Renamed" || current.Version != 2 {
+			t.Errorf("expected stale writes to leave the event alone, got %+v", current)
+		}
+	})
+
 	t.Run("GetSwappableEvents", func(t *testing.T) {
 		testQueries, user := repository.SetupTestDBWithUser(t)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
-		eventService := NewEventService(eventRepo, userRepo, swapRepo)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		otherUser, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
 			Name:     "other service user",
@@ -391,8 +614,10 @@ func TestEventService(t *testing.T) {
 		testQueries, user := repository.SetupTestDBWithUser(t)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
-		eventService := NewEventService(eventRepo, userRepo, swapRepo)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		startTime := time.Now()
 		endTime := startTime.Add(time.Hour)
@@ -412,6 +637,8 @@ func TestEventService(t *testing.T) {
 
 		// Create a busy event
 		input.Status = "BUSY"
+		input.StartTime = endTime
+		input.EndTime = endTime.Add(time.Hour)
 		_, err = eventService.CreateEvent(context.Background(), input)
 		if err != nil {
 			t.Fatalf("failed to create busy event: %v", err)
@@ -431,6 +658,91 @@ func TestEventService(t *testing.T) {
 		}
 	})
 
+	t.Run("ListEventsByUserID", func(t *testing.T) {
+		testQueries, user := repository.SetupTestDBWithUser(t)
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+
+		base := time.Date(2030, time.March, 1, 9, 0, 0, 0, time.UTC)
+		kolkata := time.FixedZone("IST", 5*60*60+30*60)
+		starts := []time.Time{
+			base.Add(48 * time.Hour),
+			base,
+			base.Add(24 * time.Hour).In(kolkata), // stored in UTC regardless of the input offset
+			base.Add(24 * time.Hour),             // same start as the previous event
+			base.Add(72 * time.Hour),
+		}
+		for i, start := range starts {
+			_, err := eventService.CreateEvent(context.Background(), CreateEventInput{
+				Title:        "Shift",
+				StartTime:    start,
+				EndTime:      start.Add(time.Hour),
+				Status:       []string{"BUSY", "SWAPPABLE"}[i%2],
+				UserID:       user.ID,
+				AllowOverlap: true,
+			})
+			if err != nil {
+				t.Fatalf("failed to create event: %v", err)
+			}
+		}
+
+		var seen []db.Event
+		filter := EventListFilter{PageRequest: PageRequest{Limit: 2}}
+		for pages := 0; ; pages++ {
+			if pages > len(starts) {
+				t.Fatal("pagination did not terminate")
+			}
+			page, err := eventService.ListEventsByUserID(context.Background(), user.ID, "", filter)
+			if err != nil {
+				t.Fatalf("failed to list events: %v", err)
+			}
+			seen = append(seen, page.Items...)
+			if page.NextCursor == "" {
+				break
+			}
+			filter.Cursor = page.NextCursor
+		}
+		if len(seen) != len(starts) {
+			t.Fatalf("expected %d events across all pages, got %d", len(starts), len(seen))
+		}
+		for i := 1; i < len(seen); i++ {
+			prev, cur := seen[i-1], seen[i]
+			if cur.StartTime.Before(prev.StartTime) || (cur.StartTime.Equal(prev.StartTime) && cur.ID <= prev.ID) {
+				t.Fatalf("events out of order at %d: %v (#%d) after %v (#%d)", i, cur.StartTime, cur.ID, prev.StartTime, prev.ID)
+			}
+		}
+
+		desc, err := eventService.ListEventsByUserID(context.Background(), user.ID, "", EventListFilter{Sort: "-start_time", PageRequest: PageRequest{Limit: 1}})
+		if err != nil {
+			t.Fatalf("failed to list events in descending order: %v", err)
+		}
+		if len(desc.Items) != 1 || !desc.Items[0].StartTime.Equal(base.Add(72*time.Hour)) {
+			t.Errorf("expected the latest event first, got %+v", desc.Items)
+		}
+
+		ranged, err := eventService.ListEventsByUserID(context.Background(), user.ID, "SWAPPABLE", EventListFilter{
+			StartFrom: base.Add(time.Hour),
+			EndTo:     base.Add(49 * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("failed to list events in range: %v", err)
+		}
+		if len(ranged.Items) != 1 || !ranged.Items[0].StartTime.Equal(base.Add(24*time.Hour)) || ranged.NextCursor != "" {
+			t.Errorf("expected the single swappable event on day two, got %+v", ranged)
+		}
+
+		if _, err := eventService.ListEventsByUserID(context.Background(), user.ID, "", EventListFilter{PageRequest: PageRequest{Cursor: "not a cursor"}}); err != ErrInvalidCursor {
+			t.Errorf("expected ErrInvalidCursor, got %v", err)
+		}
+		if _, err := eventService.ListEventsByUserID(context.Background(), user.ID, "", EventListFilter{PageRequest: PageRequest{Limit: 500}}); err == nil {
+			t.Error("expected an error for a limit above the maximum")
+		}
+	})
+
 	t.Run("UpdateEvent", func(t *testing.T) {
 		testQueries, user1 := repository.SetupTestDBWithUser(t)
 		user2, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{Name: "user2", Email: "user2@example.com", Password: "password"})
@@ -440,9 +752,11 @@ func TestEventService(t *testing.T) {
 
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
-		eventService := NewEventService(eventRepo, userRepo, swapRepo)
-		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		// Create events for both users
 		event1, err := eventService.CreateEvent(context.Background(), CreateEventInput{Title: "Event 1", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour), Status: "SWAPPABLE", UserID: user1.ID})
@@ -488,13 +802,232 @@ func TestEventService(t *testing.T) {
 			t.Errorf("expected other event status to be SWAPPABLE, got %q", updatedEvent2.Status)
 		}
 
-		// Verify swap request is deleted
+		// Verify swap request is cancelled, not deleted
 		swapRequests, err := swapRepo.GetSwapRequestsByEventID(context.Background(), event1.ID)
 		if err != nil {
 			t.Fatalf("failed to get swap requests by event ID: %v", err)
 		}
-		if len(swapRequests) != 0 {
-			t.Errorf("expected swap requests to be deleted, but found %d", len(swapRequests))
+		if len(swapRequests) != 1 || swapRequests[0].Status != "CANCELLED" {
+			t.Fatalf("expected the swap request to be cancelled, got %+v", swapRequests)
+		}
+		if reason := swapRequests[0].CancelReason; reason == nil || *reason != CancelReasonSlotModified {
+			t.Errorf("expected cancel reason %q, got %v", CancelReasonSlotModified, reason)
+		}
+
+		// Only the other party is told about it
+		for userID, want := range map[int64]int{user1.ID: 0, user2.ID: 1} {
+			notifications, err := testQueries.ListNotificationsByUserID(context.Background(), db.ListNotificationsByUserIDParams{UserID: userID, BeforeID: math.MaxInt64, Limit: 10})
+			if err != nil {
+				t.Fatalf("failed to list notifications: %v", err)
+			}
+			if len(notifications) != want {
+				t.Fatalf("expected user %d to have %d notifications, got %d", userID, want, len(notifications))
+			}
+			if want == 1 && (notifications[0].Reason != CancelReasonSlotModified || *notifications[0].SwapRequestID != swapRequests[0].ID) {
+				t.Errorf("expected a notification about the cancelled request, got %+v", notifications[0])
+			}
+		}
+	})
+
+	t.Run("PatchEvent", func(t *testing.T) {
+		testQueries, user1 := repository.SetupTestDBWithUser(t)
+		user2, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{Name: "user2", Email: "user2@example.com", Password: "password"})
+		if err != nil {
+			t.Fatalf("failed to create user2: %v", err)
+		}
+
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+		ctx := context.Background()
+
+		start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+		event1, err := eventService.CreateEvent(ctx, CreateEventInput{Title: "Event 1", StartTime: start, EndTime: start.Add(time.Hour), Status: "SWAPPABLE", UserID: user1.ID})
+		if err != nil {
+			t.Fatalf("failed to create event1: %v", err)
+		}
+		event2, err := eventService.CreateEvent(ctx, CreateEventInput{Title: "Event 2", StartTime: start, EndTime: start.Add(time.Hour), Status: "SWAPPABLE", UserID: user2.ID})
+		if err != nil {
+			t.Fatalf("failed to create event2: %v", err)
+		}
+		swap, err := swapService.CreateSwapRequest(ctx, CreateSwapRequestInput{RequesterUserID: user1.ID, ResponderUserID: user2.ID, RequesterSlotID: event1.ID, ResponderSlotID: event2.ID})
+		if err != nil {
+			t.Fatalf("failed to create swap request: %v", err)
+		}
+
+		// Renaming keeps the swap.
+		title := "Renamed"
+		result, err := eventService.PatchEvent(ctx, PatchEventInput{ID: event1.ID, UserID: user1.ID, Title: &title})
+		if err != nil {
+			t.Fatalf("failed to patch title: %v", err)
+		}
+		if result.Event.Title != title || result.Event.Status != "SWAP_PENDING" || !result.Event.EndTime.Equal(event1.EndTime) {
+			t.Errorf("expected only the title to change, got %+v", result.Event)
+		}
+		if len(result.SideEffects.CancelledSwapRequests) != 0 || result.SideEffects.StatusChanged {
+			t.Errorf("expected no side effects, got %+v", result.SideEffects)
+		}
+
+		// So does "moving" it to the time it already has.
+		sameStart := event1.StartTime.In(time.FixedZone("UTC+2", 2*60*60))
+		result, err = eventService.PatchEvent(ctx, PatchEventInput{ID: event1.ID, UserID: user1.ID, StartTime: &sameStart})
+		if err != nil {
+			t.Fatalf("failed to patch with the same start: %v", err)
+		}
+		if len(result.SideEffects.CancelledSwapRequests) != 0 || result.Event.Version != 3 {
+			t.Errorf("expected an unchanged event to be left alone, got %+v", result)
+		}
+
+		// An end before the stored start is refused under the field sent.
+		before := start.Add(-time.Hour)
+		_, err = eventService.PatchEvent(ctx, PatchEventInput{ID: event1.ID, UserID: user1.ID, EndTime: &before})
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Fields[0].Field != "end_time" {
+			t.Errorf("expected a validation error for end_time, got %v", err)
+		}
+		empty := ""
+		if _, err := eventService.PatchEvent(ctx, PatchEventInput{ID: event1.ID, UserID: user1.ID, Title: &empty}); !errors.Is(err, ErrValidation) {
+			t.Errorf("expected an empty title to be refused, got %v", err)
+		}
+
+		// Moving it cancels the swap. The other slot was never reserved.
+		later := start.Add(2 * time.Hour)
+		result, err = eventService.PatchEvent(ctx, PatchEventInput{ID: event1.ID, UserID: user1.ID, EndTime: &later})
+		if err != nil {
+			t.Fatalf("failed to patch end time: %v", err)
+		}
+		if !result.Event.EndTime.Equal(later) || result.Event.Status != "BUSY" || result.Event.Title != title {
+			t.Errorf("expected the event to move and become BUSY, got %+v", result.Event)
+		}
+		want := EventSideEffects{CancelledSwapRequests: []int64{swap.ID}, ReleasedSlots: []int64{}, StatusChanged: true}
+		if !reflect.DeepEqual(result.SideEffects, want) {
+			t.Errorf("expected side effects %+v, got %+v", want, result.SideEffects)
+		}
+
+		// Moving a swappable slot cancels the offers made for it and releases
+		// the offered slots, but keeps it on the marketplace.
+		event3, err := eventService.CreateEvent(ctx, CreateEventInput{Title: "Event 3", StartTime: start.Add(4 * time.Hour), EndTime: start.Add(5 * time.Hour), Status: "SWAPPABLE", UserID: user1.ID})
+		if err != nil {
+			t.Fatalf("failed to create event3: %v", err)
+		}
+		offer, err := swapService.CreateSwapRequest(ctx, CreateSwapRequestInput{RequesterUserID: user2.ID, ResponderUserID: user1.ID, RequesterSlotID: event2.ID, ResponderSlotID: event3.ID})
+		if err != nil {
+			t.Fatalf("failed to create offer: %v", err)
+		}
+		end3 := start.Add(6 * time.Hour)
+		result, err = eventService.PatchEvent(ctx, PatchEventInput{ID: event3.ID, UserID: user1.ID, EndTime: &end3})
+		if err != nil {
+			t.Fatalf("failed to patch event3: %v", err)
+		}
+		if result.Event.Status != "SWAPPABLE" {
+			t.Errorf("expected event3 to stay SWAPPABLE, got %q", result.Event.Status)
+		}
+		want = EventSideEffects{CancelledSwapRequests: []int64{offer.ID}, ReleasedSlots: []int64{event2.ID}}
+		if !reflect.DeepEqual(result.SideEffects, want) {
+			t.Errorf("expected side effects %+v, got %+v", want, result.SideEffects)
+		}
+		released, err := eventRepo.GetEventByID(ctx, event2.ID)
+		if err != nil {
+			t.Fatalf("failed to get event2: %v", err)
+		}
+		if released.Status != "SWAPPABLE" {
+			t.Errorf("expected the offered slot to be SWAPPABLE again, got %q", released.Status)
+		}
+	})
+
+	t.Run("CreateAndUpdateEvent_Conflicts", func(t *testing.T) {
+		testQueries, user := repository.SetupTestDBWithUser(t)
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+
+		nine := time.Date(2030, time.May, 6, 9, 0, 0, 0, time.UTC)
+		morning, err := eventService.CreateEvent(context.Background(), CreateEventInput{Title: "Morning", StartTime: nine, EndTime: nine.Add(time.Hour), Status: "BUSY", UserID: user.ID})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+
+		_, err = eventService.CreateEvent(context.Background(), CreateEventInput{Title: "Overlap", StartTime: nine.Add(30 * time.Minute), EndTime: nine.Add(90 * time.Minute), Status: "BUSY", UserID: user.ID})
+		var conflictErr *ConflictError
+		if !errors.As(err, &conflictErr) {
+			t.Fatalf("expected a ConflictError, got %v", err)
+		}
+		if len(conflictErr.Events) != 1 || conflictErr.Events[0].ID != morning.ID {
+			t.Errorf("expected the morning event as the only conflict, got %+v", conflictErr.Events)
+		}
+
+		// Back-to-back events do not overlap.
+		noon, err := eventService.CreateEvent(context.Background(), CreateEventInput{Title: "Late morning", StartTime: nine.Add(time.Hour), EndTime: nine.Add(2 * time.Hour), Status: "BUSY", UserID: user.ID})
+		if err != nil {
+			t.Fatalf("expected adjacent event to be allowed, got %v", err)
+		}
+
+		if _, err := eventService.CreateEvent(context.Background(), CreateEventInput{Title: "Double booked", StartTime: nine, EndTime: nine.Add(time.Hour), Status: "BUSY", UserID: user.ID, AllowOverlap: true}); err != nil {
+			t.Fatalf("expected AllowOverlap to bypass the check, got %v", err)
+		}
+
+		// Editing an event never conflicts with itself.
+		if _, err := eventService.UpdateEvent(context.Background(), UpdateEventInput{ID: noon.ID, Title: "Renamed", StartTime: noon.StartTime, EndTime: noon.EndTime, UserID: user.ID}); err != nil {
+			t.Fatalf("expected update in place to succeed, got %v", err)
+		}
+
+		_, err = eventService.UpdateEvent(context.Background(), UpdateEventInput{ID: noon.ID, Title: "Moved", StartTime: nine.Add(45 * time.Minute), EndTime: nine.Add(2 * time.Hour), UserID: user.ID})
+		if !errors.As(err, &conflictErr) {
+			t.Fatalf("expected a ConflictError when moving onto other events, got %v", err)
+		}
+		if len(conflictErr.Events) != 2 {
+			t.Errorf("expected 2 conflicting events, got %d", len(conflictErr.Events))
+		}
+	})
+
+	t.Run("CreateRecurringEvents", func(t *testing.T) {
+		testQueries, user := repository.SetupTestDBWithUser(t)
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+
+		if err := userRepo.UpdateUserTimeZone(context.Background(), db.UpdateUserTimeZoneParams{TimeZone: "Europe/Berlin", ID: user.ID}); err != nil {
+			t.Fatalf("failed to set time zone: %v", err)
+		}
+
+		// 09:00 in Berlin, the Sunday before clocks go forward.
+		first := time.Date(2026, time.March, 22, 8, 0, 0, 0, time.UTC)
+		events, err := eventService.CreateRecurringEvents(context.Background(), CreateEventInput{Title: "Standup", StartTime: first, EndTime: first.Add(time.Hour), Status: "BUSY", UserID: user.ID}, RecurrenceInput{Frequency: "WEEKLY", Count: 2})
+		if err != nil {
+			t.Fatalf("failed to create recurring events: %v", err)
+		}
+		if len(events) != 2 {
+			t.Fatalf("expected 2 events, got %d", len(events))
+		}
+		if events[0].TimeZone != "Europe/Berlin" {
+			t.Errorf("expected the user's time zone, got %q", events[0].TimeZone)
+		}
+		if want := time.Date(2026, time.March, 29, 7, 0, 0, 0, time.UTC); !events[1].StartTime.Equal(want) {
+			t.Errorf("expected second occurrence at %s, got %s", want, events[1].StartTime)
+		}
+
+		// A series that collides anywhere is rejected as a whole.
+		_, err = eventService.CreateRecurringEvents(context.Background(), CreateEventInput{Title: "Clash", StartTime: first.Add(-6 * 24 * time.Hour), EndTime: first.Add(-6*24*time.Hour + time.Hour), Status: "BUSY", UserID: user.ID, TimeZone: "UTC"}, RecurrenceInput{Frequency: "DAILY", Count: 7})
+		var conflictErr *ConflictError
+		if !errors.As(err, &conflictErr) {
+			t.Fatalf("expected a ConflictError, got %v", err)
+		}
+		all, err := eventService.GetEventsByUserID(context.Background(), user.ID)
+		if err != nil {
+			t.Fatalf("failed to list events: %v", err)
+		}
+		if len(all) != 2 {
+			t.Errorf("expected the rejected series to be rolled back, got %d events", len(all))
 		}
 	})
 }
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/repository"
+)
+
+// DefaultIdempotencyKeyTTL is how long a response stays available for replay
+// unless configured otherwise.
+const DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+// idempotencyClaimLease is how long a claim on a key keeps retries out. A
+// claim older than that belongs to a request that died without completing or
+// releasing it, for example with the server, and the next retry takes it
+// over. It is well above the default write timeout.
+const idempotencyClaimLease = 2 * time.Minute
+
+var (
+	ErrIdempotencyKeyReused     = newError(ErrConflict, "idempotency key was already used for a different request")
+	ErrIdempotencyKeyInProgress = newError(ErrConflict, "a request with this idempotency key is still being processed")
+)
+
+// StoredResponse is the response to the first request sent with an
+// idempotency key.
+type StoredResponse struct {
+	StatusCode int
+	// Header holds the headers the handler set, such as Content-Type, ETag
+	// and Location.
+	Header map[string][]string
+	Body   []byte
+}
+
+// IdempotencyService remembers the responses to requests sent with an
+// idempotency key, so that retries return the original response instead of
+// repeating the mutation. Keys belong to a user and expire after the TTL.
+type IdempotencyService interface {
+	// Begin claims key for a request with the given fingerprint. It returns
+	// the stored response if the request was handled before, or nil if the
+	// caller should handle it and then call Complete or Release.
+	Begin(ctx context.Context, userID int64, key, fingerprint string) (*StoredResponse, error)
+	Complete(ctx context.Context, userID int64, key string, response StoredResponse) error
+	// Release forgets key, so that a request that failed can be retried.
+	Release(ctx context.Context, userID int64, key string) error
+}
+
+type idempotencyService struct {
+	repo       repository.IdempotencyKeyRepository
+	transactor repository.Transactor
+	ttl        time.Duration
+	lease      time.Duration
+}
+
+func NewIdempotencyService(repo repository.IdempotencyKeyRepository, transactor repository.Transactor, ttl time.Duration) IdempotencyService {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyKeyTTL
+	}
+	return &idempotencyService{repo: repo, transactor: transactor, ttl: ttl, lease: idempotencyClaimLease}
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, userID int64, key, fingerprint string) (*StoredResponse, error) {
+	now := time.Now().UTC()
+	var stored *StoredResponse
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteExpiredIdempotencyKeys(ctx, now); err != nil {
+			return err
+		}
+
+		row, err := s.repo.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{UserID: userID, IdempotencyKey: key})
+		if errors.Is(err, sql.ErrNoRows) {
+			return s.claim(ctx, userID, key, fingerprint, now)
+		}
+		if err != nil {
+			return err
+		}
+
+		if row.StatusCode == 0 && now.Sub(row.CreatedAt) >= s.lease {
+			if err := s.repo.DeleteIdempotencyKey(ctx, db.DeleteIdempotencyKeyParams{UserID: userID, IdempotencyKey: key}); err != nil {
+				return err
+			}
+			return s.claim(ctx, userID, key, fingerprint, now)
+		}
+
+		if row.Fingerprint != fingerprint {
+			return ErrIdempotencyKeyReused
+		}
+		if row.StatusCode == 0 {
+			return ErrIdempotencyKeyInProgress
+		}
+		stored = &StoredResponse{StatusCode: int(row.StatusCode), Body: []byte(row.ResponseBody)}
+		return json.Unmarshal([]byte(row.ResponseHeaders), &stored.Header)
+	})
+	return stored, err
+}
+
+// claim records that a request with fingerprint is being handled under key.
+func (s *idempotencyService) claim(ctx context.Context, userID int64, key, fingerprint string, now time.Time) error {
+	created, err := s.repo.CreateIdempotencyKey(ctx, db.CreateIdempotencyKeyParams{
+		UserID:         userID,
+		IdempotencyKey: key,
+		Fingerprint:    fingerprint,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(s.ttl),
+	})
+	if err != nil {
+		return err
+	}
+	// A concurrent request claimed the key between our lookup and insert.
+	if created == 0 {
+		return ErrIdempotencyKeyInProgress
+	}
+	return nil
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, userID int64, key string, response StoredResponse) error {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+	return s.repo.CompleteIdempotencyKey(ctx, db.CompleteIdempotencyKeyParams{
+		StatusCode:      int64(response.StatusCode),
+		ResponseHeaders: string(header),
+		ResponseBody:    string(response.Body),
+		UserID:          userID,
+		IdempotencyKey:  key,
+	})
+}
+
+func (s *idempotencyService) Release(ctx context.Context, userID int64, key string) error {
+	return s.repo.DeleteIdempotencyKey(ctx, db.DeleteIdempotencyKeyParams{UserID: userID, IdempotencyKey: key})
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"slotswapper/internal/repository"
+)
+
+func TestIdempotencyService(t *testing.T) {
+	setup := func(t *testing.T, ttl time.Duration) (IdempotencyService, int64) {
+		testQueries, user := repository.SetupTestDBWithUser(t)
+		return NewIdempotencyService(repository.NewIdempotencyKeyRepository(testQueries), repository.NewTransactor(testQueries), ttl), user.ID
+	}
+	ctx := context.Background()
+	response := StoredResponse{StatusCode: 200, Header: map[string][]string{"Content-Type": {"application/json"}, "Etag": {`"1"`}}, Body: []byte(`{"id":1}`)}
+
+	t.Run("Replays a completed request", func(t *testing.T) {
+		service, userID := setup(t, time.Hour)
+
+		stored, err := service.Begin(ctx, userID, "key", "fingerprint")
+		if err != nil || stored != nil {
+			t.Fatalf("expected a new key to be claimed, got %+v, %v", stored, err)
+		}
+		if _, err := service.Begin(ctx, userID, "key", "fingerprint"); !errors.Is(err, ErrIdempotencyKeyInProgress) {
+			t.Errorf("expected a pending key to be in progress, got %v", err)
+		}
+		if err := service.Complete(ctx, userID, "key", response); err != nil {
+			t.Fatalf("failed to complete: %v", err)
+		}
+
+		stored, err = service.Begin(ctx, userID, "key", "fingerprint")
+		if err != nil {
+			t.Fatalf("failed to begin retry: %v", err)
+		}
+		if stored == nil || stored.StatusCode != 200 || !reflect.DeepEqual(stored.Header, response.Header) || string(stored.Body) != `{"id":1}` {
+			t.Errorf("expected the stored response, got %+v", stored)
+		}
+		if _, err := service.Begin(ctx, userID, "key", "other"); !errors.Is(err, ErrIdempotencyKeyReused) {
+			t.Errorf("expected a different fingerprint to be refused, got %v", err)
+		}
+	})
+
+	t.Run("Release allows a retry", func(t *testing.T) {
+		service, userID := setup(t, time.Hour)
+
+		if _, err := service.Begin(ctx, userID, "key", "fingerprint"); err != nil {
+			t.Fatalf("failed to begin: %v", err)
+		}
+		if err := service.Release(ctx, userID, "key"); err != nil {
+			t.Fatalf("failed to release: %v", err)
+		}
+		if stored, err := service.Begin(ctx, userID, "key", "other"); err != nil || stored != nil {
+			t.Errorf("expected a released key to be claimed again, got %+v, %v", stored, err)
+		}
+	})
+
+	t.Run("A stale claim is taken over", func(t *testing.T) {
+		service, userID := setup(t, time.Hour)
+		service.(*idempotencyService).lease = time.Millisecond
+
+		if _, err := service.Begin(ctx, userID, "key", "fingerprint"); err != nil {
+			t.Fatalf("failed to begin: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		if stored, err := service.Begin(ctx, userID, "key", "other"); err != nil || stored != nil {
+			t.Fatalf("expected a stale claim to be taken over, got %+v, %v", stored, err)
+		}
+		service.(*idempotencyService).lease = time.Hour
+		if _, err := service.Begin(ctx, userID, "key", "other"); !errors.Is(err, ErrIdempotencyKeyInProgress) {
+			t.Errorf("expected the new claim to be in progress, got %v", err)
+		}
+	})
+
+	t.Run("Keys expire", func(t *testing.T) {
+		service, userID := setup(t, time.Millisecond)
+
+		if _, err := service.Begin(ctx, userID, "key", "fingerprint"); err != nil {
+			t.Fatalf("failed to begin: %v", err)
+		}
+		if err := service.Complete(ctx, userID, "key", response); err != nil {
+			t.Fatalf("failed to complete: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		if stored, err := service.Begin(ctx, userID, "key", "other"); err != nil || stored != nil {
+			t.Errorf("expected an expired key to be claimed again, got %+v, %v", stored, err)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/repository"
+)
+
+// Kinds of notification.
+const (
+	NotificationSwapRequestCancelled  = "swap_request.cancelled"
+	NotificationSwapRequestWithdrawn  = "swap_request.withdrawn"
+	NotificationSwapRequestSuperseded = "swap_request.superseded"
+	NotificationSlotClaimed           = "swap_request.claimed"
+)
+
+// NotificationListFilter pages a user's notifications, newest first.
+type NotificationListFilter struct {
+	UnreadOnly bool `json:"unread"`
+	PageRequest
+}
+
+const notificationSort = "-id"
+
+type NotificationService interface {
+	ListNotifications(ctx context.Context, userID int64, filter NotificationListFilter) (*Page[db.Notification], error)
+	// MarkRead marks one of userID's notifications as read and returns it.
+	MarkRead(ctx context.Context, userID, notificationID int64) (*db.Notification, error)
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+}
+
+func NewNotificationService(notificationRepo repository.NotificationRepository) NotificationService {
+	return &notificationService{notificationRepo: notificationRepo}
+}
+
+func (s *notificationService) ListNotifications(ctx context.Context, userID int64, filter NotificationListFilter) (*Page[db.Notification], error) {
+	if err := validate(filter); err != nil {
+		return nil, err
+	}
+	filter.Limit = pageLimit(filter.Limit)
+
+	after, ok, err := decodeCursor(filter.Cursor, notificationSort)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		after.ID = math.MaxInt64
+	}
+
+	rows, err := s.notificationRepo.ListNotificationsByUserID(ctx, db.ListNotificationsByUserIDParams{
+		UserID:     userID,
+		UnreadOnly: filter.UnreadOnly,
+		BeforeID:   after.ID,
+		Limit:      filter.Limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(rows, filter.Limit, func(row db.Notification) pageCursor {
+		return pageCursor{Sort: notificationSort, ID: row.ID}
+	}), nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID, notificationID int64) (*db.Notification, error) {
+	// The user is part of the lookup, so someone else's notification is
+	// reported as missing.
+	notification, err := s.notificationRepo.MarkNotificationRead(ctx, db.MarkNotificationReadParams{ID: notificationID, UserID: userID})
+	if err != nil {
+		return nil, notFound(err, "notification not found")
+	}
+	return &notification, nil
+}
+
+// cancellationCauses completes "Swap request N was cancelled because ..." for
+// each cancel reason.
+var cancellationCauses = map[string]string{
+	CancelReasonSlotModified:    "the other slot was changed",
+	CancelReasonSlotDeleted:     "the other slot was deleted",
+	CancelReasonUserDeactivated: "the other user closed their account",
+}
+
+// notifySwapRequestCancelled tells the participants of a cancelled swap
+// request, other than the user who caused the cancellation, why it happened.
+func notifySwapRequestCancelled(ctx context.Context, notificationRepo repository.NotificationRepository, req db.SwapRequest, reason string, actorUserID int64) error {
+	message := fmt.Sprintf("Swap request %d was cancelled because %s.", req.ID, cancellationCauses[reason])
+	return notifyParticipants(ctx, notificationRepo, req, NotificationSwapRequestCancelled, reason, message, actorUserID)
+}
+
+// notifySwapRequestWithdrawn tells the responder that the requester took
+// their offer back.
+func notifySwapRequestWithdrawn(ctx context.Context, notificationRepo repository.NotificationRepository, req db.SwapRequest) error {
+	message := fmt.Sprintf("Swap request %d was withdrawn by the requester.", req.ID)
+	return notifyParticipants(ctx, notificationRepo, req, NotificationSwapRequestWithdrawn, "", message, req.RequesterUserID)
+}
+
+// notifySwapRequestSuperseded tells the requester that the slot they asked
+// for was swapped through another request.
+func notifySwapRequestSuperseded(ctx context.Context, notificationRepo repository.NotificationRepository, req db.SwapRequest, acceptedID, actorUserID int64) error {
+	message := fmt.Sprintf("Swap request %d was closed because swap request %d for the same slot was accepted.", req.ID, acceptedID)
+	return notifyParticipants(ctx, notificationRepo, req, NotificationSwapRequestSuperseded, "", message, actorUserID)
+}
+
+// notifySlotClaimed tells the owner of a first-come giveaway that their slot
+// went to the claimant of req.
+func notifySlotClaimed(ctx context.Context, notificationRepo repository.NotificationRepository, req db.SwapRequest) error {
+	message := fmt.Sprintf("Your slot was claimed through swap request %d.", req.ID)
+	return notifyParticipants(ctx, notificationRepo, req, NotificationSlotClaimed, "", message, req.RequesterUserID)
+}
+
+// notifyParticipants notifies both users of a swap request except
+// actorUserID.
+func notifyParticipants(ctx context.Context, notificationRepo repository.NotificationRepository, req db.SwapRequest, kind, reason, message string, actorUserID int64) error {
+	for _, userID := range []int64{req.RequesterUserID, req.ResponderUserID} {
+		if userID == actorUserID {
+			continue
+		}
+		_, err := notificationRepo.CreateNotification(ctx, db.CreateNotificationParams{
+			UserID:        userID,
+			Kind:          kind,
+			SwapRequestID: &req.ID,
+			Reason:        reason,
+			Message:       message,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+const defaultPageLimit = 50
+
+var ErrInvalidCursor = newValidationError("cursor", "cursor", "cursor is invalid or was issued for a different sort")
+
+// Page is one slice of a keyset-paginated listing. NextCursor is empty once
+// the last page has been returned.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+}
+
+// PageRequest holds the paging parameters shared by every list endpoint. The
+// JSON names match the query parameters and are used in validation errors.
+type PageRequest struct {
+	Limit  int64  `json:"limit" validate:"min=0,max=100"`
+	Cursor string `json:"cursor"`
+}
+
+// pageCursor is the decoded form of an opaque cursor: the sort key and id of
+// the last row on the previous page. Sort pins the cursor to the ordering it
+// was issued for.
+type pageCursor struct {
+	Sort    string    `json:"s"`
+	Time    time.Time `json:"t,omitzero"`
+	SortKey float64   `json:"k,omitempty"`
+	ID      int64     `json:"i"`
+}
+
+func encodeCursor(c pageCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses cursor and checks that it was issued for sort. An empty
+// cursor decodes to the zero value with ok set to false.
+func decodeCursor(cursor, sort string) (c pageCursor, ok bool, err error) {
+	if cursor == "" {
+		return pageCursor{}, false, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pageCursor{}, false, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sort {
+		return pageCursor{}, false, ErrInvalidCursor
+	}
+	return c, true, nil
+}
+
+func pageLimit(limit int64) int64 {
+	if limit <= 0 {
+		return defaultPageLimit
+	}
+	return limit
+}
+
+// newPage trims the extra row fetched to detect a following page and, when
+// there is one, builds its cursor from the last row kept.
+func newPage[T any](rows []T, limit int64, cursorFor func(T) pageCursor) *Page[T] {
+	page := &Page[T]{Items: rows}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if int64(len(rows)) > limit {
+		page.Items = rows[:limit]
+		page.NextCursor = encodeCursor(cursorFor(page.Items[limit-1]))
+	}
+	return page
+}
+
+// nullTime maps an unset time filter to NULL so the query ignores it.
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Actor is the role in which a status change is made. The same user can be
+// the owner of one event and the requester of a swap request.
+type Actor string
+
+const (
+	// ActorOwner is the owner of an event, changing it directly.
+	ActorOwner Actor = "owner"
+	// ActorRequester is the user who sent a swap request.
+	ActorRequester Actor = "requester"
+	// ActorResponder is the user a swap request was sent to.
+	ActorResponder Actor = "responder"
+	// ActorSystem is a change made as a side effect of another one, such as
+	// a slot being released when its swap request is rejected.
+	ActorSystem Actor = "system"
+)
+
+// Actors lists every actor, for exhaustive checks and exports.
+var Actors = []Actor{ActorOwner, ActorRequester, ActorResponder, ActorSystem}
+
+// TransitionError reports a status change that a state machine does not
+// allow. It wraps ErrValidation for a status an entity cannot be created
+// with, ErrForbidden when the transition exists but not for the actor, and
+// ErrInvalidState otherwise.
+type TransitionError struct {
+	Entity string
+	// From is empty when the entity is being created.
+	From  string
+	To    string
+	Actor Actor
+	Kind  error
+}
+
+func (e *TransitionError) Error() string {
+	switch {
+	case e.From == "":
+		return fmt.Sprintf("a new %s cannot be %s", e.Entity, e.To)
+	case e.Kind == ErrForbidden:
+		return fmt.Sprintf("the %s may not move this %s from %s to %s", e.Actor, e.Entity, e.From, e.To)
+	default:
+		return fmt.Sprintf("this %s cannot move from %s to %s", e.Entity, e.From, e.To)
+	}
+}
+
+func (e *TransitionError) Unwrap() error { return e.Kind }
+
+// transition is one edge of a state machine. An empty from is the creation
+// of the entity. The guard, if any, must also pass.
+type transition[S ~string, T any] struct {
+	from, to S
+	actors   []Actor
+	guard    guard[T]
+}
+
+// guard is a named condition on the entity, beyond its status, that a
+// transition requires. The name appears in the exported graph.
+type guard[T any] struct {
+	name  string
+	check func(T) error
+}
+
+// StateMachine declares the statuses of one kind of entity and who may move
+// it from one to another. Every status change goes through Transition.
+type StateMachine[S ~string, T any] struct {
+	entity      string
+	states      []S
+	transitions []transition[S, T]
+}
+
+// States returns the statuses in declaration order.
+func (m *StateMachine[S, T]) States() []S {
+	return slices.Clone(m.states)
+}
+
+// Transition checks that actor may move subject from one status to another,
+// returning a *TransitionError if not, or the guard's error if it fails.
+func (m *StateMachine[S, T]) Transition(from, to S, actor Actor, subject T) error {
+	for _, t := range m.transitions {
+		if t.from != from || t.to != to {
+			continue
+		}
+		if !slices.Contains(t.actors, actor) {
+			return m.transitionError(from, to, actor, ErrForbidden)
+		}
+		if t.guard.check != nil {
+			return t.guard.check(subject)
+		}
+		return nil
+	}
+	if from == "" {
+		return m.transitionError(from, to, actor, ErrValidation)
+	}
+	return m.transitionError(from, to, actor, ErrInvalidState)
+}
+
+func (m *StateMachine[S, T]) transitionError(from, to S, actor Actor, kind error) *TransitionError {
+	return &TransitionError{Entity: m.entity, From: string(from), To: string(to), Actor: actor, Kind: kind}
+}
+
+// StateGraph is an exported description of a state machine.
+type StateGraph struct {
+	Entity      string            `json:"entity"`
+	States      []string          `json:"states"`
+	Transitions []GraphTransition `json:"transitions"`
+}
+
+// GraphTransition is one edge of a StateGraph. From is empty for the
+// statuses an entity can be created with.
+type GraphTransition struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Actors []Actor `json:"actors"`
+	Guard  string  `json:"guard,omitempty"`
+}
+
+// Graph describes the machine's states and transitions.
+func (m *StateMachine[S, T]) Graph() StateGraph {
+	graph := StateGraph{Entity: m.entity}
+	for _, state := range m.states {
+		graph.States = append(graph.States, string(state))
+	}
+	for _, t := range m.transitions {
+		graph.Transitions = append(graph.Transitions, GraphTransition{
+			From:   string(t.from),
+			To:     string(t.to),
+			Actors: slices.Clone(t.actors),
+			Guard:  t.guard.name,
+		})
+	}
+	return graph
+}
+
+// DOT renders the graph in the Graphviz DOT language. Creation starts from a
+// point node, and each edge is labelled with its actors and guard.
+func (g StateGraph) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", g.Entity)
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tstart [shape=point];\n")
+	for _, state := range g.States {
+		fmt.Fprintf(&b, "\t%q;\n", state)
+	}
+	for _, t := range g.Transitions {
+		from := "start"
+		if t.From != "" {
+			from = fmt.Sprintf("%q", t.From)
+		}
+		actors := make([]string, len(t.Actors))
+		for i, actor := range t.Actors {
+			actors[i] = string(actor)
+		}
+		label := strings.Join(actors, ", ")
+		if t.Guard != "" {
+			label += " [" + t.Guard + "]"
+		}
+		fmt.Fprintf(&b, "\t%s -> %q [label=%q];\n", from, t.To, label)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
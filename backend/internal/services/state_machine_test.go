@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"slotswapper/internal/db"
+)
+
+// checkTransitionTable tries every pair of statuses, including creation, with
+// every actor and compares the outcome with want, which maps "FROM->TO" to
+// the actors allowed to take that transition.
+func checkTransitionTable[S ~string, T any](t *testing.T, m *StateMachine[S, T], subject T, want map[string][]Actor) {
+	t.Helper()
+	seen := map[string]bool{}
+	for _, from := range append([]S{""}, m.States()...) {
+		for _, to := range m.States() {
+			key := string(from) + "->" + string(to)
+			allowed, declared := want[key]
+			seen[key] = true
+			for _, actor := range Actors {
+				err := m.Transition(from, to, actor, subject)
+				var wantKind error
+				switch {
+				case declared && slices.Contains(allowed, actor):
+					wantKind = nil
+				case declared:
+					wantKind = ErrForbidden
+				case from == "":
+					wantKind = ErrValidation
+				default:
+					wantKind = ErrInvalidState
+				}
+
+				if wantKind == nil {
+					if err != nil {
+						t.Errorf("%s by %s: expected it to be allowed, got %v", key, actor, err)
+					}
+					continue
+				}
+				var transitionErr *TransitionError
+				if !errors.As(err, &transitionErr) || !errors.Is(err, wantKind) {
+					t.Errorf("%s by %s: expected a transition error wrapping %v, got %v", key, actor, wantKind, err)
+					continue
+				}
+				if transitionErr.From != string(from) || transitionErr.To != string(to) || transitionErr.Actor != actor {
+					t.Errorf("%s by %s: unexpected transition error %+v", key, actor, transitionErr)
+				}
+			}
+		}
+	}
+	for key := range want {
+		if !seen[key] {
+			t.Errorf("expected transition %s is between unknown statuses", key)
+		}
+	}
+}
+
+func TestEventStateMachine(t *testing.T) {
+	checkTransitionTable(t, EventStateMachine, db.Event{}, map[string][]Actor{
+		"->BUSY":                  {ActorOwner},
+		"->SWAPPABLE":             {ActorOwner},
+		"BUSY->BUSY":              {ActorOwner},
+		"BUSY->SWAPPABLE":         {ActorOwner},
+		"SWAPPABLE->SWAPPABLE":    {ActorOwner},
+		"SWAPPABLE->BUSY":         {ActorOwner, ActorSystem},
+		"SWAPPABLE->SWAP_PENDING": {ActorSystem},
+		"SWAP_PENDING->SWAPPABLE": {ActorSystem},
+		"SWAP_PENDING->BUSY":      {ActorOwner, ActorSystem},
+	})
+}
+
+func TestSwapStateMachine(t *testing.T) {
+	requesterSlotID := int64(1)
+	request := db.SwapRequest{RequesterUserID: 1, ResponderUserID: 2, RequesterSlotID: &requesterSlotID, Kind: "SWAP"}
+	onOffer := swapSubject{
+		Request:       request,
+		RequesterSlot: db.Event{UserID: 1, Status: "SWAP_PENDING"},
+		ResponderSlot: db.Event{UserID: 2, Status: "SWAPPABLE"},
+	}
+	firstCome := "FIRST_COME"
+	claim := swapSubject{
+		Request:       db.SwapRequest{RequesterUserID: 1, ResponderUserID: 2, Kind: "TRANSFER"},
+		ResponderSlot: db.Event{UserID: 2, Status: "SWAPPABLE", Giveaway: &firstCome},
+	}
+	checkTransitionTable(t, SwapStateMachine, claim, map[string][]Actor{
+		"->PENDING":           {ActorRequester},
+		"->ACCEPTED":          {ActorRequester},
+		"PENDING->ACCEPTED":   {ActorResponder},
+		"PENDING->REJECTED":   {ActorResponder},
+		"PENDING->WITHDRAWN":  {ActorRequester},
+		"PENDING->SUPERSEDED": {ActorSystem},
+		"PENDING->CANCELLED":  {ActorSystem},
+	})
+
+	t.Run("new requests need their slots on the marketplace", func(t *testing.T) {
+		offer := swapSubject{Request: request, RequesterSlot: db.Event{UserID: 1, Status: "SWAPPABLE"}, ResponderSlot: onOffer.ResponderSlot}
+		if err := SwapStateMachine.Transition("", SwapPending, ActorRequester, offer); err != nil {
+			t.Fatalf("expected the offer to be made, got %v", err)
+		}
+		for name, subject := range map[string]swapSubject{
+			"reserved offered slot": {Request: request, RequesterSlot: onOffer.RequesterSlot, ResponderSlot: onOffer.ResponderSlot},
+			"offered giveaway":      {Request: request, RequesterSlot: db.Event{UserID: 1, Status: "SWAPPABLE", Giveaway: &firstCome}, ResponderSlot: onOffer.ResponderSlot},
+			"busy slot asked for":   {Request: request, RequesterSlot: offer.RequesterSlot, ResponderSlot: db.Event{UserID: 2, Status: "BUSY"}},
+			"claim of a kept slot":  {Request: claim.Request, ResponderSlot: db.Event{UserID: 2, Status: "SWAPPABLE"}},
+		} {
+			err := SwapStateMachine.Transition("", SwapPending, ActorRequester, subject)
+			if !errors.Is(err, ErrInvalidState) {
+				t.Errorf("%s: expected ErrInvalidState, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("accepting needs both slots on offer", func(t *testing.T) {
+		if err := SwapStateMachine.Transition(SwapPending, SwapAccepted, ActorResponder, onOffer); err != nil {
+			t.Fatalf("expected the swap to be accepted, got %v", err)
+		}
+		for name, subject := range map[string]swapSubject{
+			"released slot": {Request: request, RequesterSlot: db.Event{UserID: 1, Status: "SWAPPABLE"}, ResponderSlot: onOffer.ResponderSlot},
+			"new owner":     {Request: request, RequesterSlot: onOffer.RequesterSlot, ResponderSlot: db.Event{UserID: 3, Status: "SWAPPABLE"}},
+			"reserved slot": {Request: request, RequesterSlot: onOffer.RequesterSlot, ResponderSlot: db.Event{UserID: 2, Status: "SWAP_PENDING"}},
+		} {
+			err := SwapStateMachine.Transition(SwapPending, SwapAccepted, ActorResponder, subject)
+			if !errors.Is(err, ErrInvalidState) {
+				t.Errorf("%s: expected ErrInvalidState, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("only first-come claims are accepted as they are made", func(t *testing.T) {
+		ownerPicks := "OWNER_PICKS"
+		for name, subject := range map[string]swapSubject{
+			"swap":        onOffer,
+			"owner picks": {Request: claim.Request, ResponderSlot: db.Event{UserID: 2, Status: "SWAPPABLE", Giveaway: &ownerPicks}},
+			"not given":   {Request: claim.Request, ResponderSlot: db.Event{UserID: 2, Status: "SWAPPABLE"}},
+			"new owner":   {Request: claim.Request, ResponderSlot: db.Event{UserID: 3, Status: "SWAPPABLE", Giveaway: &firstCome}},
+		} {
+			err := SwapStateMachine.Transition("", SwapAccepted, ActorRequester, subject)
+			if !errors.Is(err, ErrInvalidState) {
+				t.Errorf("%s: expected ErrInvalidState, got %v", name, err)
+			}
+		}
+	})
+}
+
+func TestStateGraph(t *testing.T) {
+	graph := SwapStateMachine.Graph()
+	if graph.Entity != "swap request" || len(graph.States) != 6 || len(graph.Transitions) != 7 {
+		t.Fatalf("unexpected graph %+v", graph)
+	}
+
+	dot := graph.DOT()
+	for _, want := range []string{
+		`digraph "swap request" {`,
+		`start -> "PENDING" [label="requester [slots on the marketplace]"];`,
+		`start -> "ACCEPTED" [label="requester [first-come giveaway]"];`,
+		`"PENDING" -> "ACCEPTED" [label="responder [slots on offer]"];`,
+		`"PENDING" -> "REJECTED" [label="responder"];`,
+		`"PENDING" -> "WITHDRAWN" [label="requester"];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("expected the DOT output to contain %q, got:\n%s", want, dot)
+		}
+	}
+}
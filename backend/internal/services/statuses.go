@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/repository"
+)
+
+// EventStatus is the status of an event.
+type EventStatus string
+
+const (
+	// EventBusy is an event its owner keeps.
+	EventBusy EventStatus = "BUSY"
+	// EventSwappable is on the marketplace and can receive several offers.
+	EventSwappable EventStatus = "SWAPPABLE"
+	// EventSwapPending is reserved as the requester's side of a pending swap
+	// request.
+	EventSwapPending EventStatus = "SWAP_PENDING"
+)
+
+// SwapStatus is the status of a swap request.
+type SwapStatus string
+
+const (
+	SwapPending   SwapStatus = "PENDING"
+	SwapAccepted  SwapStatus = "ACCEPTED"
+	SwapRejected  SwapStatus = "REJECTED"
+	SwapWithdrawn SwapStatus = "WITHDRAWN"
+	// SwapSuperseded is an offer closed because another one for the same
+	// slot was accepted.
+	SwapSuperseded SwapStatus = "SUPERSEDED"
+	SwapCancelled  SwapStatus = "CANCELLED"
+)
+
+// Giveaway is how a slot that its owner gives away goes to a claimant.
+type Giveaway string
+
+const (
+	// GiveawayFirstCome transfers the slot to the first user who claims it.
+	GiveawayFirstCome Giveaway = "FIRST_COME"
+	// GiveawayOwnerPicks keeps the claims pending until the owner accepts
+	// one of them.
+	GiveawayOwnerPicks Giveaway = "OWNER_PICKS"
+)
+
+// SwapKind tells trades from one-way transfers.
+type SwapKind string
+
+const (
+	// SwapKindSwap trades the requester's slot for the responder's.
+	SwapKindSwap SwapKind = "SWAP"
+	// SwapKindTransfer claims the responder's slot, which is being given
+	// away, with nothing in return. It has no requester slot.
+	SwapKindTransfer SwapKind = "TRANSFER"
+)
+
+// EventStateMachine declares how an event's status may change. Only the
+// swap flow, acting as the system, puts a slot into or takes it out of
+// SWAP_PENDING; the owner can only withdraw such a slot, which cancels its
+// pending requests.
+var EventStateMachine = &StateMachine[EventStatus, db.Event]{
+	entity: "event",
+	states: []EventStatus{EventBusy, EventSwappable, EventSwapPending},
+	transitions: []transition[EventStatus, db.Event]{
+		{from: "", to: EventBusy, actors: []Actor{ActorOwner}},
+		{from: "", to: EventSwappable, actors: []Actor{ActorOwner}},
+		{from: EventBusy, to: EventBusy, actors: []Actor{ActorOwner}},
+		{from: EventBusy, to: EventSwappable, actors: []Actor{ActorOwner}},
+		{from: EventSwappable, to: EventSwappable, actors: []Actor{ActorOwner}},
+		{from: EventSwappable, to: EventBusy, actors: []Actor{ActorOwner, ActorSystem}},
+		{from: EventSwappable, to: EventSwapPending, actors: []Actor{ActorSystem}},
+		{from: EventSwapPending, to: EventSwappable, actors: []Actor{ActorSystem}},
+		{from: EventSwapPending, to: EventBusy, actors: []Actor{ActorOwner, ActorSystem}},
+	},
+}
+
+// swapSubject is a swap request with its two slots, as the swap guards see
+// them.
+type swapSubject struct {
+	Request       db.SwapRequest
+	RequesterSlot db.Event
+	ResponderSlot db.Event
+}
+
+// SwapStateMachine declares how a swap request's status may change. A
+// request is answered once; every status but PENDING is final. A claim on a
+// first-come giveaway is accepted as it is made.
+var SwapStateMachine = &StateMachine[SwapStatus, swapSubject]{
+	entity: "swap request",
+	states: []SwapStatus{SwapPending, SwapAccepted, SwapRejected, SwapWithdrawn, SwapSuperseded, SwapCancelled},
+	transitions: []transition[SwapStatus, swapSubject]{
+		{from: "", to: SwapPending, actors: []Actor{ActorRequester}, guard: slotsOnMarketplace},
+		{from: "", to: SwapAccepted, actors: []Actor{ActorRequester}, guard: firstComeGiveaway},
+		{from: SwapPending, to: SwapAccepted, actors: []Actor{ActorResponder}, guard: slotsOnOffer},
+		{from: SwapPending, to: SwapRejected, actors: []Actor{ActorResponder}},
+		{from: SwapPending, to: SwapWithdrawn, actors: []Actor{ActorRequester}},
+		{from: SwapPending, to: SwapSuperseded, actors: []Actor{ActorSystem}},
+		{from: SwapPending, to: SwapCancelled, actors: []Actor{ActorSystem}},
+	},
+}
+
+// slotsOnMarketplace requires the slots of a new request to be on the
+// marketplace: the slot asked for, given away if the request is a claim, and
+// for a swap the offered slot, which must not be a giveaway itself.
+var slotsOnMarketplace = guard[swapSubject]{
+	name: "slots on the marketplace",
+	check: func(s swapSubject) error {
+		if SwapKind(s.Request.Kind) == SwapKindSwap {
+			if EventStatus(s.RequesterSlot.Status) != EventSwappable {
+				return newError(ErrInvalidState, "requester slot is not swappable")
+			}
+			if s.RequesterSlot.Giveaway != nil {
+				return newError(ErrInvalidState, "requester slot is being given away")
+			}
+		}
+		if EventStatus(s.ResponderSlot.Status) != EventSwappable {
+			return newError(ErrInvalidState, "responder slot is not swappable")
+		}
+		if SwapKind(s.Request.Kind) == SwapKindTransfer && s.ResponderSlot.Giveaway == nil {
+			return newError(ErrInvalidState, "slot is not being given away")
+		}
+		return nil
+	},
+}
+
+// slotsOnOffer requires the requester's slot to still be reserved for the
+// request and the responder's slot to still be on the marketplace, both with
+// the users who made the deal. A transfer has only the responder's slot.
+var slotsOnOffer = guard[swapSubject]{
+	name: "slots on offer",
+	check: func(s swapSubject) error {
+		requesterSlotGone := s.Request.RequesterSlotID != nil &&
+			(EventStatus(s.RequesterSlot.Status) != EventSwapPending || s.RequesterSlot.UserID != s.Request.RequesterUserID)
+		if requesterSlotGone || EventStatus(s.ResponderSlot.Status) != EventSwappable || s.ResponderSlot.UserID != s.Request.ResponderUserID {
+			return newError(ErrInvalidState, "one of the slots is no longer on offer")
+		}
+		return nil
+	},
+}
+
+// firstComeGiveaway requires a transfer of a slot that its owner still gives
+// away to whoever claims it first.
+var firstComeGiveaway = guard[swapSubject]{
+	name: "first-come giveaway",
+	check: func(s swapSubject) error {
+		slot := s.ResponderSlot
+		if SwapKind(s.Request.Kind) != SwapKindTransfer || EventStatus(slot.Status) != EventSwappable ||
+			slot.Giveaway == nil || Giveaway(*slot.Giveaway) != GiveawayFirstCome || slot.UserID != s.Request.ResponderUserID {
+			return newError(ErrInvalidState, "the slot is not given away to the first claimant")
+		}
+		return nil
+	},
+}
+
+// swapActor returns the role userID has in req, or false if they take no
+// part in it.
+func swapActor(req db.SwapRequest, userID int64) (Actor, bool) {
+	switch userID {
+	case req.ResponderUserID:
+		return ActorResponder, true
+	case req.RequesterUserID:
+		return ActorRequester, true
+	}
+	return "", false
+}
+
+// setEventStatus moves event to status through EventStateMachine on behalf of
+// actor and records the change. It ends any giveaway of the event.
+func setEventStatus(ctx context.Context, eventRepo repository.EventRepository, auditRepo repository.AuditLogRepository, event db.Event, status EventStatus, actor Actor, actorUserID int64) (db.Event, error) {
+	return setEventListing(ctx, eventRepo, auditRepo, event, status, nil, actor, actorUserID)
+}
+
+// setEventListing is setEventStatus for a SWAPPABLE event that its owner may
+// also be giving away.
+func setEventListing(ctx context.Context, eventRepo repository.EventRepository, auditRepo repository.AuditLogRepository, event db.Event, status EventStatus, giveaway *string, actor Actor, actorUserID int64) (db.Event, error) {
+	if err := EventStateMachine.Transition(EventStatus(event.Status), status, actor, event); err != nil {
+		return db.Event{}, err
+	}
+	updated, err := eventRepo.UpdateEventStatus(ctx, db.UpdateEventStatusParams{ID: event.ID, Status: string(status), Giveaway: giveaway})
+	if err != nil {
+		return db.Event{}, err
+	}
+	err = recordAudit(ctx, auditRepo, auditRecord{
+		ActorUserID: actorUserID,
+		Action:      AuditActionEventStatusUpdate,
+		EntityType:  AuditEntityEvent,
+		EntityID:    event.ID,
+		Before:      event,
+		After:       updated,
+		Subjects:    []int64{event.UserID},
+	})
+	return updated, err
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/logging"
+	"slotswapper/internal/repository"
+)
+
+// Reasons a swap request was cancelled, recorded with the CANCELLED status.
+const (
+	// CancelReasonSlotModified: one of the slots was moved or renamed.
+	CancelReasonSlotModified = "slot_modified"
+	// CancelReasonSlotDeleted: one of the slots was deleted.
+	CancelReasonSlotDeleted = "slot_deleted"
+	// CancelReasonUserDeactivated: one of the users closed their account.
+	CancelReasonUserDeactivated = "user_deactivated"
+)
+
+// swapCanceller closes pending swap requests that can no longer go ahead. A
+// cancelled request is kept, with its reason, so that both users can see in
+// their history what happened to it.
+type swapCanceller struct {
+	eventRepo        repository.EventRepository
+	swapRepo         repository.SwapRequestRepository
+	auditRepo        repository.AuditLogRepository
+	notificationRepo repository.NotificationRepository
+}
+
+// cancel closes req as CANCELLED with reason and, unless releaseSlotID is
+// zero, hands that slot, the one the requester reserved, back to the
+// marketplace. The participants other than actorUserID are notified. Call it
+// inside a transaction.
+func (c swapCanceller) cancel(ctx context.Context, req db.SwapRequest, reason string, releaseSlotID, actorUserID int64) error {
+	if err := SwapStateMachine.Transition(SwapStatus(req.Status), SwapCancelled, ActorSystem, swapSubject{Request: req}); err != nil {
+		return err
+	}
+	if releaseSlotID != 0 {
+		if err := c.release(ctx, releaseSlotID, actorUserID); err != nil {
+			return err
+		}
+	}
+
+	cancelled, err := c.swapRepo.CancelSwapRequest(ctx, db.CancelSwapRequestParams{
+		ID:               req.ID,
+		CancelReason:     &reason,
+		ResolvedByUserID: &actorUserID,
+	})
+	if err != nil {
+		return err
+	}
+	err = recordAudit(ctx, c.auditRepo, auditRecord{
+		ActorUserID: actorUserID,
+		Action:      AuditActionSwapRequestCancel,
+		EntityType:  AuditEntitySwapRequest,
+		EntityID:    req.ID,
+		Before:      req,
+		After:       cancelled,
+		Subjects:    []int64{req.RequesterUserID, req.ResponderUserID},
+	})
+	if err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Info("pending swap request cancelled", "swap_request_id", req.ID, "reason", reason)
+	return notifySwapRequestCancelled(ctx, c.notificationRepo, req, reason, actorUserID)
+}
+
+// supersede closes req as SUPERSEDED because acceptedID, another request for
+// one of its slots, was accepted, and hands the requester's reserved slot, if
+// any, back to the marketplace. The requester is notified. Call it inside a
+// transaction.
+func (c swapCanceller) supersede(ctx context.Context, req db.SwapRequest, acceptedID, actorUserID int64) error {
+	if err := SwapStateMachine.Transition(SwapStatus(req.Status), SwapSuperseded, ActorSystem, swapSubject{Request: req}); err != nil {
+		return err
+	}
+	if req.RequesterSlotID != nil {
+		if err := c.release(ctx, *req.RequesterSlotID, actorUserID); err != nil {
+			return err
+		}
+	}
+
+	superseded, err := c.swapRepo.ResolveSwapRequest(ctx, db.ResolveSwapRequestParams{
+		ID:               req.ID,
+		Status:           string(SwapSuperseded),
+		ResolvedByUserID: &actorUserID,
+	})
+	if err != nil {
+		return err
+	}
+	err = recordAudit(ctx, c.auditRepo, auditRecord{
+		ActorUserID: actorUserID,
+		Action:      AuditActionSwapRequestSupersede,
+		EntityType:  AuditEntitySwapRequest,
+		EntityID:    req.ID,
+		Before:      req,
+		After:       superseded,
+		Subjects:    []int64{req.RequesterUserID, req.ResponderUserID},
+	})
+	if err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Info("pending swap request superseded", "swap_request_id", req.ID, "accepted_swap_request_id", acceptedID)
+	return notifySwapRequestSuperseded(ctx, c.notificationRepo, req, acceptedID, actorUserID)
+}
+
+// release puts slotID back on the marketplace. The slot may have been taken
+// off the marketplace, or deleted, in the meantime; it is then left alone.
+func (c swapCanceller) release(ctx context.Context, slotID, actorUserID int64) error {
+	slot, err := c.eventRepo.GetEventByID(ctx, slotID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if EventStatus(slot.Status) != EventSwapPending {
+		return nil
+	}
+	_, err = setEventStatus(ctx, c.eventRepo, c.auditRepo, slot, EventSwappable, ActorSystem, actorUserID)
+	return err
+}
@@ -2,209 +2,674 @@ package services
 
 import (
 	"context"
-	"errors"
+	"database/sql"
+	"math"
+	"time"
 
 	"slotswapper/internal/db"
+	"slotswapper/internal/logging"
+	"slotswapper/internal/metrics"
 	"slotswapper/internal/repository"
-	"slotswapper/internal/validation"
 )
 
 type CreateSwapRequestInput struct {
-	RequesterUserID int64 `json:"requester_user_id" validate:"required"`
+	RequesterUserID int64 `json:"-" validate:"required"` // Set from the authenticated user
 	ResponderUserID int64 `json:"responder_user_id" validate:"required"`
 	RequesterSlotID int64 `json:"requester_slot_id" validate:"required"`
 	ResponderSlotID int64 `json:"responder_slot_id" validate:"required"`
 }
 
 type UpdateSwapRequestStatusInput struct {
-	ID     int64  `json:"id" validate:"required"`
+	ID     int64  `json:"-" validate:"required"`
 	Status string `json:"status" validate:"required,oneof=PENDING ACCEPTED REJECTED"`
-	UserID int64  `json:"user_id" validate:"required"` // User performing the update
+	UserID int64  `json:"-" validate:"required"` // User performing the update
+	// AllowOverlap accepts the swap even if the responder ends up owning
+	// overlapping events. A conflict on the requester's side still fails.
+	AllowOverlap bool `json:"allow_overlap"`
 }
 
+// ClaimSlotInput asks for a slot that its owner gives away.
+type ClaimSlotInput struct {
+	EventID int64 `json:"-" validate:"required"`
+	UserID  int64 `json:"-" validate:"required"` // Set from the authenticated user
+	// AllowOverlap claims a first-come slot even if it overlaps the
+	// claimant's events. Owner-picks claims are checked when accepted.
+	AllowOverlap bool `json:"-"`
+}
+
+// SwapRequestHistoryFilter narrows a swap request history listing. Zero values
+// mean "no filter"; From and To bound the creation time inclusively.
+type SwapRequestHistoryFilter struct {
+	UserID         int64     `json:"-" validate:"required"`
+	Status         string    `json:"status" validate:"omitempty,oneof=PENDING ACCEPTED REJECTED WITHDRAWN SUPERSEDED CANCELLED"`
+	CounterpartyID int64     `json:"counterparty_id" validate:"min=0"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	Sort           string    `json:"sort" validate:"omitempty,oneof=created_at -created_at resolved_at -resolved_at"`
+	PageRequest
+}
+
+// SwapRequestListFilter orders and pages the pending swap request listings.
+// Requests are ordered by creation, with the id breaking ties.
+type SwapRequestListFilter struct {
+	Sort string `json:"sort" validate:"omitempty,oneof=created_at -created_at"`
+	PageRequest
+}
+
+const (
+	defaultSwapHistorySort  = "-created_at"
+	defaultSwapHistoryLimit = 20
+	defaultSwapListSort     = "-created_at"
+)
+
 type SwapRequestService interface {
 	CreateSwapRequest(ctx context.Context, input CreateSwapRequestInput) (*db.SwapRequest, error)
 	GetSwapRequestByID(ctx context.Context, id int64) (*db.SwapRequest, error)
-	GetIncomingSwapRequests(ctx context.Context, responderUserID int64) ([]db.GetIncomingSwapRequestsRow, error)
-	GetOutgoingSwapRequests(ctx context.Context, requesterUserID int64) ([]db.GetOutgoingSwapRequestsRow, error)
 	UpdateSwapRequestStatus(ctx context.Context, input UpdateSwapRequestStatusInput) (*db.SwapRequest, error)
+	// WithdrawSwapRequest lets the requester take back a pending request. The
+	// requester's slot goes back on the marketplace and the responder is
+	// notified.
+	WithdrawSwapRequest(ctx context.Context, id, userID int64) (*db.SwapRequest, error)
+	// ClaimSlot asks for a slot that is being given away with a TRANSFER
+	// request. A first-come slot changes hands at once and the request comes
+	// back ACCEPTED; otherwise it stays PENDING until the owner picks one.
+	ClaimSlot(ctx context.Context, input ClaimSlotInput) (*db.SwapRequest, error)
+	ListIncomingSwapRequests(ctx context.Context, responderUserID int64, filter SwapRequestListFilter) (*Page[db.ListIncomingSwapRequestsRow], error)
+	ListOutgoingSwapRequests(ctx context.Context, requesterUserID int64, filter SwapRequestListFilter) (*Page[db.ListOutgoingSwapRequestsRow], error)
+	GetIncomingSwapRequestHistory(ctx context.Context, filter SwapRequestHistoryFilter) (*Page[db.GetIncomingSwapRequestHistoryRow], error)
+	GetOutgoingSwapRequestHistory(ctx context.Context, filter SwapRequestHistoryFilter) (*Page[db.GetOutgoingSwapRequestHistoryRow], error)
 }
 
 type swapRequestService struct {
-	swapRepo  repository.SwapRequestRepository
-	eventRepo repository.EventRepository
-	userRepo  repository.UserRepository
+	swapRepo         repository.SwapRequestRepository
+	eventRepo        repository.EventRepository
+	userRepo         repository.UserRepository
+	auditRepo        repository.AuditLogRepository
+	notificationRepo repository.NotificationRepository
+	transactor       repository.Transactor
 }
 
-func NewSwapRequestService(swapRepo repository.SwapRequestRepository, eventRepo repository.EventRepository, userRepo repository.UserRepository) SwapRequestService {
-	return &swapRequestService{swapRepo: swapRepo, eventRepo: eventRepo, userRepo: userRepo}
+func NewSwapRequestService(swapRepo repository.SwapRequestRepository, eventRepo repository.EventRepository, userRepo repository.UserRepository, auditRepo repository.AuditLogRepository, notificationRepo repository.NotificationRepository, transactor repository.Transactor) SwapRequestService {
+	return &tracedSwapRequestService{next: &swapRequestService{swapRepo: swapRepo, eventRepo: eventRepo, userRepo: userRepo, auditRepo: auditRepo, notificationRepo: notificationRepo, transactor: transactor}}
 }
 
 func (s *swapRequestService) CreateSwapRequest(ctx context.Context, input CreateSwapRequestInput) (*db.SwapRequest, error) {
-	if err := validation.Validate.Struct(input); err != nil {
+	if err := validate(input); err != nil {
 		return nil, err
 	}
 
 	if input.RequesterUserID == input.ResponderUserID {
-		return nil, errors.New("cannot swap with yourself")
+		return nil, newError(ErrValidation, "cannot swap with yourself")
 	}
 
-	requesterEvent, err := s.eventRepo.GetEventByID(ctx, input.RequesterSlotID)
+	var swapRequest db.SwapRequest
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		// Read the slots inside the transaction, so that two offers of the
+		// same slot cannot both reserve it.
+		requesterEvent, err := s.eventRepo.GetEventByID(ctx, input.RequesterSlotID)
+		if err != nil {
+			return notFound(err, "requester slot not found")
+		}
+		if requesterEvent.UserID != input.RequesterUserID {
+			return newError(ErrForbidden, "requester does not own the requester slot")
+		}
+		responderEvent, err := s.eventRepo.GetEventByID(ctx, input.ResponderSlotID)
+		if err != nil {
+			return notFound(err, "responder slot not found")
+		}
+		if responderEvent.UserID != input.ResponderUserID {
+			return newError(ErrValidation, "responder does not own the responder slot")
+		}
+
+		arg := db.CreateSwapRequestParams{
+			RequesterUserID: input.RequesterUserID,
+			ResponderUserID: input.ResponderUserID,
+			RequesterSlotID: &input.RequesterSlotID,
+			ResponderSlotID: input.ResponderSlotID,
+			Status:          string(SwapPending),
+			Kind:            string(SwapKindSwap),
+		}
+		subject := swapSubject{
+			Request:       db.SwapRequest{RequesterUserID: arg.RequesterUserID, ResponderUserID: arg.ResponderUserID, RequesterSlotID: arg.RequesterSlotID, ResponderSlotID: arg.ResponderSlotID, Kind: arg.Kind},
+			RequesterSlot: requesterEvent,
+			ResponderSlot: responderEvent,
+		}
+		if err := SwapStateMachine.Transition("", SwapPending, ActorRequester, subject); err != nil {
+			return err
+		}
+
+		// Only the offered slot is reserved. The responder's slot stays on
+		// the marketplace so that others can make competing offers.
+		if _, err := setEventStatus(ctx, s.eventRepo, s.auditRepo, requesterEvent, EventSwapPending, ActorSystem, input.RequesterUserID); err != nil {
+			return err
+		}
+
+		swapRequest, err = s.swapRepo.CreateSwapRequest(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo, auditRecord{
+			ActorUserID: input.RequesterUserID,
+			Action:      AuditActionSwapRequestCreate,
+			EntityType:  AuditEntitySwapRequest,
+			EntityID:    swapRequest.ID,
+			After:       swapRequest,
+			Subjects:    []int64{swapRequest.RequesterUserID, swapRequest.ResponderUserID},
+		})
+	})
 	if err != nil {
-		return nil, errors.New("requester slot not found")
+		return nil, err
 	}
-	if requesterEvent.Status != "SWAPPABLE" {
-		return nil, errors.New("requester slot is not swappable")
+
+	logging.FromContext(ctx).Info("swap request created",
+		"swap_request_id", swapRequest.ID,
+		"requester_slot_id", swapRequest.RequesterSlotID,
+		"responder_slot_id", swapRequest.ResponderSlotID,
+		"responder_user_id", swapRequest.ResponderUserID)
+	return &swapRequest, nil
+}
+
+func (s *swapRequestService) GetSwapRequestByID(ctx context.Context, id int64) (*db.SwapRequest, error) {
+	swapRequest, err := s.swapRepo.GetSwapRequestByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "swap request not found")
 	}
-	if requesterEvent.UserID != input.RequesterUserID {
-		return nil, errors.New("requester does not own the requester slot")
+	return &swapRequest, nil
+}
+
+// ListIncomingSwapRequests returns one page of the pending requests addressed
+// to responderUserID.
+func (s *swapRequestService) ListIncomingSwapRequests(ctx context.Context, responderUserID int64, filter SwapRequestListFilter) (*Page[db.ListIncomingSwapRequestsRow], error) {
+	filter, after, err := prepareSwapRequestListFilter(filter)
+	if err != nil {
+		return nil, err
 	}
 
-	responderEvent, err := s.eventRepo.GetEventByID(ctx, input.ResponderSlotID)
+	limit := pageLimit(filter.Limit)
+	arg := db.ListIncomingSwapRequestsParams{UserID: responderUserID, AfterID: after.ID, Limit: limit + 1}
+
+	var requests []db.ListIncomingSwapRequestsRow
+	if filter.Sort == "-created_at" {
+		rows, err := s.swapRepo.ListIncomingSwapRequestsDesc(ctx, db.ListIncomingSwapRequestsDescParams(arg))
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			requests = append(requests, db.ListIncomingSwapRequestsRow(row))
+		}
+	} else {
+		requests, err = s.swapRepo.ListIncomingSwapRequests(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newPage(requests, limit, func(request db.ListIncomingSwapRequestsRow) pageCursor {
+		return pageCursor{Sort: filter.Sort, ID: request.ID}
+	}), nil
+}
+
+// ListOutgoingSwapRequests returns one page of the pending requests sent by
+// requesterUserID.
+func (s *swapRequestService) ListOutgoingSwapRequests(ctx context.Context, requesterUserID int64, filter SwapRequestListFilter) (*Page[db.ListOutgoingSwapRequestsRow], error) {
+	filter, after, err := prepareSwapRequestListFilter(filter)
 	if err != nil {
-		return nil, errors.New("responder slot not found")
+		return nil, err
+	}
+
+	limit := pageLimit(filter.Limit)
+	arg := db.ListOutgoingSwapRequestsParams{UserID: requesterUserID, AfterID: after.ID, Limit: limit + 1}
+
+	var requests []db.ListOutgoingSwapRequestsRow
+	if filter.Sort == "-created_at" {
+		rows, err := s.swapRepo.ListOutgoingSwapRequestsDesc(ctx, db.ListOutgoingSwapRequestsDescParams(arg))
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			requests = append(requests, db.ListOutgoingSwapRequestsRow(row))
+		}
+	} else {
+		requests, err = s.swapRepo.ListOutgoingSwapRequests(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newPage(requests, limit, func(request db.ListOutgoingSwapRequestsRow) pageCursor {
+		return pageCursor{Sort: filter.Sort, ID: request.ID}
+	}), nil
+}
+
+// prepareSwapRequestListFilter validates filter, applies the default sort and
+// returns the id to continue after. Ids grow with creation time, so they
+// double as the sort key.
+func prepareSwapRequestListFilter(filter SwapRequestListFilter) (SwapRequestListFilter, pageCursor, error) {
+	if err := validate(filter); err != nil {
+		return filter, pageCursor{}, err
+	}
+	if filter.Sort == "" {
+		filter.Sort = defaultSwapListSort
 	}
-	if responderEvent.Status != "SWAPPABLE" {
-		return nil, errors.New("responder slot is not swappable")
+
+	after, ok, err := decodeCursor(filter.Cursor, filter.Sort)
+	if err != nil {
+		return filter, pageCursor{}, err
 	}
-	if responderEvent.UserID != input.ResponderUserID {
-		return nil, errors.New("responder does not own the responder slot")
+	if !ok && filter.Sort == "-created_at" {
+		after.ID = math.MaxInt64
 	}
+	return filter, after, nil
+}
 
-	_, err = s.eventRepo.UpdateEventStatus(ctx, db.UpdateEventStatusParams{
-		ID:     requesterEvent.ID,
-		Status: "SWAP_PENDING",
-	})
+func (s *swapRequestService) GetIncomingSwapRequestHistory(ctx context.Context, filter SwapRequestHistoryFilter) (*Page[db.GetIncomingSwapRequestHistoryRow], error) {
+	filter, after, err := prepareHistoryFilter(filter)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = s.eventRepo.UpdateEventStatus(ctx, db.UpdateEventStatusParams{
-		ID:     responderEvent.ID,
-		Status: "SWAP_PENDING",
+	rows, err := s.swapRepo.GetIncomingSwapRequestHistory(ctx, db.GetIncomingSwapRequestHistoryParams{
+		Sort:           filter.Sort,
+		UserID:         filter.UserID,
+		Status:         sql.NullString{String: filter.Status, Valid: filter.Status != ""},
+		CounterpartyID: sql.NullInt64{Int64: filter.CounterpartyID, Valid: filter.CounterpartyID != 0},
+		CreatedFrom:    nullTime(filter.From),
+		CreatedTo:      nullTime(filter.To),
+		AfterSortKey:   after.SortKey,
+		AfterID:        after.ID,
+		Limit:          filter.Limit + 1,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	arg := db.CreateSwapRequestParams{
-		RequesterUserID: input.RequesterUserID,
-		ResponderUserID: input.ResponderUserID,
-		RequesterSlotID: input.RequesterSlotID,
-		ResponderSlotID: input.ResponderSlotID,
-		Status:          "PENDING",
-	}
+	return newPage(rows, filter.Limit, func(row db.GetIncomingSwapRequestHistoryRow) pageCursor {
+		return pageCursor{Sort: filter.Sort, SortKey: row.SortKey, ID: row.ID}
+	}), nil
+}
 
-	swapRequest, err := s.swapRepo.CreateSwapRequest(ctx, arg)
+func (s *swapRequestService) GetOutgoingSwapRequestHistory(ctx context.Context, filter SwapRequestHistoryFilter) (*Page[db.GetOutgoingSwapRequestHistoryRow], error) {
+	filter, after, err := prepareHistoryFilter(filter)
 	if err != nil {
 		return nil, err
 	}
 
-	return &swapRequest, nil
-}
-
-func (s *swapRequestService) GetSwapRequestByID(ctx context.Context, id int64) (*db.SwapRequest, error) {
-	swapRequest, err := s.swapRepo.GetSwapRequestByID(ctx, id)
+	rows, err := s.swapRepo.GetOutgoingSwapRequestHistory(ctx, db.GetOutgoingSwapRequestHistoryParams{
+		Sort:           filter.Sort,
+		UserID:         filter.UserID,
+		Status:         sql.NullString{String: filter.Status, Valid: filter.Status != ""},
+		CounterpartyID: sql.NullInt64{Int64: filter.CounterpartyID, Valid: filter.CounterpartyID != 0},
+		CreatedFrom:    nullTime(filter.From),
+		CreatedTo:      nullTime(filter.To),
+		AfterSortKey:   after.SortKey,
+		AfterID:        after.ID,
+		Limit:          filter.Limit + 1,
+	})
 	if err != nil {
 		return nil, err
 	}
-	return &swapRequest, nil
-}
 
-func (s *swapRequestService) GetIncomingSwapRequests(ctx context.Context, responderUserID int64) ([]db.GetIncomingSwapRequestsRow, error) {
-	return s.swapRepo.GetIncomingSwapRequests(ctx, responderUserID)
+	return newPage(rows, filter.Limit, func(row db.GetOutgoingSwapRequestHistoryRow) pageCursor {
+		return pageCursor{Sort: filter.Sort, SortKey: row.SortKey, ID: row.ID}
+	}), nil
 }
 
-func (s *swapRequestService) GetOutgoingSwapRequests(ctx context.Context, requesterUserID int64) ([]db.GetOutgoingSwapRequestsRow, error) {
-	return s.swapRepo.GetOutgoingSwapRequests(ctx, requesterUserID)
+// prepareHistoryFilter validates filter, fills in the defaults and returns the
+// keyset position to continue from. Without a cursor that position sorts
+// before every row.
+func prepareHistoryFilter(filter SwapRequestHistoryFilter) (SwapRequestHistoryFilter, pageCursor, error) {
+	if err := validate(filter); err != nil {
+		return filter, pageCursor{}, err
+	}
+	if filter.Sort == "" {
+		filter.Sort = defaultSwapHistorySort
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultSwapHistoryLimit
+	}
+
+	after, ok, err := decodeCursor(filter.Cursor, filter.Sort)
+	if err != nil {
+		return filter, pageCursor{}, err
+	}
+	if !ok {
+		after = pageCursor{SortKey: -math.MaxFloat64}
+	}
+	return filter, after, nil
 }
 
 func (s *swapRequestService) UpdateSwapRequestStatus(ctx context.Context, input UpdateSwapRequestStatusInput) (*db.SwapRequest, error) {
-	if err := validation.Validate.Struct(input); err != nil {
+	if err := validate(input); err != nil {
 		return nil, err
 	}
 
-	swapRequest, err := s.swapRepo.GetSwapRequestByID(ctx, input.ID)
+	var updatedSwapRequest db.SwapRequest
+	var superseded int
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		// Read the request inside the transaction, so that the transition
+		// is checked against its current status.
+		swapRequest, err := s.swapRepo.GetSwapRequestByID(ctx, input.ID)
+		if err != nil {
+			return notFound(err, "swap request not found")
+		}
+		actor, ok := swapActor(swapRequest, input.UserID)
+		if !ok {
+			return newError(ErrForbidden, "user is not authorized to update this swap request")
+		}
+
+		// A transfer has no requester slot; requesterEvent stays empty.
+		var requesterEvent db.Event
+		if swapRequest.RequesterSlotID != nil {
+			requesterEvent, err = s.eventRepo.GetEventByID(ctx, *swapRequest.RequesterSlotID)
+			if err != nil {
+				return err
+			}
+		}
+		responderEvent, err := s.eventRepo.GetEventByID(ctx, swapRequest.ResponderSlotID)
+		if err != nil {
+			return err
+		}
+		isSwap := swapRequest.RequesterSlotID != nil
+
+		subject := swapSubject{Request: swapRequest, RequesterSlot: requesterEvent, ResponderSlot: responderEvent}
+		if err := SwapStateMachine.Transition(SwapStatus(swapRequest.Status), SwapStatus(input.Status), actor, subject); err != nil {
+			return err
+		}
+
+		switch SwapStatus(input.Status) {
+		case SwapRejected:
+			if isSwap {
+				if _, err := setEventStatus(ctx, s.eventRepo, s.auditRepo, requesterEvent, EventSwappable, ActorSystem, input.UserID); err != nil {
+					return err
+				}
+			}
+		case SwapAccepted:
+			// The responder may waive their own check, but not the
+			// requester's: they asked for the slot without that waiver.
+			var own error
+			if isSwap && !input.AllowOverlap {
+				own = checkConflicts(ctx, s.eventRepo, slotClaim{UserID: responderEvent.UserID, StartTime: requesterEvent.StartTime, EndTime: requesterEvent.EndTime, ExcludeID: responderEvent.ID})
+			}
+			requesterClaim := slotClaim{UserID: swapRequest.RequesterUserID, StartTime: responderEvent.StartTime, EndTime: responderEvent.EndTime, ExcludeID: requesterEvent.ID}
+			if err := checkCounterpartyConflicts(ctx, s.eventRepo, own, ActorRequester, requesterClaim); err != nil {
+				return err
+			}
+			if isSwap {
+				if err := s.transferEvent(ctx, requesterEvent, responderEvent.UserID, input.UserID); err != nil {
+					return err
+				}
+			}
+			if err := s.transferEvent(ctx, responderEvent, swapRequest.RequesterUserID, input.UserID); err != nil {
+				return err
+			}
+			superseded, err = s.supersedeCompetingOffers(ctx, swapRequest, input.UserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		updatedSwapRequest, err = s.swapRepo.ResolveSwapRequest(ctx, db.ResolveSwapRequestParams{
+			ID:               input.ID,
+			Status:           input.Status,
+			ResolvedByUserID: &input.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo, auditRecord{
+			ActorUserID: input.UserID,
+			Action:      AuditActionSwapRequestResolve,
+			EntityType:  AuditEntitySwapRequest,
+			EntityID:    swapRequest.ID,
+			Before:      swapRequest,
+			After:       updatedSwapRequest,
+			Subjects:    []int64{swapRequest.RequesterUserID, swapRequest.ResponderUserID},
+		})
+	})
 	if err != nil {
-		return nil, errors.New("swap request not found")
+		return nil, err
 	}
 
-	if swapRequest.Status != "PENDING" {
-		return nil, errors.New("swap request is not in PENDING status")
+	switch SwapStatus(updatedSwapRequest.Status) {
+	case SwapAccepted:
+		metrics.SwapRequestsResolved.WithLabelValues(metrics.OutcomeAccepted).Inc()
+		metrics.SwapRequestsResolved.WithLabelValues(metrics.OutcomeSuperseded).Add(float64(superseded))
+	case SwapRejected:
+		metrics.SwapRequestsResolved.WithLabelValues(metrics.OutcomeRejected).Inc()
 	}
+	logging.FromContext(ctx).Info("swap request resolved", "swap_request_id", updatedSwapRequest.ID, "status", updatedSwapRequest.Status)
+	return &updatedSwapRequest, nil
+}
 
-	if input.Status == "REJECTED" && swapRequest.RequesterUserID == input.UserID {
-		// Requester is cancelling
-	} else if swapRequest.ResponderUserID != input.UserID {
-		return nil, errors.New("user is not authorized to update this swap request")
-	}
+func (s *swapRequestService) WithdrawSwapRequest(ctx context.Context, id, userID int64) (*db.SwapRequest, error) {
+	var withdrawn db.SwapRequest
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		swapRequest, err := s.swapRepo.GetSwapRequestByID(ctx, id)
+		if err != nil {
+			return notFound(err, "swap request not found")
+		}
+		actor, ok := swapActor(swapRequest, userID)
+		if !ok {
+			return newError(ErrForbidden, "user is not authorized to update this swap request")
+		}
+		if err := SwapStateMachine.Transition(SwapStatus(swapRequest.Status), SwapWithdrawn, actor, swapSubject{Request: swapRequest}); err != nil {
+			return err
+		}
+
+		if swapRequest.RequesterSlotID != nil {
+			requesterEvent, err := s.eventRepo.GetEventByID(ctx, *swapRequest.RequesterSlotID)
+			if err != nil {
+				return err
+			}
+			if _, err := setEventStatus(ctx, s.eventRepo, s.auditRepo, requesterEvent, EventSwappable, ActorSystem, userID); err != nil {
+				return err
+			}
+		}
 
-	switch input.Status {
-	case "REJECTED":
-		_, err = s.eventRepo.UpdateEventStatus(ctx, db.UpdateEventStatusParams{
-			ID:     swapRequest.RequesterSlotID,
-			Status: "SWAPPABLE",
+		withdrawn, err = s.swapRepo.ResolveSwapRequest(ctx, db.ResolveSwapRequestParams{
+			ID:               id,
+			Status:           string(SwapWithdrawn),
+			ResolvedByUserID: &userID,
 		})
 		if err != nil {
-			return nil, err
+			return err
 		}
-		_, err = s.eventRepo.UpdateEventStatus(ctx, db.UpdateEventStatusParams{
-			ID:     swapRequest.ResponderSlotID,
-			Status: "SWAPPABLE",
+		err = recordAudit(ctx, s.auditRepo, auditRecord{
+			ActorUserID: userID,
+			Action:      AuditActionSwapRequestWithdraw,
+			EntityType:  AuditEntitySwapRequest,
+			EntityID:    swapRequest.ID,
+			Before:      swapRequest,
+			After:       withdrawn,
+			Subjects:    []int64{swapRequest.RequesterUserID, swapRequest.ResponderUserID},
 		})
 		if err != nil {
-			return nil, err
+			return err
 		}
-	case "ACCEPTED":
+		return notifySwapRequestWithdrawn(ctx, s.notificationRepo, swapRequest)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.SwapRequestsResolved.WithLabelValues(metrics.OutcomeWithdrawn).Inc()
+	logging.FromContext(ctx).Info("swap request withdrawn", "swap_request_id", withdrawn.ID)
+	return &withdrawn, nil
+}
+
+func (s *swapRequestService) ClaimSlot(ctx context.Context, input ClaimSlotInput) (*db.SwapRequest, error) {
+	if err := validate(input); err != nil {
+		return nil, err
+	}
 
-		requesterEvent, err := s.eventRepo.GetEventByID(ctx, swapRequest.RequesterSlotID)
+	var claim db.SwapRequest
+	var superseded int
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		slot, err := s.eventRepo.GetEventByID(ctx, input.EventID)
 		if err != nil {
-			return nil, err
+			return notFound(err, "slot not found")
 		}
-		responderEvent, err := s.eventRepo.GetEventByID(ctx, swapRequest.ResponderSlotID)
+		if slot.UserID == input.UserID {
+			return newError(ErrValidation, "cannot claim your own slot")
+		}
+		if EventStatus(slot.Status) != EventSwappable || slot.Giveaway == nil {
+			return newError(ErrInvalidState, "slot is not being given away")
+		}
+
+		requests, err := s.swapRepo.GetSwapRequestsByEventID(ctx, slot.ID)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		for _, req := range requests {
+			if req.RequesterUserID == input.UserID && SwapKind(req.Kind) == SwapKindTransfer && SwapStatus(req.Status) == SwapPending {
+				return newError(ErrInvalidState, "you have already claimed this slot")
+			}
 		}
 
-		_, err = s.eventRepo.UpdateEventUserID(ctx, db.UpdateEventUserIDParams{
-			ID:     requesterEvent.ID,
-			UserID: responderEvent.UserID,
-		})
+		firstCome := Giveaway(*slot.Giveaway) == GiveawayFirstCome
+		arg := db.CreateSwapRequestParams{
+			RequesterUserID: input.UserID,
+			ResponderUserID: slot.UserID,
+			ResponderSlotID: slot.ID,
+			Status:          string(SwapPending),
+			Kind:            string(SwapKindTransfer),
+		}
+		to := SwapPending
+		if firstCome {
+			to = SwapAccepted
+		}
+		subject := swapSubject{Request: db.SwapRequest{RequesterUserID: arg.RequesterUserID, ResponderUserID: arg.ResponderUserID, Kind: arg.Kind}, ResponderSlot: slot}
+		if err := SwapStateMachine.Transition("", to, ActorRequester, subject); err != nil {
+			return err
+		}
+		if firstCome && !input.AllowOverlap {
+			if err := checkConflicts(ctx, s.eventRepo, slotClaim{UserID: input.UserID, StartTime: slot.StartTime, EndTime: slot.EndTime}); err != nil {
+				return err
+			}
+		}
+
+		claim, err = s.swapRepo.CreateSwapRequest(ctx, arg)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		_, err = s.eventRepo.UpdateEventUserID(ctx, db.UpdateEventUserIDParams{
-			ID:     responderEvent.ID,
-			UserID: requesterEvent.UserID,
+		err = recordAudit(ctx, s.auditRepo, auditRecord{
+			ActorUserID: input.UserID,
+			Action:      AuditActionSwapRequestCreate,
+			EntityType:  AuditEntitySwapRequest,
+			EntityID:    claim.ID,
+			After:       claim,
+			Subjects:    []int64{claim.RequesterUserID, claim.ResponderUserID},
 		})
-		if err != nil {
-			return nil, err
+		if err != nil || !firstCome {
+			return err
 		}
 
-		_, err = s.eventRepo.UpdateEventStatus(ctx, db.UpdateEventStatusParams{
-			ID:     requesterEvent.ID,
-			Status: "BUSY",
+		// The first claimant takes the slot; the request is recorded as
+		// accepted by them, since the owner agreed in advance.
+		if err := s.transferEvent(ctx, slot, input.UserID, input.UserID); err != nil {
+			return err
+		}
+		created := claim
+		claim, err = s.swapRepo.ResolveSwapRequest(ctx, db.ResolveSwapRequestParams{
+			ID:               claim.ID,
+			Status:           string(SwapAccepted),
+			ResolvedByUserID: &input.UserID,
 		})
 		if err != nil {
-			return nil, err
+			return err
 		}
-		_, err = s.eventRepo.UpdateEventStatus(ctx, db.UpdateEventStatusParams{
-			ID:     responderEvent.ID,
-			Status: "BUSY",
+		err = recordAudit(ctx, s.auditRepo, auditRecord{
+			ActorUserID: input.UserID,
+			Action:      AuditActionSwapRequestResolve,
+			EntityType:  AuditEntitySwapRequest,
+			EntityID:    claim.ID,
+			Before:      created,
+			After:       claim,
+			Subjects:    []int64{claim.RequesterUserID, claim.ResponderUserID},
 		})
 		if err != nil {
-			return nil, err
+			return err
+		}
+		superseded, err = s.supersedeCompetingOffers(ctx, claim, input.UserID)
+		if err != nil {
+			return err
 		}
+		return notifySlotClaimed(ctx, s.notificationRepo, claim)
+	})
+	if err != nil {
+		return nil, err
 	}
-	arg := db.UpdateSwapRequestStatusParams{
-		ID:     input.ID,
-		Status: input.Status,
+
+	if SwapStatus(claim.Status) == SwapAccepted {
+		metrics.SwapRequestsResolved.WithLabelValues(metrics.OutcomeAccepted).Inc()
+		metrics.SwapRequestsResolved.WithLabelValues(metrics.OutcomeSuperseded).Add(float64(superseded))
 	}
+	logging.FromContext(ctx).Info("slot claimed",
+		"swap_request_id", claim.ID,
+		"event_id", claim.ResponderSlotID,
+		"status", claim.Status)
+	return &claim, nil
+}
 
-	updatedSwapRequest, err := s.swapRepo.UpdateSwapRequestStatus(ctx, arg)
+// supersedeCompetingOffers closes the other pending requests for the two
+// slots of accepted, which have just changed hands, and releases the slots
+// reserved for them. It returns how many it closed.
+func (s *swapRequestService) supersedeCompetingOffers(ctx context.Context, accepted db.SwapRequest, actorUserID int64) (int, error) {
+	canceller := swapCanceller{eventRepo: s.eventRepo, swapRepo: s.swapRepo, auditRepo: s.auditRepo, notificationRepo: s.notificationRepo}
+	var superseded int
+	slotIDs := []int64{accepted.ResponderSlotID}
+	if accepted.RequesterSlotID != nil {
+		slotIDs = append(slotIDs, *accepted.RequesterSlotID)
+	}
+	for _, slotID := range slotIDs {
+		requests, err := s.swapRepo.GetSwapRequestsByEventID(ctx, slotID)
+		if err != nil {
+			return superseded, err
+		}
+		for _, req := range requests {
+			if req.ID == accepted.ID || SwapStatus(req.Status) != SwapPending {
+				continue
+			}
+			if err := canceller.supersede(ctx, req, accepted.ID, actorUserID); err != nil {
+				return superseded, err
+			}
+			superseded++
+		}
+	}
+	return superseded, nil
+}
+
+// transferEvent hands a swapped slot to its new owner and marks it BUSY. Both
+// the previous and the new owner can see the entry in their history.
+func (s *swapRequestService) transferEvent(ctx context.Context, event db.Event, newOwnerID, actorUserID int64) error {
+	if err := EventStateMachine.Transition(EventStatus(event.Status), EventBusy, ActorSystem, event); err != nil {
+		return err
+	}
+	_, err := s.eventRepo.UpdateEventUserID(ctx, db.UpdateEventUserIDParams{
+		ID:     event.ID,
+		UserID: newOwnerID,
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
+	updatedEvent, err := s.eventRepo.UpdateEventStatus(ctx, db.UpdateEventStatusParams{
+		ID:     event.ID,
+		Status: string(EventBusy),
+	})
+	if err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Debug("slot transferred", "event_id", event.ID, "from_user_id", event.UserID, "to_user_id", newOwnerID)
 
-	return &updatedSwapRequest, nil
+	return recordAudit(ctx, s.auditRepo, auditRecord{
+		ActorUserID: actorUserID,
+		Action:      AuditActionEventTransfer,
+		EntityType:  AuditEntityEvent,
+		EntityID:    event.ID,
+		Before:      event,
+		After:       updatedEvent,
+		Subjects:    []int64{event.UserID, newOwnerID},
+	})
 }
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"errors"
+	"math"
 	"testing"
 	"time"
 
@@ -48,7 +50,9 @@ func TestSwapRequestService(t *testing.T) {
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
-		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		input := CreateSwapRequestInput{
 			RequesterUserID: user1.ID,
@@ -85,8 +89,52 @@ func TestSwapRequestService(t *testing.T) {
 		if err != nil {
 			t.Fatalf("failed to get updated event2: %v", err)
 		}
-		if updatedEvent2.Status != "SWAP_PENDING" {
-			t.Errorf("expected event2 status to be SWAP_PENDING, got %q", updatedEvent2.Status)
+		if updatedEvent2.Status != "SWAPPABLE" {
+			t.Errorf("expected event2 to stay SWAPPABLE for other offers, got %q", updatedEvent2.Status)
+		}
+	})
+
+	t.Run("CreateSwapRequest_ConcurrentOffers", func(t *testing.T) {
+		ctx := context.Background()
+		testQueries, user1 := repository.SetupTestDBWithUser(t)
+		user2, err := testQueries.CreateUser(ctx, db.CreateUserParams{Name: "user2", Email: "user2@example.com", Password: "password"})
+		if err != nil {
+			t.Fatalf("failed to create user2: %v", err)
+		}
+		start := time.Now().Add(time.Hour)
+		createEvent := func(title string, userID int64, offset time.Duration) db.Event {
+			event, err := testQueries.CreateEvent(ctx, db.CreateEventParams{Title: title, StartTime: start.Add(offset), EndTime: start.Add(offset + time.Hour), Status: "SWAPPABLE", UserID: userID})
+			if err != nil {
+				t.Fatalf("failed to create %s: %v", title, err)
+			}
+			return event
+		}
+		offered := createEvent("Offered", user1.ID, 0)
+		wanted := createEvent("Wanted", user2.ID, 2*time.Hour)
+		alsoWanted := createEvent("Also Wanted", user2.ID, 4*time.Hour)
+
+		newService := func(transactor repository.Transactor) SwapRequestService {
+			return NewSwapRequestService(repository.NewSwapRequestRepository(testQueries), repository.NewEventRepository(testQueries), repository.NewUserRepository(testQueries), repository.NewAuditLogRepository(testQueries), repository.NewNotificationRepository(testQueries), transactor)
+		}
+		other := newService(repository.NewTransactor(testQueries))
+		// The competing offer of the same slot lands just before this one
+		// starts its transaction.
+		racing := &racingTransactor{Transactor: repository.NewTransactor(testQueries), before: func() {
+			if _, err := other.CreateSwapRequest(ctx, CreateSwapRequestInput{RequesterUserID: user1.ID, ResponderUserID: user2.ID, RequesterSlotID: offered.ID, ResponderSlotID: alsoWanted.ID}); err != nil {
+				t.Fatalf("failed to create the competing offer: %v", err)
+			}
+		}}
+
+		_, err = newService(racing).CreateSwapRequest(ctx, CreateSwapRequestInput{RequesterUserID: user1.ID, ResponderUserID: user2.ID, RequesterSlotID: offered.ID, ResponderSlotID: wanted.ID})
+		if !errors.Is(err, ErrInvalidState) {
+			t.Errorf("expected the second offer of the slot to fail with ErrInvalidState, got %v", err)
+		}
+		pending, err := repository.NewSwapRequestRepository(testQueries).GetSwapRequestsByEventID(ctx, offered.ID)
+		if err != nil {
+			t.Fatalf("failed to list swap requests: %v", err)
+		}
+		if len(pending) != 1 || pending[0].ResponderSlotID != alsoWanted.ID {
+			t.Errorf("expected only the competing offer to reserve the slot, got %+v", pending)
 		}
 	})
 
@@ -126,7 +174,9 @@ func TestSwapRequestService(t *testing.T) {
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
-		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		testCases := []struct {
 			name          string
@@ -140,7 +190,7 @@ func TestSwapRequestService(t *testing.T) {
 					RequesterSlotID: event1.ID,
 					ResponderSlotID: event2.ID,
 				},
-				expectedError: "Key: 'CreateSwapRequestInput.RequesterUserID' Error:Field validation for 'RequesterUserID' failed on the 'required' tag",
+				expectedError: "RequesterUserID is required",
 			},
 			{
 				name: "missing responder user ID",
@@ -149,7 +199,7 @@ func TestSwapRequestService(t *testing.T) {
 					RequesterSlotID: event1.ID,
 					ResponderSlotID: event2.ID,
 				},
-				expectedError: "Key: 'CreateSwapRequestInput.ResponderUserID' Error:Field validation for 'ResponderUserID' failed on the 'required' tag",
+				expectedError: "responder_user_id is required",
 			},
 			{
 				name: "missing requester slot ID",
@@ -158,7 +208,7 @@ func TestSwapRequestService(t *testing.T) {
 					ResponderUserID: user2.ID,
 					ResponderSlotID: event2.ID,
 				},
-				expectedError: "Key: 'CreateSwapRequestInput.RequesterSlotID' Error:Field validation for 'RequesterSlotID' failed on the 'required' tag",
+				expectedError: "requester_slot_id is required",
 			},
 			{
 				name: "missing responder slot ID",
@@ -167,7 +217,7 @@ func TestSwapRequestService(t *testing.T) {
 					ResponderUserID: user2.ID,
 					RequesterSlotID: event1.ID,
 				},
-				expectedError: "Key: 'CreateSwapRequestInput.ResponderSlotID' Error:Field validation for 'ResponderSlotID' failed on the 'required' tag",
+				expectedError: "responder_slot_id is required",
 			},
 			{
 				name: "swap with self",
@@ -230,7 +280,9 @@ func TestSwapRequestService(t *testing.T) {
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
-		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		createInput := CreateSwapRequestInput{
 			RequesterUserID: user1.ID,
@@ -312,7 +364,7 @@ func TestSwapRequestService(t *testing.T) {
 			Title:     "User2 Event Reject",
 			StartTime: time.Now().Add(2 * time.Hour),
 			EndTime:   time.Now().Add(3 * time.Hour),
-			Status:    "SWAP_PENDING", // Already in SWAP_PENDING state
+			Status:    "SWAPPABLE",
 			UserID:    user2.ID,
 		})
 		if err != nil {
@@ -322,9 +374,10 @@ func TestSwapRequestService(t *testing.T) {
 		createdSwapRequest, err := testQueries.CreateSwapRequest(context.Background(), db.CreateSwapRequestParams{
 			RequesterUserID: user1.ID,
 			ResponderUserID: user2.ID,
-			RequesterSlotID: event1.ID,
+			RequesterSlotID: &event1.ID,
 			ResponderSlotID: event2.ID,
 			Status:          "PENDING",
+			Kind:            "SWAP",
 		})
 		if err != nil {
 			t.Fatalf("failed to create swap request for rejection test: %v", err)
@@ -333,7 +386,9 @@ func TestSwapRequestService(t *testing.T) {
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
-		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		updateInput := UpdateSwapRequestStatusInput{
 			ID:     createdSwapRequest.ID,
@@ -371,6 +426,108 @@ func TestSwapRequestService(t *testing.T) {
 		}
 	})
 
+	t.Run("WithdrawSwapRequest", func(t *testing.T) {
+		testQueries, user1 := repository.SetupTestDBWithUser(t)
+		user2, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
+			Name:     "user2_withdraw",
+			Email:    "user2_withdraw@example.com",
+			Password: "password",
+		})
+		if err != nil {
+			t.Fatalf("failed to create user2: %v", err)
+		}
+		outsider, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
+			Name:     "outsider_withdraw",
+			Email:    "outsider_withdraw@example.com",
+			Password: "password",
+		})
+		if err != nil {
+			t.Fatalf("failed to create outsider: %v", err)
+		}
+
+		event1, err := testQueries.CreateEvent(context.Background(), db.CreateEventParams{
+			Title:     "User1 Event Withdraw",
+			StartTime: time.Now(),
+			EndTime:   time.Now().Add(time.Hour),
+			Status:    "SWAPPABLE",
+			UserID:    user1.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create event1: %v", err)
+		}
+		event2, err := testQueries.CreateEvent(context.Background(), db.CreateEventParams{
+			Title:     "User2 Event Withdraw",
+			StartTime: time.Now().Add(2 * time.Hour),
+			EndTime:   time.Now().Add(3 * time.Hour),
+			Status:    "SWAPPABLE",
+			UserID:    user2.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create event2: %v", err)
+		}
+
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		notificationRepo := repository.NewNotificationRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, notificationRepo, transactor)
+
+		swapRequest, err := swapService.CreateSwapRequest(context.Background(), CreateSwapRequestInput{
+			RequesterUserID: user1.ID,
+			ResponderUserID: user2.ID,
+			RequesterSlotID: event1.ID,
+			ResponderSlotID: event2.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create swap request: %v", err)
+		}
+
+		for name, userID := range map[string]int64{"responder": user2.ID, "outsider": outsider.ID} {
+			if _, err := swapService.WithdrawSwapRequest(context.Background(), swapRequest.ID, userID); !errors.Is(err, ErrForbidden) {
+				t.Errorf("expected the %s to be forbidden from withdrawing, got %v", name, err)
+			}
+		}
+		_, err = swapService.UpdateSwapRequestStatus(context.Background(), UpdateSwapRequestStatusInput{ID: swapRequest.ID, Status: "REJECTED", UserID: user1.ID})
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected the requester to be forbidden from rejecting, got %v", err)
+		}
+
+		withdrawn, err := swapService.WithdrawSwapRequest(context.Background(), swapRequest.ID, user1.ID)
+		if err != nil {
+			t.Fatalf("failed to withdraw swap request: %v", err)
+		}
+		if withdrawn.Status != "WITHDRAWN" {
+			t.Errorf("expected status WITHDRAWN, got %q", withdrawn.Status)
+		}
+		if withdrawn.ResolvedByUserID == nil || *withdrawn.ResolvedByUserID != user1.ID {
+			t.Errorf("expected request to be resolved by user %d, got %v", user1.ID, withdrawn.ResolvedByUserID)
+		}
+
+		for _, id := range []int64{event1.ID, event2.ID} {
+			event, err := eventRepo.GetEventByID(context.Background(), id)
+			if err != nil {
+				t.Fatalf("failed to get event %d: %v", id, err)
+			}
+			if event.Status != "SWAPPABLE" {
+				t.Errorf("expected event %d to be SWAPPABLE again, got %q", id, event.Status)
+			}
+		}
+
+		notifications, err := notificationRepo.ListNotificationsByUserID(context.Background(), db.ListNotificationsByUserIDParams{UserID: user2.ID, BeforeID: math.MaxInt64, Limit: 10})
+		if err != nil {
+			t.Fatalf("failed to list notifications: %v", err)
+		}
+		if len(notifications) == 0 || notifications[0].Kind != NotificationSwapRequestWithdrawn {
+			t.Errorf("expected the responder to be notified of the withdrawal, got %+v", notifications)
+		}
+
+		if _, err := swapService.WithdrawSwapRequest(context.Background(), swapRequest.ID, user1.ID); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("expected withdrawing twice to fail with ErrInvalidState, got %v", err)
+		}
+	})
+
 	t.Run("UpdateSwapRequestStatus_NotPending", func(t *testing.T) {
 		testQueries, user1 := repository.SetupTestDBWithUser(t)
 		user2, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
@@ -407,9 +564,10 @@ func TestSwapRequestService(t *testing.T) {
 		createdSwapRequest, err := testQueries.CreateSwapRequest(context.Background(), db.CreateSwapRequestParams{
 			RequesterUserID: user1.ID,
 			ResponderUserID: user2.ID,
-			RequesterSlotID: event1.ID,
+			RequesterSlotID: &event1.ID,
 			ResponderSlotID: event2.ID,
 			Status:          "ACCEPTED",
+			Kind:            "SWAP",
 		})
 		if err != nil {
 			t.Fatalf("failed to create swap request for not pending test: %v", err)
@@ -418,7 +576,9 @@ func TestSwapRequestService(t *testing.T) {
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
-		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		updateInput := UpdateSwapRequestStatusInput{
 			ID:     createdSwapRequest.ID,
@@ -431,12 +591,16 @@ func TestSwapRequestService(t *testing.T) {
 			t.Fatal("expected an error for updating non-pending swap request, got nil")
 		}
 
-		if err.Error() != "swap request is not in PENDING status" {
-			t.Errorf("expected 'swap request is not in PENDING status' error, got %q", err)
+		var transitionErr *TransitionError
+		if !errors.As(err, &transitionErr) || !errors.Is(err, ErrInvalidState) {
+			t.Fatalf("expected an invalid state transition, got %v", err)
+		}
+		if transitionErr.From != "ACCEPTED" || transitionErr.To != "REJECTED" || transitionErr.Actor != ActorResponder {
+			t.Errorf("unexpected transition error %+v", transitionErr)
 		}
 	})
 
-	t.Run("GetIncomingSwapRequests", func(t *testing.T) {
+	t.Run("ListIncomingSwapRequests", func(t *testing.T) {
 		testQueries, user1 := repository.SetupTestDBWithUser(t)
 		user2, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
 			Name:     "user2_incoming",
@@ -472,7 +636,9 @@ func TestSwapRequestService(t *testing.T) {
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
-		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		_, err = swapService.CreateSwapRequest(context.Background(), CreateSwapRequestInput{
 			RequesterUserID: user1.ID,
@@ -484,10 +650,11 @@ func TestSwapRequestService(t *testing.T) {
 			t.Fatalf("failed to create swap request: %v", err)
 		}
 
-		incomingRequests, err := swapService.GetIncomingSwapRequests(context.Background(), user2.ID)
+		page, err := swapService.ListIncomingSwapRequests(context.Background(), user2.ID, SwapRequestListFilter{})
 		if err != nil {
-			t.Fatalf("failed to get incoming swap requests: %v", err)
+			t.Fatalf("failed to list incoming swap requests: %v", err)
 		}
+		incomingRequests := page.Items
 
 		if len(incomingRequests) != 1 {
 			t.Errorf("expected 1 incoming request, got %d", len(incomingRequests))
@@ -501,7 +668,7 @@ func TestSwapRequestService(t *testing.T) {
 		}
 	})
 
-	t.Run("GetOutgoingSwapRequests", func(t *testing.T) {
+	t.Run("ListOutgoingSwapRequests", func(t *testing.T) {
 		testQueries, user1 := repository.SetupTestDBWithUser(t)
 		user2, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
 			Name:     "user2_outgoing",
@@ -537,7 +704,9 @@ func TestSwapRequestService(t *testing.T) {
 		swapRepo := repository.NewSwapRequestRepository(testQueries)
 		eventRepo := repository.NewEventRepository(testQueries)
 		userRepo := repository.NewUserRepository(testQueries)
-		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
 
 		_, err = swapService.CreateSwapRequest(context.Background(), CreateSwapRequestInput{
 			RequesterUserID: user1.ID,
@@ -549,10 +718,11 @@ func TestSwapRequestService(t *testing.T) {
 			t.Fatalf("failed to create swap request: %v", err)
 		}
 
-		outgoingRequests, err := swapService.GetOutgoingSwapRequests(context.Background(), user1.ID)
+		page, err := swapService.ListOutgoingSwapRequests(context.Background(), user1.ID, SwapRequestListFilter{})
 		if err != nil {
-			t.Fatalf("failed to get outgoing swap requests: %v", err)
+			t.Fatalf("failed to list outgoing swap requests: %v", err)
 		}
+		outgoingRequests := page.Items
 
 		if len(outgoingRequests) != 1 {
 			t.Errorf("expected 1 outgoing request, got %d", len(outgoingRequests))
@@ -566,4 +736,496 @@ func TestSwapRequestService(t *testing.T) {
 		}
 	})
 
+	t.Run("GetSwapRequestHistory", func(t *testing.T) {
+		testQueries, user1 := repository.SetupTestDBWithUser(t)
+		user2, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
+			Name:     "user2_history",
+			Email:    "user2_history@example.com",
+			Password: "password",
+		})
+		if err != nil {
+			t.Fatalf("failed to create user2: %v", err)
+		}
+
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+
+		createSwap := func(title string) *db.SwapRequest {
+			event1, err := testQueries.CreateEvent(context.Background(), db.CreateEventParams{
+				Title:     title + " (user1)",
+				StartTime: time.Now(),
+				EndTime:   time.Now().Add(time.Hour),
+				Status:    "SWAPPABLE",
+				UserID:    user1.ID,
+			})
+			if err != nil {
+				t.Fatalf("failed to create event1: %v", err)
+			}
+			event2, err := testQueries.CreateEvent(context.Background(), db.CreateEventParams{
+				Title:     title + " (user2)",
+				StartTime: time.Now().Add(2 * time.Hour),
+				EndTime:   time.Now().Add(3 * time.Hour),
+				Status:    "SWAPPABLE",
+				UserID:    user2.ID,
+			})
+			if err != nil {
+				t.Fatalf("failed to create event2: %v", err)
+			}
+			swapRequest, err := swapService.CreateSwapRequest(context.Background(), CreateSwapRequestInput{
+				RequesterUserID: user1.ID,
+				ResponderUserID: user2.ID,
+				RequesterSlotID: event1.ID,
+				ResponderSlotID: event2.ID,
+			})
+			if err != nil {
+				t.Fatalf("failed to create swap request: %v", err)
+			}
+			return swapRequest
+		}
+
+		accepted := createSwap("Accepted")
+		withdrawn := createSwap("Withdrawn")
+		createSwap("Pending")
+
+		if _, err := swapService.UpdateSwapRequestStatus(context.Background(), UpdateSwapRequestStatusInput{ID: accepted.ID, Status: "ACCEPTED", UserID: user2.ID}); err != nil {
+			t.Fatalf("failed to accept swap request: %v", err)
+		}
+		if _, err := swapService.WithdrawSwapRequest(context.Background(), withdrawn.ID, user1.ID); err != nil {
+			t.Fatalf("failed to withdraw swap request: %v", err)
+		}
+
+		incoming, err := swapService.GetIncomingSwapRequestHistory(context.Background(), SwapRequestHistoryFilter{UserID: user2.ID})
+		if err != nil {
+			t.Fatalf("failed to get incoming history: %v", err)
+		}
+		if len(incoming.Items) != 3 {
+			t.Fatalf("expected 3 incoming requests in history, got %d", len(incoming.Items))
+		}
+
+		acceptedOnly, err := swapService.GetIncomingSwapRequestHistory(context.Background(), SwapRequestHistoryFilter{UserID: user2.ID, Status: "ACCEPTED"})
+		if err != nil {
+			t.Fatalf("failed to get accepted history: %v", err)
+		}
+		if len(acceptedOnly.Items) != 1 || acceptedOnly.Items[0].ID != accepted.ID {
+			t.Fatalf("expected only the accepted request, got %+v", acceptedOnly.Items)
+		}
+		if acceptedOnly.Items[0].ResolvedByUserID == nil || *acceptedOnly.Items[0].ResolvedByUserID != user2.ID {
+			t.Errorf("expected request to be resolved by user %d, got %v", user2.ID, acceptedOnly.Items[0].ResolvedByUserID)
+		}
+		if acceptedOnly.Items[0].ResolvedByName != user2.Name {
+			t.Errorf("expected resolver name %q, got %q", user2.Name, acceptedOnly.Items[0].ResolvedByName)
+		}
+		if acceptedOnly.Items[0].ResolvedAt == nil {
+			t.Error("expected resolved_at to be set")
+		}
+
+		outgoing, err := swapService.GetOutgoingSwapRequestHistory(context.Background(), SwapRequestHistoryFilter{
+			UserID:         user1.ID,
+			Status:         "WITHDRAWN",
+			CounterpartyID: user2.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to get outgoing history: %v", err)
+		}
+		if len(outgoing.Items) != 1 || outgoing.Items[0].ID != withdrawn.ID {
+			t.Fatalf("expected only the withdrawn request, got %+v", outgoing.Items)
+		}
+		if outgoing.Items[0].ResolvedByUserID == nil || *outgoing.Items[0].ResolvedByUserID != user1.ID {
+			t.Errorf("expected request to be resolved by user %d, got %v", user1.ID, outgoing.Items[0].ResolvedByUserID)
+		}
+
+		firstPage, err := swapService.GetOutgoingSwapRequestHistory(context.Background(), SwapRequestHistoryFilter{UserID: user1.ID, Sort: "resolved_at", PageRequest: PageRequest{Limit: 2}})
+		if err != nil {
+			t.Fatalf("failed to get first history page: %v", err)
+		}
+		if len(firstPage.Items) != 2 || firstPage.NextCursor == "" {
+			t.Fatalf("expected 2 requests and a cursor on the first page, got %d and %q", len(firstPage.Items), firstPage.NextCursor)
+		}
+		secondPage, err := swapService.GetOutgoingSwapRequestHistory(context.Background(), SwapRequestHistoryFilter{UserID: user1.ID, Sort: "resolved_at", PageRequest: PageRequest{Limit: 2, Cursor: firstPage.NextCursor}})
+		if err != nil {
+			t.Fatalf("failed to get second history page: %v", err)
+		}
+		if len(secondPage.Items) != 1 || secondPage.NextCursor != "" {
+			t.Fatalf("expected 1 request and no cursor on the second page, got %d and %q", len(secondPage.Items), secondPage.NextCursor)
+		}
+		if secondPage.Items[0].ResolvedAt != nil {
+			t.Errorf("expected the pending request to sort last by resolution time, got %+v", secondPage.Items[0])
+		}
+
+		if _, err := swapService.GetOutgoingSwapRequestHistory(context.Background(), SwapRequestHistoryFilter{UserID: user1.ID, Sort: "created_at", PageRequest: PageRequest{Cursor: firstPage.NextCursor}}); err != ErrInvalidCursor {
+			t.Errorf("expected ErrInvalidCursor for a cursor issued for another sort, got %v", err)
+		}
+
+		future, err := swapService.GetOutgoingSwapRequestHistory(context.Background(), SwapRequestHistoryFilter{UserID: user1.ID, From: time.Now().Add(time.Hour)})
+		if err != nil {
+			t.Fatalf("failed to get history by date: %v", err)
+		}
+		if len(future.Items) != 0 {
+			t.Errorf("expected no requests created in the future, got %d", len(future.Items))
+		}
+
+		if _, err := swapService.GetOutgoingSwapRequestHistory(context.Background(), SwapRequestHistoryFilter{UserID: user1.ID, Sort: "title"}); err == nil {
+			t.Error("expected an error for an unsupported sort field")
+		}
+	})
+
+	t.Run("UpdateSwapRequestStatus_AcceptedConflict", func(t *testing.T) {
+		testQueries, user1 := repository.SetupTestDBWithUser(t)
+		user2, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{Name: "user2", Email: "user2@example.com", Password: "password"})
+		if err != nil {
+			t.Fatalf("failed to create user2: %v", err)
+		}
+
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, repository.NewNotificationRepository(testQueries), transactor)
+
+		nine := time.Date(2030, time.May, 6, 9, 0, 0, 0, time.UTC)
+		createEvent := func(title string, start time.Time, status string, userID int64) db.Event {
+			event, err := testQueries.CreateEvent(context.Background(), db.CreateEventParams{Title: title, StartTime: start, EndTime: start.Add(time.Hour), Status: status, UserID: userID})
+			if err != nil {
+				t.Fatalf("failed to create event: %v", err)
+			}
+			return event
+		}
+		offered := createEvent("User1 Morning", nine, "SWAPPABLE", user1.ID)
+		wanted := createEvent("User2 Afternoon", nine.Add(5*time.Hour), "SWAPPABLE", user2.ID)
+		clash := createEvent("User2 Meeting", nine.Add(30*time.Minute), "BUSY", user2.ID)
+
+		swapRequest, err := swapService.CreateSwapRequest(context.Background(), CreateSwapRequestInput{
+			RequesterUserID: user1.ID,
+			ResponderUserID: user2.ID,
+			RequesterSlotID: offered.ID,
+			ResponderSlotID: wanted.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create swap request: %v", err)
+		}
+
+		_, err = swapService.UpdateSwapRequestStatus(context.Background(), UpdateSwapRequestStatusInput{ID: swapRequest.ID, Status: "ACCEPTED", UserID: user2.ID})
+		var conflictErr *ConflictError
+		if !errors.As(err, &conflictErr) {
+			t.Fatalf("expected a ConflictError, got %v", err)
+		}
+		if len(conflictErr.Events) != 1 || conflictErr.Events[0].ID != clash.ID || conflictErr.Counterparty != "" {
+			t.Errorf("expected user2's meeting as the only conflict, got %+v", conflictErr)
+		}
+
+		// The failed accept must leave everything untouched.
+		unchanged, err := swapRepo.GetSwapRequestByID(context.Background(), swapRequest.ID)
+		if err != nil {
+			t.Fatalf("failed to get swap request: %v", err)
+		}
+		if unchanged.Status != "PENDING" {
+			t.Errorf("expected swap request to stay PENDING, got %s", unchanged.Status)
+		}
+
+		// The responder cannot waive the requester's check, and the
+		// requester's events are not disclosed to them.
+		requesterClash := createEvent("User1 Doctor", nine.Add(5*time.Hour), "BUSY", user1.ID)
+		_, err = swapService.UpdateSwapRequestStatus(context.Background(), UpdateSwapRequestStatusInput{ID: swapRequest.ID, Status: "ACCEPTED", UserID: user2.ID, AllowOverlap: true})
+		if !errors.As(err, &conflictErr) {
+			t.Fatalf("expected a ConflictError, got %v", err)
+		}
+		if len(conflictErr.Events) != 0 || conflictErr.Counterparty != ActorRequester || err.Error() != "the requester has a conflicting event" {
+			t.Errorf("expected only the requester's side to be reported, got %+v: %v", conflictErr, err)
+		}
+		_, err = swapService.UpdateSwapRequestStatus(context.Background(), UpdateSwapRequestStatusInput{ID: swapRequest.ID, Status: "ACCEPTED", UserID: user2.ID})
+		if !errors.As(err, &conflictErr) || len(conflictErr.Events) != 1 || conflictErr.Events[0].ID != clash.ID || conflictErr.Counterparty != ActorRequester {
+			t.Errorf("expected user2's meeting and the requester's side, got %v", err)
+		}
+		if err := testQueries.DeleteEvent(context.Background(), requesterClash.ID); err != nil {
+			t.Fatalf("failed to delete event: %v", err)
+		}
+
+		if _, err := swapService.UpdateSwapRequestStatus(context.Background(), UpdateSwapRequestStatusInput{ID: swapRequest.ID, Status: "ACCEPTED", UserID: user2.ID, AllowOverlap: true}); err != nil {
+			t.Fatalf("expected AllowOverlap to bypass the check, got %v", err)
+		}
+	})
+
+	t.Run("UpdateSwapRequestStatus_SupersedesCompetingOffers", func(t *testing.T) {
+		testQueries, owner := repository.SetupTestDBWithUser(t)
+		newUser := func(name string) db.User {
+			user, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{Name: name, Email: name + "@example.com", Password: "password"})
+			if err != nil {
+				t.Fatalf("failed to create %s: %v", name, err)
+			}
+			return user
+		}
+		winner, loser, bystander := newUser("winner"), newUser("loser"), newUser("bystander")
+
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		notificationRepo := repository.NewNotificationRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, notificationRepo, transactor)
+
+		nine := time.Date(2030, time.May, 7, 9, 0, 0, 0, time.UTC)
+		createEvent := func(title string, start time.Time, userID int64) db.Event {
+			event, err := testQueries.CreateEvent(context.Background(), db.CreateEventParams{Title: title, StartTime: start, EndTime: start.Add(time.Hour), Status: "SWAPPABLE", UserID: userID})
+			if err != nil {
+				t.Fatalf("failed to create event: %v", err)
+			}
+			return event
+		}
+		wanted := createEvent("Owner Shift", nine, owner.ID)
+		winnerSlot := createEvent("Winner Shift", nine.Add(2*time.Hour), winner.ID)
+		loserSlot := createEvent("Loser Shift", nine.Add(4*time.Hour), loser.ID)
+		bystanderSlot := createEvent("Bystander Shift", nine.Add(6*time.Hour), bystander.ID)
+
+		offer := func(requester db.User, from, to db.Event) db.SwapRequest {
+			swapRequest, err := swapService.CreateSwapRequest(context.Background(), CreateSwapRequestInput{
+				RequesterUserID: requester.ID,
+				ResponderUserID: to.UserID,
+				RequesterSlotID: from.ID,
+				ResponderSlotID: to.ID,
+			})
+			if err != nil {
+				t.Fatalf("failed to offer %q for %q: %v", from.Title, to.Title, err)
+			}
+			return *swapRequest
+		}
+		// The bystander bids on the winner's slot before the winner offers it.
+		bystanderOffer := offer(bystander, bystanderSlot, winnerSlot)
+		winnerOffer := offer(winner, winnerSlot, wanted)
+		loserOffer := offer(loser, loserSlot, wanted)
+
+		incoming, err := swapService.ListIncomingSwapRequests(context.Background(), owner.ID, SwapRequestListFilter{})
+		if err != nil {
+			t.Fatalf("failed to list incoming requests: %v", err)
+		}
+		if len(incoming.Items) != 2 {
+			t.Fatalf("expected both offers for the owner's slot, got %d", len(incoming.Items))
+		}
+		// An offered slot is reserved and cannot be offered again.
+		if _, err := swapService.CreateSwapRequest(context.Background(), CreateSwapRequestInput{
+			RequesterUserID: winner.ID, ResponderUserID: loser.ID, RequesterSlotID: winnerSlot.ID, ResponderSlotID: loserSlot.ID,
+		}); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("expected a reserved slot to be refused, got %v", err)
+		}
+
+		if _, err := swapService.UpdateSwapRequestStatus(context.Background(), UpdateSwapRequestStatusInput{ID: winnerOffer.ID, Status: "ACCEPTED", UserID: owner.ID}); err != nil {
+			t.Fatalf("failed to accept the winning offer: %v", err)
+		}
+
+		for _, req := range []db.SwapRequest{loserOffer, bystanderOffer} {
+			closed, err := swapRepo.GetSwapRequestByID(context.Background(), req.ID)
+			if err != nil {
+				t.Fatalf("failed to get swap request %d: %v", req.ID, err)
+			}
+			if closed.Status != "SUPERSEDED" || closed.ResolvedByUserID == nil || *closed.ResolvedByUserID != owner.ID {
+				t.Errorf("expected request %d to be superseded by the owner, got %+v", req.ID, closed)
+			}
+
+			released, err := eventRepo.GetEventByID(context.Background(), *req.RequesterSlotID)
+			if err != nil {
+				t.Fatalf("failed to get slot %d: %v", req.RequesterSlotID, err)
+			}
+			if released.Status != "SWAPPABLE" {
+				t.Errorf("expected slot %d to be SWAPPABLE again, got %q", released.ID, released.Status)
+			}
+
+			notifications, err := notificationRepo.ListNotificationsByUserID(context.Background(), db.ListNotificationsByUserIDParams{UserID: req.RequesterUserID, BeforeID: math.MaxInt64, Limit: 10})
+			if err != nil {
+				t.Fatalf("failed to list notifications: %v", err)
+			}
+			if len(notifications) != 1 || notifications[0].Kind != NotificationSwapRequestSuperseded || *notifications[0].SwapRequestID != req.ID {
+				t.Errorf("expected user %d to be told the offer was superseded, got %+v", req.RequesterUserID, notifications)
+			}
+		}
+
+		for slot, ownerID := range map[int64]int64{wanted.ID: winner.ID, winnerSlot.ID: owner.ID} {
+			swapped, err := eventRepo.GetEventByID(context.Background(), slot)
+			if err != nil {
+				t.Fatalf("failed to get slot %d: %v", slot, err)
+			}
+			if swapped.UserID != ownerID || swapped.Status != "BUSY" {
+				t.Errorf("expected slot %d to belong to user %d and be BUSY, got %+v", slot, ownerID, swapped)
+			}
+		}
+	})
+
+	t.Run("ClaimSlot", func(t *testing.T) {
+		testQueries, owner := repository.SetupTestDBWithUser(t)
+		newUser := func(name string) db.User {
+			user, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{Name: name, Email: name + "@example.com", Password: "password"})
+			if err != nil {
+				t.Fatalf("failed to create %s: %v", name, err)
+			}
+			return user
+		}
+		first, second, trader := newUser("first"), newUser("second"), newUser("trader")
+
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		eventRepo := repository.NewEventRepository(testQueries)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		notificationRepo := repository.NewNotificationRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, notificationRepo, transactor)
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, notificationRepo, transactor)
+
+		nine := time.Date(2030, time.May, 7, 9, 0, 0, 0, time.UTC)
+		createEvent := func(title string, start time.Time, userID int64, status string) db.Event {
+			event, err := testQueries.CreateEvent(context.Background(), db.CreateEventParams{Title: title, StartTime: start, EndTime: start.Add(time.Hour), Status: status, UserID: userID})
+			if err != nil {
+				t.Fatalf("failed to create event: %v", err)
+			}
+			return event
+		}
+		giveAway := func(event db.Event, giveaway string) {
+			if _, err := eventService.UpdateEventStatus(context.Background(), UpdateEventStatusInput{ID: event.ID, Status: "SWAPPABLE", Giveaway: giveaway, UserID: event.UserID}); err != nil {
+				t.Fatalf("failed to give %q away: %v", event.Title, err)
+			}
+		}
+		claim := func(event db.Event, user db.User) (*db.SwapRequest, error) {
+			return swapService.ClaimSlot(context.Background(), ClaimSlotInput{EventID: event.ID, UserID: user.ID})
+		}
+
+		t.Run("first come", func(t *testing.T) {
+			shift := createEvent("First Come Shift", nine, owner.ID, "SWAPPABLE")
+			traderSlot := createEvent("Trader Shift", nine.Add(time.Hour), trader.ID, "SWAPPABLE")
+			offer, err := swapService.CreateSwapRequest(context.Background(), CreateSwapRequestInput{RequesterUserID: trader.ID, ResponderUserID: owner.ID, RequesterSlotID: traderSlot.ID, ResponderSlotID: shift.ID})
+			if err != nil {
+				t.Fatalf("failed to offer a swap: %v", err)
+			}
+
+			if _, err := claim(shift, first); !errors.Is(err, ErrInvalidState) {
+				t.Errorf("expected a slot that is only swappable to be refused, got %v", err)
+			}
+			giveAway(shift, "FIRST_COME")
+			if _, err := claim(shift, owner); !errors.Is(err, ErrValidation) {
+				t.Errorf("expected the owner's own claim to be refused, got %v", err)
+			}
+
+			// The claimant already has a shift at that time.
+			createEvent("First Busy", nine.Add(30*time.Minute), first.ID, "BUSY")
+			var conflict *ConflictError
+			if _, err := claim(shift, first); !errors.As(err, &conflict) {
+				t.Fatalf("expected a conflict, got %v", err)
+			}
+
+			accepted, err := swapService.ClaimSlot(context.Background(), ClaimSlotInput{EventID: shift.ID, UserID: first.ID, AllowOverlap: true})
+			if err != nil {
+				t.Fatalf("failed to claim the slot: %v", err)
+			}
+			if accepted.Status != "ACCEPTED" || accepted.Kind != "TRANSFER" || accepted.RequesterSlotID != nil ||
+				accepted.ResolvedByUserID == nil || *accepted.ResolvedByUserID != first.ID {
+				t.Errorf("expected an accepted transfer resolved by the claimant, got %+v", accepted)
+			}
+			taken, err := eventRepo.GetEventByID(context.Background(), shift.ID)
+			if err != nil {
+				t.Fatalf("failed to get the slot: %v", err)
+			}
+			if taken.UserID != first.ID || taken.Status != "BUSY" || taken.Giveaway != nil {
+				t.Errorf("expected the slot to belong to the claimant and be BUSY, got %+v", taken)
+			}
+
+			if _, err := claim(shift, second); !errors.Is(err, ErrInvalidState) {
+				t.Errorf("expected a second claim to be refused, got %v", err)
+			}
+			closed, err := swapRepo.GetSwapRequestByID(context.Background(), offer.ID)
+			if err != nil {
+				t.Fatalf("failed to get the swap offer: %v", err)
+			}
+			if closed.Status != "SUPERSEDED" {
+				t.Errorf("expected the swap offer to be superseded, got %q", closed.Status)
+			}
+
+			notifications, err := notificationRepo.ListNotificationsByUserID(context.Background(), db.ListNotificationsByUserIDParams{UserID: owner.ID, BeforeID: math.MaxInt64, Limit: 10})
+			if err != nil {
+				t.Fatalf("failed to list notifications: %v", err)
+			}
+			// The newest one; the owner is also told about the superseded offer.
+			if len(notifications) != 2 || notifications[0].Kind != NotificationSlotClaimed || *notifications[0].SwapRequestID != accepted.ID {
+				t.Errorf("expected the owner to be told the slot was claimed, got %+v", notifications)
+			}
+		})
+
+		t.Run("owner picks", func(t *testing.T) {
+			shift := createEvent("Owner Picks Shift", nine.Add(24*time.Hour), owner.ID, "BUSY")
+			giveAway(shift, "OWNER_PICKS")
+
+			firstClaim, err := claim(shift, first)
+			if err != nil {
+				t.Fatalf("failed to claim the slot: %v", err)
+			}
+			if _, err := claim(shift, first); !errors.Is(err, ErrInvalidState) {
+				t.Errorf("expected a repeated claim to be refused, got %v", err)
+			}
+			secondClaim, err := claim(shift, second)
+			if err != nil {
+				t.Fatalf("failed to claim the slot: %v", err)
+			}
+			if firstClaim.Status != "PENDING" || secondClaim.Status != "PENDING" {
+				t.Fatalf("expected the claims to wait for the owner, got %q and %q", firstClaim.Status, secondClaim.Status)
+			}
+
+			if _, err := swapService.UpdateSwapRequestStatus(context.Background(), UpdateSwapRequestStatusInput{ID: secondClaim.ID, Status: "ACCEPTED", UserID: owner.ID}); err != nil {
+				t.Fatalf("failed to accept the claim: %v", err)
+			}
+			taken, err := eventRepo.GetEventByID(context.Background(), shift.ID)
+			if err != nil {
+				t.Fatalf("failed to get the slot: %v", err)
+			}
+			if taken.UserID != second.ID || taken.Status != "BUSY" {
+				t.Errorf("expected the slot to go to the picked claimant, got %+v", taken)
+			}
+			closed, err := swapRepo.GetSwapRequestByID(context.Background(), firstClaim.ID)
+			if err != nil {
+				t.Fatalf("failed to get the first claim: %v", err)
+			}
+			if closed.Status != "SUPERSEDED" {
+				t.Errorf("expected the other claim to be superseded, got %q", closed.Status)
+			}
+		})
+
+		t.Run("ending a giveaway cancels its claims", func(t *testing.T) {
+			shift := createEvent("Withdrawn Shift", nine.Add(48*time.Hour), owner.ID, "SWAPPABLE")
+			giveAway(shift, "OWNER_PICKS")
+			pending, err := claim(shift, first)
+			if err != nil {
+				t.Fatalf("failed to claim the slot: %v", err)
+			}
+
+			if _, err := eventService.UpdateEventStatus(context.Background(), UpdateEventStatusInput{ID: shift.ID, Status: "BUSY", Giveaway: "OWNER_PICKS", UserID: owner.ID}); !errors.Is(err, ErrValidation) {
+				t.Errorf("expected a giveaway of a BUSY slot to be refused, got %v", err)
+			}
+			giveAway(shift, "")
+			cancelled, err := swapRepo.GetSwapRequestByID(context.Background(), pending.ID)
+			if err != nil {
+				t.Fatalf("failed to get the claim: %v", err)
+			}
+			if cancelled.Status != "CANCELLED" {
+				t.Errorf("expected the claim to be cancelled, got %q", cancelled.Status)
+			}
+			if _, err := claim(shift, second); !errors.Is(err, ErrInvalidState) {
+				t.Errorf("expected a slot that is no longer given away to be refused, got %v", err)
+			}
+		})
+	})
+}
+
+// racingTransactor runs before once, ahead of the first unit of work, as a
+// concurrent request would.
+type racingTransactor struct {
+	repository.Transactor
+	before func()
+}
+
+func (t *racingTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if before := t.before; before != nil {
+		t.before = nil
+		before()
+	}
+	return t.Transactor.WithinTx(ctx, fn)
 }
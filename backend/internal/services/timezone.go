@@ -0,0 +1,86 @@
+package services
+
+import (
+	"time"
+)
+
+// defaultTimeZone is used for users and events created without a zone.
+const defaultTimeZone = "UTC"
+
+// RecurrenceInput repeats an event Count times in total. Occurrences keep the
+// wall-clock start time of the first one in the event's time zone, so a
+// weekly 09:00 meeting stays at 09:00 local time across DST changes.
+type RecurrenceInput struct {
+	Frequency string `json:"frequency" validate:"required,oneof=DAILY WEEKLY"`
+	Count     int    `json:"count" validate:"required,min=1,max=366"`
+}
+
+func (r RecurrenceInput) intervalDays() int {
+	if r.Frequency == "WEEKLY" {
+		return 7
+	}
+	return 1
+}
+
+// occurrence is one instance of a recurring event, in UTC.
+type occurrence struct {
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// occurrences expands a recurrence starting at start/end. Each start is
+// shifted by whole calendar days in loc rather than by multiples of 24 hours,
+// and every occurrence lasts as long as the first.
+func occurrences(start, end time.Time, loc *time.Location, recurrence RecurrenceInput) []occurrence {
+	start = start.In(loc)
+	duration := end.Sub(start)
+	out := make([]occurrence, 0, recurrence.Count)
+	for i := range recurrence.Count {
+		s := shiftDays(start, i*recurrence.intervalDays(), loc)
+		out = append(out, occurrence{StartTime: s, EndTime: s.Add(duration)})
+	}
+	return out
+}
+
+func shiftDays(t time.Time, days int, loc *time.Location) time.Time {
+	y, m, d := t.Date()
+	return wallClock(time.Date(y, m, d+days, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC), loc)
+}
+
+// wallClock returns the instant at which clocks in loc show the date and time
+// of wall, read as a zone-less value. time.Date leaves DST edge cases
+// unspecified, so they are resolved here: a time repeated when clocks go back
+// picks its first occurrence, and a time skipped when clocks go forward moves
+// later by the size of the gap.
+func wallClock(wall time.Time, loc *time.Location) time.Time {
+	y, m, d := wall.Date()
+	hh, mm, ss := wall.Clock()
+	_, before := time.Date(y, m, d-1, hh, mm, ss, 0, loc).Zone()
+	_, after := time.Date(y, m, d+1, hh, mm, ss, 0, loc).Zone()
+
+	var first time.Time
+	for _, offset := range []int{before, after} {
+		t := wall.Add(-time.Duration(offset) * time.Second)
+		local := t.In(loc)
+		if local.Year() != y || local.YearDay() != wall.YearDay() || local.Hour() != hh || local.Minute() != mm || local.Second() != ss {
+			continue
+		}
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+	}
+	if first.IsZero() {
+		// In a gap: read the time with the offset in force before it.
+		first = wall.Add(-time.Duration(before) * time.Second)
+	}
+	return first.UTC()
+}
+
+// loadLocation resolves a stored zone name. Rows written before zones were
+// tracked, or inserted directly with an empty zone, are treated as UTC.
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
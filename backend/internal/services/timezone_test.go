@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/repository"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", name, err)
+	}
+	return loc
+}
+
+func utc(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t.UTC()
+}
+
+func TestOccurrences(t *testing.T) {
+	testCases := []struct {
+		name       string
+		zone       string
+		start      string // wall clock in zone
+		duration   time.Duration
+		recurrence RecurrenceInput
+		want       []string // UTC starts
+	}{
+		{
+			name:       "Berlin weekly across spring forward",
+			zone:       "Europe/Berlin",
+			start:      "2026-03-22T09:00:00",
+			duration:   time.Hour,
+			recurrence: RecurrenceInput{Frequency: "WEEKLY", Count: 3},
+			want:       []string{"2026-03-22T08:00:00Z", "2026-03-29T07:00:00Z", "2026-04-05T07:00:00Z"},
+		},
+		{
+			name:       "Berlin daily across fall back",
+			zone:       "Europe/Berlin",
+			start:      "2026-10-24T09:00:00",
+			duration:   time.Hour,
+			recurrence: RecurrenceInput{Frequency: "DAILY", Count: 3},
+			want:       []string{"2026-10-24T07:00:00Z", "2026-10-25T08:00:00Z", "2026-10-26T08:00:00Z"},
+		},
+		{
+			name:       "New York daily across spring forward",
+			zone:       "America/New_York",
+			start:      "2026-03-07T09:00:00",
+			duration:   30 * time.Minute,
+			recurrence: RecurrenceInput{Frequency: "DAILY", Count: 2},
+			want:       []string{"2026-03-07T14:00:00Z", "2026-03-08T13:00:00Z"},
+		},
+		{
+			name:       "New York start in the spring gap moves forward",
+			zone:       "America/New_York",
+			start:      "2026-03-07T02:30:00",
+			duration:   time.Hour,
+			recurrence: RecurrenceInput{Frequency: "DAILY", Count: 3},
+			want:       []string{"2026-03-07T07:30:00Z", "2026-03-08T07:30:00Z", "2026-03-09T06:30:00Z"},
+		},
+		{
+			name:       "Berlin start in the spring gap moves forward",
+			zone:       "Europe/Berlin",
+			start:      "2026-03-28T02:30:00",
+			duration:   time.Hour,
+			recurrence: RecurrenceInput{Frequency: "DAILY", Count: 2},
+			want:       []string{"2026-03-28T01:30:00Z", "2026-03-29T01:30:00Z"},
+		},
+		{
+			name:       "New York ambiguous start picks the first occurrence",
+			zone:       "America/New_York",
+			start:      "2026-10-31T01:30:00",
+			duration:   time.Hour,
+			recurrence: RecurrenceInput{Frequency: "DAILY", Count: 3},
+			want:       []string{"2026-10-31T05:30:00Z", "2026-11-01T05:30:00Z", "2026-11-02T06:30:00Z"},
+		},
+		{
+			name:       "Berlin ambiguous start picks the first occurrence",
+			zone:       "Europe/Berlin",
+			start:      "2026-10-24T02:30:00",
+			duration:   time.Hour,
+			recurrence: RecurrenceInput{Frequency: "DAILY", Count: 2},
+			want:       []string{"2026-10-24T00:30:00Z", "2026-10-25T00:30:00Z"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loc := mustLoadLocation(t, tc.zone)
+			start, err := time.ParseInLocation("2006-01-02T15:04:05", tc.start, loc)
+			if err != nil {
+				t.Fatalf("failed to parse start: %v", err)
+			}
+
+			got := occurrences(start, start.Add(tc.duration), loc, tc.recurrence)
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected %d occurrences, got %d", len(tc.want), len(got))
+			}
+			for i, o := range got {
+				if want := utc(tc.want[i]); !o.StartTime.Equal(want) {
+					t.Errorf("occurrence %d: expected start %s, got %s", i, want, o.StartTime)
+				}
+				if d := o.EndTime.Sub(o.StartTime); d != tc.duration {
+					t.Errorf("occurrence %d: expected duration %s, got %s", i, tc.duration, d)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckConflicts_DST(t *testing.T) {
+	// On 2026-11-01 New York clocks go back at 02:00 EDT, so 01:00-02:00
+	// local time happens twice: first at 05:00Z (EDT), then at 06:00Z (EST).
+	testCases := []struct {
+		name         string
+		existing     [2]string
+		claim        [2]string
+		wantConflict bool
+	}{
+		{
+			name:         "same wall clock in the repeated hour does not overlap",
+			existing:     [2]string{"2026-11-01T01:00:00-04:00", "2026-11-01T01:45:00-04:00"},
+			claim:        [2]string{"2026-11-01T01:15:00-05:00", "2026-11-01T01:45:00-05:00"},
+			wantConflict: false,
+		},
+		{
+			name:         "event spanning the transition overlaps the second pass",
+			existing:     [2]string{"2026-11-01T01:30:00-04:00", "2026-11-01T01:30:00-05:00"},
+			claim:        [2]string{"2026-11-01T01:15:00-05:00", "2026-11-01T01:45:00-05:00"},
+			wantConflict: true,
+		},
+		{
+			name:         "back-to-back across the transition",
+			existing:     [2]string{"2026-11-01T00:30:00-04:00", "2026-11-01T01:00:00-05:00"},
+			claim:        [2]string{"2026-11-01T01:00:00-05:00", "2026-11-01T02:00:00-05:00"},
+			wantConflict: false,
+		},
+		{
+			name:         "two hour event on the short spring day",
+			existing:     [2]string{"2026-03-08T01:30:00-05:00", "2026-03-08T03:30:00-04:00"},
+			claim:        [2]string{"2026-03-08T03:00:00-04:00", "2026-03-08T04:00:00-04:00"},
+			wantConflict: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testQueries, user := repository.SetupTestDBWithUser(t)
+			eventRepo := repository.NewEventRepository(testQueries)
+
+			_, err := eventRepo.CreateEvent(context.Background(), db.CreateEventParams{
+				Title:     "Existing",
+				StartTime: utc(tc.existing[0]),
+				EndTime:   utc(tc.existing[1]),
+				Status:    "BUSY",
+				UserID:    user.ID,
+				TimeZone:  "America/New_York",
+			})
+			if err != nil {
+				t.Fatalf("failed to create event: %v", err)
+			}
+
+			err = checkConflicts(context.Background(), eventRepo, slotClaim{
+				UserID:    user.ID,
+				StartTime: utc(tc.claim[0]),
+				EndTime:   utc(tc.claim[1]),
+			})
+			var conflictErr *ConflictError
+			if got := errors.As(err, &conflictErr); got != tc.wantConflict {
+				t.Errorf("expected conflict %v, got %v", tc.wantConflict, err)
+			}
+		})
+	}
+}
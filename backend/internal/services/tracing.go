@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+
+	"slotswapper/internal/db"
+	"slotswapper/internal/tracing"
+)
+
+// The event and swap request services returned by the constructors are
+// wrapped in the traced types below, so each call shows up as a span between
+// the HTTP request and the repository calls it makes.
+
+type tracedEventService struct {
+	next EventService
+}
+
+func (s *tracedEventService) CreateEvent(ctx context.Context, input CreateEventInput) (*db.Event, error) {
+	ctx, span := tracing.Start(ctx, "EventService.CreateEvent")
+	result, err := s.next.CreateEvent(ctx, input)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedEventService) CreateRecurringEvents(ctx context.Context, input CreateEventInput, recurrence RecurrenceInput) ([]db.Event, error) {
+	ctx, span := tracing.Start(ctx, "EventService.CreateRecurringEvents")
+	result, err := s.next.CreateRecurringEvents(ctx, input, recurrence)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedEventService) GetEventByID(ctx context.Context, id int64) (*db.Event, error) {
+	ctx, span := tracing.Start(ctx, "EventService.GetEventByID")
+	result, err := s.next.GetEventByID(ctx, id)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedEventService) GetEventsByUserID(ctx context.Context, userID int64) ([]db.Event, error) {
+	ctx, span := tracing.Start(ctx, "EventService.GetEventsByUserID")
+	result, err := s.next.GetEventsByUserID(ctx, userID)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedEventService) GetEventsByUserIDAndStatus(ctx context.Context, userID int64, status string) ([]db.Event, error) {
+	ctx, span := tracing.Start(ctx, "EventService.GetEventsByUserIDAndStatus")
+	result, err := s.next.GetEventsByUserIDAndStatus(ctx, userID, status)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedEventService) UpdateEventStatus(ctx context.Context, input UpdateEventStatusInput) (*db.Event, error) {
+	ctx, span := tracing.Start(ctx, "EventService.UpdateEventStatus")
+	result, err := s.next.UpdateEventStatus(ctx, input)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedEventService) UpdateEvent(ctx context.Context, input UpdateEventInput) (*db.Event, error) {
+	ctx, span := tracing.Start(ctx, "EventService.UpdateEvent")
+	result, err := s.next.UpdateEvent(ctx, input)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedEventService) PatchEvent(ctx context.Context, input PatchEventInput) (*PatchEventResult, error) {
+	ctx, span := tracing.Start(ctx, "EventService.PatchEvent")
+	result, err := s.next.PatchEvent(ctx, input)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedEventService) DeleteEvent(ctx context.Context, eventID, userID, version int64) error {
+	ctx, span := tracing.Start(ctx, "EventService.DeleteEvent")
+	return tracing.End(span, s.next.DeleteEvent(ctx, eventID, userID, version))
+}
+
+func (s *tracedEventService) GetSwappableEvents(ctx context.Context, userID int64) ([]db.GetSwappableEventsRow, error) {
+	ctx, span := tracing.Start(ctx, "EventService.GetSwappableEvents")
+	result, err := s.next.GetSwappableEvents(ctx, userID)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedEventService) ListEventsByUserID(ctx context.Context, userID int64, status string, filter EventListFilter) (*Page[db.Event], error) {
+	ctx, span := tracing.Start(ctx, "EventService.ListEventsByUserID")
+	result, err := s.next.ListEventsByUserID(ctx, userID, status, filter)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedEventService) ListSwappableEvents(ctx context.Context, userID int64, filter EventListFilter) (*Page[db.ListSwappableEventsRow], error) {
+	ctx, span := tracing.Start(ctx, "EventService.ListSwappableEvents")
+	result, err := s.next.ListSwappableEvents(ctx, userID, filter)
+	return result, tracing.End(span, err)
+}
+
+type tracedSwapRequestService struct {
+	next SwapRequestService
+}
+
+func (s *tracedSwapRequestService) CreateSwapRequest(ctx context.Context, input CreateSwapRequestInput) (*db.SwapRequest, error) {
+	ctx, span := tracing.Start(ctx, "SwapRequestService.CreateSwapRequest")
+	result, err := s.next.CreateSwapRequest(ctx, input)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedSwapRequestService) GetSwapRequestByID(ctx context.Context, id int64) (*db.SwapRequest, error) {
+	ctx, span := tracing.Start(ctx, "SwapRequestService.GetSwapRequestByID")
+	result, err := s.next.GetSwapRequestByID(ctx, id)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedSwapRequestService) UpdateSwapRequestStatus(ctx context.Context, input UpdateSwapRequestStatusInput) (*db.SwapRequest, error) {
+	ctx, span := tracing.Start(ctx, "SwapRequestService.UpdateSwapRequestStatus")
+	result, err := s.next.UpdateSwapRequestStatus(ctx, input)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedSwapRequestService) WithdrawSwapRequest(ctx context.Context, id, userID int64) (*db.SwapRequest, error) {
+	ctx, span := tracing.Start(ctx, "SwapRequestService.WithdrawSwapRequest")
+	result, err := s.next.WithdrawSwapRequest(ctx, id, userID)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedSwapRequestService) ClaimSlot(ctx context.Context, input ClaimSlotInput) (*db.SwapRequest, error) {
+	ctx, span := tracing.Start(ctx, "SwapRequestService.ClaimSlot")
+	result, err := s.next.ClaimSlot(ctx, input)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedSwapRequestService) ListIncomingSwapRequests(ctx context.Context, responderUserID int64, filter SwapRequestListFilter) (*Page[db.ListIncomingSwapRequestsRow], error) {
+	ctx, span := tracing.Start(ctx, "SwapRequestService.ListIncomingSwapRequests")
+	result, err := s.next.ListIncomingSwapRequests(ctx, responderUserID, filter)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedSwapRequestService) ListOutgoingSwapRequests(ctx context.Context, requesterUserID int64, filter SwapRequestListFilter) (*Page[db.ListOutgoingSwapRequestsRow], error) {
+	ctx, span := tracing.Start(ctx, "SwapRequestService.ListOutgoingSwapRequests")
+	result, err := s.next.ListOutgoingSwapRequests(ctx, requesterUserID, filter)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedSwapRequestService) GetIncomingSwapRequestHistory(ctx context.Context, filter SwapRequestHistoryFilter) (*Page[db.GetIncomingSwapRequestHistoryRow], error) {
+	ctx, span := tracing.Start(ctx, "SwapRequestService.GetIncomingSwapRequestHistory")
+	result, err := s.next.GetIncomingSwapRequestHistory(ctx, filter)
+	return result, tracing.End(span, err)
+}
+
+func (s *tracedSwapRequestService) GetOutgoingSwapRequestHistory(ctx context.Context, filter SwapRequestHistoryFilter) (*Page[db.GetOutgoingSwapRequestHistoryRow], error) {
+	ctx, span := tracing.Start(ctx, "SwapRequestService.GetOutgoingSwapRequestHistory")
+	result, err := s.next.GetOutgoingSwapRequestHistory(ctx, filter)
+	return result, tracing.End(span, err)
+}
@@ -5,8 +5,9 @@ import (
 
 	"slotswapper/internal/crypto"
 	"slotswapper/internal/db"
+	"slotswapper/internal/logging"
+	"slotswapper/internal/metrics"
 	"slotswapper/internal/repository"
-	"slotswapper/internal/validation"
 )
 
 // CreateUserInput defines the input for creating a user.
@@ -14,6 +15,13 @@ type CreateUserInput struct {
 	Name     string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
+	TimeZone string `json:"time_zone" validate:"omitempty,timezone"`
+}
+
+// UpdateTimeZoneInput sets the IANA zone a user's events default to.
+type UpdateTimeZoneInput struct {
+	UserID   int64  `json:"-" validate:"required"`
+	TimeZone string `json:"time_zone" validate:"required,timezone"`
 }
 
 // UserService is responsible for user-related operations.
@@ -21,19 +29,30 @@ type UserService interface {
 	CreateUser(ctx context.Context, input CreateUserInput) (*db.User, error)
 	GetUserByID(ctx context.Context, id int64) (*db.GetUserByIDRow, error)
 	GetPublicUserByID(ctx context.Context, id int64) (*db.GetPublicUserByIDRow, error)
+	UpdateTimeZone(ctx context.Context, input UpdateTimeZoneInput) (*db.GetUserByIDRow, error)
+	// DeactivateAccount closes the user's account. Their pending swap
+	// requests are cancelled, their slots leave the marketplace and their
+	// access tokens are revoked. The account can no longer sign in.
+	DeactivateAccount(ctx context.Context, userID int64) error
 }
 
 type userService struct {
-	userRepo repository.UserRepository
-	password crypto.Password
+	userRepo         repository.UserRepository
+	eventRepo        repository.EventRepository
+	swapRepo         repository.SwapRequestRepository
+	tokenRepo        repository.AccessTokenRepository
+	auditRepo        repository.AuditLogRepository
+	notificationRepo repository.NotificationRepository
+	transactor       repository.Transactor
+	password         crypto.Password
 }
 
-func NewUserService(userRepo repository.UserRepository, password crypto.Password) UserService {
-	return &userService{userRepo: userRepo, password: password}
+func NewUserService(userRepo repository.UserRepository, eventRepo repository.EventRepository, swapRepo repository.SwapRequestRepository, tokenRepo repository.AccessTokenRepository, auditRepo repository.AuditLogRepository, notificationRepo repository.NotificationRepository, transactor repository.Transactor, password crypto.Password) UserService {
+	return &userService{userRepo: userRepo, eventRepo: eventRepo, swapRepo: swapRepo, tokenRepo: tokenRepo, auditRepo: auditRepo, notificationRepo: notificationRepo, transactor: transactor, password: password}
 }
 
 func (s *userService) CreateUser(ctx context.Context, input CreateUserInput) (*db.User, error) {
-	if err := validation.Validate.Struct(input); err != nil {
+	if err := validate(input); err != nil {
 		return nil, err
 	}
 
@@ -48,7 +67,7 @@ func (s *userService) CreateUser(ctx context.Context, input CreateUserInput) (*d
 		Password: hashedPassword,
 	}
 
-	user, err := s.userRepo.CreateUser(ctx, arg)
+	user, err := createUserAudited(ctx, s.userRepo, s.auditRepo, s.transactor, arg, input.TimeZone)
 	if err != nil {
 		return nil, err
 	}
@@ -56,10 +75,48 @@ func (s *userService) CreateUser(ctx context.Context, input CreateUserInput) (*d
 	return &user, nil
 }
 
+// createUserAudited inserts a user and records the signup in the audit log.
+// The password hash is never written to the log. An empty timeZone keeps the
+// column default.
+func createUserAudited(ctx context.Context, userRepo repository.UserRepository, auditRepo repository.AuditLogRepository, transactor repository.Transactor, arg db.CreateUserParams, timeZone string) (db.User, error) {
+	var user db.User
+	err := transactor.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = userRepo.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+		if timeZone != "" && timeZone != user.TimeZone {
+			if err := userRepo.UpdateUserTimeZone(ctx, db.UpdateUserTimeZoneParams{TimeZone: timeZone, ID: user.ID}); err != nil {
+				return err
+			}
+			user.TimeZone = timeZone
+		}
+
+		return recordAudit(ctx, auditRepo, auditRecord{
+			ActorUserID: user.ID,
+			Action:      AuditActionUserCreate,
+			EntityType:  AuditEntityUser,
+			EntityID:    user.ID,
+			After: db.GetUserByIDRow{
+				ID:        user.ID,
+				Name:      user.Name,
+				Email:     user.Email,
+				IsAdmin:   user.IsAdmin,
+				TimeZone:  user.TimeZone,
+				CreatedAt: user.CreatedAt,
+				UpdatedAt: user.UpdatedAt,
+			},
+			Subjects: []int64{user.ID},
+		})
+	})
+	return user, err
+}
+
 func (s *userService) GetUserByID(ctx context.Context, id int64) (*db.GetUserByIDRow, error) {
 	user, err := s.userRepo.GetUserByID(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, "user not found")
 	}
 	return &user, nil
 }
@@ -67,7 +124,107 @@ func (s *userService) GetUserByID(ctx context.Context, id int64) (*db.GetUserByI
 func (s *userService) GetPublicUserByID(ctx context.Context, id int64) (*db.GetPublicUserByIDRow, error) {
 	user, err := s.userRepo.GetPublicUserByID(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, "user not found")
 	}
 	return &user, nil
 }
+
+func (s *userService) UpdateTimeZone(ctx context.Context, input UpdateTimeZoneInput) (*db.GetUserByIDRow, error) {
+	if err := validate(input); err != nil {
+		return nil, err
+	}
+
+	var updated db.GetUserByIDRow
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.userRepo.GetUserByID(ctx, input.UserID)
+		if err != nil {
+			return err
+		}
+		if err := s.userRepo.UpdateUserTimeZone(ctx, db.UpdateUserTimeZoneParams{TimeZone: input.TimeZone, ID: input.UserID}); err != nil {
+			return err
+		}
+		updated, err = s.userRepo.GetUserByID(ctx, input.UserID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo, auditRecord{
+			ActorUserID: input.UserID,
+			Action:      AuditActionUserUpdate,
+			EntityType:  AuditEntityUser,
+			EntityID:    input.UserID,
+			Before:      before,
+			After:       updated,
+			Subjects:    []int64{input.UserID},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func (s *userService) DeactivateAccount(ctx context.Context, userID int64) error {
+	var cancelled int
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return notFound(err, "user not found")
+		}
+
+		// When the other user made the offer, their reserved slot goes back on
+		// the marketplace. The user's own slots are taken off it below.
+		canceller := swapCanceller{eventRepo: s.eventRepo, swapRepo: s.swapRepo, auditRepo: s.auditRepo, notificationRepo: s.notificationRepo}
+		pending, err := s.swapRepo.GetPendingSwapRequestsByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		for _, req := range pending {
+			var releaseSlotID int64
+			if req.ResponderUserID == userID && req.RequesterSlotID != nil {
+				releaseSlotID = *req.RequesterSlotID
+			}
+			if err := canceller.cancel(ctx, req, CancelReasonUserDeactivated, releaseSlotID, userID); err != nil {
+				return err
+			}
+		}
+		cancelled = len(pending)
+
+		// The user's own slots leave the marketplace.
+		for _, status := range []EventStatus{EventSwappable, EventSwapPending} {
+			events, err := s.eventRepo.GetEventsByUserIDAndStatus(ctx, db.GetEventsByUserIDAndStatusParams{UserID: userID, Status: string(status)})
+			if err != nil {
+				return err
+			}
+			for _, event := range events {
+				if _, err := setEventStatus(ctx, s.eventRepo, s.auditRepo, event, EventBusy, ActorSystem, userID); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := s.tokenRepo.DeletePersonalAccessTokensByUserID(ctx, userID); err != nil {
+			return err
+		}
+		if err := s.userRepo.DeactivateUser(ctx, userID); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo, auditRecord{
+			ActorUserID: userID,
+			Action:      AuditActionUserDeactivate,
+			EntityType:  AuditEntityUser,
+			EntityID:    userID,
+			Before:      before,
+			Subjects:    []int64{userID},
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	metrics.SwapRequestsResolved.WithLabelValues(metrics.OutcomeCancelled).Add(float64(cancelled))
+	logging.FromContext(ctx).Info("account deactivated", "user_id", userID, "cancelled_swap_requests", cancelled)
+	return nil
+}
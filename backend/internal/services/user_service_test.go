@@ -2,10 +2,14 @@ package services
 
 import (
 	"context"
+	"errors"
+	"math"
 	"testing"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"slotswapper/internal/crypto"
+	"slotswapper/internal/db"
 	"slotswapper/internal/repository"
 )
 
@@ -13,8 +17,10 @@ func TestUserService(t *testing.T) {
 	t.Run("CreateUser", func(t *testing.T) {
 		testQueries := repository.SetupTestDB(t)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		passwordCrypto := crypto.NewPassword()
-		userService := NewUserService(userRepo, passwordCrypto)
+		userService := NewUserService(userRepo, repository.NewEventRepository(testQueries), repository.NewSwapRequestRepository(testQueries), repository.NewAccessTokenRepository(testQueries), auditRepo, repository.NewNotificationRepository(testQueries), transactor, passwordCrypto)
 
 		password := "password123"
 		input := CreateUserInput{
@@ -53,8 +59,10 @@ func TestUserService(t *testing.T) {
 	t.Run("CreateUser_ValidationErrors", func(t *testing.T) {
 		testQueries := repository.SetupTestDB(t)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		passwordCrypto := crypto.NewPassword()
-		userService := NewUserService(userRepo, passwordCrypto)
+		userService := NewUserService(userRepo, repository.NewEventRepository(testQueries), repository.NewSwapRequestRepository(testQueries), repository.NewAccessTokenRepository(testQueries), auditRepo, repository.NewNotificationRepository(testQueries), transactor, passwordCrypto)
 
 		testCases := []struct {
 			name  string
@@ -105,8 +113,10 @@ func TestUserService(t *testing.T) {
 	t.Run("CreateUser_DuplicateEmail", func(t *testing.T) {
 		testQueries := repository.SetupTestDB(t)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		passwordCrypto := crypto.NewPassword()
-		userService := NewUserService(userRepo, passwordCrypto)
+		userService := NewUserService(userRepo, repository.NewEventRepository(testQueries), repository.NewSwapRequestRepository(testQueries), repository.NewAccessTokenRepository(testQueries), auditRepo, repository.NewNotificationRepository(testQueries), transactor, passwordCrypto)
 
 		// First create a user
 		arg1 := CreateUserInput{
@@ -139,8 +149,10 @@ func TestUserService(t *testing.T) {
 	t.Run("GetUserByID", func(t *testing.T) {
 		testQueries := repository.SetupTestDB(t)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		passwordCrypto := crypto.NewPassword()
-		userService := NewUserService(userRepo, passwordCrypto)
+		userService := NewUserService(userRepo, repository.NewEventRepository(testQueries), repository.NewSwapRequestRepository(testQueries), repository.NewAccessTokenRepository(testQueries), auditRepo, repository.NewNotificationRepository(testQueries), transactor, passwordCrypto)
 
 		createInput := CreateUserInput{
 			Name:     "user for get by id",
@@ -173,8 +185,10 @@ func TestUserService(t *testing.T) {
 	t.Run("GetPublicUserByID", func(t *testing.T) {
 		testQueries := repository.SetupTestDB(t)
 		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
 		passwordCrypto := crypto.NewPassword()
-		userService := NewUserService(userRepo, passwordCrypto)
+		userService := NewUserService(userRepo, repository.NewEventRepository(testQueries), repository.NewSwapRequestRepository(testQueries), repository.NewAccessTokenRepository(testQueries), auditRepo, repository.NewNotificationRepository(testQueries), transactor, passwordCrypto)
 
 		createInput := CreateUserInput{
 			Name:     "public user for get by id",
@@ -203,4 +217,118 @@ func TestUserService(t *testing.T) {
 		// Verify that the returned struct does not contain the email field
 		// This is implicitly tested by the type db.GetPublicUserByIDRow not having an Email field
 	})
+
+	t.Run("UpdateTimeZone", func(t *testing.T) {
+		testQueries := repository.SetupTestDB(t)
+		userRepo := repository.NewUserRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		passwordCrypto := crypto.NewPassword()
+		userService := NewUserService(userRepo, repository.NewEventRepository(testQueries), repository.NewSwapRequestRepository(testQueries), repository.NewAccessTokenRepository(testQueries), auditRepo, repository.NewNotificationRepository(testQueries), transactor, passwordCrypto)
+
+		createdUser, err := userService.CreateUser(context.Background(), CreateUserInput{
+			Name:     "user with zone",
+			Email:    "zone@example.com",
+			Password: "password",
+			TimeZone: "Asia/Kolkata",
+		})
+		if err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		if createdUser.TimeZone != "Asia/Kolkata" {
+			t.Errorf("expected time zone %q, got %q", "Asia/Kolkata", createdUser.TimeZone)
+		}
+
+		updated, err := userService.UpdateTimeZone(context.Background(), UpdateTimeZoneInput{UserID: createdUser.ID, TimeZone: "America/New_York"})
+		if err != nil {
+			t.Fatalf("failed to update time zone: %v", err)
+		}
+		if updated.TimeZone != "America/New_York" {
+			t.Errorf("expected time zone %q, got %q", "America/New_York", updated.TimeZone)
+		}
+
+		for _, zone := range []string{"", "Local", "Mars/Olympus_Mons", "+05:30"} {
+			if _, err := userService.UpdateTimeZone(context.Background(), UpdateTimeZoneInput{UserID: createdUser.ID, TimeZone: zone}); err == nil {
+				t.Errorf("expected time zone %q to be rejected", zone)
+			}
+		}
+	})
+
+	t.Run("DeactivateAccount", func(t *testing.T) {
+		testQueries, leaver := repository.SetupTestDBWithUser(t)
+		stayer, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{Name: "stayer", Email: "stayer@example.com", Password: "password"})
+		if err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		userRepo := repository.NewUserRepository(testQueries)
+		eventRepo := repository.NewEventRepository(testQueries)
+		swapRepo := repository.NewSwapRequestRepository(testQueries)
+		tokenRepo := repository.NewAccessTokenRepository(testQueries)
+		auditRepo := repository.NewAuditLogRepository(testQueries)
+		notificationRepo := repository.NewNotificationRepository(testQueries)
+		transactor := repository.NewTransactor(testQueries)
+		userService := NewUserService(userRepo, eventRepo, swapRepo, tokenRepo, auditRepo, notificationRepo, transactor, crypto.NewPassword())
+		eventService := NewEventService(eventRepo, userRepo, swapRepo, auditRepo, notificationRepo, transactor)
+		swapService := NewSwapRequestService(swapRepo, eventRepo, userRepo, auditRepo, notificationRepo, transactor)
+		accessTokenService := NewAccessTokenService(tokenRepo, auditRepo, transactor)
+
+		start := time.Now().Add(time.Hour)
+		createEvent := func(userID int64, status string) *db.Event {
+			event, err := eventService.CreateEvent(context.Background(), CreateEventInput{Title: "Shift", StartTime: start, EndTime: start.Add(time.Hour), Status: status, UserID: userID, AllowOverlap: true})
+			if err != nil {
+				t.Fatalf("failed to create event: %v", err)
+			}
+			return event
+		}
+		leaverSlot := createEvent(leaver.ID, "SWAPPABLE")
+		stayerSlot := createEvent(stayer.ID, "SWAPPABLE")
+		onOffer := createEvent(leaver.ID, "SWAPPABLE")
+		swap, err := swapService.CreateSwapRequest(context.Background(), CreateSwapRequestInput{RequesterUserID: stayer.ID, ResponderUserID: leaver.ID, RequesterSlotID: stayerSlot.ID, ResponderSlotID: leaverSlot.ID})
+		if err != nil {
+			t.Fatalf("failed to create swap request: %v", err)
+		}
+		token, err := accessTokenService.CreateAccessToken(context.Background(), CreateAccessTokenInput{UserID: leaver.ID, Name: "script", Scopes: []string{ScopeEventsRead}})
+		if err != nil {
+			t.Fatalf("failed to create access token: %v", err)
+		}
+
+		if err := userService.DeactivateAccount(context.Background(), leaver.ID); err != nil {
+			t.Fatalf("failed to deactivate account: %v", err)
+		}
+
+		cancelled, err := swapRepo.GetSwapRequestByID(context.Background(), swap.ID)
+		if err != nil {
+			t.Fatalf("failed to get swap request: %v", err)
+		}
+		if cancelled.Status != "CANCELLED" || cancelled.CancelReason == nil || *cancelled.CancelReason != CancelReasonUserDeactivated {
+			t.Errorf("expected the swap request to be cancelled as %s, got %s %v", CancelReasonUserDeactivated, cancelled.Status, cancelled.CancelReason)
+		}
+		for id, want := range map[int64]string{stayerSlot.ID: "SWAPPABLE", leaverSlot.ID: "BUSY", onOffer.ID: "BUSY"} {
+			event, err := eventRepo.GetEventByID(context.Background(), id)
+			if err != nil {
+				t.Fatalf("failed to get event: %v", err)
+			}
+			if event.Status != want {
+				t.Errorf("expected event %d to be %s, got %s", id, want, event.Status)
+			}
+		}
+
+		notifications, err := notificationRepo.ListNotificationsByUserID(context.Background(), db.ListNotificationsByUserIDParams{UserID: stayer.ID, BeforeID: math.MaxInt64, Limit: 10})
+		if err != nil {
+			t.Fatalf("failed to list notifications: %v", err)
+		}
+		if len(notifications) != 1 || notifications[0].Reason != CancelReasonUserDeactivated {
+			t.Errorf("expected the other user to be notified, got %+v", notifications)
+		}
+
+		if _, err := accessTokenService.Authenticate(context.Background(), token.Token, ScopeEventsRead); !errors.Is(err, ErrAccessTokenInvalid) {
+			t.Errorf("expected the access token to be revoked, got %v", err)
+		}
+		if _, err := userService.GetUserByID(context.Background(), leaver.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected a deactivated user to be gone, got %v", err)
+		}
+		if err := userService.DeactivateAccount(context.Background(), leaver.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected a second deactivation to fail with not found, got %v", err)
+		}
+	})
 }
@@ -0,0 +1,77 @@
+// Package tracing sets up OpenTelemetry tracing and starts the spans that
+// follow a request from the HTTP layer through the services down to each
+// repository call.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const serviceName = "slotswapper"
+
+// tracer goes through the global provider, so spans started before Setup
+// runs, or without it, are no-ops.
+var tracer = otel.Tracer("slotswapper")
+
+// Setup installs the W3C trace context propagator and a tracer provider
+// sending spans to exporter: "stdout" writes them to w as JSON, "otlp"
+// sends them over OTLP/HTTP to endpoint or, when endpoint is empty, to the
+// OTEL_EXPORTER_OTLP_* environment settings, and "" or "none" disables
+// tracing. The returned function flushes pending spans.
+func Setup(ctx context.Context, w io.Writer, exporter, endpoint string) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var spanExporter sdktrace.SpanExporter
+	switch strings.ToLower(exporter) {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	case "otlp":
+		var opts []otlptracehttp.Option
+		if endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		spanExporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("invalid tracing exporter %q: expected stdout, otlp or none", exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start starts a span named name as a child of the span in ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, opts...)
+}
+
+// End marks span as failed when err is not nil, ends it and returns err.
+func End(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	return err
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	t.Run("stdout", func(t *testing.T) {
+		var buf bytes.Buffer
+		shutdown, err := Setup(context.Background(), &buf, "stdout", "")
+		if err != nil {
+			t.Fatalf("Setup: %v", err)
+		}
+		_, span := Start(context.Background(), "EventService.CreateEvent")
+		End(span, errors.New("boom"))
+		if err := shutdown(context.Background()); err != nil {
+			t.Fatalf("shutdown: %v", err)
+		}
+
+		var exported struct {
+			Name   string
+			Status struct{ Code string }
+		}
+		if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+			t.Fatalf("expected a JSON span, got %q: %v", buf.String(), err)
+		}
+		if exported.Name != "EventService.CreateEvent" || exported.Status.Code != codes.Error.String() {
+			t.Errorf("unexpected span %+v", exported)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), nil, "", "")
+		if err != nil || shutdown(context.Background()) != nil {
+			t.Errorf("expected tracing to be off without an exporter, got %v", err)
+		}
+	})
+
+	t.Run("invalid exporter", func(t *testing.T) {
+		if _, err := Setup(context.Background(), nil, "zipkin", ""); err == nil {
+			t.Error("expected an error for an unknown exporter")
+		}
+	})
+}
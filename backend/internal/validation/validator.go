@@ -1,7 +1,24 @@
 package validation
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 )
 
-var Validate = validator.New()
+var Validate = newValidator()
+
+// newValidator reports fields by their JSON names, which are the names API
+// clients see. Fields without one, or hidden from JSON, keep their Go name.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
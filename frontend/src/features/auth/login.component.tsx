@@ -3,6 +3,7 @@ import { useState, useId } from "react";
 import { z } from "zod";
 import { useMutation } from "@tanstack/react-query";
 import { useAuthStore } from "@/features/auth/auth.store.ts";
+import { problemMessage } from "@/lib/problem.ts";
 import type { TreeifyError } from "@/lib/types.ts";
 import { Button } from "@/components/ui/button.tsx";
 import {
@@ -36,8 +37,7 @@ async function loginUser(values: LoginSchema) {
 	});
 
 	if (!res.ok) {
-		const error = await res.text();
-		throw new Error(error || "Login failed");
+		throw new Error(await problemMessage(res, "Login failed"));
 	}
 
 	return res.json();
@@ -3,6 +3,7 @@ import { useState, useId } from "react";
 import { z } from "zod";
 import { useMutation } from "@tanstack/react-query";
 import { useAuthStore } from "./auth.store";
+import { problemMessage } from "@/lib/problem.ts";
 import type { TreeifyError } from "@/lib/types.ts";
 import { Button } from "@/components/ui/button.tsx";
 import {
@@ -35,8 +36,7 @@ async function registerUser(values: RegisterSchema) {
 	);
 
 	if (!res.ok) {
-		const error = await res.text();
-		throw new Error(error || "Registration failed");
+		throw new Error(await problemMessage(res, "Registration failed"));
 	}
 
 	return res.json();
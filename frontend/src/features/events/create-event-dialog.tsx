@@ -23,7 +23,7 @@ const createEventSchema = z
 		title: z.string().min(1, "Title is required"),
 		start_time: z.string().datetime(),
 		end_time: z.string().datetime(),
-		status: z.enum(["BUSY", "SWAPPABLE", "SWAP_PENDING"]),
+		status: z.enum(["BUSY", "SWAPPABLE"]),
 	})
 	.refine((data) => new Date(data.end_time) > new Date(data.start_time), {
 		message: "End time must be after start time",
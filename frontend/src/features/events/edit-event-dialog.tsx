@@ -37,7 +37,7 @@ export function EditEventDialog({
 	mutation: UseMutationResult<
 		Event,
 		Error,
-		{ id: number; event: UpdateEventSchema },
+		{ id: number; version: number; event: UpdateEventSchema },
 		unknown
 	>;
 }) {
@@ -71,7 +71,11 @@ export function EditEventDialog({
 		};
 		const result = updateEventSchema.safeParse(updatedEvent);
 		if (result.success) {
-			mutation.mutate({ id: event.id, event: result.data });
+			mutation.mutate({
+				id: event.id,
+				version: event.version,
+				event: result.data,
+			});
 			setFormErrors(null);
 		} else {
 			setFormErrors(z.treeifyError(result.error));
@@ -4,4 +4,6 @@ export interface Event {
 	start_time: string;
 	end_time: string;
 	status: string;
+	// Changes on every write; sent back in If-Match.
+	version: number;
 }
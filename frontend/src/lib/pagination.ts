@@ -0,0 +1,26 @@
+// Envelope returned by the paginated list endpoints.
+export interface Page<T> {
+	items: T[];
+	next_cursor: string;
+}
+
+// Fetches every page of a list endpoint by following next_cursor.
+export async function fetchAllPages<T>(
+	url: string,
+	errorMessage: string,
+): Promise<T[]> {
+	const items: T[] = [];
+	const separator = url.includes("?") ? "&" : "?";
+	let cursor = "";
+	do {
+		const pageUrl = `${url}${separator}limit=100${cursor ? `&cursor=${encodeURIComponent(cursor)}` : ""}`;
+		const res = await fetch(pageUrl, { credentials: "include" });
+		if (!res.ok) {
+			throw new Error(errorMessage);
+		}
+		const page: Page<T> = await res.json();
+		items.push(...page.items);
+		cursor = page.next_cursor;
+	} while (cursor);
+	return items;
+}
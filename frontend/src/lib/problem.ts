@@ -0,0 +1,25 @@
+// RFC 9457 problem details returned by the API for every error response.
+export interface Problem {
+	type: string;
+	title: string;
+	status: number;
+	detail?: string;
+	code?: string;
+	errors?: { field: string; rule: string; message: string }[];
+}
+
+// Reads the most specific message from an error response.
+export async function problemMessage(
+	res: Response,
+	fallback: string,
+): Promise<string> {
+	try {
+		const problem: Problem = await res.json();
+		if (problem.errors?.length) {
+			return problem.errors.map((e) => e.message).join("; ");
+		}
+		return problem.detail || problem.title || fallback;
+	} catch {
+		return fallback;
+	}
+}
@@ -7,13 +7,15 @@ import { EditEventDialog } from "@/features/events/edit-event-dialog";
 import { z } from "zod";
 
 import type { Event } from "@/features/events/types";
+import { fetchAllPages } from "@/lib/pagination.ts";
+import { problemMessage } from "@/lib/problem.ts";
 
 const createEventSchema = z
 	.object({
 		title: z.string().min(1, "Title is required"),
 		start_time: z.iso.datetime(),
 		end_time: z.iso.datetime(),
-		status: z.enum(["BUSY", "SWAPPABLE", "SWAP_PENDING"]),
+		status: z.enum(["BUSY", "SWAPPABLE"]),
 	})
 	.refine((data) => new Date(data.end_time) > new Date(data.start_time), {
 		message: "End time must be after start time",
@@ -35,14 +37,10 @@ type UpdateEventSchema = z.infer<typeof updateEventSchema>;
 
 // API function to fetch events
 async function fetchEvents(): Promise<Event[]> {
-	const res = await fetch(
+	return fetchAllPages<Event>(
 		`${import.meta.env.VITE_HTTP_SERVER_URL}/api/events/user`,
-		{ credentials: "include" },
+		"Failed to fetch events",
 	);
-	if (!res.ok) {
-		throw new Error("Failed to fetch events");
-	}
-	return res.json();
 }
 
 // API function to create an event
@@ -62,63 +60,87 @@ async function createEvent(newEvent: CreateEventSchema): Promise<Event> {
 	return res.json();
 }
 
-// API function to update an event
+// Makes a write fail with 412 instead of overwriting a change made elsewhere,
+// e.g. in another tab, since the event was loaded.
+function ifMatch(version: number) {
+	return { "If-Match": `"${version}"` };
+}
+
+// API function to update an event. PATCH leaves the status alone; pending
+// swaps are only cancelled if the times change.
 async function updateEvent({
 	id,
+	version,
 	event,
 }: {
 	id: number;
+	version: number;
 	event: UpdateEventSchema;
 }): Promise<Event> {
 	const res = await fetch(
 		`${import.meta.env.VITE_HTTP_SERVER_URL}/api/events/${id}`,
 		{
-			method: "PUT",
-			headers: { "Content-Type": "application/json" },
-			body: JSON.stringify({ ...event, status: "BUSY" }),
+			method: "PATCH",
+			headers: {
+				"Content-Type": "application/merge-patch+json",
+				...ifMatch(version),
+			},
+			body: JSON.stringify(event),
 			credentials: "include",
 		},
 	);
 	if (!res.ok) {
-		throw new Error("Failed to update event");
+		throw new Error(await problemMessage(res, "Failed to update event"));
 	}
-	return res.json();
+	const { event: updated } = await res.json();
+	return updated;
 }
 
 // API function to update an event's status
 async function updateEventStatus({
 	id,
+	version,
 	status,
 }: {
 	id: number;
+	version: number;
 	status: string;
 }): Promise<Event> {
 	const res = await fetch(
 		`${import.meta.env.VITE_HTTP_SERVER_URL}/api/events/${id}/status`,
 		{
 			method: "POST",
-			headers: { "Content-Type": "application/json" },
+			headers: { "Content-Type": "application/json", ...ifMatch(version) },
 			body: JSON.stringify({ status }),
 			credentials: "include",
 		},
 	);
 	if (!res.ok) {
-		throw new Error("Failed to update event status");
+		throw new Error(
+			await problemMessage(res, "Failed to update event status"),
+		);
 	}
 	return res.json();
 }
 
 // API function to delete an event
-async function deleteEvent(id: number): Promise<void> {
+async function deleteEvent({
+	id,
+	version,
+}: {
+	id: number;
+	version: number;
+}): Promise<void> {
 	const res = await fetch(
 		`${import.meta.env.VITE_HTTP_SERVER_URL}/api/events/${id}`,
 		{
 			method: "DELETE",
+			headers: ifMatch(version),
 			credentials: "include",
 		},
 	);
 	if (!res.ok) {
-		throw new Error("Failed to delete event");
+		throw new Error(await problemMessage(res, "Failed to delete event"));
 	}
 }
 
@@ -147,21 +169,22 @@ function DashboardComponent() {
 
 	const updateEventMutation = useMutation({
 		mutationFn: updateEvent,
-		onSuccess: () => {
+		// Refetch after a failure too: a 412 means this tab is out of date.
+		onSettled: () => {
 			queryClient.invalidateQueries({ queryKey: ["events"] });
 		},
 	});
 
 	const updateEventStatusMutation = useMutation({
 		mutationFn: updateEventStatus,
-		onSuccess: () => {
+		onSettled: () => {
 			queryClient.invalidateQueries({ queryKey: ["events"] });
 		},
 	});
 
 	const deleteEventMutation = useMutation({
 		mutationFn: deleteEvent,
-		onSuccess: () => {
+		onSettled: () => {
 			queryClient.invalidateQueries({ queryKey: ["events"] });
 		},
 	});
@@ -200,6 +223,7 @@ function DashboardComponent() {
 									onClick={() =>
 										updateEventStatusMutation.mutate({
 											id: event.id,
+											version: event.version,
 											status: event.status === "BUSY" ? "SWAPPABLE" : "BUSY",
 										})
 									}
@@ -213,7 +237,12 @@ function DashboardComponent() {
 								<Button
 									size="sm"
 									variant="destructive"
-									onClick={() => deleteEventMutation.mutate(event.id)}
+									onClick={() =>
+										deleteEventMutation.mutate({
+											id: event.id,
+											version: event.version,
+										})
+									}
 									disabled={
 										deleteEventMutation.isPending ||
 										event.status === "SWAP_PENDING"
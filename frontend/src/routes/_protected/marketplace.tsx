@@ -17,6 +17,7 @@ import {
 	DialogClose,
 } from "@/components/ui/dialog.tsx";
 import { useState } from "react";
+import { fetchAllPages } from "@/lib/pagination.ts";
 
 // Define the SwappableEvent type based on the backend response
 interface SwappableEvent {
@@ -26,6 +27,7 @@ interface SwappableEvent {
 	end_time: string;
 	owner_name: string;
 	user_id: number;
+	giveaway: "FIRST_COME" | "OWNER_PICKS" | null;
 }
 
 // Define the type for the user's own swappable events
@@ -36,26 +38,18 @@ interface MySwappableEvent {
 
 // API function to fetch swappable events
 async function fetchSwappableEvents(): Promise<SwappableEvent[]> {
-	const res = await fetch(
+	return fetchAllPages<SwappableEvent>(
 		`${import.meta.env.VITE_HTTP_SERVER_URL}/api/swappable-slots`,
-		{ credentials: "include" },
+		"Failed to fetch swappable events",
 	);
-	if (!res.ok) {
-		throw new Error("Failed to fetch swappable events");
-	}
-	return res.json();
 }
 
 // API function to fetch the user's own swappable events
 async function fetchMySwappableEvents(): Promise<MySwappableEvent[]> {
-	const res = await fetch(
+	return fetchAllPages<MySwappableEvent>(
 		`${import.meta.env.VITE_HTTP_SERVER_URL}/api/events/user?status=SWAPPABLE`,
-		{ credentials: "include" },
+		"Failed to fetch your swappable events",
 	);
-	if (!res.ok) {
-		throw new Error("Failed to fetch your swappable events");
-	}
-	return res.json();
 }
 
 // API function to create a swap request
@@ -82,6 +76,20 @@ async function createSwapRequest(
 	}
 }
 
+// API function to claim a slot that is being given away
+async function claimSlot(eventId: number): Promise<void> {
+	const res = await fetch(
+		`${import.meta.env.VITE_HTTP_SERVER_URL}/api/events/${eventId}/claim`,
+		{
+			method: "POST",
+			credentials: "include",
+		},
+	);
+	if (!res.ok) {
+		throw new Error("Failed to claim slot");
+	}
+}
+
 export const Route = createFileRoute("/_protected/marketplace")({
 	component: MarketplaceComponent,
 });
@@ -112,14 +120,69 @@ function MarketplaceComponent() {
 			{isError && <div>Error fetching available slots.</div>}
 
 			<div className="grid gap-4 md:grid-cols-2 lg:grid-cols-3">
-				{events?.map((event) => (
-					<SwapRequestDialog key={event.id} event={event} myEvents={myEvents} />
-				))}
+				{events?.map((event) =>
+					event.giveaway ? (
+						<GiveawayCard key={event.id} event={event} />
+					) : (
+						<SwapRequestDialog
+							key={event.id}
+							event={event}
+							myEvents={myEvents}
+						/>
+					),
+				)}
 			</div>
 		</div>
 	);
 }
 
+function GiveawayCard({ event }: { event: SwappableEvent }) {
+	const queryClient = useQueryClient();
+
+	const mutation = useMutation({
+		mutationFn: () => claimSlot(event.id),
+		onSuccess: () => {
+			queryClient.invalidateQueries({ queryKey: ["swappable-events"] });
+			queryClient.invalidateQueries({ queryKey: ["outgoing-requests"] });
+			queryClient.invalidateQueries({ queryKey: ["events"] });
+		},
+	});
+
+	return (
+		<Card>
+			<CardHeader>
+				<CardTitle>{event.title}</CardTitle>
+			</CardHeader>
+			<CardContent className="grid gap-2">
+				<p className="text-sm">
+					<strong>Owner:</strong> {event.owner_name}
+				</p>
+				<p className="text-sm">
+					<strong>From:</strong> {new Date(event.start_time).toLocaleString()}
+				</p>
+				<p className="text-sm">
+					<strong>To:</strong> {new Date(event.end_time).toLocaleString()}
+				</p>
+				<p className="text-sm text-muted-foreground">
+					{event.giveaway === "FIRST_COME"
+						? "Free to the first taker."
+						: "Free; the owner picks among the claims."}
+				</p>
+				<Button
+					className="mt-4 w-full"
+					onClick={() => mutation.mutate()}
+					disabled={mutation.isPending || mutation.isSuccess}
+				>
+					{mutation.isSuccess ? "Claimed" : "Claim"}
+				</Button>
+				{mutation.isError && (
+					<p className="text-sm text-destructive">{mutation.error.message}</p>
+				)}
+			</CardContent>
+		</Card>
+	);
+}
+
 function SwapRequestDialog({
 	event,
 	myEvents,
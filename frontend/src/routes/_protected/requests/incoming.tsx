@@ -2,10 +2,12 @@ import { createFileRoute } from "@tanstack/react-router";
 import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
 import { Button } from "@/components/ui/button";
+import { fetchAllPages } from "@/lib/pagination.ts";
 
 // Define the types for the swap requests
 interface IncomingSwapRequest {
 	id: number;
+	kind: "SWAP" | "TRANSFER";
 	requester_name: string;
 	requester_event_title: string;
 	requester_event_start_time: string;
@@ -17,14 +19,10 @@ interface IncomingSwapRequest {
 
 // API functions
 async function fetchIncomingRequests(): Promise<IncomingSwapRequest[]> {
-	const res = await fetch(
+	return fetchAllPages<IncomingSwapRequest>(
 		`${import.meta.env.VITE_HTTP_SERVER_URL}/api/swap-requests/incoming`,
-		{ credentials: "include" },
+		"Failed to fetch incoming requests",
 	);
-	if (!res.ok) {
-		throw new Error("Failed to fetch incoming requests");
-	}
-	return res.json();
 }
 
 async function respondToSwapRequest(
@@ -87,16 +85,20 @@ function IncomingRequestsComponent() {
 				{incoming?.map((req) => (
 					<Card key={req.id}>
 						<CardHeader>
-							<CardTitle>Swap Request</CardTitle>
+							<CardTitle>
+								{req.kind === "TRANSFER" ? "Claim" : "Swap Request"}
+							</CardTitle>
 						</CardHeader>
 						<CardContent className="grid gap-2">
 							<p>
 								<strong>From:</strong> {req.requester_name}
 							</p>
-							<p>
-								<strong>Their Event:</strong> {req.requester_event_title} (
-								{new Date(req.requester_event_start_time).toLocaleString()})
-							</p>
+							{req.kind === "SWAP" && (
+								<p>
+									<strong>Their Event:</strong> {req.requester_event_title} (
+									{new Date(req.requester_event_start_time).toLocaleString()})
+								</p>
+							)}
 							<p>
 								<strong>Your Event:</strong> {req.responder_event_title} (
 								{new Date(req.responder_event_start_time).toLocaleString()})
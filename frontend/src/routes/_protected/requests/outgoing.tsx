@@ -2,10 +2,12 @@ import { createFileRoute } from "@tanstack/react-router";
 import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
 import { Button } from "@/components/ui/button";
+import { fetchAllPages } from "@/lib/pagination.ts";
 
 // Define the types for the swap requests
 interface OutgoingSwapRequest {
 	id: number;
+	kind: "SWAP" | "TRANSFER";
 	responder_name: string;
 	requester_event_title: string;
 	requester_event_start_time: string;
@@ -17,31 +19,22 @@ interface OutgoingSwapRequest {
 
 // API functions
 async function fetchOutgoingRequests(): Promise<OutgoingSwapRequest[]> {
-	const res = await fetch(
+	return fetchAllPages<OutgoingSwapRequest>(
 		`${import.meta.env.VITE_HTTP_SERVER_URL}/api/swap-requests/outgoing`,
-		{ credentials: "include" },
+		"Failed to fetch outgoing requests",
 	);
-	if (!res.ok) {
-		throw new Error("Failed to fetch outgoing requests");
-	}
-	return res.json();
 }
 
-async function respondToSwapRequest(
-	id: number,
-	status: "ACCEPTED" | "REJECTED",
-): Promise<void> {
+async function withdrawSwapRequest(id: number): Promise<void> {
 	const res = await fetch(
-		`${import.meta.env.VITE_HTTP_SERVER_URL}/api/swap-response/${id}`,
+		`${import.meta.env.VITE_HTTP_SERVER_URL}/api/swap-requests/${id}`,
 		{
-			method: "POST",
-			headers: { "Content-Type": "application/json" },
-			body: JSON.stringify({ status }),
+			method: "DELETE",
 			credentials: "include",
 		},
 	);
 	if (!res.ok) {
-		throw new Error("Failed to respond to swap request");
+		throw new Error("Failed to withdraw swap request");
 	}
 }
 
@@ -60,13 +53,7 @@ function OutgoingRequestsComponent() {
 	});
 
 	const mutation = useMutation({
-		mutationFn: ({
-			id,
-			status,
-		}: {
-			id: number;
-			status: "ACCEPTED" | "REJECTED";
-		}) => respondToSwapRequest(id, status),
+		mutationFn: (id: number) => withdrawSwapRequest(id),
 		onSuccess: () => {
 			queryClient.invalidateQueries({ queryKey: ["incoming-requests"] });
 			queryClient.invalidateQueries({ queryKey: ["outgoing-requests"] });
@@ -87,16 +74,20 @@ function OutgoingRequestsComponent() {
 				{outgoing?.map((req) => (
 					<Card key={req.id}>
 						<CardHeader>
-							<CardTitle>Swap Request</CardTitle>
+							<CardTitle>
+								{req.kind === "TRANSFER" ? "Claim" : "Swap Request"}
+							</CardTitle>
 						</CardHeader>
 						<CardContent className="grid gap-2">
 							<p>
 								<strong>To:</strong> {req.responder_name}
 							</p>
-							<p>
-								<strong>Your Event:</strong> {req.requester_event_title} (
-								{new Date(req.requester_event_start_time).toLocaleString()})
-							</p>
+							{req.kind === "SWAP" && (
+								<p>
+									<strong>Your Event:</strong> {req.requester_event_title} (
+									{new Date(req.requester_event_start_time).toLocaleString()})
+								</p>
+							)}
 							<p>
 								<strong>Their Event:</strong> {req.responder_event_title} (
 								{new Date(req.responder_event_start_time).toLocaleString()})
@@ -107,11 +98,9 @@ function OutgoingRequestsComponent() {
 							<Button
 								size="sm"
 								variant="destructive"
-								onClick={() =>
-									mutation.mutate({ id: req.id, status: "REJECTED" })
-								}
+								onClick={() => mutation.mutate(req.id)}
 							>
-								Cancel
+								Withdraw
 							</Button>
 						</CardContent>
 					</Card>